@@ -0,0 +1,84 @@
+package charset
+
+// The Arabic presentation forms, which some producers use in place
+// of the letters themselves, and the letters they stand for.
+// Character sets such as windows-1256 hold only the letters,
+// leaving the choice of form to the renderer, so the code page
+// encoders translate each form to its letters instead of
+// treating it as unmappable. Text is translated in logical
+// order either way; no bidirectional reordering is done.
+var arabicForms = []struct {
+	forms, letters string
+}{
+	{"\ufb56\ufb57\ufb58\ufb59", "\u067e"}, // peh
+	{"\ufb66\ufb67\ufb68\ufb69", "\u0679"}, // tteh
+	{"\ufb7a\ufb7b\ufb7c\ufb7d", "\u0686"}, // tcheh
+	{"\ufb88\ufb89", "\u0688"},             // ddal
+	{"\ufb8a\ufb8b", "\u0698"},             // jeh
+	{"\ufb8c\ufb8d", "\u0691"},             // rreh
+	{"\ufb8e\ufb8f\ufb90\ufb91", "\u06a9"}, // keheh
+	{"\ufb92\ufb93\ufb94\ufb95", "\u06af"}, // gaf
+	{"\ufb9e\ufb9f", "\u06ba"},             // noon ghunna
+	{"\ufba6\ufba7\ufba8\ufba9", "\u06c1"}, // heh goal
+	{"\ufbaa\ufbab\ufbac\ufbad", "\u06be"}, // heh doachashmee
+	{"\ufbae\ufbaf", "\u06d2"},             // yeh barree
+	{"\ufbe8\ufbe9\ufeef\ufef0", "\u0649"}, // alef maksura
+	{"\ufe80", "\u0621"},                   // hamza
+	{"\ufe81\ufe82", "\u0622"},             // alef with madda above
+	{"\ufe83\ufe84", "\u0623"},             // alef with hamza above
+	{"\ufe85\ufe86", "\u0624"},             // waw with hamza above
+	{"\ufe87\ufe88", "\u0625"},             // alef with hamza below
+	{"\ufe89\ufe8a\ufe8b\ufe8c", "\u0626"}, // yeh with hamza above
+	{"\ufe8d\ufe8e", "\u0627"},             // alef
+	{"\ufe8f\ufe90\ufe91\ufe92", "\u0628"}, // beh
+	{"\ufe93\ufe94", "\u0629"},             // teh marbuta
+	{"\ufe95\ufe96\ufe97\ufe98", "\u062a"}, // teh
+	{"\ufe99\ufe9a\ufe9b\ufe9c", "\u062b"}, // theh
+	{"\ufe9d\ufe9e\ufe9f\ufea0", "\u062c"}, // jeem
+	{"\ufea1\ufea2\ufea3\ufea4", "\u062d"}, // hah
+	{"\ufea5\ufea6\ufea7\ufea8", "\u062e"}, // khah
+	{"\ufea9\ufeaa", "\u062f"},             // dal
+	{"\ufeab\ufeac", "\u0630"},             // thal
+	{"\ufead\ufeae", "\u0631"},             // reh
+	{"\ufeaf\ufeb0", "\u0632"},             // zain
+	{"\ufeb1\ufeb2\ufeb3\ufeb4", "\u0633"}, // seen
+	{"\ufeb5\ufeb6\ufeb7\ufeb8", "\u0634"}, // sheen
+	{"\ufeb9\ufeba\ufebb\ufebc", "\u0635"}, // sad
+	{"\ufebd\ufebe\ufebf\ufec0", "\u0636"}, // dad
+	{"\ufec1\ufec2\ufec3\ufec4", "\u0637"}, // tah
+	{"\ufec5\ufec6\ufec7\ufec8", "\u0638"}, // zah
+	{"\ufec9\ufeca\ufecb\ufecc", "\u0639"}, // ain
+	{"\ufecd\ufece\ufecf\ufed0", "\u063a"}, // ghain
+	{"\ufed1\ufed2\ufed3\ufed4", "\u0641"}, // feh
+	{"\ufed5\ufed6\ufed7\ufed8", "\u0642"}, // qaf
+	{"\ufed9\ufeda\ufedb\ufedc", "\u0643"}, // kaf
+	{"\ufedd\ufede\ufedf\ufee0", "\u0644"}, // lam
+	{"\ufee1\ufee2\ufee3\ufee4", "\u0645"}, // meem
+	{"\ufee5\ufee6\ufee7\ufee8", "\u0646"}, // noon
+	{"\ufee9\ufeea\ufeeb\ufeec", "\u0647"}, // heh
+	{"\ufeed\ufeee", "\u0648"},             // waw
+	{"\ufef1\ufef2\ufef3\ufef4", "\u064a"}, // yeh
+	{"\ufef5\ufef6", "\u0644\u0622"},       // lam alef with madda above
+	{"\ufef7\ufef8", "\u0644\u0623"},       // lam alef with hamza above
+	{"\ufef9\ufefa", "\u0644\u0625"},       // lam alef with hamza below
+	{"\ufefb\ufefc", "\u0644\u0627"},       // lam alef
+}
+
+var arabicFormMap = makeArabicFormMap()
+
+func makeArabicFormMap() map[rune]string {
+	m := make(map[rune]string)
+	for _, f := range arabicForms {
+		for _, r := range f.forms {
+			m[r] = f.letters
+		}
+	}
+	return m
+}
+
+// arabicLetters returns the letters for which r
+// is a presentation form, or false if it is not one.
+func arabicLetters(r rune) (string, bool) {
+	s, ok := arabicFormMap[r]
+	return s, ok
+}
@@ -0,0 +1,86 @@
+package charset
+
+import (
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("ascii", fromASCII, toASCII)
+}
+
+type translateFromASCII struct {
+	strict  bool
+	scratch []byte
+}
+
+func (p *translateFromASCII) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromASCII) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = ensureCap(p.scratch, len(data))[:0]
+	for i, b := range data {
+		if b < utf8.RuneSelf {
+			p.scratch = append(p.scratch, b)
+			continue
+		}
+		if p.strict {
+			return i, p.scratch, &InvalidByteError{Offset: i, Byte: b}
+		}
+		p.scratch = append(p.scratch, errorBytes...)
+	}
+	return len(data), p.scratch, nil
+}
+
+type translateToASCII struct {
+	strict  bool
+	scratch []byte
+}
+
+func (p *translateToASCII) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToASCII) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = ensureCap(p.scratch, len(data))[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		if p.strict {
+			return n, p.scratch, strictError(n, r, size)
+		}
+		p.scratch = append(p.scratch, '?')
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+// fromASCII decodes bytes outside ASCII as utf8.RuneError,
+// or with the "strict" option, stops with an *InvalidByteError
+// at the first of them.
+func fromASCII(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "strict")
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromASCII{strict: opts.has("strict")}, nil
+}
+
+// toASCII accepts the "strict" option, as for code pages.
+func toASCII(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "strict")
+	if err != nil {
+		return nil, err
+	}
+	return &translateToASCII{strict: opts.has("strict")}, nil
+}
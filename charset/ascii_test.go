@@ -0,0 +1,34 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+func TestASCII(t *testing.T) {
+	bad := string(utf8.RuneError)
+	if out := decodeString(t, "us-ascii", "ab\x80c\xff"); out != "ab"+bad+"c"+bad {
+		t.Errorf("expected %q got %q", "ab"+bad+"c"+bad, out)
+	}
+	if out := encodeString(t, "ascii", "abécd"); out != "ab?cd" {
+		t.Errorf("expected %q got %q", "ab?cd", out)
+	}
+
+	tr, err := charset.TranslatorFrom("us-ascii?strict")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if n, cdata, err := tr.Translate([]byte("plain"), true); n != 5 || string(cdata) != "plain" || err != nil {
+		t.Errorf("expected 5, %q, nil; got %d, %q, %v", "plain", n, cdata, err)
+	}
+	n, cdata, err := tr.Translate([]byte("abc\xe9d\x80"), true)
+	berr, ok := err.(*charset.InvalidByteError)
+	if !ok {
+		t.Fatalf("expected *InvalidByteError, got %v", err)
+	}
+	if berr.Offset != 3 || berr.Byte != 0xe9 || n != 3 || string(cdata) != "abc" {
+		t.Errorf("expected offset 3, byte e9, 3, %q; got %d, %x, %d, %q", "abc", berr.Offset, berr.Byte, n, cdata)
+	}
+}
@@ -0,0 +1,37 @@
+package charset
+
+// bestFitCp949Folds gives characters that cp949 lacks and
+// similar ones that it has, after the best-fit table that
+// Windows uses for code page 949.
+var bestFitCp949Folds = []struct {
+	from, to string
+}{
+	{"\u00a0\u2002\u2003\u2009", " "},
+	{"‐‑‒–−", "-"},
+	{"—", "―"},
+	{"‚", ","},
+	{"„", "\""},
+	{"‹", "<"},
+	{"›", ">"},
+	{"¦", "|"},
+	{"∕", "/"},
+	{"«", "《"},
+	{"»", "》"},
+	{"•・⋅", "·"},
+	{"µ", "μ"},
+	{"〜", "～"},
+	{"¢", "￠"},
+	{"£", "￡"},
+	{"¥", "￥"},
+	{"¬", "￢"},
+	{"₩", "￦"},
+}
+
+var bestFitCp949Map = makeFoldMap(bestFitCp949Folds)
+
+// bestFitCp949 returns a character similar to r that cp949 has,
+// or false if there is none.
+func bestFitCp949(r rune) (string, bool) {
+	s, ok := bestFitCp949Map[r]
+	return s, ok
+}
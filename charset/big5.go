@@ -2,11 +2,13 @@ package charset
 
 import (
 	"fmt"
+	"sort"
 	"unicode/utf8"
 )
 
 func init() {
 	registerClass("big5", fromBig5, nil)
+	registerClass("big5-hkscs", fromBig5HKSCS, toBig5HKSCS)
 }
 
 // Big5 consists of 89 fonts of 157 chars each
@@ -16,10 +18,23 @@ const (
 	big5Data = "big5.dat"
 )
 
+// The Hong Kong Supplementary Character Set adds fonts 87..a0
+// and overrides some Big5 codes. Its codes are held in hkscs.dat,
+// which holds only those codes that differ from big5.dat.
+// Some of them map to runes outside the Basic Multilingual Plane.
+const hkscsData = "hkscs.dat"
+
 type translateFromBig5 struct {
-	font    int
-	scratch []byte
-	big5map []rune
+	font        int
+	scratch     []byte
+	big5map     []rune
+	hkscs       cp949Table // overrides sorted by native code, or nil for plain big5
+	replacement []byte     // substituted for untranslatable bytes
+}
+
+func (p *translateFromBig5) Reset() {
+	p.font = -1
+	p.scratch = p.scratch[:0]
 }
 
 func (p *translateFromBig5) Translate(data []byte, eof bool) (int, []byte, error) {
@@ -27,32 +42,43 @@ func (p *translateFromBig5) Translate(data []byte, eof bool) (int, []byte, error
 	n := 0
 	for len(data) > 0 {
 		c := int(data[0])
+		if p.font != -1 && !isBig5Trail(c) {
+			// A byte that cannot trail the lead byte,
+			// such as a newline, is decoded afresh.
+			p.font = -1
+			p.scratch = append(p.scratch, p.replacement...)
+		}
 		data = data[1:]
 		n++
 		if p.font == -1 {
 			// idle state
-			if c >= 0xa1 {
+			if c >= 0xa1 || p.hkscs != nil && c >= 0x87 && c != 0xff {
 				p.font = c
 				continue
 			}
 			if c == 26 {
 				c = '\n'
 			}
+			r := rune(c)
+			if c >= 0x80 {
+				r = utf8.RuneError
+			}
+			p.scratch = appendDecoded(p.scratch, r, p.replacement)
 			continue
 		}
 		f := p.font
 		p.font = -1
+		if r, ok := p.lookupHKSCS(f, c); ok {
+			p.scratch = appendRune(p.scratch, r)
+			continue
+		}
 		r := utf8.RuneError
-		switch {
-		case c >= 64 && c <= 126:
+		if c <= 126 {
 			c -= 64
-		case c >= 161 && c <= 254:
+		} else {
 			c = c - 161 + 63
-		default:
-			// bad big5 char
-			f = 255
 		}
-		if f <= 254 {
+		if f >= 161 && f <= 254 {
 			f -= 161
 			ix := f*big5Font + c
 			if ix < len(p.big5map) {
@@ -62,14 +88,88 @@ func (p *translateFromBig5) Translate(data []byte, eof bool) (int, []byte, error
 				r = utf8.RuneError
 			}
 		}
-		p.scratch = appendRune(p.scratch, r)
+		p.scratch = appendDecoded(p.scratch, r, p.replacement)
+	}
+	if eof && p.font != -1 {
+		// A lead byte with nothing after it.
+		p.font = -1
+		p.scratch = append(p.scratch, p.replacement...)
+	}
+	return n, p.scratch, nil
+}
+
+// isBig5Trail reports whether c can be the second byte of a code.
+func isBig5Trail(c int) bool {
+	return c >= 0x40 && c <= 0x7e || c >= 0xa1 && c <= 0xfe
+}
+
+func (p *translateFromBig5) lookupHKSCS(f, c int) (rune, bool) {
+	t := p.hkscs
+	code := uint16(f)<<8 | uint16(c)
+	i := sort.Search(len(t), func(i int) bool {
+		return code <= t[i].native
+	})
+	if i < len(t) && t[i].native == code {
+		return t[i].unicode, true
+	}
+	return 0, false
+}
+
+type translateToBig5 struct {
+	rune2code   map[rune]uint16
+	replacement []byte
+	strict      bool
+	scratch     []byte
+}
+
+func (p *translateToBig5) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToBig5) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		code, ok := p.rune2code[r]
+		switch {
+		case ok:
+			p.scratch = append(p.scratch, byte(code>>8), byte(code))
+		case p.strict:
+			return n, p.scratch, strictError(n, r, size)
+		default:
+			p.scratch = append(p.scratch, p.replacement...)
+		}
+		data = data[size:]
+		n += size
 	}
 	return n, p.scratch, nil
 }
 
 type big5Key bool
+type hkscsKey bool
+type hkscsKeyTo bool
 
+// fromBig5 accepts the same "replacement" and "drop"
+// options as fromCp949.
 func fromBig5(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
 	big5map, err := cache(big5Key(false), func() (interface{}, error) {
 		data, err := readFile(big5Data)
 		if err != nil {
@@ -84,5 +184,85 @@ func fromBig5(arg string) (Translator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &translateFromBig5{big5map: big5map.([]rune), font: -1}, nil
+	return &translateFromBig5{big5map: big5map.([]rune), font: -1, replacement: repl}, nil
+}
+
+func fromBig5HKSCS(arg string) (Translator, error) {
+	tr, err := fromBig5(arg)
+	if err != nil {
+		return nil, err
+	}
+	hkscs, err := getHKSCSTable()
+	if err != nil {
+		return nil, err
+	}
+	tr.(*translateFromBig5).hkscs = hkscs
+	return tr, nil
+}
+
+// toBig5HKSCS accepts the same "replacement", "drop" and "strict"
+// options as toCp949.
+func toBig5HKSCS(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop", "strict")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := fromBig5HKSCS("")
+	if err != nil {
+		return nil, err
+	}
+	from := tr.(*translateFromBig5)
+	m, err := cache(hkscsKeyTo(true), func() (interface{}, error) {
+		// Codes in the Big5 fonts are preferred to those
+		// in the HKSCS fonts where both have a character.
+		m := make(map[rune]uint16)
+		add := func(f, c int) {
+			from.font = -1
+			_, out, _ := from.Translate([]byte{byte(f), byte(c)}, true)
+			r, _ := utf8.DecodeRune(out)
+			if _, ok := m[r]; !ok && r != utf8.RuneError {
+				m[r] = uint16(f)<<8 | uint16(c)
+			}
+		}
+		for _, leads := range [][2]int{{0xa1, 0xfe}, {0x87, 0xa0}} {
+			for f := leads[0]; f <= leads[1]; f++ {
+				for c := 0x40; c <= 0x7e; c++ {
+					add(f, c)
+				}
+				for c := 0xa1; c <= 0xfe; c++ {
+					add(f, c)
+				}
+			}
+		}
+		return m, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &translateToBig5{
+		rune2code:   m.(map[rune]uint16),
+		replacement: repl,
+		strict:      opts.has("strict"),
+	}, nil
+}
+
+func getHKSCSTable() (cp949Table, error) {
+	t, err := cache(hkscsKey(true), func() (interface{}, error) {
+		t, err := loadCodeTable(hkscsData)
+		if err != nil {
+			return nil, err
+		}
+		if !sort.IsSorted(cp949TableSortByNative{t}) {
+			panic("hkscs.dat is not sorted by native code!")
+		}
+		return t, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.(cp949Table), nil
 }
@@ -0,0 +1,33 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+var hkscsTests = []struct {
+	native  string
+	unicode string
+}{
+	{"\x87\x45", "\U00027267"},
+	{"\x87\x50", "\U000242bf"},
+	{"\x9d\xbb", "\U00025e81"},
+	{"\xfe\x56", "䣭"},
+	{"\x88\x40", "㇀"},
+	{"\xa1\x40", "　"},
+}
+
+func TestBig5HKSCS(t *testing.T) {
+	for _, test := range hkscsTests {
+		if out := decodeString(t, "big5-hkscs", test.native); out != test.unicode {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "big5-hkscs", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.unicode, test.native, out)
+		}
+	}
+	// The HKSCS fonts are not part of plain big5.
+	if out := decodeString(t, "big5", "\x87\x45"); out != string([]rune{utf8.RuneError, 'E'}) {
+		t.Errorf("big5 decoded HKSCS code as %q", out)
+	}
+}
@@ -0,0 +1,75 @@
+package charset
+
+import (
+	"bytes"
+)
+
+func init() {
+	registerTransform("strip-bom", newStripBOM, newStripBOM)
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// The byte order marks, in the order they must be checked;
+// the UTF-32LE mark starts with the UTF-16LE mark.
+var boms = []struct {
+	charset string
+	bom     []byte
+}{
+	{"utf-8", utf8BOM},
+	{"utf-32le", []byte("\xff\xfe\x00\x00")},
+	{"utf-32be", []byte("\x00\x00\xfe\xff")},
+	{"utf-16le", []byte("\xff\xfe")},
+	{"utf-16be", []byte("\xfe\xff")},
+}
+
+// DetectBOM looks for a Unicode byte order mark at the start of data.
+// If it finds one, it returns the name of the character set
+// it indicates and the length of the mark; otherwise
+// it returns "" and 0.
+func DetectBOM(data []byte) (charset string, bomLen int) {
+	for _, b := range boms {
+		if bytes.HasPrefix(data, b.bom) {
+			return b.charset, len(b.bom)
+		}
+	}
+	return "", 0
+}
+
+// translateStripBOM passes its input through unchanged,
+// except for a UTF-8 byte order mark at the very start,
+// which it removes.
+type translateStripBOM struct {
+	first   bool // at the start of the stream
+	scratch []byte
+}
+
+func (p *translateStripBOM) Reset() {
+	p.first = true
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateStripBOM) Translate(data []byte, eof bool) (int, []byte, error) {
+	n := 0
+	if p.first {
+		if len(data) < len(utf8BOM) && !eof && bytes.HasPrefix(utf8BOM, data) {
+			// This may be the start of a byte order mark.
+			return 0, nil, nil
+		}
+		if bytes.HasPrefix(data, utf8BOM) {
+			n = len(utf8BOM)
+		}
+		p.first = false
+	}
+	p.scratch = append(p.scratch[:0], data[n:]...)
+	return len(data), p.scratch, nil
+}
+
+// factory to create translateStripBOM, which translates
+// the same way in both directions.
+func newStripBOM(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	return &translateStripBOM{first: true}, nil
+}
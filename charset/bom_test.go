@@ -0,0 +1,108 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var detectBOMTests = []struct {
+	in      string
+	charset string
+	bomLen  int
+}{
+	{"\xef\xbb\xbfabc", "utf-8", 3},
+	{"\xff\xfea\x00", "utf-16le", 2},
+	{"\xfe\xff\x00a", "utf-16be", 2},
+	{"\xff\xfe\x00\x00a\x00\x00\x00", "utf-32le", 4},
+	{"\x00\x00\xfe\xff\x00\x00\x00a", "utf-32be", 4},
+	{"abc", "", 0},
+	{"\xef\xbb", "", 0},
+	{"", "", 0},
+}
+
+func TestDetectBOM(t *testing.T) {
+	for _, test := range detectBOMTests {
+		cs, n := charset.DetectBOM([]byte(test.in))
+		if cs != test.charset || n != test.bomLen {
+			t.Errorf("%x: expected %q, %d; got %q, %d", test.in, test.charset, test.bomLen, cs, n)
+		}
+	}
+}
+
+var bomTests = []struct {
+	charset string
+	in      string
+	out     string
+}{
+	{"utf-8", "\xef\xbb\xbfabc", "abc"},
+	{"utf-8", "abc\xef\xbb\xbf", "abc\ufeff"},
+	{"utf-16", "\xff\xfea\x00b\x00", "ab"},
+	{"utf-16", "\xfe\xff\x00a\x00b", "ab"},
+	{"utf-16", "a\x00b\x00", "ab"},
+	{"utf-16le", "\xff\xfea\x00", "a"},
+	{"utf-16be", "\xfe\xff\x00a", "a"},
+	{"utf-16le", "a\x00", "a"},
+	{"utf-32", "\xff\xfe\x00\x00a\x00\x00\x00", "a"},
+	{"utf-32le", "\xff\xfe\x00\x00a\x00\x00\x00", "a"},
+	{"utf-32be", "\x00\x00\xfe\xff\x00\x00\x00a", "a"},
+	{"utf-32be", "\x00\x00\x00a", "a"},
+}
+
+func TestBOM(t *testing.T) {
+	for _, test := range bomTests {
+		if out := decodeString(t, test.charset, test.in); out != test.out {
+			t.Errorf("%s: decoding %x: expected %q got %q", test.charset, test.in, test.out, out)
+		}
+	}
+}
+
+func TestUTF8BOMSplit(t *testing.T) {
+	tr, err := charset.TranslatorFrom("utf-8")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	n, cdata, err := tr.Translate([]byte("\xef\xbb"), false)
+	if n != 0 || len(cdata) != 0 || err != nil {
+		t.Fatalf("expected nothing, got %d, %q, %v", n, cdata, err)
+	}
+	n, cdata, err = tr.Translate([]byte("\xef\xbb\xbfa"), true)
+	if n != 4 || string(cdata) != "a" || err != nil {
+		t.Fatalf("expected 4, %q; got %d, %q, %v", "a", n, cdata, err)
+	}
+}
+
+var stripBOMTests = []struct {
+	chunks []string
+	out    string
+}{
+	{[]string{"\xef\xbb\xbfabc"}, "abc"},
+	{[]string{"\xef", "\xbb\xbfabc"}, "abc"},
+	{[]string{"\xef\xbb", "\xbfa", "\xef\xbb\xbf"}, "a\xef\xbb\xbf"},
+	{[]string{"abc\xef\xbb\xbf"}, "abc\xef\xbb\xbf"},
+	{[]string{"\xef", "\xbbx"}, "\xef\xbbx"},
+	{[]string{"\xef\xbb"}, "\xef\xbb"},
+	{[]string{"\xff\xfea\x00"}, "\xff\xfea\x00"},
+}
+
+func TestStripBOM(t *testing.T) {
+	for _, test := range stripBOMTests {
+		tr, err := charset.TranslatorFrom("strip-bom")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		var out, pending []byte
+		for i, chunk := range test.chunks {
+			pending = append(pending, chunk...)
+			n, cdata, err := tr.Translate(pending, i == len(test.chunks)-1)
+			if err != nil {
+				t.Fatalf("%q: translate error: %v", test.chunks, err)
+			}
+			out = append(out, cdata...)
+			pending = pending[n:]
+		}
+		if string(out) != test.out || len(pending) != 0 {
+			t.Errorf("%q: expected %q, got %q with %q left", test.chunks, test.out, out, pending)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package charset
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCacheKey int
+
+func TestCacheConcurrent(t *testing.T) {
+	var loads int32
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(10 * time.Millisecond)
+		return []int{1, 2, 3}, nil
+	}
+	var wg sync.WaitGroup
+	results := make([]interface{}, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			x, err := cache(testCacheKey(1), load)
+			if err != nil {
+				t.Errorf("cache failed: %v", err)
+			}
+			results[i] = x
+		}(i)
+	}
+	wg.Wait()
+	if loads != 1 {
+		t.Errorf("expected 1 load, got %d", loads)
+	}
+	for i, x := range results {
+		if x == nil || &x.([]int)[0] != &results[0].([]int)[0] {
+			t.Errorf("goroutine %d: result not shared", i)
+		}
+	}
+}
+
+func TestCacheError(t *testing.T) {
+	fail := true
+	load := func() (interface{}, error) {
+		if fail {
+			return nil, errors.New("load failed")
+		}
+		return "ok", nil
+	}
+	if _, err := cache(testCacheKey(2), load); err == nil {
+		t.Fatalf("expected error")
+	}
+	fail = false
+	if x, err := cache(testCacheKey(2), load); err != nil || x != "ok" {
+		t.Fatalf("expected retry to succeed, got %v, %v", x, err)
+	}
+}
+
+func TestCacheNested(t *testing.T) {
+	done := make(chan bool)
+	go func() {
+		x, err := cache(testCacheKey(3), func() (interface{}, error) {
+			return cache(testCacheKey(4), func() (interface{}, error) {
+				return "inner", nil
+			})
+		})
+		if err != nil || x != "inner" {
+			t.Errorf("expected inner, got %v, %v", x, err)
+		}
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("nested calls to cache deadlocked")
+	}
+}
@@ -0,0 +1,54 @@
+package charset
+
+import (
+	"fmt"
+)
+
+func init() {
+	registerTransform("case", newCase, newCase)
+}
+
+// translateCase folds the ASCII letters A to Z and a to z
+// to one case and passes everything else through, so that
+// no locale rules are involved. Because the bytes of a
+// multibyte UTF-8 sequence are never ASCII, it needs no
+// knowledge of where runes start and end.
+type translateCase struct {
+	upper   bool // fold to upper case rather than lower
+	scratch []byte
+}
+
+func (p *translateCase) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateCase) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = append(p.scratch[:0], data...)
+	for i, b := range p.scratch {
+		switch {
+		case p.upper && b >= 'a' && b <= 'z':
+			p.scratch[i] = b - 'a' + 'A'
+		case !p.upper && b >= 'A' && b <= 'Z':
+			p.scratch[i] = b - 'A' + 'a'
+		}
+	}
+	return len(data), p.scratch, nil
+}
+
+// factory to create translateCase, which translates
+// the same way in both directions. The "fold" option
+// gives the case, "lower" (the default) or "upper".
+func newCase(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "fold")
+	if err != nil {
+		return nil, err
+	}
+	switch fold := opts["fold"]; fold {
+	case "", "lower":
+		return &translateCase{}, nil
+	case "upper":
+		return &translateCase{upper: true}, nil
+	default:
+		return nil, fmt.Errorf("charset: unknown case folding %q", fold)
+	}
+}
@@ -0,0 +1,45 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var caseTests = []struct {
+	name string
+	in   string
+	out  string
+}{
+	{"case?fold=lower", "Hello 한글", "hello 한글"},
+	{"case", "ABC xyz ÀÉ", "abc xyz ÀÉ"},
+	{"case?fold=upper", "Hello 한글 é", "HELLO 한글 é"},
+	{"case?fold=upper", "[@`{]", "[@`{]"},
+}
+
+func TestCase(t *testing.T) {
+	for _, test := range caseTests {
+		tr, err := charset.TranslatorFrom(test.name)
+		if err != nil {
+			t.Fatalf("%s: cannot make translator: %v", test.name, err)
+		}
+		if out, err := translate(tr, test.in); err != nil || out != test.out {
+			t.Errorf("%s: %q: expected %q got %q, %v", test.name, test.in, test.out, out, err)
+		}
+	}
+	if _, err := charset.TranslatorFrom("case?fold=title"); err == nil {
+		t.Errorf("expected error for unknown folding")
+	}
+}
+
+func TestCaseChain(t *testing.T) {
+	// "Hello 한글" in cp949, decoded and folded.
+	tr, err := charset.NewTranslatorChain("cp949", "case?fold=lower")
+	if err != nil {
+		t.Fatalf("cannot make chain: %v", err)
+	}
+	out, err := translate(tr, "Hello \xc7\xd1\xb1\xdb")
+	if err != nil || out != "hello 한글" {
+		t.Errorf("expected %q, got %q, %v", "hello 한글", out, err)
+	}
+}
@@ -0,0 +1,155 @@
+package charset
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("cesu8", fromCESU8, toCESU8)
+}
+
+// encoding details
+//
+// CESU-8 is UTF-8 in which each rune above U+FFFF is encoded
+// as its UTF-16 surrogate pair, each surrogate taking three bytes
+// (ed a0..af 80..bf for the high one, ed b0..bf 80..bf for the low),
+// rather than as a four-byte sequence.
+//
+// Java's modified UTF-8 is CESU-8 in which NUL is encoded
+// as c0 80, so that the encoded text holds no zero bytes.
+
+type translateFromCESU8 struct {
+	modified bool // decode c0 80 as NUL
+	scratch  []byte
+}
+
+func (p *translateFromCESU8) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromCESU8) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for n < len(data) {
+		if b := data[n]; b < utf8.RuneSelf {
+			p.scratch = append(p.scratch, b)
+			n++
+			continue
+		}
+		if p.modified && data[n] == 0xc0 {
+			if len(data)-n < 2 && !eof {
+				break
+			}
+			if len(data)-n >= 2 && data[n+1] == 0x80 {
+				p.scratch = append(p.scratch, 0)
+				n += 2
+				continue
+			}
+		}
+		if len(data)-n < 3 && data[n] == 0xed && !eof {
+			// The start of a surrogate, which utf8.FullRune
+			// does not recognise.
+			break
+		}
+		s, ok := surrogate(data[n:])
+		if !ok {
+			if !utf8.FullRune(data[n:]) && !eof {
+				break
+			}
+			r, size := utf8.DecodeRune(data[n:])
+			p.scratch = appendRune(p.scratch, r)
+			n += size
+			continue
+		}
+		r := utf8.RuneError
+		size := 3
+		if s < 0xdc00 {
+			if len(data)-n < 6 && !eof {
+				// The low surrogate may follow in the next call.
+				break
+			}
+			if low, ok := surrogate(data[n+3:]); ok && low >= 0xdc00 {
+				r = utf16.DecodeRune(s, low)
+				size = 6
+			}
+		}
+		p.scratch = appendRune(p.scratch, r)
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+// surrogate decodes a surrogate encoded as three bytes
+// at the start of data.
+func surrogate(data []byte) (rune, bool) {
+	if len(data) < 3 || data[0] != 0xed || data[1] < 0xa0 || data[1] > 0xbf || data[2]&0xc0 != 0x80 {
+		return 0, false
+	}
+	return 0xd000 | rune(data[1]&0x3f)<<6 | rune(data[2]&0x3f), true
+}
+
+type translateToCESU8 struct {
+	modified bool // encode NUL as c0 80
+	scratch  []byte
+}
+
+func (p *translateToCESU8) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToCESU8) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for n < len(data) {
+		if b := data[n]; b < utf8.RuneSelf {
+			if b == 0 && p.modified {
+				p.scratch = append(p.scratch, 0xc0, 0x80)
+			} else {
+				p.scratch = append(p.scratch, b)
+			}
+			n++
+			continue
+		}
+		if !utf8.FullRune(data[n:]) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data[n:])
+		if r > 0xffff {
+			hi, lo := utf16.EncodeRune(r)
+			p.scratch = appendSurrogate(appendSurrogate(p.scratch, hi), lo)
+		} else {
+			p.scratch = appendRune(p.scratch, r)
+		}
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+// appendSurrogate appends the three-byte encoding of the surrogate s.
+func appendSurrogate(buf []byte, s rune) []byte {
+	return append(buf, 0xed, 0x80|byte(s>>6)&0x3f, 0x80|byte(s)&0x3f)
+}
+
+// factory to create translateFromCESU8. Four-byte UTF-8
+// sequences are accepted too, and unpaired surrogates are
+// decoded as utf8.RuneError. With the argument "modified",
+// c0 80 is decoded as NUL, as is a zero byte.
+func fromCESU8(arg string) (Translator, error) {
+	arg, _, err := splitArg(arg)
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromCESU8{modified: arg == "modified"}, nil
+}
+
+// factory to create translateToCESU8. Invalid UTF-8 is
+// encoded as utf8.RuneError. With the argument "modified",
+// NUL is encoded as c0 80.
+func toCESU8(arg string) (Translator, error) {
+	arg, _, err := splitArg(arg)
+	if err != nil {
+		return nil, err
+	}
+	return &translateToCESU8{modified: arg == "modified"}, nil
+}
@@ -0,0 +1,104 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var cesu8Tests = []struct {
+	cesu8 string
+	utf8  string
+}{
+	{"abc", "abc"},
+	{"\xed\xa0\xbd\xed\xb8\x80", "😀"},
+	{"a\xed\xa0\xbd\xed\xb8\x80b", "a😀b"},
+	{"가\xed\xa0\x80\xed\xb0\x80", "가𐀀"},
+}
+
+func TestCESU8(t *testing.T) {
+	for _, test := range cesu8Tests {
+		if out := decodeString(t, "cesu-8", test.cesu8); out != test.utf8 {
+			t.Errorf("decoding %x: expected %q got %q", test.cesu8, test.utf8, out)
+		}
+		if out := encodeString(t, "cesu-8", test.utf8); out != test.cesu8 {
+			t.Errorf("encoding %q: expected %x got %x", test.utf8, test.cesu8, out)
+		}
+	}
+}
+
+func TestCESU8Invalid(t *testing.T) {
+	bad := "�"
+	for in, out := range map[string]string{
+		"\xed\xa0\xbd":             bad,
+		"\xed\xa0\xbda":            bad + "a",
+		"\xed\xb8\x80":             bad,
+		"\xed\xa0\xbd\xed\xa0\xbd": bad + bad,
+		"\xf0\x9f\x98\x80":         "😀",
+		"\xff":                     bad,
+	} {
+		if got := decodeString(t, "cesu-8", in); got != out {
+			t.Errorf("decoding %x: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestCESU8Split(t *testing.T) {
+	in := "\xed\xa0\xbd\xed\xb8\x80"
+	for i := 1; i < len(in); i++ {
+		tr, err := charset.TranslatorFrom("cesu-8")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate([]byte(in[:i]), false)
+		if err != nil || len(cdata) != 0 {
+			t.Fatalf("split at %d: expected nothing, got %q, %v", i, cdata, err)
+		}
+		_, cdata, err = tr.Translate([]byte(in[n:]), true)
+		if err != nil || string(cdata) != "😀" {
+			t.Errorf("split at %d: expected %q, got %q, %v", i, "😀", cdata, err)
+		}
+	}
+}
+
+var modifiedUTF8Tests = []struct {
+	native  string
+	unicode string
+}{
+	{"abc", "abc"},
+	{"a\xc0\x80b", "a\x00b"},
+	{"\xc0\x80", "\x00"},
+	{"\xed\xa0\xbd\xed\xb8\x80", "😀"},
+	{"\xc3\xa9\xc0\x80\xed\xa0\x80\xed\xb0\x80", "é\x00𐀀"},
+}
+
+func TestModifiedUTF8(t *testing.T) {
+	for _, test := range modifiedUTF8Tests {
+		if out := decodeString(t, "modified-utf-8", test.native); out != test.unicode {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "modified-utf-8", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.unicode, test.native, out)
+		}
+	}
+	// A zero byte is accepted, and c0 is not a NUL in CESU-8.
+	if out := decodeString(t, "modified-utf-8", "a\x00\xc0"); out != "a\x00�" {
+		t.Errorf("expected %q, got %q", "a\x00�", out)
+	}
+	if out := decodeString(t, "cesu-8", "\xc0\x80"); out != "��" {
+		t.Errorf("cesu-8: expected %q, got %q", "��", out)
+	}
+
+	tr, err := charset.TranslatorFrom("modified-utf-8")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	n, cdata, err := tr.Translate([]byte("a\xc0"), false)
+	if n != 1 || string(cdata) != "a" || err != nil {
+		t.Fatalf("expected 1, %q; got %d, %q, %v", "a", n, cdata, err)
+	}
+	n, cdata, err = tr.Translate([]byte("\xc0\x80"), true)
+	if n != 2 || string(cdata) != "\x00" || err != nil {
+		t.Errorf("expected 2, %q; got %d, %q, %v", "\x00", n, cdata, err)
+	}
+}
@@ -0,0 +1,80 @@
+package charset
+
+import (
+	"fmt"
+)
+
+// translatorChain translates through UTF-8 from one
+// character set to another.
+type translatorChain struct {
+	from, to Translator
+	mid      []byte // UTF-8 not yet consumed by to.
+}
+
+// NewTranslatorChain returns a Translator that translates from
+// the character set named from to the one named to, by way of UTF-8.
+// The number of bytes it reports consumed refers to its input
+// in the first character set.
+func NewTranslatorChain(from, to string) (Translator, error) {
+	trFrom, err := TranslatorFrom(from)
+	if err != nil {
+		return nil, err
+	}
+	trTo, err := TranslatorTo(to)
+	if err != nil {
+		return nil, err
+	}
+	return &translatorChain{from: trFrom, to: trTo}, nil
+}
+
+func (c *translatorChain) Reset() {
+	resetTranslator(c.from)
+	resetTranslator(c.to)
+	c.mid = c.mid[:0]
+}
+
+func (c *translatorChain) Translate(data []byte, eof bool) (int, []byte, error) {
+	n, cdata, err := c.from.Translate(data, eof)
+	c.mid = append(c.mid, cdata...)
+	// The second stage reaches eof only when
+	// the first has consumed all its input.
+	m, cdata, err2 := c.to.Translate(c.mid, eof && n == len(data) && err == nil)
+	c.mid = c.mid[:copy(c.mid, c.mid[m:])]
+	if err == nil {
+		err = err2
+	}
+	return n, cdata, err
+}
+
+// CheckConversion checks that each of runes, encoded in the
+// character set a, converted from a to b by a translator chain
+// and decoded from b, comes back unchanged, as it should if both
+// character sets can represent it. It returns an error for
+// the first rune that does not.
+func CheckConversion(a, b string, runes []rune) error {
+	enc, err := TranslatorTo(a)
+	if err != nil {
+		return err
+	}
+	chain, err := NewTranslatorChain(a, b)
+	if err != nil {
+		return err
+	}
+	dec, err := TranslatorFrom(b)
+	if err != nil {
+		return err
+	}
+	for _, r := range runes {
+		data := []byte(string(r))
+		for _, tr := range []Translator{enc, chain, dec} {
+			resetTranslator(tr)
+			if data, err = translateAll(tr, data); err != nil {
+				return fmt.Errorf("charset: converting %q from %s to %s: %w", r, a, b, err)
+			}
+		}
+		if string(data) != string(r) {
+			return fmt.Errorf("charset: %q converted from %s to %s comes back as %q", r, a, b, data)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var chainTests = []struct {
+	from, to string
+	in, out  string
+}{
+	{"cp949", "utf-16be", "a\xbe\xc6\xb8\xa7", "\x00a\xc5\x44\xb9\x84"},
+	{"cp949", "gb18030", "\xf1\xe9\xd9\xfe", "\xd6\xd0\xce\xc4"},
+	{"cp949", "iso-2022-jp", "a\xaa\xa2", "a\x1b$B$\"\x1b(B"},
+	{"iso-2022-jp", "cp949", "\x1b$B$\"\x1b(Ba", "\xaa\xa2a"},
+}
+
+func TestTranslatorChain(t *testing.T) {
+	for _, test := range chainTests {
+		tr, err := charset.NewTranslatorChain(test.from, test.to)
+		if err != nil {
+			t.Fatalf("cannot make chain: %v", err)
+		}
+		if out, err := translate(tr, test.in); err != nil || out != test.out {
+			t.Errorf("%s to %s: expected %x got %x, %v", test.from, test.to, test.out, out, err)
+		}
+
+		// Feed the input a byte at a time.
+		tr.(charset.ResetTranslator).Reset()
+		var out, pending []byte
+		for i := 0; i < len(test.in); i++ {
+			pending = append(pending, test.in[i])
+			n, cdata, err := tr.Translate(pending, i == len(test.in)-1)
+			if err != nil {
+				t.Fatalf("%s to %s: translate error: %v", test.from, test.to, err)
+			}
+			out = append(out, cdata...)
+			pending = pending[n:]
+		}
+		if string(out) != test.out {
+			t.Errorf("%s to %s, bytewise: expected %x got %x", test.from, test.to, test.out, out)
+		}
+	}
+	if _, err := charset.NewTranslatorChain("cp949", "big5"); err == nil {
+		t.Errorf("expected error for a chain to big5")
+	}
+}
+
+// cp949Runes returns the runes of cp949, and those that
+// also have an encoding in other.
+func cp949Runes(t *testing.T, other string) (all, shared []rune) {
+	err := charset.EachMapping("cp949", func(native []byte, r rune) {
+		all = append(all, r)
+		if ok, _ := charset.CanEncode(other, r); ok {
+			shared = append(shared, r)
+		}
+	})
+	if err != nil {
+		t.Fatalf("cannot list cp949: %v", err)
+	}
+	return all, shared
+}
+
+func TestCheckConversion(t *testing.T) {
+	all, _ := cp949Runes(t, "utf-16")
+	for _, pair := range [][2]string{{"cp949", "utf-16"}, {"utf-16", "cp949"}} {
+		if err := charset.CheckConversion(pair[0], pair[1], all); err != nil {
+			t.Errorf("%s to %s: %v", pair[0], pair[1], err)
+		}
+	}
+	_, shared := cp949Runes(t, "gbk")
+	if len(shared) < 5000 {
+		t.Errorf("only %d runes shared by cp949 and gbk", len(shared))
+	}
+	for _, pair := range [][2]string{{"cp949", "gbk"}, {"gbk", "cp949"}} {
+		if err := charset.CheckConversion(pair[0], pair[1], shared); err != nil {
+			t.Errorf("%s to %s: %v", pair[0], pair[1], err)
+		}
+	}
+
+	// 가 is not in gbk, and comes back as '?'.
+	if err := charset.CheckConversion("cp949", "gbk", []rune("中가")); err == nil {
+		t.Errorf("expected error for 가")
+	}
+}
@@ -6,11 +6,56 @@
 // 
 //	import _ "code.google.com/p/go-charset/data"
 //
-// It can also made available in a data directory (by settting CharsetDir).
+// Alternatively, importing the datafiles package embeds the same data
+// using go:embed. It can also made available in a data directory
+// (by settting CharsetDir) or any fs.FS (with SetDataFS).
+//
+// Some character sets accept options, given after a '?' in the
+// character set name and separated by '&', for example
+// "cp949?replacement=0x1a". Without options, the default
+// behaviour is used. The cp949 character set understands:
+//
+//	replacement=n	substitute n for untranslatable characters: when
+//			decoding, the rune n in place of U+FFFD (utf8.RuneError),
+//			and when encoding, the byte n in place of '?'
+//	drop		omit untranslatable characters
+//	strict		when translating to cp949, stop with an *UnmappableError
+//			at the first character that cannot be translated,
+//			or an *InvalidUTF8Error at malformed input
+//			(for euc-kr, strict also refuses the codes that cp949
+//			adds to KS X 1001, in both directions)
+//	bestfit		when translating to cp949, replace characters with
+//			similar ones where possible, as Windows does
+//			("₩" becomes the fullwidth "￦")
+//	translit	when translating to cp949, replace characters with
+//			ASCII approximations where possible ("café" becomes "cafe")
+//	maxbytes=n	stop with ErrOutputLimit rather than produce
+//			more than n bytes of output in total
+//	user-defined	map the rows c9 and fe, which KS X 1001 leaves
+//			for user-defined characters, to the private use
+//			area from U+E000, and back
+//
+// The other double-byte character sets (big5, big5-hkscs, shift_jis,
+// cp932, euc-jp, euc-tw, johab and gbk) and the code page character
+// sets understand replacement and drop in both directions too.
+// Code page character sets such as latin1 also understand strict,
+// and passthrough-undefined, which decodes each byte undefined in the
+// code page as a rune in the private use range U+F780 to U+F7FF
+// (as x-user-defined does) and encodes those runes as the bytes
+// again, so that any file survives a round trip.
+// The us-ascii, gb18030 and gbk character sets understand strict too.
+// With us-ascii and gb18030 it also applies to decoding, which
+// stops with an *InvalidByteError at the first invalid byte.
+// With gb2312, strict decodes the codes that GBK adds to GB 2312,
+// and the euro sign at 0x80, as utf8.RuneError.
 package charset
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"unicode/utf8"
 )
@@ -31,10 +76,169 @@ type Charset struct {
 // overwritten on the next call to Translate), and any
 // conversion error. If eof is true, the data represents
 // the final bytes of the input.
+//
+// When Translate returns an error, n and cdata still describe
+// the input before the point of the error: cdata holds the
+// translation of data[:n], which the caller may use
+// before handling the error, as with io.Reader.
 type Translator interface {
 	Translate(data []byte, eof bool) (n int, cdata []byte, err error)
 }
 
+// TranslatorInto is implemented by Translators that can append
+// their output to a buffer supplied by the caller, avoiding
+// the need to copy it out of the Translator's own buffer.
+// TranslateInto is like Translate, but appends the converted
+// data to dst and returns the extended slice.
+type TranslatorInto interface {
+	Translator
+	TranslateInto(dst, data []byte, eof bool) (n int, cdata []byte, err error)
+}
+
+// ResetTranslator is implemented by Translators that hold state
+// between calls to Translate, such as the current shift state,
+// and can be returned to their initial state so that they can
+// be reused for an unrelated stream of data.
+// All the Translators of this package implement it.
+type ResetTranslator interface {
+	Translator
+	Reset()
+}
+
+// Stats holds the counts kept by a StatsTranslator.
+type Stats struct {
+	Chars         int // Characters produced by the most recent call to Translate.
+	TotalChars    int // Characters produced by all calls so far.
+	Substitutions int // Characters substituted by all calls so far.
+}
+
+// StatsTranslator is implemented by Translators that count
+// the characters they produce, for progress reporting.
+// A character that takes several bytes in the output,
+// such as a Hangul syllable, counts once.
+type StatsTranslator interface {
+	Translator
+	Stats() Stats
+}
+
+// ErrorsTranslator is implemented by Translators that keep
+// a record of the characters they substitute, so that a caller
+// translating leniently can find out afterwards what was lost.
+type ErrorsTranslator interface {
+	Translator
+	// Errors returns, for each character substituted since the
+	// Translator was made or last reset, the error that the
+	// "strict" option would have caused: when encoding, an
+	// *UnmappableError or an *InvalidUTF8Error, and when
+	// decoding, an *InvalidByteError for the first byte that
+	// could not be decoded. Offsets in the errors count
+	// from the start of all the input since then. Only the
+	// first MaxErrors are kept; a Translator that is also a
+	// StatsTranslator counts them all in Stats.Substitutions.
+	Errors() []error
+}
+
+// MaxErrors is the most errors that an ErrorsTranslator keeps
+// between resets, so that translating a large corrupt file does
+// not use memory for every bad byte. The value is taken each
+// time an error is recorded.
+var MaxErrors = 1000
+
+// ErrorsReader is implemented by the Readers returned by
+// NewReader and NewTranslatingReader. Errors returns the
+// substitutions made by the Translator so far if it is an
+// ErrorsTranslator, and nil otherwise.
+type ErrorsReader interface {
+	io.Reader
+	Errors() []error
+}
+
+// SwitchingReader is implemented by the Readers returned by
+// NewReader and NewTranslatingReader. SetCharset makes the
+// Reader translate from the named character set from now on,
+// for input, such as a document whose header gives its encoding,
+// that changes character set part way through. Input that the
+// Reader holds untranslated is passed to the new Translator;
+// text already translated, including any that the Reader has
+// translated but not yet returned, is unaffected. If the
+// character set is not known, SetCharset returns an error
+// and the Reader is unchanged.
+type SwitchingReader interface {
+	io.Reader
+	SetCharset(name string) error
+}
+
+// OffsetMapTranslator is implemented by Translators to UTF-8
+// that record where each character of their output came from,
+// so that a position in the decoded text can be mapped back
+// to a position in the original.
+type OffsetMapTranslator interface {
+	Translator
+	// OffsetMap returns, for each rune produced by the most
+	// recent call to Translate, the byte offset in the data
+	// passed to that call of the input it was translated from.
+	// The slice is reused by the next call.
+	OffsetMap() []int
+}
+
+// UnmappableError is returned by a Translator in strict mode
+// when it finds a character that cannot be represented
+// in the target character set.
+// In the Errors of an ErrorsTranslator, Offset counts from the
+// start of all the input since the Translator was made or reset.
+type UnmappableError struct {
+	Offset int  // Byte offset of the character in the data passed to Translate.
+	Rune   rune // The character that could not be translated.
+}
+
+func (e *UnmappableError) Error() string {
+	return fmt.Sprintf("charset: cannot translate %U at offset %d", e.Rune, e.Offset)
+}
+
+// InvalidUTF8Error is returned by a Translator in strict mode
+// when its input is not valid UTF-8. Without strict mode,
+// malformed input is treated like a character with no translation.
+// As for UnmappableError, Offset counts from the start of all the
+// input in the Errors of an ErrorsTranslator.
+type InvalidUTF8Error struct {
+	Offset int // Byte offset of the malformed sequence in the data passed to Translate.
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("charset: invalid UTF-8 at offset %d", e.Offset)
+}
+
+// InvalidByteError is returned by a Translator in strict mode
+// when its input holds a byte that is not valid in the
+// character set being translated from.
+// As for UnmappableError, Offset counts from the start of all the
+// input in the Errors of an ErrorsTranslator.
+type InvalidByteError struct {
+	Offset int  // Byte offset of the byte in the data passed to Translate.
+	Byte   byte // The invalid byte.
+}
+
+func (e *InvalidByteError) Error() string {
+	return fmt.Sprintf("charset: invalid byte %#02x at offset %d", e.Byte, e.Offset)
+}
+
+// strictError returns the error for a strict Translator that
+// cannot translate the rune r, decoded from size bytes at offset.
+func strictError(offset int, r rune, size int) error {
+	if r == utf8.RuneError && size == 1 {
+		return &InvalidUTF8Error{Offset: offset}
+	}
+	return &UnmappableError{Offset: offset, Rune: r}
+}
+
+// ErrOutputLimit is returned by a Translator when its output
+// would exceed the limit given by a "maxbytes" option.
+var ErrOutputLimit = errors.New("charset: output limit exceeded")
+
+// ErrReadAhead is returned by a Reader whose Translator
+// leaves more than MaxReadAhead bytes of input unconsumed.
+var ErrReadAhead = errors.New("charset: too much input held back by translator")
+
 // A Factory can be used to make character set translators.
 type Factory interface {
 	// TranslatorFrom creates a translator that will translate from the named character
@@ -62,6 +266,9 @@ func Register(factory Factory) {
 
 // NewReader returns a new Reader that translates from the named
 // character set to UTF-8 as it reads r.
+// The Reader is an ErrorsReader, through which the
+// substitutions made for undecodable input can be found,
+// and a SwitchingReader.
 func NewReader(charset string, r io.Reader) (io.Reader, error) {
 	tr, err := TranslatorFrom(charset)
 	if err != nil {
@@ -73,7 +280,10 @@ func NewReader(charset string, r io.Reader) (io.Reader, error) {
 // NewWriter returns a new WriteCloser writing to w.  It converts writes
 // of UTF-8 text into writes on w of text in the named character set.
 // The Close is necessary to flush any remaining partially translated
-// characters to the output.
+// characters to the output. With the "strict" option, as in
+// "cp949?strict", a Write of text that cannot be encoded in the
+// character set returns the error, having written the text before
+// it, and the number of bytes of its argument so written.
 func NewWriter(charset string, w io.Writer) (io.WriteCloser, error) {
 	tr, err := TranslatorTo(charset)
 	if err != nil {
@@ -82,8 +292,228 @@ func NewWriter(charset string, w io.Writer) (io.WriteCloser, error) {
 	return NewTranslatingWriter(w, tr), nil
 }
 
+// NewWriterThreshold is like NewWriter, but a Write of more than
+// threshold bytes is translated threshold bytes at a time, the
+// text translated from each piece being written to w before
+// the next is translated, so that output starts without waiting
+// for the whole Write. A threshold of 0 or less translates each
+// Write at once, as NewWriter does.
+func NewWriterThreshold(charset string, w io.Writer, threshold int) (io.WriteCloser, error) {
+	tr, err := TranslatorTo(charset)
+	if err != nil {
+		return nil, err
+	}
+	resetTranslator(tr)
+	return &translatingWriter{w: w, tr: tr, threshold: threshold}, nil
+}
+
+// Decode returns the result of translating data from
+// the named character set to UTF-8. If translation fails,
+// the result of translating the data before the failure
+// is returned with the error.
+func Decode(charset string, data []byte) ([]byte, error) {
+	tr, err := TranslatorFrom(charset)
+	if err != nil {
+		return nil, err
+	}
+	return translateAll(tr, data)
+}
+
+// DecodeWithErrors is like Decode, but also returns the offset
+// in data of the first byte of each character that could not be
+// decoded and was replaced, up to MaxErrors of them. The named
+// character set's Translator must be an ErrorsTranslator,
+// as that of cp949 is.
+func DecodeWithErrors(charset string, data []byte) (out []byte, bad []int, err error) {
+	tr, err := TranslatorFrom(charset)
+	if err != nil {
+		return nil, nil, err
+	}
+	et, ok := tr.(ErrorsTranslator)
+	if !ok {
+		return nil, nil, fmt.Errorf("charset: %q does not report decoding errors", charset)
+	}
+	out, err = translateAll(tr, data)
+	for _, e := range et.Errors() {
+		if e, ok := e.(*InvalidByteError); ok {
+			bad = append(bad, e.Offset)
+		}
+	}
+	return out, bad, err
+}
+
+// Encode returns the result of translating data from
+// UTF-8 to the named character set. As with Decode, an error
+// is returned with the translation of the data before it.
+func Encode(charset string, data []byte) ([]byte, error) {
+	tr, err := TranslatorTo(charset)
+	if err != nil {
+		return nil, err
+	}
+	return translateAll(tr, data)
+}
+
+// DecodeString is like Decode but operates on strings.
+func DecodeString(charset, s string) (string, error) {
+	out, err := Decode(charset, []byte(s))
+	return string(out), err
+}
+
+// EncodeString is like Encode but operates on strings.
+func EncodeString(charset, s string) (string, error) {
+	out, err := Encode(charset, []byte(s))
+	return string(out), err
+}
+
+// runeChecker is implemented by to-translators that can tell
+// directly whether a rune has an encoding.
+type runeChecker interface {
+	canEncode(r rune) bool
+}
+
+// CanEncode reports whether r can be represented in the named
+// character set. Where the translator cannot say directly,
+// r is encoded and decoded again, and must survive the trip.
+func CanEncode(charset string, r rune) (bool, error) {
+	canEncode, err := runeTester(charset)
+	if err != nil {
+		return false, err
+	}
+	return canEncode(r), nil
+}
+
+// CanEncodeAll reports whether all of s can be represented
+// in the named character set. If not, it also returns the
+// first rune that cannot, which is utf8.RuneError if s is not
+// valid UTF-8 there or the character set is not known.
+func CanEncodeAll(charset string, s string) (ok bool, firstBad rune) {
+	canEncode, err := runeTester(charset)
+	if err != nil {
+		return false, utf8.RuneError
+	}
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 || !canEncode(r) {
+			return false, r
+		}
+		s = s[size:]
+	}
+	return true, 0
+}
+
+// EncodableCharsets returns the canonical names, in alphabetical
+// order, of all the character sets that can represent every rune
+// of s, as reported by CanEncodeAll. Character sets that cannot
+// be translated to, or whose translators cannot be made, are left
+// out, as are all of them if s is not valid UTF-8. Only the
+// character sets of this package are considered: the transforms
+// such as identity and newlines, which pass their text through
+// as UTF-8, and those added by RegisterCharset or another
+// Factory are left out. The Unicode encodings are included.
+func EncodableCharsets(s string) []string {
+	if !utf8.ValidString(s) {
+		return nil
+	}
+	runes := []rune(s)
+	var names []string
+names:
+	for _, name := range Names() {
+		if cs := Info(name); cs == nil || cs.NoTo || !isEncoding(name) {
+			continue
+		}
+		canEncode, err := runeTester(name)
+		if err != nil {
+			continue
+		}
+		for _, r := range runes {
+			if !canEncode(r) {
+				continue names
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// runeTester returns a function reporting whether
+// a rune can be represented in the named character set.
+func runeTester(charset string) (func(rune) bool, error) {
+	tr, err := TranslatorTo(charset)
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := tr.(runeChecker); ok {
+		return func(r rune) bool {
+			return utf8.ValidRune(r) && rc.canEncode(r)
+		}, nil
+	}
+	return roundTripper(charset, tr)
+}
+
+// RoundTrip reports whether r, encoded in the named character
+// set and decoded again, comes back unchanged. It does for every
+// rune that the character set can represent, unless its tables
+// disagree with each other. RoundTrip returns false if the
+// character set is not known.
+func RoundTrip(charset string, r rune) bool {
+	tr, err := TranslatorTo(charset)
+	if err != nil {
+		return false
+	}
+	roundTrip, err := roundTripper(charset, tr)
+	if err != nil {
+		return false
+	}
+	return roundTrip(r)
+}
+
+// roundTripper returns a function reporting whether a rune
+// survives encoding with tr and decoding from the named
+// character set.
+func roundTripper(charset string, tr Translator) (func(rune) bool, error) {
+	dec, err := TranslatorFrom(charset)
+	if err != nil {
+		return nil, err
+	}
+	return func(r rune) bool {
+		if !utf8.ValidRune(r) {
+			return false
+		}
+		s := string(r)
+		resetTranslator(tr)
+		enc, err := translateAll(tr, []byte(s))
+		if err != nil {
+			return false
+		}
+		resetTranslator(dec)
+		out, err := translateAll(dec, enc)
+		return err == nil && string(out) == s
+	}, nil
+}
+
+// translateAll passes all of data through tr as final input.
+func translateAll(tr Translator, data []byte) ([]byte, error) {
+	var out []byte
+	for {
+		n, cdata, err := tr.Translate(data, true)
+		out = append(out, cdata...)
+		if err != nil {
+			return out, err
+		}
+		data = data[n:]
+		// As in translatingWriter.Close, if the Translator
+		// makes no progress then assume that it never will.
+		if len(data) == 0 || n == 0 && len(cdata) == 0 {
+			break
+		}
+	}
+	return out, nil
+}
+
 // Info returns information about a character set, or nil
-// if the character set is not found.
+// if the character set is not found. Case, hyphens and underscores
+// in the name are ignored, and it may be an alias or carry options;
+// the returned Charset always gives the canonical name.
 func Info(name string) *Charset {
 	for _, f := range factories {
 		if info := f.Info(name); info != nil {
@@ -95,14 +525,73 @@ func Info(name string) *Charset {
 
 // Names returns the canonical names of all supported character sets, in alphabetical order.
 func Names() []string {
-	// TODO eliminate duplicates
+	seen := make(map[string]bool)
 	var names []string
 	for _, f := range factories {
-		names = append(names, f.Names()...)
+		for _, name := range f.Names() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AllNames returns the canonical names and the aliases of all
+// supported character sets, in alphabetical order, for listing
+// every name by which a character set can be asked for.
+func AllNames() []string {
+	var names []string
+	for _, cs := range Charsets() {
+		names = append(names, cs.Name)
+		names = append(names, cs.Aliases...)
 	}
+	sort.Strings(names)
 	return names
 }
 
+// Charsets returns information about all supported character sets,
+// in alphabetical order of canonical name. Each character set
+// appears once, with its aliases.
+func Charsets() []*Charset {
+	var charsets []*Charset
+	for _, name := range Names() {
+		if cs := Info(name); cs != nil {
+			charsets = append(charsets, cs)
+		}
+	}
+	return charsets
+}
+
+// Preload loads the data for the named character sets, in both
+// directions, so that making translators for them later
+// does not need to read it. It returns the first error
+// encountered. With no names, all character sets are preloaded.
+func Preload(names ...string) error {
+	if len(names) == 0 {
+		names = Names()
+	}
+	for _, name := range names {
+		cs := Info(name)
+		if cs == nil {
+			return fmt.Errorf("character set %q not found", name)
+		}
+		if !cs.NoFrom {
+			if _, err := TranslatorFrom(name); err != nil {
+				return err
+			}
+		}
+		if !cs.NoTo {
+			if _, err := TranslatorTo(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // TranslatorFrom returns a translator that will translate from
 // the named character set to UTF-8.
 func TranslatorFrom(charset string) (Translator, error) {
@@ -154,18 +643,54 @@ func NormalizedName(s string) string {
 }
 
 type translatingWriter struct {
-	w   io.Writer
-	tr  Translator
-	buf []byte // unconsumed data from writer.
+	w         io.Writer
+	tr        Translator
+	buf       []byte          // unconsumed data from writer.
+	ctx       context.Context // if not nil, cancels writing.
+	threshold int             // if > 0, the most input translated at once.
 }
 
 // NewTranslatingWriter returns a new WriteCloser writing to w.
 // It passes the written bytes through the given Translator.
+// Close translates any remaining input with eof set,
+// which lets a stateful Translator write a final sequence
+// returning to its initial state, and returns any
+// error from the Translator. It does not close w.
+// If the Translator returns an error, Write returns it
+// with the number of bytes translated before it.
+// If tr is a ResetTranslator, it is reset first,
+// so that one Translator can be used for several streams in turn.
 func NewTranslatingWriter(w io.Writer, tr Translator) io.WriteCloser {
+	resetTranslator(tr)
 	return &translatingWriter{w: w, tr: tr}
 }
 
-func (w *translatingWriter) Write(data []byte) (rn int, rerr error) {
+func (w *translatingWriter) Write(data []byte) (int, error) {
+	if w.ctx != nil {
+		if err := w.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	if w.threshold <= 0 {
+		return w.write(data)
+	}
+	n := 0
+	for n < len(data) {
+		end := n + w.threshold
+		if end > len(data) {
+			end = len(data)
+		}
+		wn, err := w.write(data[n:end])
+		n += wn
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// write translates data and writes the result to w.w.
+func (w *translatingWriter) write(data []byte) (rn int, rerr error) {
 	wdata := data
 	if len(w.buf) > 0 {
 		w.buf = append(w.buf, data...)
@@ -173,7 +698,20 @@ func (w *translatingWriter) Write(data []byte) (rn int, rerr error) {
 	}
 	n, cdata, err := w.tr.Translate(wdata, false)
 	if err != nil {
-		// TODO
+		// Write the text translated before the error and
+		// report how much of data it came from, so that the
+		// caller can write the rest some other way.
+		if len(cdata) > 0 {
+			if _, werr := w.w.Write(cdata); werr != nil {
+				return 0, werr
+			}
+		}
+		rn = n - (len(wdata) - len(data))
+		if rn < 0 {
+			rn = 0
+		}
+		w.buf = w.buf[:0]
+		return rn, err
 	}
 	if n > 0 {
 		_, err = w.w.Write(cdata)
@@ -189,47 +727,92 @@ func (w *translatingWriter) Write(data []byte) (rn int, rerr error) {
 }
 
 func (p *translatingWriter) Close() error {
+	if p.ctx != nil {
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+	}
 	for {
 		n, data, err := p.tr.Translate(p.buf, true)
 		p.buf = p.buf[n:]
-		if err != nil {
-			// TODO
-		}
-		// If the Translator produces no data
-		// at EOF, then assume that it never will.
-		if len(data) == 0 {
-			break
+		if len(data) > 0 {
+			nw, werr := p.w.Write(data)
+			if werr != nil {
+				return werr
+			}
+			if nw < len(data) {
+				return io.ErrShortWrite
+			}
 		}
-		n, err = p.w.Write(data)
 		if err != nil {
 			return err
 		}
-		if n < len(data) {
-			return io.ErrShortWrite
-		}
-		if len(p.buf) == 0 {
+		// If the Translator produces no data
+		// at EOF, then assume that it never will.
+		if len(data) == 0 || len(p.buf) == 0 {
 			break
 		}
 	}
 	return nil
 }
 
+// MaxReadAhead is the most input that a Reader made by NewReader
+// or NewTranslatingReader holds back while its Translator waits
+// for the rest of a character. Beyond that, the Reader returns
+// ErrReadAhead rather than buffering input from a stream that
+// never completes the character. The value is taken when the
+// Reader is made; 0 means no limit.
+var MaxReadAhead = 4096
+
 type translatingReader struct {
-	r     io.Reader
-	tr    Translator
-	cdata []byte // unconsumed data from converter.
-	rdata []byte // unconverted data from reader.
-	err   error  // final error from reader.
+	r          io.Reader
+	tr         Translator
+	ctx        context.Context // if not nil, cancels reading.
+	maxPending int             // limit on len(rdata), or 0.
+	cdata      []byte          // unconsumed data from converter.
+	rdata      []byte          // unconverted data from reader.
+	err        error           // final error from reader.
 }
 
 // NewTranslatingReader returns a new Reader that
-// translates data using the given Translator as it reads r.   
+// translates data using the given Translator as it reads r.
+// Input that the Translator leaves unconsumed, such as the
+// first byte of a character split between two reads of r,
+// is kept and passed to it again with the next read;
+// only at the end of r is it told that no more data will come.
+// If the Translator returns an error, the Reader returns it
+// after the data translated so far.
+// As with NewTranslatingWriter, tr is reset first if it is a ResetTranslator.
+// The Reader is an ErrorsReader and a SwitchingReader.
 func NewTranslatingReader(r io.Reader, tr Translator) io.Reader {
-	return &translatingReader{r: r, tr: tr}
+	resetTranslator(tr)
+	return &translatingReader{r: r, tr: tr, maxPending: MaxReadAhead}
+}
+
+func (r *translatingReader) Errors() []error {
+	if et, ok := r.tr.(ErrorsTranslator); ok {
+		return et.Errors()
+	}
+	return nil
+}
+
+func (r *translatingReader) SetCharset(name string) error {
+	tr, err := TranslatorFrom(name)
+	if err != nil {
+		return err
+	}
+	resetTranslator(tr)
+	r.tr = tr
+	return nil
 }
 
 func (r *translatingReader) Read(buf []byte) (int, error) {
 	for {
+		if r.ctx != nil {
+			if err := r.ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
 		if len(r.cdata) > 0 {
 			n := copy(buf, r.cdata)
 			r.cdata = r.cdata[n:]
@@ -248,10 +831,14 @@ func (r *translatingReader) Read(buf []byte) (int, error) {
 			break
 		}
 		nc, cdata, cvterr := r.tr.Translate(r.rdata, r.err != nil)
+		r.cdata = cdata
 		if cvterr != nil {
-			// TODO
+			// Return the data translated so far, then the error,
+			// rather than offering the Translator the same
+			// data again.
+			r.err = cvterr
+			nc = len(r.rdata)
 		}
-		r.cdata = cdata
 
 		// Ensure that we consume all bytes at eof
 		// if the converter refuses them.
@@ -261,10 +848,21 @@ func (r *translatingReader) Read(buf []byte) (int, error) {
 
 		// Copy unconsumed data to the start of the rdata buffer.
 		r.rdata = r.rdata[0:copy(r.rdata, r.rdata[nc:])]
+		if r.maxPending > 0 && len(r.rdata) > r.maxPending && r.err == nil {
+			r.err = ErrReadAhead
+			r.rdata = r.rdata[:0]
+		}
 	}
 	return 0, r.err
 }
 
+// resetTranslator resets tr if it is a ResetTranslator.
+func resetTranslator(tr Translator) {
+	if tr, ok := tr.(ResetTranslator); ok {
+		tr.Reset()
+	}
+}
+
 // ensureCap returns s with a capacity of at least n bytes.
 // If cap(s) < n, then it returns a new copy of s with the
 // required capacity.
@@ -293,6 +891,16 @@ func ensureCap(s []byte, n int) []byte {
 	return t
 }
 
+// appendDecoded appends the UTF-8 encoding of r to buf, or
+// repl if r is utf8.RuneError, which the decoders produce
+// for bytes that they cannot translate.
+func appendDecoded(buf []byte, r rune, repl []byte) []byte {
+	if r == utf8.RuneError {
+		return append(buf, repl...)
+	}
+	return appendRune(buf, r)
+}
+
 func appendRune(buf []byte, r rune) []byte {
 	n := len(buf)
 	buf = ensureCap(buf, n+utf8.UTFMax)
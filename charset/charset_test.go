@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"testing"
 	"testing/iotest"
@@ -30,6 +31,18 @@ var tests = []translateTest{
 	{true, "sjis", "", ""},
 	{true, "latin1", "\xa35 for Pepp\xe9", "£5 for Peppé"},
 	{true, "cp949", "\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee \xbf\xec\xb8\xae\xb8\xbb", "아름다운 우리말"},
+	{true, "cp1251", "\xcf\xf0\xe8\xe2\xe5\xf2, \xec\xe8\xf0 \x88", "Привет, мир €"},
+	{true, "cp1252", "\x80\x96 \x91quoted\x92 \x84low\x94 \x85", "€– ‘quoted’ „low” …"},
+	{true, "tis-620", "\xca\xc7\xd1\xca\xb4\xd5\xa4\xc3\xd1\xba \xbb\xc3\xd0\xe0\xb7\xc8\xe4\xb7\xc2", "สวัสดีครับ ประเทศไทย"},
+	{true, "windows-874", "\x93\xe4\xb7\xc2\x94 \x80", "“ไทย” €"},
+	{true, "viscii", "Ti\xaang Vi\xaet: \x14 \x1e \xc7 \x02", "Tiếng Việt: Ỷ Ỵ ẵ Ẳ"},
+	{true, "mac-roman", "Cr\x8fme br\x9el\x8ee \x88 S\x8bo Paulo \xd1 \x84and\x9c", "Crème brûlée à São Paulo — Ñandú"},
+	{true, "cp437", "\xc9\xcd\xbb\n\xba\xb0\xba\n\xc8\xcd\xbc \xb2\xdb\xdf\xdc \xda\xc2\xbf", "╔═╗\n║░║\n╚═╝ ▓█▀▄ ┌┬┐"},
+	{true, "cp850", "\xc9\xcd\xbb\n\xba\xb0\xba\n\xc8\xcd\xbc \xb2\xdb\xdf\xdc \xda\xc2\xbf", "╔═╗\n║░║\n╚═╝ ▓█▀▄ ┌┬┐"},
+	{true, "koi8-r", "\xfa\xc4\xd2\xc1\xd7\xd3\xd4\xd7\xd5\xca \xcd\xc9\xd2", "Здравствуй мир"},
+	{true, "koi8-u", "\xf0\xd2\xc9\xd7\xa6\xd4, \xbd\xc1\xce\xcf\xcb", "Привіт, Ґанок"},
+	{true, "big5", "a\xa4\xa4\xa4\xe5", "a中文"},
+	{true, "big5-hkscs", "a\xa4\xa4\xa4\xe5\x92\x5e\x87\x45", "a中文嚞\U00027267"},
 }
 
 func TestCharsets(t *testing.T) {
@@ -83,8 +96,6 @@ func (test translateTest) run(t *testing.T) {
 	}
 }
 
-// TODO test big5
-
 var testReaders = []func(io.Reader) io.Reader{
 	func(r io.Reader) io.Reader { return r },
 	iotest.OneByteReader,
@@ -102,7 +113,7 @@ var testTranslators = []func() charset.Translator{
 	func() charset.Translator { return new(shortTranslator) },
 }
 
-var codepageCharsets = []string{"latin1"}
+var codepageCharsets = []string{"latin1", "viscii", "ibm437", "ibm850"}
 
 func TestCodepages(t *testing.T) {
 	for _, name := range codepageCharsets {
@@ -150,6 +161,211 @@ func testCodepage(t *testing.T, name string, inReader, outReader func(io.Reader)
 	}
 }
 
+func TestNames(t *testing.T) {
+	names := charset.Names()
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("names are not sorted: %q", names)
+	}
+	found := make(map[string]bool)
+	for _, name := range names {
+		if found[name] {
+			t.Errorf("duplicate name %q", name)
+		}
+		found[name] = true
+	}
+	for _, name := range []string{"utf-8", "us-ascii", "windows-949"} {
+		if !found[name] {
+			t.Errorf("name %q not found", name)
+		}
+	}
+
+	all := charset.AllNames()
+	if !sort.StringsAreSorted(all) {
+		t.Errorf("all names are not sorted: %q", all)
+	}
+	foundAll := make(map[string]bool)
+	for _, name := range all {
+		foundAll[name] = true
+	}
+	for _, name := range []string{"cp949", "windows-949", "utf-8", "utf8", "us-ascii", "ascii"} {
+		if !foundAll[name] {
+			t.Errorf("name %q not found in all names", name)
+		}
+	}
+	for _, name := range names {
+		if !foundAll[name] {
+			t.Errorf("canonical name %q not found in all names", name)
+		}
+	}
+
+	charsets := charset.Charsets()
+	if len(charsets) != len(names) {
+		t.Fatalf("expected %d charsets, got %d", len(names), len(charsets))
+	}
+	aliases := make(map[string]string)
+	for i, cs := range charsets {
+		if cs.Name != names[i] {
+			t.Errorf("charset %d: expected %q got %q", i, names[i], cs.Name)
+		}
+		for _, a := range cs.Aliases {
+			aliases[a] = cs.Name
+		}
+	}
+	for alias, name := range map[string]string{
+		"cp949": "windows-949",
+		"cp936": "gbk",
+		"ascii": "us-ascii",
+		"utf8":  "utf-8",
+	} {
+		if aliases[alias] != name {
+			t.Errorf("alias %q: expected %q got %q", alias, name, aliases[alias])
+		}
+	}
+}
+
+var infoTests = []struct {
+	name      string
+	canonical string
+	noTo      bool
+}{
+	{"windows-949", "windows-949", false},
+	{"CP949", "windows-949", false},
+	{"Shift_JIS", "shift_jis", false},
+	{"latin1?strict", "iso-8859-1", false},
+	{"big5", "big5", true},
+	{"no-such-charset", "", false},
+}
+
+func TestInfo(t *testing.T) {
+	for _, test := range infoTests {
+		cs := charset.Info(test.name)
+		if test.canonical == "" {
+			if cs != nil {
+				t.Errorf("%q: expected nil, got %+v", test.name, cs)
+			}
+			continue
+		}
+		if cs == nil {
+			t.Errorf("%q: no info found", test.name)
+			continue
+		}
+		if cs.Name != test.canonical || cs.NoTo != test.noTo {
+			t.Errorf("%q: expected name %q, NoTo %v; got %q, %v", test.name, test.canonical, test.noTo, cs.Name, cs.NoTo)
+		}
+	}
+
+	// Changing the returned aliases must not affect later calls.
+	cs := charset.Info("cp949")
+	cs.Aliases[0] = "changed"
+	if cs := charset.Info("cp949"); cs.Aliases[0] == "changed" {
+		t.Errorf("aliases were changed")
+	}
+}
+
+var spellingTests = [][]string{
+	{"Shift_JIS", "shift-jis", "shiftjis", "SHIFT_JIS"},
+	{"UTF-8", "utf8", "utf_8", "Utf-8"},
+	{"ISO-8859-1", "iso_8859_1", "iso88591", "Latin1"},
+}
+
+func TestSpellings(t *testing.T) {
+	for _, names := range spellingTests {
+		want := charset.Info(names[0])
+		if want == nil {
+			t.Fatalf("no info found for %q", names[0])
+		}
+		for _, name := range names {
+			cs := charset.Info(name)
+			if cs == nil || cs.Name != want.Name {
+				t.Errorf("%q: expected %q got %+v", name, want.Name, cs)
+			}
+			if _, err := charset.TranslatorFrom(name); err != nil {
+				t.Errorf("%q: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestPreload(t *testing.T) {
+	if err := charset.Preload("cp949"); err != nil {
+		t.Fatalf("preload failed: %v", err)
+	}
+	// Loading the table takes thousands of allocations;
+	// making a translator should take only a few.
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := charset.TranslatorFrom("cp949"); err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+	})
+	if allocs > 10 {
+		t.Errorf("expected few allocations after preload, got %v", allocs)
+	}
+	if err := charset.Preload("no-such-charset"); err == nil {
+		t.Errorf("expected error preloading unknown charset")
+	}
+}
+
+func TestDecodeEncode(t *testing.T) {
+	for i, test := range tests {
+		r, err := charset.NewReader(test.charset, strings.NewReader(test.in))
+		if err != nil {
+			t.Fatalf("test %d: cannot make reader: %v", i, err)
+		}
+		want, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("test %d: read failed: %v", i, err)
+		}
+		out, err := charset.Decode(test.charset, []byte(test.in))
+		if err != nil {
+			t.Fatalf("test %d: decode failed: %v", i, err)
+		}
+		if string(out) != string(want) {
+			t.Errorf("test %d: decoding %q: expected %q got %q", i, test.charset, want, out)
+		}
+		if cs := charset.Info(test.charset); cs.NoTo || !test.canRoundTrip {
+			continue
+		}
+		out, err = charset.Encode(test.charset, []byte(test.out))
+		if err != nil {
+			t.Fatalf("test %d: encode failed: %v", i, err)
+		}
+		if string(out) != test.in {
+			t.Errorf("test %d: encoding %q: expected %q got %q", i, test.charset, test.in, out)
+		}
+	}
+	// Translators that hold data back until eof.
+	if out, err := charset.Decode("cp949", []byte("a\xb0")); err != nil || string(out) != "a\ufffd" {
+		t.Errorf("cp949 lone lead byte: got %q, %v", out, err)
+	}
+	if out, err := charset.Encode("utf-7", []byte("日本語")); err != nil || string(out) != "+ZeVnLIqe-" {
+		t.Errorf("utf-7: got %q, %v", out, err)
+	}
+	if _, err := charset.Decode("no-such-charset", nil); err == nil {
+		t.Errorf("expected error for unknown charset")
+	}
+}
+
+func TestDecodeEncodeString(t *testing.T) {
+	const text = "아름다운 우리말"
+	enc, err := charset.EncodeString("cp949", text)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if enc != "\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee \xbf\xec\xb8\xae\xb8\xbb" {
+		t.Errorf("encoding: got %q", enc)
+	}
+	dec, err := charset.DecodeString("cp949", enc)
+	if err != nil || dec != text {
+		t.Errorf("round trip: expected %q, got %q, %v", text, dec, err)
+	}
+	for _, f := range []func(string, string) (string, error){charset.DecodeString, charset.EncodeString} {
+		_, err := f("no-such-charset", "x")
+		if err == nil || !strings.Contains(err.Error(), "no-such-charset") {
+			t.Errorf("expected error naming the charset, got %v", err)
+		}
+	}
+}
+
 func TestTranslatingReader(t *testing.T) {
 	for _, tr := range testTranslators {
 		for _, inr := range testReaders {
@@ -177,7 +393,7 @@ func testTranslatingReader(t *testing.T, tr charset.Translator, inReader, outRea
 	}
 	err = checkTranslation(data, outbuf.Bytes())
 	if err != nil {
-		t.Fatalf("translator %T, readers %T, %T, %v\n", err)
+		t.Fatalf("translator %T, readers %T, %T, %v\n", tr, inr, outr, err)
 	}
 }
 
@@ -244,6 +460,16 @@ func (t *holdingTranslator) Translate(buf []byte, eof bool) (int, []byte, error)
 	return len(buf), t.scratch, nil
 }
 
+// waitingTranslator consumes nothing until the end.
+type waitingTranslator struct{}
+
+func (waitingTranslator) Translate(buf []byte, eof bool) (int, []byte, error) {
+	if !eof {
+		return 0, nil, nil
+	}
+	return len(buf), buf, nil
+}
+
 // shortTranslator translates only one byte at a time, even at eof.
 type shortTranslator [1]byte
 
@@ -277,3 +503,470 @@ func (w *oneByteWriter) Write(buf []byte) (int, error) {
 	}
 	return n, nil
 }
+
+func TestReset(t *testing.T) {
+	for _, name := range charset.Names() {
+		for dir, f := range map[string]func(string) (charset.Translator, error){"from": charset.TranslatorFrom, "to": charset.TranslatorTo} {
+			tr, err := f(name)
+			if err != nil {
+				continue
+			}
+			if _, ok := tr.(charset.ResetTranslator); !ok {
+				t.Errorf("%s translator %s %T does not implement ResetTranslator", name, dir, tr)
+			}
+		}
+	}
+
+	// Leave the translator in the JIS X 0208 shift state.
+	tr, err := charset.TranslatorFrom("iso-2022-jp")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if _, cdata, _ := tr.Translate([]byte("\x1b$BF|"), false); string(cdata) != "日" {
+		t.Fatalf("expected %q got %q", "日", cdata)
+	}
+	const in = "F|K\\ \x1b$BF|\x1b(B"
+	want := decodeString(t, "iso-2022-jp", in)
+	r := charset.NewTranslatingReader(strings.NewReader(in), tr)
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(out) != want {
+		t.Errorf("reused translator: expected %q got %q", want, out)
+	}
+}
+
+var astralTests = []struct {
+	charset string
+	in      string
+	out     string
+}{
+	{"gb18030", "a\U00020000b", "a\x95\x32\x82\x36b"},
+	{"big5-hkscs", "a\U00027267b", "a\x87\x45b"},
+	{"cp949", "a\U00020000b", "a?b"},
+	{"latin1", "a\U00020000b", "a?b"},
+	{"utf-8", "a\U00020000b", "a\U00020000b"},
+	{"utf-32be", "\U00020000", "\x00\x02\x00\x00"},
+}
+
+func TestEncodeAstral(t *testing.T) {
+	for _, test := range astralTests {
+		for split := 0; split <= len(test.in); split++ {
+			tr, err := charset.TranslatorTo(test.charset)
+			if err != nil {
+				t.Fatalf("%s: cannot make translator: %v", test.charset, err)
+			}
+			n, cdata, err := tr.Translate([]byte(test.in[:split]), false)
+			if err != nil {
+				t.Fatalf("%s: split %d: translate error: %v", test.charset, split, err)
+			}
+			out := string(cdata)
+			_, cdata, err = tr.Translate([]byte(test.in[n:]), true)
+			if err != nil {
+				t.Fatalf("%s: split %d: translate error: %v", test.charset, split, err)
+			}
+			out += string(cdata)
+			if out != test.out {
+				t.Errorf("%s: split %d: expected %x got %x", test.charset, split, test.out, out)
+			}
+		}
+	}
+}
+
+var canEncodeTests = []struct {
+	charset string
+	r       rune
+	ok      bool
+}{
+	{"cp949", '한', true},
+	{"cp949", 'a', true},
+	{"cp949", '😀', false},
+	{"cp949", '갂', true},
+	{"euc-kr?strict", '갂', false},
+	{"latin1", 'é', true},
+	{"latin1", '한', false},
+	{"latin1", '?', true},
+	{"shift_jis", 'あ', true},
+	{"utf-8", '😀', true},
+	{"utf-8", 0xd800, false},
+}
+
+func TestCanEncode(t *testing.T) {
+	for _, test := range canEncodeTests {
+		ok, err := charset.CanEncode(test.charset, test.r)
+		if err != nil {
+			t.Errorf("%s: %q: unexpected error: %v", test.charset, test.r, err)
+			continue
+		}
+		if ok != test.ok {
+			t.Errorf("%s: %q: expected %v got %v", test.charset, test.r, test.ok, ok)
+		}
+	}
+	if _, err := charset.CanEncode("no-such-charset", 'a'); err == nil {
+		t.Errorf("expected error for unknown character set")
+	}
+}
+
+var canEncodeAllTests = []struct {
+	charset string
+	s       string
+	ok      bool
+	bad     rune
+}{
+	{"cp949", "아름다운 우리말", true, 0},
+	{"cp949", "아름다운 ☃ 우리말", false, '☃'},
+	{"cp949", "", true, 0},
+	{"latin1", "café", true, 0},
+	{"latin1", "café 한 😀", false, '한'},
+	{"iso-2022-jp", "日本語", true, 0},
+	{"iso-2022-jp", "日本語한", false, '한'},
+	{"utf-8", "a\xffb", false, utf8.RuneError},
+	{"no-such-charset", "a", false, utf8.RuneError},
+}
+
+func TestCanEncodeAll(t *testing.T) {
+	for _, test := range canEncodeAllTests {
+		ok, bad := charset.CanEncodeAll(test.charset, test.s)
+		if ok != test.ok || bad != test.bad {
+			t.Errorf("%s: %q: expected %v, %q; got %v, %q", test.charset, test.s, test.ok, test.bad, ok, bad)
+		}
+	}
+}
+
+func TestEncodableCharsets(t *testing.T) {
+	names := charset.EncodableCharsets("한국어 텍스트")
+	has := make(map[string]bool)
+	for _, name := range names {
+		has[name] = true
+	}
+	for _, name := range []string{"windows-949", "euc-kr", "johab", "utf-8", "utf-16"} {
+		if !has[name] {
+			t.Errorf("%s missing from %q", name, names)
+		}
+	}
+	for _, name := range []string{"iso-8859-1", "us-ascii", "gbk", "shift_jis", "big5",
+		"identity", "newlines", "case", "width", "strip-bom", "x-rot13"} {
+		if has[name] {
+			t.Errorf("%s unexpected in %q", name, names)
+		}
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("names not sorted: %q", names)
+	}
+	if names := charset.EncodableCharsets("a\xffb"); names != nil {
+		t.Errorf("invalid UTF-8: expected nil, got %q", names)
+	}
+	// U+FFFD itself is valid input.
+	names = charset.EncodableCharsets("a\ufffd")
+	for _, name := range []string{"utf-8", "utf-16", "utf-32", "gb18030"} {
+		if i := sort.SearchStrings(names, name); i == len(names) || names[i] != name {
+			t.Errorf("U+FFFD: %s missing from %q", name, names)
+		}
+	}
+	names = charset.EncodableCharsets("abc")
+	if i := sort.SearchStrings(names, "iso-8859-1"); i == len(names) || names[i] != "iso-8859-1" {
+		t.Errorf("abc: iso-8859-1 missing from %q", names)
+	}
+}
+
+var errorPrefixTests = []struct {
+	charset string
+	decode  bool
+	in      string
+	n       int
+	out     string
+}{
+	{"us-ascii?strict", true, "abc\xffdef", 3, "abc"},
+	{"gb18030?strict", true, "a\xd6\xd0\x80b", 3, "a中"},
+	{"gb18030?strict", true, "a\x84\x31\xa5\x30", 1, "a"},
+	{"cp949?maxbytes=3", true, "ab\xbe\xc6", 2, "ab"},
+	{"cp949?strict", false, "ab☃c", 2, "ab"},
+	{"gb18030?strict", false, "中\xffb", 3, "\xd6\xd0"},
+	{"gbk?strict", false, "中☃", 3, "\xd6\xd0"},
+	{"latin1?strict", false, "é☃", 2, "\xe9"},
+}
+
+// TestErrorPrefix checks that a Translator returning an error
+// also returns the translation of the input before it.
+func TestErrorPrefix(t *testing.T) {
+	for _, test := range errorPrefixTests {
+		newTr, translate := charset.TranslatorTo, charset.Encode
+		if test.decode {
+			newTr, translate = charset.TranslatorFrom, charset.Decode
+		}
+		tr, err := newTr(test.charset)
+		if err != nil {
+			t.Fatalf("%s: cannot make translator: %v", test.charset, err)
+		}
+		n, cdata, err := tr.Translate([]byte(test.in), true)
+		if err == nil || n != test.n || string(cdata) != test.out {
+			t.Errorf("%s: %q: expected %d, %q, error; got %d, %q, %v", test.charset, test.in, test.n, test.out, n, cdata, err)
+		}
+		out, err := translate(test.charset, []byte(test.in))
+		if err == nil || string(out) != test.out {
+			t.Errorf("%s: %q: expected %q, error; got %q, %v", test.charset, test.in, test.out, out, err)
+		}
+	}
+}
+
+func TestReadAhead(t *testing.T) {
+	in := strings.Repeat("x", 2*charset.MaxReadAhead)
+	r := charset.NewTranslatingReader(iotest.OneByteReader(strings.NewReader(in)), waitingTranslator{})
+	if out, err := io.ReadAll(r); err != charset.ErrReadAhead || len(out) != 0 {
+		t.Errorf("expected ErrReadAhead, got %q, %v", out, err)
+	}
+
+	// Text in cp949 read a byte at a time is held back
+	// no more than a byte, whatever the limit.
+	defer func(n int) { charset.MaxReadAhead = n }(charset.MaxReadAhead)
+	charset.MaxReadAhead = 1
+	r, err := charset.NewReader("cp949", iotest.OneByteReader(strings.NewReader("\xbe\xc6\xb8\xa7")))
+	if err != nil {
+		t.Fatalf("cannot make reader: %v", err)
+	}
+	if out, err := io.ReadAll(r); err != nil || string(out) != "아름" {
+		t.Errorf("expected %q, got %q, %v", "아름", out, err)
+	}
+
+	// With no limit, everything is held until the end.
+	charset.MaxReadAhead = 0
+	r = charset.NewTranslatingReader(strings.NewReader(in), waitingTranslator{})
+	if out, err := io.ReadAll(r); err != nil || string(out) != in {
+		t.Errorf("expected all the input, got %d bytes, %v", len(out), err)
+	}
+}
+
+func TestSetCharset(t *testing.T) {
+	// The header, in ASCII, gives the character set of the rest.
+	in := "charset: cp949\n\xbe\xc6\xb8\xa7"
+	r, err := charset.NewReader("us-ascii", strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("cannot make reader: %v", err)
+	}
+	var header []byte
+	buf := make([]byte, 1)
+	for len(header) == 0 || header[len(header)-1] != '\n' {
+		n, err := r.Read(buf)
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		header = append(header, buf[:n]...)
+	}
+	sr := r.(charset.SwitchingReader)
+	if err := sr.SetCharset("no-such-charset"); err == nil {
+		t.Errorf("expected error for unknown charset")
+	}
+	if err := sr.SetCharset(strings.TrimSpace(strings.TrimPrefix(string(header), "charset:"))); err != nil {
+		t.Fatalf("cannot set charset: %v", err)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil || string(body) != "아름" {
+		t.Errorf("expected %q, got %q, %v", "아름", body, err)
+	}
+}
+
+func TestSetCharsetHeld(t *testing.T) {
+	// A two-byte Read leaves the lead byte of 아 held
+	// by the cp949 Translator when the Reader switches.
+	r, err := charset.NewReader("cp949", strings.NewReader("a\xbe\xc6"))
+	if err != nil {
+		t.Fatalf("cannot make reader: %v", err)
+	}
+	buf := make([]byte, 2)
+	if n, err := r.Read(buf); n != 1 || err != nil || buf[0] != 'a' {
+		t.Fatalf("expected %q, got %q, %v", "a", buf[:n], err)
+	}
+	if err := r.(charset.SwitchingReader).SetCharset("latin1"); err != nil {
+		t.Fatalf("cannot set charset: %v", err)
+	}
+	if out, err := io.ReadAll(r); err != nil || string(out) != "¾Æ" {
+		t.Errorf("expected %q, got %q, %v", "¾Æ", out, err)
+	}
+}
+
+// TestLeadByteThenControl checks that a byte that cannot
+// trail a lead byte, such as a newline, ends the character
+// and is decoded as itself.
+func TestLeadByteThenControl(t *testing.T) {
+	leads := map[string]string{
+		"big5":        "\xa4",
+		"big5-hkscs":  "\xa4",
+		"cp949":       "\xb0",
+		"euc-jp":      "\xa4",
+		"euc-kr":      "\xb0",
+		"euc-tw":      "\xa4",
+		"gb18030":     "\xb0",
+		"gbk":         "\xb0",
+		"johab":       "\x88",
+		"shift_jis":   "\x82",
+		"windows-31j": "\x82",
+	}
+	for cs, lead := range leads {
+		for _, c := range []string{"\n", "\x00", "\r", " ", "\x1f"} {
+			in := "a" + lead + c + "b"
+			want := "a" + string(utf8.RuneError) + c + "b"
+			if out := decodeString(t, cs, in); out != want {
+				t.Errorf("%s: decoding %x: expected %q got %q", cs, in, want, out)
+			}
+		}
+	}
+}
+
+var maxBytesPerCharTests = []struct {
+	charset string
+	max     int
+}{
+	{"latin1", 1},
+	{"us-ascii", 1},
+	{"windows-1252", 1},
+	{"cp949", 2},
+	{"euc-kr", 2},
+	{"gbk", 2},
+	{"big5", 2},
+	{"shift_jis", 2},
+	{"euc-jp", 3},
+	{"gb18030", 4},
+	{"utf-8", 4},
+	{"utf-16le", 4},
+	{"cesu-8", 6},
+	{"iso-2022-jp", 0},
+	{"no-such-charset", 0},
+}
+
+func TestMaxBytesPerChar(t *testing.T) {
+	for _, test := range maxBytesPerCharTests {
+		if max := charset.MaxBytesPerChar(test.charset); max != test.max {
+			t.Errorf("%s: expected %d, got %d", test.charset, test.max, max)
+		}
+	}
+}
+
+// TestLoneLeadByteAtEOF checks that a lead byte at the very
+// end of the input decodes to a single utf8.RuneError.
+func TestLoneLeadByteAtEOF(t *testing.T) {
+	leads := map[string][]string{
+		"big5":        {"\xa4"},
+		"big5-hkscs":  {"\xa4", "\x88"},
+		"cp949":       {"\xb0", "\x81"},
+		"euc-jp":      {"\xa4", "\x8e", "\x8f"},
+		"euc-kr":      {"\xb0"},
+		"euc-tw":      {"\xa4", "\x8e"},
+		"gb18030":     {"\xb0", "\x81"},
+		"gbk":         {"\xb0"},
+		"johab":       {"\x88"},
+		"shift_jis":   {"\x82"},
+		"windows-31j": {"\x82"},
+		"utf-8":       {"\xe3"},
+		"cesu-8":      {"\xed", "\xed\xa0\xbd"},
+	}
+	want := "a" + string(utf8.RuneError)
+	for cs, tails := range leads {
+		for _, tail := range tails {
+			tr, err := charset.TranslatorFrom(cs)
+			if err != nil {
+				t.Fatalf("%s: cannot make translator: %v", cs, err)
+			}
+			in := "a" + tail
+			n, cdata, err := tr.Translate([]byte(in), true)
+			if n != len(in) || string(cdata) != want || err != nil {
+				t.Errorf("%s: %x: expected %d, %q; got %d, %q, %v", cs, in, len(in), want, n, cdata, err)
+			}
+		}
+	}
+}
+
+// writeRecorder records the data of each Write.
+type writeRecorder struct {
+	writes []string
+}
+
+func (w *writeRecorder) Write(data []byte) (int, error) {
+	w.writes = append(w.writes, string(data))
+	return len(data), nil
+}
+
+func TestWriterThreshold(t *testing.T) {
+	in := strings.Repeat("아름다운 ", 10)
+	want := strings.Repeat("\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee ", 10)
+	for _, test := range []struct {
+		threshold int
+		writes    int
+	}{
+		{0, 1},
+		{-1, 1},
+		{16, (len(in) + 15) / 16},
+		{1000, 1},
+	} {
+		var rec writeRecorder
+		w, err := charset.NewWriterThreshold("cp949", &rec, test.threshold)
+		if err != nil {
+			t.Fatalf("cannot make writer: %v", err)
+		}
+		if n, err := w.Write([]byte(in)); n != len(in) || err != nil {
+			t.Fatalf("threshold %d: expected %d, got %d, %v", test.threshold, len(in), n, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("threshold %d: close: %v", test.threshold, err)
+		}
+		if out := strings.Join(rec.writes, ""); out != want {
+			t.Errorf("threshold %d: expected %x, got %x", test.threshold, want, out)
+		}
+		if len(rec.writes) != test.writes {
+			t.Errorf("threshold %d: expected %d writes, got %d", test.threshold, test.writes, len(rec.writes))
+		}
+		for _, s := range rec.writes {
+			if test.threshold > 0 && len(s) > test.threshold {
+				t.Errorf("threshold %d: write of %d bytes", test.threshold, len(s))
+			}
+		}
+	}
+}
+
+var replacementOptionTests = []struct {
+	from bool
+	name string
+	in   string
+	out  string
+}{
+	{true, "big5", "a\x80b", "a�b"},
+	{true, "big5?replacement=0x3f", "a\x80b", "a?b"},
+	{true, "big5-hkscs?drop", "a\x80b", "ab"},
+	{true, "shift_jis?replacement=0x3f", "a\x81 b", "a? b"},
+	{true, "euc-jp?drop", "a\x8e b", "a b"},
+	{true, "euc-tw?replacement=0x2a", "a\xa4", "a*"},
+	{true, "johab?replacement=0x3f", "a\x84 ", "a? "},
+	{true, "gbk?replacement=0x3f", "a\xffb", "a?b"},
+	{true, "gb2312?strict&drop", "a\x81\x40b", "ab"},
+	{true, "windows-1252", "a\x81b", "a�b"},
+	{true, "windows-1252?replacement=0x3f", "a\x81b", "a?b"},
+	{true, "windows-1252?drop", "a\x81b", "ab"},
+	{false, "gbk", "a☃b", "a?b"},
+	{false, "gbk?replacement=0x2a", "a☃b", "a*b"},
+	{false, "gb2312?drop", "a☃b", "ab"},
+	{false, "latin1", "a☃b", "a?b"},
+	{false, "latin1?replacement=0x2a", "a☃b", "a*b"},
+	{false, "latin1?drop", "a☃b", "ab"},
+}
+
+func TestReplacementOptions(t *testing.T) {
+	for _, test := range replacementOptionTests {
+		var tr charset.Translator
+		var err error
+		if test.from {
+			tr, err = charset.TranslatorFrom(test.name)
+		} else {
+			tr, err = charset.TranslatorTo(test.name)
+		}
+		if err != nil {
+			t.Fatalf("%q: cannot make translator: %v", test.name, err)
+		}
+		out, err := translate(tr, test.in)
+		if err != nil {
+			t.Fatalf("%q: translate error: %v", test.name, err)
+		}
+		if out != test.out {
+			t.Errorf("%q: expected %q got %q", test.name, test.out, out)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package charset
+
+// charSizer is implemented by from-translators that
+// can say how long the encoding of a character can be.
+type charSizer interface {
+	maxCharBytes() int
+}
+
+// MaxBytesPerChar returns the most bytes that one character
+// can take in the named character set: 1 for single-byte
+// character sets such as latin1, 2 for cp949, 4 for gb18030
+// and utf-8, and so on. It returns 0 if the character set is not
+// known, or if it has no such bound, as for the stateful
+// encodings such as iso-2022-jp, in which an escape sequence
+// may come before any character.
+func MaxBytesPerChar(name string) int {
+	tr, err := TranslatorFrom(name)
+	if err != nil {
+		return 0
+	}
+	if cs, ok := tr.(charSizer); ok {
+		return cs.maxCharBytes()
+	}
+	return 0
+}
+
+func (*translateFromASCII) maxCharBytes() int       { return 1 }
+func (*translateFromCodePage) maxCharBytes() int    { return 1 }
+func (*translateFromUserDefined) maxCharBytes() int { return 1 }
+func (*translateFromBig5) maxCharBytes() int        { return 2 }
+func (*translateFromCP932) maxCharBytes() int       { return 2 }
+func (*translateFromCp949) maxCharBytes() int       { return 2 }
+func (*translateFromGBK) maxCharBytes() int         { return 2 }
+func (*translateFromJohab) maxCharBytes() int       { return 2 }
+func (*translateFromEUCJP) maxCharBytes() int       { return 3 }
+func (*translateFromEUCTW) maxCharBytes() int       { return 4 }
+func (*translateFromGB18030) maxCharBytes() int     { return 4 }
+func (*translateFromUTF8) maxCharBytes() int        { return 4 }
+func (*translateFromUTF16) maxCharBytes() int       { return 4 }
+func (*translateFromUTF32) maxCharBytes() int       { return 4 }
+
+// A character above U+FFFF takes two three-byte surrogates.
+func (*translateFromCESU8) maxCharBytes() int { return 6 }
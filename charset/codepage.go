@@ -2,6 +2,7 @@ package charset
 
 import (
 	"fmt"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -10,14 +11,27 @@ func init() {
 }
 
 type translateFromCodePage struct {
-	byte2rune *[256]rune
-	scratch   []byte
+	byte2rune   *[256]rune
+	latin1      bool   // every byte decodes to the rune of the same value
+	replacement []byte // substituted for undefined bytes
+	scratch     []byte
 }
 
 type cpKeyFrom string
 type cpKeyTo string
 
+// passthrough0 is added to an undefined byte to give its rune
+// with the "passthrough-undefined" option.
+const passthrough0 = 0xf700
+
+func (p *translateFromCodePage) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
 func (p *translateFromCodePage) Translate(data []byte, eof bool) (int, []byte, error) {
+	if p.latin1 {
+		return len(data), p.translateLatin1(data), nil
+	}
 	p.scratch = ensureCap(p.scratch, len(data)*utf8.UTFMax)[:0]
 	buf := p.scratch
 	for _, x := range data {
@@ -26,22 +40,73 @@ func (p *translateFromCodePage) Translate(data []byte, eof bool) (int, []byte, e
 			buf = append(buf, byte(r))
 			continue
 		}
+		if r == utf8.RuneError {
+			buf = append(buf, p.replacement...)
+			continue
+		}
 		size := utf8.EncodeRune(buf[len(buf):cap(buf)], r)
 		buf = buf[0 : len(buf)+size]
 	}
 	return len(data), buf, nil
 }
 
+// translateLatin1 decodes data in which every byte stands
+// for the rune of the same value, as in ISO 8859-1, without
+// consulting the table.
+func (p *translateFromCodePage) translateLatin1(data []byte) []byte {
+	p.scratch = ensureCap(p.scratch, len(data)*2)
+	buf := p.scratch[:len(data)*2]
+	n := 0
+	for _, x := range data {
+		if x < utf8.RuneSelf {
+			buf[n] = x
+			n++
+			continue
+		}
+		buf[n] = 0xc0 | x>>6
+		buf[n+1] = 0x80 | x&0x3f
+		n += 2
+	}
+	return buf[:n]
+}
+
 type toCodePageInfo struct {
 	rune2byte map[rune]byte
 	// same gives the number of runes at start of code page that map exactly to
 	// unicode.
 	same rune
+	// undefined holds the positions undefined in the code page.
+	undefined [256]bool
+}
+
+// arabicForm returns the encoding of the letters for which r
+// is an Arabic presentation form, if the code page holds them.
+func (p *toCodePageInfo) arabicForm(r rune) ([]byte, bool) {
+	letters, ok := arabicLetters(r)
+	if !ok {
+		return nil, false
+	}
+	var code []byte
+	for _, l := range letters {
+		b, ok := p.rune2byte[l]
+		if !ok {
+			return nil, false
+		}
+		code = append(code, b)
+	}
+	return code, true
 }
 
 type translateToCodePage struct {
 	toCodePageInfo
-	scratch []byte
+	strict      bool
+	passthrough bool   // encode passthrough runes as undefined bytes
+	replacement []byte // substituted for runes with no encoding
+	scratch     []byte
+}
+
+func (p *translateToCodePage) Reset() {
+	p.scratch = p.scratch[:0]
 }
 
 func (p *translateToCodePage) Translate(data []byte, eof bool) (int, []byte, error) {
@@ -53,7 +118,7 @@ func (p *translateToCodePage) Translate(data []byte, eof bool) (int, []byte, err
 		size := 1
 		if r >= utf8.RuneSelf {
 			r, size = utf8.DecodeRune(data[i:])
-			if size == 1 && !eof && !utf8.FullRune(data) {
+			if size == 1 && !eof && !utf8.FullRune(data[i:]) {
 				return i, buf, nil
 			}
 		}
@@ -64,8 +129,22 @@ func (p *translateToCodePage) Translate(data []byte, eof bool) (int, []byte, err
 		} else {
 			var ok bool
 			b, ok = p.rune2byte[r]
+			if !ok && p.passthrough && r >= passthrough0 && r < passthrough0+256 {
+				b = byte(r - passthrough0)
+				ok = p.undefined[b]
+			}
 			if !ok {
-				b = '?'
+				if letters, ok := p.arabicForm(r); ok {
+					buf = append(buf, letters...)
+					i += size
+					continue
+				}
+				if p.strict {
+					return i, buf, strictError(i, r, size)
+				}
+				buf = append(buf, p.replacement...)
+				i += size
+				continue
 			}
 		}
 		buf = append(buf, b)
@@ -74,16 +153,25 @@ func (p *translateToCodePage) Translate(data []byte, eof bool) (int, []byte, err
 	return len(data), buf, nil
 }
 
+// factory to create translateFromCodePage.
+// Undefined bytes decode to utf8.RuneError, or to the rune
+// given by the "replacement" option, or are omitted with the
+// "drop" option, or with the "passthrough-undefined" option
+// decode to passthrough0 plus the byte.
 func fromCodePage(arg string) (Translator, error) {
+	arg, opts, err := splitArg(arg, "passthrough-undefined", "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
 	runes, err := cache(cpKeyFrom(arg), func() (interface{}, error) {
-		data, err := readFile(arg)
+		runes, err := codePageRunes(arg)
 		if err != nil {
 			return nil, err
 		}
-		runes := []rune(string(data))
-		if len(runes) != 256 {
-			return nil, fmt.Errorf("charset: %q has wrong rune count (%d)", arg, len(runes))
-		}
 		r := new([256]rune)
 		copy(r[:], runes)
 		return r, nil
@@ -91,12 +179,43 @@ func fromCodePage(arg string) (Translator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &translateFromCodePage{byte2rune: runes.(*[256]rune)}, nil
+	byte2rune := runes.(*[256]rune)
+	if opts.has("passthrough-undefined") {
+		t := *byte2rune
+		for i, r := range t {
+			if r == utf8.RuneError {
+				t[i] = passthrough0 + rune(i)
+			}
+		}
+		byte2rune = &t
+	}
+	latin1 := true
+	for i, r := range byte2rune {
+		if r != rune(i) {
+			latin1 = false
+			break
+		}
+	}
+	return &translateFromCodePage{byte2rune: byte2rune, latin1: latin1, replacement: repl}, nil
 }
 
+// factory to create translateToCodePage. Runes with no
+// encoding are translated to '?', or to the byte given by
+// the "replacement" option, or are omitted with the "drop"
+// option, or with the "strict" option cause an *UnmappableError.
+// The "passthrough-undefined" option encodes the runes given by
+// fromCodePage for undefined bytes as those bytes.
 func toCodePage(arg string) (Translator, error) {
+	arg, opts, err := splitArg(arg, "strict", "passthrough-undefined", "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
 	m, err := cache(cpKeyTo(arg), func() (interface{}, error) {
-		data, err := readFile(arg)
+		runes, err := codePageRunes(arg)
 		if err != nil {
 			return nil, err
 		}
@@ -107,7 +226,7 @@ func toCodePage(arg string) (Translator, error) {
 		}
 		atStart := true
 		i := rune(0)
-		for _, r := range string(data) {
+		for _, r := range runes {
 			if atStart {
 				if r == i {
 					i++
@@ -116,18 +235,71 @@ func toCodePage(arg string) (Translator, error) {
 				info.same = i
 				atStart = false
 			}
-			info.rune2byte[r] = byte(i)
+			// Undefined positions hold utf8.RuneError,
+			// which must not be encoded as any of them.
+			if r != utf8.RuneError {
+				info.rune2byte[r] = byte(i)
+			} else {
+				info.undefined[i] = true
+			}
 			i++
 		}
-		// TODO fix tables
-		// fmt.Printf("%s, same = %d\n", arg, info.same)
-		if i != 256 {
-			return nil, fmt.Errorf("charset: %q has wrong rune count (%d)", arg, i)
-		}
 		return info, nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &translateToCodePage{toCodePageInfo: m.(toCodePageInfo)}, nil
+	return &translateToCodePage{
+		toCodePageInfo: m.(toCodePageInfo),
+		strict:         opts.has("strict"),
+		passthrough:    opts.has("passthrough-undefined"),
+		replacement:    repl,
+	}, nil
+}
+
+// codePageOverrides holds changes to the table of a code page, for
+// character sets that share the table of another with a few bytes
+// different. They are named after the file in the class argument,
+// as in "tis-620.cp windows-874".
+var codePageOverrides = map[string]map[byte]rune{
+	// Windows puts punctuation in some of the C1 controls
+	// of TIS-620, leaving the others undefined, and defines
+	// 0xa0 as a no-break space.
+	"windows-874": {
+		0x80: '€', 0x81: utf8.RuneError, 0x82: utf8.RuneError, 0x83: utf8.RuneError,
+		0x84: utf8.RuneError, 0x85: '…', 0x86: utf8.RuneError, 0x87: utf8.RuneError,
+		0x88: utf8.RuneError, 0x89: utf8.RuneError, 0x8a: utf8.RuneError, 0x8b: utf8.RuneError,
+		0x8c: utf8.RuneError, 0x8d: utf8.RuneError, 0x8e: utf8.RuneError, 0x8f: utf8.RuneError,
+		0x90: utf8.RuneError, 0x91: '‘', 0x92: '’', 0x93: '“',
+		0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+		0x98: utf8.RuneError, 0x99: utf8.RuneError, 0x9a: utf8.RuneError, 0x9b: utf8.RuneError,
+		0x9c: utf8.RuneError, 0x9d: utf8.RuneError, 0x9e: utf8.RuneError, 0x9f: utf8.RuneError,
+		0xa0: 0xa0,
+	},
+}
+
+// codePageRunes returns the runes for the 256 bytes of the code
+// page given by arg: the name of its file, optionally followed
+// by a space and the name of an entry in codePageOverrides.
+func codePageRunes(arg string) ([]rune, error) {
+	file, override := arg, ""
+	if i := strings.IndexByte(arg, ' '); i >= 0 {
+		file, override = arg[:i], arg[i+1:]
+	}
+	changes, ok := codePageOverrides[override]
+	if override != "" && !ok {
+		return nil, fmt.Errorf("charset: unknown code page override %q", override)
+	}
+	data, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(string(data))
+	if len(runes) != 256 {
+		return nil, fmt.Errorf("charset: %q has wrong rune count (%d)", file, len(runes))
+	}
+	for b, r := range changes {
+		runes[b] = r
+	}
+	return runes, nil
 }
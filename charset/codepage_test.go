@@ -0,0 +1,231 @@
+package charset_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var codepageUndefinedTests = []struct {
+	charset   string
+	undefined byte
+}{
+	{"windows-1251", 0x98},
+	{"windows-1252", 0x81},
+	{"windows-1252", 0x8d},
+	{"windows-1252", 0x8f},
+	{"windows-1252", 0x90},
+	{"windows-1252", 0x9d},
+	{"tis-620", 0xa0},
+	{"tis-620", 0xdb},
+	{"tis-620", 0xfc},
+	{"windows-874", 0x81},
+	{"windows-874", 0xff},
+	{"macintosh", 0xf0},
+	{"windows-1255", 0xd9},
+	{"windows-1255", 0xdf},
+	{"windows-1255", 0xfb},
+	{"windows-1255", 0xfc},
+	{"windows-1255", 0xff},
+	{"iso-8859-11", 0xdb},
+	{"iso-8859-11", 0xff},
+}
+
+// TestCodepageUndefined checks that undefined positions in
+// a code page decode to utf8.RuneError and that utf8.RuneError
+// is not encoded as an undefined position.
+func TestCodepageUndefined(t *testing.T) {
+	for _, test := range codepageUndefinedTests {
+		if out := decodeString(t, test.charset, string([]byte{test.undefined})); out != string(utf8.RuneError) {
+			t.Errorf("%q: %#x decoded to %q", test.charset, test.undefined, out)
+		}
+		if out := encodeString(t, test.charset, string(utf8.RuneError)); out != "?" {
+			t.Errorf("%q: utf8.RuneError encoded to %x", test.charset, out)
+		}
+	}
+}
+
+var iso8859Tests = []struct {
+	charset string
+	utf8    string
+	cs      string
+}{
+	{"iso-8859-6", "مرحبا", "\xe5\xd1\xcd\xc8\xc7"},
+	{"iso-8859-7", "Καλημέρα", "\xca\xe1\xeb\xe7\xec\xdd\xf1\xe1"},
+	{"iso-8859-8", "שלום", "\xf9\xec\xe5\xed"},
+	{"iso-8859-11", "สวัสดี", "\xca\xc7\xd1\xca\xb4\xd5"},
+	{"iso-8859-13", "Łódź ąčęėįšųūž", "\xd9\xf3d\xea \xe0\xe8\xe6\xeb\xe1\xf0\xf8\xfb\xfe"},
+	{"iso-8859-14", "Ŵŷ ḃḋḟ", "\xd0\xfe \xa2\xab\xb1"},
+	{"iso-8859-15", "€ œŒ Ÿ", "\xa4 \xbd\xbc \xbe"},
+	{"iso-8859-16", "ș ț Ș Ț €", "\xba \xfe \xaa \xde \xa4"},
+	{"greek", "Ω", "\xd9"},
+	{"latin8", "ẞ", "?"},
+	{"latin10", "中", "?"},
+}
+
+func TestISO8859(t *testing.T) {
+	for _, test := range iso8859Tests {
+		if out := encodeString(t, test.charset, test.utf8); out != test.cs {
+			t.Errorf("%s: encoding %q: expected %x got %x", test.charset, test.utf8, test.cs, out)
+		}
+		if test.cs == "?" {
+			continue
+		}
+		if out := decodeString(t, test.charset, test.cs); out != test.utf8 {
+			t.Errorf("%s: decoding %x: expected %q got %q", test.charset, test.cs, test.utf8, out)
+		}
+	}
+}
+
+func TestCodepagePassthrough(t *testing.T) {
+	var all []byte
+	for i := 0; i < 256; i++ {
+		all = append(all, byte(i))
+	}
+	for _, cs := range []string{"windows-1252", "windows-1255", "tis-620", "latin1"} {
+		name := cs + "?passthrough-undefined"
+		out := decodeString(t, name, string(all))
+		if strings.ContainsRune(out, utf8.RuneError) {
+			t.Errorf("%s: decoded to %q", name, out)
+		}
+		if back := encodeString(t, name, out); back != string(all) {
+			t.Errorf("%s: round trip gave %x", name, back)
+		}
+	}
+	if out := decodeString(t, "windows-1252?passthrough-undefined", "a\x81"); out != "a\uf781" {
+		t.Errorf("expected %q, got %q", "a\uf781", out)
+	}
+	// Only undefined positions are encoded so, and
+	// only with the option.
+	for name, out := range map[string]string{
+		"windows-1252":                       "??",
+		"windows-1252?passthrough-undefined": "\x81?",
+	} {
+		if got := encodeString(t, name, "\uf781\uf7e9"); got != out {
+			t.Errorf("%s: expected %x, got %x", name, out, got)
+		}
+	}
+}
+
+func TestWindows1256(t *testing.T) {
+	const (
+		arabic = "السلام عليكم، كيف حالك؟"
+		native = "\xc7\xe1\xd3\xe1\xc7\xe3 \xda\xe1\xed\xdf\xe3\xa1 \xdf\xed\xdd \xcd\xc7\xe1\xdf\xbf"
+	)
+	if out := decodeString(t, "windows-1256", native); out != arabic {
+		t.Errorf("decoding: expected %q got %q", arabic, out)
+	}
+	if out := encodeString(t, "cp1256", arabic); out != native {
+		t.Errorf("encoding: expected %x got %x", native, out)
+	}
+	// Presentation forms are encoded as the letters they stand for:
+	// meem initial, reh final, hah medial, beh medial and alef final,
+	// then the ligature of lam with alef madda.
+	for _, test := range []struct {
+		name, in, out string
+	}{
+		{"windows-1256", "\ufee3\ufeae\ufea4\ufe92\ufe8e", "\xe3\xd1\xcd\xc8\xc7"},
+		{"windows-1256", "\ufef5", "\xe1\xc2"},
+		{"windows-1256?strict", "\ufef5", "\xe1\xc2"},
+		{"iso-8859-6", "\ufef5", "\xe4\xc2"},
+	} {
+		if out := encodeString(t, test.name, test.in); out != test.out {
+			t.Errorf("%s: encoding %q: expected %x got %x", test.name, test.in, test.out, out)
+		}
+	}
+	// windows-1252 has no Arabic letters to fall back on.
+	if out := encodeString(t, "windows-1252", "\ufef5"); out != "?" {
+		t.Errorf("windows-1252: expected %q got %q", "?", out)
+	}
+}
+
+func TestWindows1255(t *testing.T) {
+	for _, test := range []struct {
+		native, hebrew string
+	}{
+		// The letters alef to tav.
+		{"\xe0\xe1\xe2\xe3\xe4\xe5\xe6\xe7\xe8\xe9\xea\xeb\xec\xed\xee\xef\xf0\xf1\xf2\xf3\xf4\xf5\xf6\xf7\xf8\xf9\xfa", "אבגדהוזחטיךכלםמןנסעףפץצקרשת"},
+		// "shalom olam" with qamats, shin dot, holam and patah points.
+		{"\xf9\xc8\xd1\xec\xe5\xc9\xed \xf2\xe5\xc9\xec\xc8\xed", "\u05e9\u05b8\u05c1\u05dc\u05d5\u05b9\u05dd \u05e2\u05d5\u05b9\u05dc\u05b8\u05dd"},
+		{"\xc0\xc4\xca\xd8", "\u05b0\u05b4\u05ba\u05f4"},
+		{"\xa4", "\u20aa"},
+	} {
+		if out := decodeString(t, "windows-1255", test.native); out != test.hebrew {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.hebrew, out)
+		}
+		if out := encodeString(t, "cp1255", test.hebrew); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.hebrew, test.native, out)
+		}
+	}
+	// The C0 controls and DEL are as in ASCII.
+	controls := "\r\n"
+	for b := 0; b < 0x20; b++ {
+		controls += string(rune(b))
+	}
+	controls += "\x7f"
+	if out := decodeString(t, "windows-1255", controls); out != controls {
+		t.Errorf("decoding %x: got %x", controls, out)
+	}
+	if out := encodeString(t, "windows-1255", controls); out != controls {
+		t.Errorf("encoding %x: got %x", controls, out)
+	}
+}
+
+// TestLatin1 checks that every byte decodes to the rune
+// with the same value, and encodes back to itself.
+func TestLatin1(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	for _, name := range []string{"latin1", "iso-8859-1"} {
+		out := decodeString(t, name, string(all))
+		i := 0
+		for _, r := range out {
+			if r != rune(i) {
+				t.Errorf("%s: %#02x decodes to %U", name, i, r)
+			}
+			i++
+		}
+		if i != 256 {
+			t.Errorf("%s: expected 256 runes, got %d", name, i)
+		}
+		if back := encodeString(t, name, out); back != string(all) {
+			t.Errorf("%s: round trip failed: got %x", name, back)
+		}
+		if out := encodeString(t, name, "\u00ff\u0100€😀"); out != "\xff???" {
+			t.Errorf("%s: expected %x, got %x", name, "\xff???", out)
+		}
+	}
+}
+
+func BenchmarkLatin1Decode(b *testing.B) {
+	tr, err := charset.TranslatorFrom("latin1")
+	if err != nil {
+		b.Fatalf("cannot make translator: %v", err)
+	}
+	line := []byte("Voix ambigu\xeb d'un c\x9cur qui, au z\xe9phyr, pr\xe9f\xe8re les jattes de kiwis.\n")
+	in := bytes.Repeat(line, 1<<20/len(line))
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		tr.Translate(in, true)
+	}
+}
+
+// TestWindows874 checks that windows-874, which shares the
+// table of tis-620, differs from it only in 80..a0.
+func TestWindows874(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		b := string([]byte{byte(i)})
+		tis, win := decodeString(t, "tis-620", b), decodeString(t, "windows-874", b)
+		if (tis != win) != (i >= 0x80 && i <= 0xa0) {
+			t.Errorf("%#x: tis-620 gives %q, windows-874 %q", i, tis, win)
+		}
+	}
+	if out := encodeString(t, "windows-874", "…ก\u00a0"); out != "\x85\xa1\xa0" {
+		t.Errorf("expected %x, got %x", "\x85\xa1\xa0", out)
+	}
+}
@@ -0,0 +1,49 @@
+package charset
+
+import (
+	"io"
+	"mime"
+)
+
+// contentTypeCharset returns the charset parameter
+// of a MIME content type, if it has one.
+func contentTypeCharset(contentType string) (string, bool) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	cs, ok := params["charset"]
+	return cs, ok && cs != ""
+}
+
+// FromContentType returns the canonical name of the character set
+// given by the charset parameter of a MIME content type, such as
+// an HTTP Content-Type header "text/html; charset=EUC-KR".
+// It returns "" and false if there is no charset parameter,
+// or if it names an unknown character set, so that the caller
+// can fall back to another way of finding the character set,
+// such as Detect.
+func FromContentType(contentType string) (name string, ok bool) {
+	cs, ok := contentTypeCharset(contentType)
+	if !ok {
+		return "", false
+	}
+	info := Info(cs)
+	if info == nil {
+		return "", false
+	}
+	return info.Name, true
+}
+
+// NewReaderByContentType returns a Reader that translates r
+// to UTF-8 from the character set given by the charset parameter
+// of the MIME content type. If there is no charset parameter,
+// r is assumed to be UTF-8 already and is returned unchanged.
+// It returns an error if the character set is unknown.
+func NewReaderByContentType(contentType string, r io.Reader) (io.Reader, error) {
+	cs, ok := contentTypeCharset(contentType)
+	if !ok {
+		return r, nil
+	}
+	return NewReader(cs, r)
+}
@@ -0,0 +1,59 @@
+package charset_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var contentTypeTests = []struct {
+	contentType string
+	name        string
+	ok          bool
+}{
+	{"text/html; charset=EUC-KR", "euc-kr", true},
+	{"text/plain; charset=\"Shift_JIS\"", "shift_jis", true},
+	{"text/plain; format=flowed; CHARSET=cp949; delsp=yes", "windows-949", true},
+	{"text/html;charset=utf-8", "utf-8", true},
+	{"text/html", "", false},
+	{"text/html; charset=", "", false},
+	{"text/html; charset=no-such-charset", "", false},
+	{"", "", false},
+}
+
+func TestFromContentType(t *testing.T) {
+	for _, test := range contentTypeTests {
+		name, ok := charset.FromContentType(test.contentType)
+		if name != test.name || ok != test.ok {
+			t.Errorf("%q: expected %q, %v; got %q, %v", test.contentType, test.name, test.ok, name, ok)
+		}
+	}
+}
+
+func TestNewReaderByContentType(t *testing.T) {
+	for _, test := range []struct {
+		contentType string
+		in, out     string
+	}{
+		{"text/html; charset=EUC-KR", "<p>\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee</p>", "<p>아름다운</p>"},
+		{"text/html", "<p>아름다운</p>", "<p>아름다운</p>"},
+		{"", "plain", "plain"},
+	} {
+		r, err := charset.NewReaderByContentType(test.contentType, strings.NewReader(test.in))
+		if err != nil {
+			t.Fatalf("%q: cannot make reader: %v", test.contentType, err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%q: read failed: %v", test.contentType, err)
+		}
+		if string(out) != test.out {
+			t.Errorf("%q: expected %q got %q", test.contentType, test.out, out)
+		}
+	}
+	if _, err := charset.NewReaderByContentType("text/html; charset=no-such-charset", strings.NewReader("")); err == nil {
+		t.Errorf("expected error for unknown charset")
+	}
+}
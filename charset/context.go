@@ -0,0 +1,31 @@
+package charset
+
+import (
+	"context"
+	"io"
+)
+
+// NewReaderContext is like NewReader, but the Reader stops
+// once ctx is done: each Read first checks ctx, and returns
+// ctx.Err() rather than reading more or returning data
+// already translated. A Read blocked in r is not interrupted.
+func NewReaderContext(ctx context.Context, charset string, r io.Reader) (io.Reader, error) {
+	tr, err := TranslatorFrom(charset)
+	if err != nil {
+		return nil, err
+	}
+	resetTranslator(tr)
+	return &translatingReader{r: r, tr: tr, ctx: ctx, maxPending: MaxReadAhead}, nil
+}
+
+// NewWriterContext is like NewWriter, but each Write, and Close,
+// first checks ctx and returns ctx.Err() once it is done,
+// without writing anything to w.
+func NewWriterContext(ctx context.Context, charset string, w io.Writer) (io.WriteCloser, error) {
+	tr, err := TranslatorTo(charset)
+	if err != nil {
+		return nil, err
+	}
+	resetTranslator(tr)
+	return &translatingWriter{w: w, tr: tr, ctx: ctx}, nil
+}
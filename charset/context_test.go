@@ -0,0 +1,52 @@
+package charset_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+func TestReaderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := iotest.OneByteReader(strings.NewReader(cp949Sample))
+	r, err := charset.NewReaderContext(ctx, "cp949", in)
+	if err != nil {
+		t.Fatalf("cannot make reader: %v", err)
+	}
+	buf := make([]byte, 3)
+	n, err := r.Read(buf)
+	if err != nil || string(buf[:n]) != "아" {
+		t.Fatalf("expected %q, nil; got %q, %v", "아", buf[:n], err)
+	}
+	cancel()
+	if n, err := r.Read(buf); n != 0 || err != context.Canceled {
+		t.Errorf("expected 0, context.Canceled; got %d, %v", n, err)
+	}
+}
+
+func TestWriterContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	w, err := charset.NewWriterContext(ctx, "cp949", &buf)
+	if err != nil {
+		t.Fatalf("cannot make writer: %v", err)
+	}
+	if _, err := w.Write([]byte("아")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	cancel()
+	if n, err := w.Write([]byte("름")); n != 0 || err != context.Canceled {
+		t.Errorf("expected 0, context.Canceled; got %d, %v", n, err)
+	}
+	if err := w.Close(); err != context.Canceled {
+		t.Errorf("expected context.Canceled from Close, got %v", err)
+	}
+	if buf.String() != "\xbe\xc6" {
+		t.Errorf("expected %q, got %q", "\xbe\xc6", buf.Bytes())
+	}
+}
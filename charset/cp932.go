@@ -6,7 +6,7 @@ import (
 )
 
 func init() {
-	registerClass("cp932", fromCP932, nil)
+	registerClass("cp932", fromCP932, toCP932)
 }
 
 // encoding details
@@ -73,8 +73,13 @@ type jisTables struct {
 }
 
 type translateFromCP932 struct {
-	tables  *jisTables
-	scratch []byte
+	tables      *jisTables
+	replacement []byte // substituted for untranslatable bytes
+	scratch     []byte
+}
+
+func (p *translateFromCP932) Reset() {
+	p.scratch = p.scratch[:0]
 }
 
 func (p *translateFromCP932) Translate(data []byte, eof bool) (int, []byte, error) {
@@ -85,15 +90,28 @@ func (p *translateFromCP932) Translate(data []byte, eof bool) (int, []byte, erro
 		b := data[i]
 		r := tables.page0[b]
 		if r != -1 {
-			p.scratch = appendRune(p.scratch, r)
+			p.scratch = appendDecoded(p.scratch, r, p.replacement)
 			n++
 			continue
 		}
 		// DBCS
 		i++
 		if i >= len(data) {
+			if eof {
+				// A lead byte with nothing after it.
+				p.scratch = append(p.scratch, p.replacement...)
+				n++
+			}
 			break
 		}
+		if t := data[i]; t < 0x40 || t > 0xfc {
+			// A byte that cannot trail the lead byte,
+			// such as a newline, is decoded afresh.
+			p.scratch = append(p.scratch, p.replacement...)
+			n++
+			i--
+			continue
+		}
 		pnum := tables.dbcsoff[b]
 		ix := int(data[i]) - cp932Char0
 		if pnum == -1 || ix < 0 || ix >= cp932PageSize {
@@ -101,16 +119,135 @@ func (p *translateFromCP932) Translate(data []byte, eof bool) (int, []byte, erro
 		} else {
 			r = tables.cp932[pnum*cp932PageSize+ix]
 		}
-		p.scratch = appendRune(p.scratch, r)
+		p.scratch = appendDecoded(p.scratch, r, p.replacement)
 		n += 2
 	}
 	return n, p.scratch, nil
 }
 
+// translateToCP932 translates from UTF-8 to shift-jis or cp932.
+type translateToCP932 struct {
+	rune2code   map[rune]uint16 // single byte codes are < 0x100
+	replacement []byte
+	strict      bool
+	scratch     []byte
+}
+
+func (p *translateToCP932) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToCP932) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		code, ok := p.rune2code[r]
+		switch {
+		case !ok && p.strict:
+			return n, p.scratch, strictError(n, r, size)
+		case !ok:
+			p.scratch = append(p.scratch, p.replacement...)
+		case code < 0x100:
+			p.scratch = append(p.scratch, byte(code))
+		default:
+			p.scratch = append(p.scratch, byte(code>>8), byte(code))
+		}
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
 type cp932Key bool
+type cp932KeyTo bool
 
+// fromCP932 accepts the same "replacement" and "drop"
+// options as fromCp949.
 func fromCP932(arg string) (Translator, error) {
-	shiftJIS := arg == "shiftjis"
+	arg, opts, err := splitArg(arg, "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getJISTables(arg == "shiftjis")
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromCP932{tables: tables, replacement: repl}, nil
+}
+
+// toCP932 accepts the same "replacement", "drop" and "strict"
+// options as toCp949.
+func toCP932(arg string) (Translator, error) {
+	arg, opts, err := splitArg(arg, "replacement", "drop", "strict")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	m, err := getJISEncoding(arg == "shiftjis")
+	if err != nil {
+		return nil, err
+	}
+	return &translateToCP932{
+		rune2code:   m,
+		replacement: repl,
+		strict:      opts.has("strict"),
+	}, nil
+}
+
+// getJISEncoding returns the map from runes to codes in
+// shift-jis, or in cp932 if shiftJIS is false.
+func getJISEncoding(shiftJIS bool) (map[rune]uint16, error) {
+	tables, err := getJISTables(shiftJIS)
+	if err != nil {
+		return nil, err
+	}
+	m, err := cache(cp932KeyTo(shiftJIS), func() (interface{}, error) {
+		// Where several codes map to the same rune,
+		// the first (lowest) code is used.
+		m := make(map[rune]uint16)
+		add := func(r rune, code uint16) {
+			if r == utf8.RuneError {
+				return
+			}
+			if _, ok := m[r]; !ok {
+				m[r] = code
+			}
+		}
+		for b, r := range tables.page0 {
+			if r != -1 && (r != 0 || b == 0) {
+				add(r, uint16(b))
+			}
+		}
+		for b, pnum := range tables.dbcsoff {
+			if tables.page0[b] != -1 {
+				continue
+			}
+			for ix := 0; ix < cp932PageSize; ix++ {
+				add(tables.cp932[pnum*cp932PageSize+ix], uint16(b)<<8|uint16(ix+cp932Char0))
+			}
+		}
+		return m, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.(map[rune]uint16), nil
+}
+
+// getJISTables returns the decoding tables for
+// shift-jis, or for cp932 if shiftJIS is false.
+func getJISTables(shiftJIS bool) (*jisTables, error) {
 	tables, err := cache(cp932Key(shiftJIS), func() (interface{}, error) {
 		tables := new(jisTables)
 		kana, err := jisGetMap("jisx0201kana.dat", kanaPageSize, kanaPages)
@@ -128,7 +265,7 @@ func fromCP932(arg string) (Translator, error) {
 		}
 
 		// 00..7f same as ascii in cp932
-		for i := rune(0); i < 0x7f; i++ {
+		for i := rune(0); i <= 0x7f; i++ {
 			tables.page0[i] = i
 		}
 
@@ -178,8 +315,7 @@ func fromCP932(arg string) (Translator, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	return &translateFromCP932{tables: tables.(*jisTables)}, nil
+	return tables.(*jisTables), nil
 }
 
 func jisGetMap(name string, pgsize, npages int) ([]rune, error) {
@@ -0,0 +1,95 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var cp932EncodeTests = []struct {
+	charset string
+	in      string
+	out     string
+}{
+	{"shift_jis", "日本語", "\x93\xfa\x96\x7b\x8c\xea"},
+	{"shift_jis", "ｱｲｳ", "\xb1\xb2\xb3"},
+	{"shift_jis", "¥", "\x5c"},
+	{"cp932", "日本語", "\x93\xfa\x96\x7b\x8c\xea"},
+	{"cp932", "①Ⅰ", "\x87\x40\x87\x54"},
+	{"cp932", "a\\b", "a\\b"},
+}
+
+func TestCP932Encode(t *testing.T) {
+	for _, test := range cp932EncodeTests {
+		tr, err := charset.TranslatorTo(test.charset)
+		if err != nil {
+			t.Fatalf("cannot make translator to %q: %v", test.charset, err)
+		}
+		out, err := translate(tr, test.in)
+		if err != nil {
+			t.Fatalf("%q: translate error: %v", test.charset, err)
+		}
+		if out != test.out {
+			t.Errorf("%q: encoding %q, expected %x got %x", test.charset, test.in, test.out, out)
+		}
+	}
+}
+
+// TestCP932RoundTrip checks that every decodable double-byte
+// code in the first few rows decodes to a rune which encodes
+// back to a code with the same decoding.
+func TestCP932RoundTrip(t *testing.T) {
+	for _, name := range []string{"shift_jis", "cp932"} {
+		count := 0
+		for lead := 0x81; lead <= 0x84; lead++ {
+			for trail := 0x40; trail <= 0xfc; trail++ {
+				in := string([]byte{byte(lead), byte(trail)})
+				r := decodeString(t, name, in)
+				if r == string(utf8.RuneError) {
+					continue
+				}
+				enc := encodeString(t, name, r)
+				if back := decodeString(t, name, enc); back != r {
+					t.Errorf("%q: %x decodes to %q, encodes to %x, decodes to %q", name, in, r, enc, back)
+				}
+				count++
+			}
+		}
+		if count < 300 {
+			t.Errorf("%q: only %d codes round-tripped", name, count)
+		}
+	}
+}
+
+func decodeString(t *testing.T, name, s string) string {
+	tr, err := charset.TranslatorFrom(name)
+	if err != nil {
+		t.Fatalf("cannot make translator from %q: %v", name, err)
+	}
+	out, err := translate(tr, s)
+	if err != nil {
+		t.Fatalf("cannot translate from %q: %v", name, err)
+	}
+	return out
+}
+
+func encodeString(t *testing.T, name, s string) string {
+	tr, err := charset.TranslatorTo(name)
+	if err != nil {
+		t.Fatalf("cannot make translator to %q: %v", name, err)
+	}
+	out, err := translate(tr, s)
+	if err != nil {
+		t.Fatalf("cannot translate to %q: %v", name, err)
+	}
+	return out
+}
+
+func TestCP932DEL(t *testing.T) {
+	for _, name := range []string{"shift_jis", "cp932"} {
+		if out := decodeString(t, name, "a\x7f"); out != "a\x7f" {
+			t.Errorf("%q: expected %q got %q", name, "a\x7f", out)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package charset
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"sort"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -11,7 +13,9 @@ func init() {
 	registerClass("cp949", fromCp949, toCp949)
 }
 
-// code pair for a Korean chracter
+// code pair for a Korean chracter.
+// The same representation, and the same data file format,
+// is used for the other double-byte character sets.
 type cp949Code struct {
 	native  uint16 // cp949
 	unicode rune   // ucs4
@@ -42,88 +46,432 @@ func (t cp949TableSortByUnicode) Less(i, j int) bool {
 	return t.cp949Table[i].unicode < t.cp949Table[j].unicode
 }
 
+// cp949Pages is a two-level table for decoding double-byte codes,
+// indexed by lead byte and then by trailing byte.
+// The page for a byte that is not a lead byte is nil,
+// and unmapped codes hold 0.
+type cp949Pages [256][]rune
+
+func newCp949Pages(t cp949Table) *cp949Pages {
+	pages := new(cp949Pages)
+	for _, c := range t {
+		lead := c.native >> 8
+		if pages[lead] == nil {
+			pages[lead] = make([]rune, 256)
+		}
+		pages[lead][c.native&0xff] = c.unicode
+	}
+	return pages
+}
+
+// lookup returns the rune for code c, or utf8.RuneError
+// and false if there is none.
+func (pages *cp949Pages) lookup(c uint16) (rune, bool) {
+	if page := pages[c>>8]; page != nil {
+		if r := page[c&0xff]; r != 0 {
+			return r, true
+		}
+	}
+	return utf8.RuneError, false
+}
+
+// cp949Reverse is a two-level table for encoding, indexed by
+// the rune without its low byte and then by the low byte.
+// The page for runes with no codes is nil, and unmapped
+// runes hold 0.
+type cp949Reverse [(unicode.MaxRune + 1) >> 8]*[256]uint16
+
+// newCp949Reverse makes the encoding table for t. Where
+// two codes stand for the same rune, the first is used.
+func newCp949Reverse(t cp949Table) *cp949Reverse {
+	rev := new(cp949Reverse)
+	for _, c := range t {
+		page := rev[c.unicode>>8]
+		if page == nil {
+			page = new([256]uint16)
+			rev[c.unicode>>8] = page
+		}
+		if page[c.unicode&0xff] == 0 {
+			page[c.unicode&0xff] = c.native
+		}
+	}
+	return rev
+}
+
+// lookup returns the code for r, or false if there is none.
+func (rev *cp949Reverse) lookup(r rune) (uint16, bool) {
+	if r < 0 || r > unicode.MaxRune {
+		return 0, false
+	}
+	if page := rev[r>>8]; page != nil {
+		if c := page[r&0xff]; c != 0 {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
 // use same struct to from-translator and to-translator.
-// each translators use sort.Search() to find corresponding code.
-// And, the lookup table is sorted by a filed which have
-// same encoding of input data.
+// the from-translator looks codes up by native code in pages,
+// and the to-translator by rune in reverse.
 type translateCp949 struct {
-	table   cp949Table // lookup table
-	scratch []byte     // buffer for output
+	pages       *cp949Pages   // decoding table
+	reverse     *cp949Reverse // encoding table
+	replacement []byte        // substituted for untranslatable characters
+	strict      bool          // return an error instead of substituting
+	translit    bool          // try an ASCII approximation before substituting
+	bestFit     bool          // try a similar character before substituting
+	ksc         bool          // allow only the KS X 1001 codes of EUC-KR
+	maxBytes    int           // limit on the total output, or 0 for no limit
+	total       int           // output so far
+	stats       Stats         // characters output
+	offsets     []int         // input offset of each rune output by the from-translator
+	consumed    int           // input so far
+	errs        []error       // substitutions made
+	scratch     []byte        // buffer for output
+}
+
+// overLimit reports whether n more bytes of output
+// would exceed the limit.
+func (p *translateCp949) overLimit(n int) bool {
+	return p.maxBytes > 0 && p.total+n > p.maxBytes
+}
+
+// reset returns p to its initial state.
+func (p *translateCp949) reset() {
+	p.total = 0
+	p.stats = Stats{}
+	p.scratch = p.scratch[:0]
+	p.offsets = p.offsets[:0]
+	p.consumed = 0
+	p.errs = nil
+}
+
+// substituted records err for a substituted character,
+// keeping no more than MaxErrors of them.
+func (p *translateCp949) substituted(err error) {
+	p.stats.Substitutions++
+	if len(p.errs) < MaxErrors {
+		p.errs = append(p.errs, err)
+	}
+}
+
+// emitted counts the characters output by a call to TranslateInto.
+func (p *translateCp949) emitted(n int) {
+	p.stats.Chars = n
+	p.stats.TotalChars += n
 }
 
 // from cp949 to unicode translator
 type translateFromCp949 translateCp949
 
 func (p *translateFromCp949) Translate(data []byte, eof bool) (int, []byte, error) {
-	p.scratch = p.scratch[:0]
-	c := 0
+	n, cdata, err := p.TranslateInto(p.scratch[:0], data, eof)
+	p.scratch = cdata
+	return n, cdata, err
+}
+
+func (p *translateFromCp949) Reset() {
+	(*translateCp949)(p).reset()
+}
+
+func (p *translateFromCp949) Stats() Stats {
+	return p.stats
+}
+
+func (p *translateFromCp949) Errors() []error {
+	return p.errs
+}
+
+func (p *translateFromCp949) OffsetMap() []int {
+	return p.offsets
+}
+
+func (p *translateFromCp949) TranslateInto(dst, data []byte, eof bool) (int, []byte, error) {
+	start := len(dst)
+	c, chars := 0, 0
+	p.offsets = p.offsets[:0]
 	for len(data) > 0 {
+		if data[0]&0x80 != 0 && len(data) < 2 && !eof {
+			// A lead byte without its trailing byte.
+			// Leave it for the next call.
+			break
+		}
 		if data[0]&0x80 == 0 {
-			p.scratch = append(p.scratch, data[0])
-			data = data[1:]
-			c += 1
+			// Copy a run of ASCII in one go.
+			k := asciiPrefix(data)
+			if p.maxBytes > 0 {
+				if room := p.maxBytes - p.total - (len(dst) - start); k > room {
+					k = room
+				}
+				if k <= 0 {
+					p.total += len(dst) - start
+					p.consumed += c
+					(*translateCp949)(p).emitted(chars)
+					return c, dst, ErrOutputLimit
+				}
+			}
+			dst = append(dst, data[:k]...)
+			for i := 0; i < k; i++ {
+				p.offsets = append(p.offsets, c+i)
+			}
+			data = data[k:]
+			chars += k
+			c += k
 			continue
 		}
-
-		n := uint16(data[0])<<8 | uint16(data[1])
-		fi := sort.Search(len(p.table), func(i int) bool {
-			if n <= p.table[i].native {
-				return true
+		mark, size, bad := len(dst), 1, true
+		switch {
+		case len(data) < 2:
+			// A lead byte at the end of the input,
+			// which is replaced like an unknown code.
+			dst = append(dst, p.replacement...)
+		case !isCp949Trail(data[0], data[1]):
+			// The second byte cannot follow this lead,
+			// so resynchronize by skipping only the lead.
+			dst = append(dst, p.replacement...)
+		default:
+			n := uint16(data[0])<<8 | uint16(data[1])
+			r, ok := p.pages.lookup(n)
+			switch {
+			case !ok:
+				// Resynchronize after a corrupt byte by
+				// skipping only that byte; its successor
+				// may start the next character.
+				dst = append(dst, p.replacement...)
+			case p.ksc && !isKSX1001(n):
+				dst = append(dst, p.replacement...)
+				size = 2
+			default:
+				dst = appendRune(dst, r)
+				size, bad = 2, false
 			}
-			return false
-		})
+		}
+		if (*translateCp949)(p).overLimit(len(dst) - start) {
+			dst = dst[:mark]
+			p.total += mark - start
+			p.consumed += c
+			(*translateCp949)(p).emitted(chars)
+			return c, dst, ErrOutputLimit
+		}
+		if bad {
+			(*translateCp949)(p).substituted(&InvalidByteError{Offset: p.consumed + c, Byte: data[0]})
+		}
+		data = data[size:]
+		if len(dst) > mark {
+			chars++
+			for i := utf8.RuneCount(dst[mark:]); i > 0; i-- {
+				p.offsets = append(p.offsets, c)
+			}
+		}
+		c += size
+	}
+	p.total += len(dst) - start
+	p.consumed += c
+	(*translateCp949)(p).emitted(chars)
+	return c, dst, nil
+}
 
-		f := p.table[fi]
-		if n == f.native {
-			p.scratch = appendRune(p.scratch, f.unicode)
-		} else {
-			p.scratch = appendRune(p.scratch, utf8.RuneError)
+// asciiPrefix returns the length of the run of
+// ASCII bytes at the start of data.
+func asciiPrefix(data []byte) int {
+	for i, b := range data {
+		if b&0x80 != 0 {
+			return i
 		}
-		data = data[2:]
-		c += 2
 	}
-	return c, p.scratch, nil
+	return len(data)
 }
 
 // from unicode to cp949 translator
 type translateToCp949 translateCp949
 
 func (p *translateToCp949) Translate(data []byte, eof bool) (int, []byte, error) {
-	p.scratch = p.scratch[:0]
-	c := 0
+	n, cdata, err := p.TranslateInto(p.scratch[:0], data, eof)
+	p.scratch = cdata
+	return n, cdata, err
+}
+
+func (p *translateToCp949) Reset() {
+	(*translateCp949)(p).reset()
+}
+
+func (p *translateToCp949) Stats() Stats {
+	return p.stats
+}
+
+func (p *translateToCp949) Errors() []error {
+	return p.errs
+}
+
+// code returns the double-byte code for the non-ASCII rune r.
+func (p *translateToCp949) code(r rune) (uint16, bool) {
+	if c, ok := p.reverse.lookup(r); ok && (!p.ksc || isKSX1001(c)) {
+		return c, true
+	}
+	return 0, false
+}
+
+func (p *translateToCp949) canEncode(r rune) bool {
+	if r < utf8.RuneSelf {
+		return true
+	}
+	_, ok := p.code(r)
+	return ok
+}
+
+// appendBestFit appends the best-fit encoding of r
+// to dst if the "bestfit" option was given.
+func (p *translateToCp949) appendBestFit(dst []byte, r rune) ([]byte, bool) {
+	if !p.bestFit {
+		return dst, false
+	}
+	s, ok := bestFitCp949(r)
+	if !ok {
+		return dst, false
+	}
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			dst = append(dst, byte(r))
+		} else if c, ok := p.code(r); ok {
+			dst = append(dst, byte(c>>8), byte(c))
+		} else {
+			return dst, false
+		}
+	}
+	return dst, true
+}
+
+// transliterate returns the ASCII approximation
+// of r if the "translit" option was given.
+func (p *translateToCp949) transliterate(r rune) (string, bool) {
+	if !p.translit {
+		return "", false
+	}
+	return translit(r)
+}
+
+func (p *translateToCp949) TranslateInto(dst, data []byte, eof bool) (int, []byte, error) {
+	start := len(dst)
+	c, chars := 0, 0
 	for len(data) > 0 {
+		mark, size := len(dst), 1
 		if data[0]&0x80 == 0 {
-			p.scratch = append(p.scratch, data[0])
-			data = data[1:]
-			c += 1
-			continue
+			dst = append(dst, data[0])
+		} else {
+			if !utf8.FullRune(data) && !eof {
+				// Leave a partial character for the next call.
+				break
+			}
+			var r rune
+			r, size = utf8.DecodeRune(data)
+			if code, ok := p.code(r); ok {
+				dst = append(dst, byte(code>>8), byte(code&0xff))
+			} else if bf, ok := p.appendBestFit(dst, r); ok {
+				dst = bf
+			} else if t, ok := p.transliterate(r); ok {
+				dst = append(dst, t...)
+			} else if p.strict {
+				p.total += len(dst) - start
+				p.consumed += c
+				(*translateCp949)(p).emitted(chars)
+				return c, dst, strictError(c, r, size)
+			} else {
+				dst = append(dst, p.replacement...)
+				(*translateCp949)(p).substituted(strictError(p.consumed+c, r, size))
+			}
+		}
+		if (*translateCp949)(p).overLimit(len(dst) - start) {
+			dst = dst[:mark]
+			p.total += mark - start
+			p.consumed += c
+			(*translateCp949)(p).emitted(chars)
+			return c, dst, ErrOutputLimit
 		}
+		data = data[size:]
+		if len(dst) > mark {
+			chars++
+		}
+		c += size
+	}
+	p.total += len(dst) - start
+	p.consumed += c
+	(*translateCp949)(p).emitted(chars)
+	return c, dst, nil
+}
 
-		r, s := utf8.DecodeRune(data)
-		fi := sort.Search(len(p.table), func(i int) bool {
-			if r <= p.table[i].unicode {
-				return true
-			}
-			return false
-		})
+// cp949Patches holds corrections and late additions to cp949.dat,
+// by native code, so that single mappings can be fixed without
+// regenerating the data file. They are applied when it is loaded.
+// A rune of 0 removes the code.
+var cp949Patches = map[uint16]rune{
+	0xa2e8: '㉾', // added in KS X 1001:2002
+}
 
-		f := p.table[fi]
-		if r == f.unicode {
-			p.scratch = append(p.scratch,
-				byte(f.native>>8), byte(f.native&0xff))
-		} else {
-			p.scratch = append(p.scratch, '?')
+// load cp949.dat to cp949Table, with cp949Patches applied
+// and the user-defined codes if userDefined is set.
+func loadCp949Table(userDefined bool) (cp949Table, error) {
+	t, err := loadCodeTable("cp949.dat")
+	if err != nil {
+		return nil, err
+	}
+	t = patchCodeTable(t, cp949Patches)
+	if userDefined {
+		t = append(t, cp949UserDefined()...)
+	}
+	return t, nil
+}
+
+// patchCodeTable applies patches to t, which is sorted by native
+// code, and returns the result, also sorted by native code.
+func patchCodeTable(t cp949Table, patches map[uint16]rune) cp949Table {
+	patched := make(cp949Table, 0, len(t)+len(patches))
+	for _, c := range t {
+		if r, ok := patches[c.native]; ok {
+			c.unicode = r
+		}
+		if c.unicode != 0 {
+			patched = append(patched, c)
+		}
+	}
+	for native, r := range patches {
+		i := sort.Search(len(t), func(i int) bool {
+			return native <= t[i].native
+		})
+		if r != 0 && (i == len(t) || t[i].native != native) {
+			patched = append(patched, cp949Code{native, r})
 		}
+	}
+	sort.Sort(cp949TableSortByNative{patched})
+	return patched
+}
 
-		data = data[s:]
-		c += s
+// cp949UserDefined returns the codes of the rows c9 and fe,
+// which KS X 1001 leaves for user-defined characters, mapped
+// in order to the private use area from U+E000, as Windows does.
+func cp949UserDefined() cp949Table {
+	var t cp949Table
+	u := rune(0xe000)
+	for _, lead := range []uint16{0xc9, 0xfe} {
+		for trail := uint16(0xa1); trail <= 0xfe; trail++ {
+			t = append(t, cp949Code{native: lead<<8 | trail, unicode: u})
+			u++
+		}
 	}
-	return c, p.scratch, nil
+	return t
 }
 
-// load cp949.dat to cp949Table
-func loadCp949Table() (cp949Table, error) {
-	dat, err := readFile("cp949.dat")
+// loadCodeTable reads a table of double-byte codes from the named
+// data file. The file holds a header giving the number of codes
+// and chunks, followed by the chunks, each holding a starting
+// native code and the UTF-8 encoded runes for consecutive codes.
+// All numbers are big-endian uint16. WriteTable writes such files.
+// A file that does not agree with its header is an error.
+func loadCodeTable(name string) (cp949Table, error) {
+	dat, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
 	buf := bytes.NewReader(dat)
 
 	// read info header
@@ -131,7 +479,7 @@ func loadCp949Table() (cp949Table, error) {
 		CodeCnt, ChunkCnt uint16
 	}
 	if err = binary.Read(buf, binary.BigEndian, &datInfo); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("charset: %s: truncated header", name)
 	}
 
 	// read code chunks to table
@@ -142,12 +490,15 @@ func loadCp949Table() (cp949Table, error) {
 	}
 	for i := uint16(0); i < datInfo.ChunkCnt; i++ {
 		if err = binary.Read(buf, binary.BigEndian, &chunk); err != nil {
-			return nil, err
+			return nil, fmt.Errorf("charset: %s: truncated in chunk %d of %d", name, i+1, datInfo.ChunkCnt)
 		}
 
 		line := make([]byte, chunk.Len)
-		if n, err := buf.Read(line); n != int(chunk.Len) || err != nil {
-			return nil, err
+		if n, _ := buf.Read(line); n != int(chunk.Len) {
+			return nil, fmt.Errorf("charset: %s: truncated in chunk %d of %d", name, i+1, datInfo.ChunkCnt)
+		}
+		if !utf8.Valid(line) {
+			return nil, fmt.Errorf("charset: %s: invalid UTF-8 in chunk %d", name, i+1)
 		}
 
 		for _, u := range string(line) {
@@ -157,41 +508,144 @@ func loadCp949Table() (cp949Table, error) {
 		}
 	}
 
+	if buf.Len() > 0 {
+		return nil, fmt.Errorf("charset: %s: unexpected data after the last chunk", name)
+	}
+	if len(table) != int(datInfo.CodeCnt) {
+		return nil, fmt.Errorf("charset: %s: expected %d codes, got %d", name, datInfo.CodeCnt, len(table))
+	}
 	return table, nil
 }
 
-// factory to create translateFromCp949
+// isCp949Trail reports whether trail may follow lead in a
+// double-byte code. The extended Hangul codes of cp949 take
+// trailing bytes 41..5a, 61..7a and 81..fe after the lead
+// bytes 81..c6; the lead bytes c7..fe, like KS X 1001,
+// take only a1..fe.
+func isCp949Trail(lead, trail byte) bool {
+	switch {
+	case lead >= 0x81 && lead <= 0xc6:
+		return trail >= 0x41 && trail <= 0x5a ||
+			trail >= 0x61 && trail <= 0x7a ||
+			trail >= 0x81 && trail <= 0xfe
+	case lead >= 0xc7 && lead <= 0xfe:
+		return trail >= 0xa1 && trail <= 0xfe
+	}
+	return false
+}
+
+// isKSX1001 reports whether the double-byte code c is in
+// the KS X 1001 (KS C 5601) set of EUC-KR, in which both
+// bytes are in the range a1..fe, rather than among the
+// extended codes added by cp949.
+func isKSX1001(c uint16) bool {
+	return c>>8 >= 0xa1 && c>>8 <= 0xfe && c&0xff >= 0xa1 && c&0xff <= 0xfe
+}
+
+// factory to create translateFromCp949.
+// The "replacement" option gives the rune to substitute for
+// unknown byte sequences (utf8.RuneError by default);
+// the "drop" option omits them from the output.
+// The "maxbytes=n" option causes Translate to return
+// ErrOutputLimit rather than produce more than n bytes in total.
+// With the argument "euc-kr", the "strict" option makes
+// the codes outside KS X 1001 unknown too.
+// The "user-defined" option decodes the user-defined
+// rows c9 and fe to the private use area from U+E000.
 func fromCp949(arg string) (Translator, error) {
-	type cp949KeyFrom bool
-	table, err := cache(cp949KeyFrom(true), func() (interface{}, error) {
-		t, err := loadCp949Table()
+	known := []string{"replacement", "drop", "maxbytes", "user-defined"}
+	if a, _ := splitName(arg); a == "euc-kr" {
+		known = append(known, "strict")
+	}
+	arg, opts, err := splitArg(arg, known...)
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	max, err := opts.maxBytes()
+	if err != nil {
+		return nil, err
+	}
+	pages, err := getCp949Pages(opts.has("user-defined"))
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromCp949{
+		pages:       pages,
+		replacement: repl,
+		maxBytes:    max,
+		ksc:         arg == "euc-kr" && opts.has("strict"),
+	}, nil
+}
+
+// The cache keys of the cp949 tables,
+// with or without the user-defined codes.
+type cp949KeyFrom bool
+type cp949KeyTo bool
+
+// getCp949Pages returns the cp949 decoding table, with
+// the user-defined codes if userDefined is set.
+func getCp949Pages(userDefined bool) (*cp949Pages, error) {
+	pages, err := cache(cp949KeyFrom(userDefined), func() (interface{}, error) {
+		t, err := loadCp949Table(userDefined)
 		if err != nil {
 			return nil, err
 		}
-		if !sort.IsSorted(cp949TableSortByNative{t}) {
-			panic("cp949.dat is not sorted by native code!")
-		}
-		return t, nil
+		return newCp949Pages(t), nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &translateFromCp949{table: table.(cp949Table)}, nil
+	return pages.(*cp949Pages), nil
 }
 
-// factory to create translateToCp949
+// factory to create translateToCp949.
+// The "replacement" option gives the byte to substitute for
+// runes with no cp949 encoding ('?' by default);
+// the "drop" option omits them from the output.
+// The "strict" option causes Translate to return an
+// *UnmappableError instead; with the argument "euc-kr",
+// it also makes the runes outside KS X 1001 unmappable.
+// The "bestfit" option first substitutes a similar character
+// that cp949 has, as Windows does, such as '￦' for '₩'.
+// The "translit" option substitutes an ASCII approximation,
+// such as 'e' for 'é', where there is one, before falling
+// back to the others. "maxbytes" and "user-defined"
+// are as for fromCp949.
 func toCp949(arg string) (Translator, error) {
-	type cp949KeyTo bool
-	table, err := cache(cp949KeyTo(true), func() (interface{}, error) {
-		t, err := loadCp949Table()
+	arg, opts, err := splitArg(arg, "replacement", "drop", "strict", "bestfit", "translit", "maxbytes", "user-defined")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	max, err := opts.maxBytes()
+	if err != nil {
+		return nil, err
+	}
+	userDefined := opts.has("user-defined")
+	reverse, err := cache(cp949KeyTo(userDefined), func() (interface{}, error) {
+		t, err := loadCp949Table(userDefined)
 		if err != nil {
 			return nil, err
 		}
-		sort.Sort(cp949TableSortByUnicode{t})
-		return t, nil
+		return newCp949Reverse(t), nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &translateToCp949{table: table.(cp949Table)}, nil
+	return &translateToCp949{
+		reverse:     reverse.(*cp949Reverse),
+		replacement: repl,
+		strict:      opts.has("strict"),
+		translit:    opts.has("translit"),
+		bestFit:     opts.has("bestfit"),
+		ksc:         arg == "euc-kr" && opts.has("strict"),
+		maxBytes:    max,
+	}, nil
 }
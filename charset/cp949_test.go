@@ -0,0 +1,753 @@
+package charset_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+func TestCp949SplitPair(t *testing.T) {
+	in := []byte("\xbe\xc6\xb8\xa7")
+	want := "아름"
+	for split := 1; split < len(in); split++ {
+		tr, err := charset.TranslatorFrom("cp949")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate(in[:split], false)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		out := string(cdata)
+		if n > split {
+			t.Fatalf("split %d: consumed %d bytes of %d", split, n, split)
+		}
+		rest := in[n:]
+		n, cdata, err = tr.Translate(rest, true)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		if n != len(rest) {
+			t.Fatalf("split %d: consumed %d bytes, expected %d", split, n, len(rest))
+		}
+		out += string(cdata)
+		if out != want {
+			t.Fatalf("split %d: expected %q got %q", split, want, out)
+		}
+	}
+}
+
+func TestCp949LoneLeadByte(t *testing.T) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	n, cdata, err := tr.Translate([]byte("a\xbe"), false)
+	if err != nil {
+		t.Fatalf("translate error: %v", err)
+	}
+	if n != 1 || string(cdata) != "a" {
+		t.Fatalf("expected 1, %q; got %d, %q", "a", n, cdata)
+	}
+	n, cdata, err = tr.Translate([]byte("\xbe"), true)
+	if err != nil {
+		t.Fatalf("translate error: %v", err)
+	}
+	if n != 1 || string(cdata) != string(utf8.RuneError) {
+		t.Fatalf("expected 1, %q at eof; got %d, %q", string(utf8.RuneError), n, cdata)
+	}
+}
+
+func TestCp949Reader(t *testing.T) {
+	in := "a\xbe\xc6\xb8\xa7 \xbe"
+	want := "a아름 " + string(utf8.RuneError)
+	for _, outr := range testReaders {
+		r, err := charset.NewReader("cp949", iotest.OneByteReader(strings.NewReader(in)))
+		if err != nil {
+			t.Fatalf("cannot make reader: %v", err)
+		}
+		out, err := io.ReadAll(outr(r))
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if string(out) != want {
+			t.Errorf("expected %q got %q", want, out)
+		}
+	}
+}
+
+func TestCp949ReaderError(t *testing.T) {
+	r, err := charset.NewReader("cp949?maxbytes=4", strings.NewReader("ab\xbe\xc6cd"))
+	if err != nil {
+		t.Fatalf("cannot make reader: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != charset.ErrOutputLimit || string(out) != "ab" {
+		t.Errorf("expected %q, ErrOutputLimit; got %q, %v", "ab", out, err)
+	}
+}
+
+func TestCp949Unmapped(t *testing.T) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	// Each byte of an unmapped pair is replaced separately.
+	_, cdata, err := tr.Translate([]byte("\xff\xff"), true)
+	if err != nil {
+		t.Fatalf("translate error: %v", err)
+	}
+	if want := strings.Repeat(string(utf8.RuneError), 2); string(cdata) != want {
+		t.Fatalf("expected %q got %q", want, cdata)
+	}
+
+	tr, err = charset.TranslatorTo("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	_, cdata, err = tr.Translate([]byte("\U0010ffff"), true)
+	if err != nil {
+		t.Fatalf("translate error: %v", err)
+	}
+	if string(cdata) != "?" {
+		t.Fatalf("expected %q got %q", "?", cdata)
+	}
+}
+
+func TestCp949Resync(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		// A stray byte in the middle of "아름다운".
+		"\xbe\xc6\xb8\xa7\x80\xb4\xd9\xbf\xee": "아름" + bad + "다운",
+		"\xbe\xc6\xff\xb8\xa7":                 "아" + bad + "름",
+		// A lead byte followed by ASCII keeps the ASCII.
+		"\xbe\xc6\xb8!": "아" + bad + "!",
+		"\x80\xb0\xa1":  bad + "가",
+	} {
+		if got := decodeString(t, "cp949", in); got != out {
+			t.Errorf("decoding %x: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestCp949IllegalTrail(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		// Lead bytes from c7 on take only a1..fe.
+		"\xc7\x41\xb0\xa1": bad + "A가",
+		"\xc7\x81!":        bad + bad + "!",
+		// Lead bytes before that skip 5b..60 and 7b..80.
+		"\x81\x5b": bad + "[",
+		"\x81\x7f": bad + "\x7f",
+		"\x81\x80": bad + bad,
+		"\x81\x41": "갂",
+	} {
+		if got := decodeString(t, "cp949", in); got != out {
+			t.Errorf("decoding %x: expected %q got %q", in, out, got)
+		}
+	}
+	// Only the lead byte is reported as bad.
+	_, offsets, err := charset.DecodeWithErrors("cp949", []byte("a\xc7\x41b"))
+	if err != nil || !reflect.DeepEqual(offsets, []int{1}) {
+		t.Errorf("expected [1], got %v, %v", offsets, err)
+	}
+}
+
+func TestCp949RandomPairs(t *testing.T) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	buf := make([]byte, 2)
+	for i := 0; i < 100000; i++ {
+		buf[0] = byte(rnd.Intn(256))
+		buf[1] = byte(rnd.Intn(256))
+		if _, _, err := tr.Translate(buf, true); err != nil {
+			t.Fatalf("translate %x: %v", buf, err)
+		}
+	}
+}
+
+var cp949OptionTests = []struct {
+	from bool
+	name string
+	in   string
+	out  string
+}{
+	{true, "cp949", "a\xffb", "a�b"},
+	{true, "cp949?replacement=0x3f", "a\xffb", "a?b"},
+	{true, "cp949?drop", "a\xffb", "ab"},
+	{false, "cp949", "a☃b", "a?b"},
+	{false, "cp949?replacement=0x1a", "a☃b", "a\x1ab"},
+	{false, "cp949?drop", "a☃b", "ab"},
+}
+
+func TestCp949Options(t *testing.T) {
+	for _, test := range cp949OptionTests {
+		var tr charset.Translator
+		var err error
+		if test.from {
+			tr, err = charset.TranslatorFrom(test.name)
+		} else {
+			tr, err = charset.TranslatorTo(test.name)
+		}
+		if err != nil {
+			t.Fatalf("%q: cannot make translator: %v", test.name, err)
+		}
+		out, err := translate(tr, test.in)
+		if err != nil {
+			t.Fatalf("%q: translate error: %v", test.name, err)
+		}
+		if out != test.out {
+			t.Errorf("%q: expected %q got %q", test.name, test.out, out)
+		}
+	}
+}
+
+func TestBadOptions(t *testing.T) {
+	for _, name := range []string{"cp949?nonsense", "cp949?replacement=x", "cp949?maxbytes=0", "latin1?bestfit", "gbk?replacement=0x100"} {
+		if _, err := charset.TranslatorTo(name); err == nil {
+			t.Errorf("%q: expected error", name)
+		}
+	}
+}
+
+func TestStrictEncoding(t *testing.T) {
+	for _, name := range []string{"cp949?strict", "latin1?strict"} {
+		tr, err := charset.TranslatorTo(name)
+		if err != nil {
+			t.Fatalf("%q: cannot make translator: %v", name, err)
+		}
+		n, cdata, err := tr.Translate([]byte("ab☃c"), true)
+		uerr, ok := err.(*charset.UnmappableError)
+		if !ok {
+			t.Fatalf("%q: expected *UnmappableError, got %v", name, err)
+		}
+		if uerr.Offset != 2 || uerr.Rune != '☃' {
+			t.Errorf("%q: expected offset 2, rune ☃; got %d, %q", name, uerr.Offset, uerr.Rune)
+		}
+		if n != 2 || string(cdata) != "ab" {
+			t.Errorf("%q: expected 2, %q; got %d, %q", name, "ab", n, cdata)
+		}
+	}
+}
+
+func TestStrictWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := charset.NewWriter("cp949?strict", &buf)
+	if err != nil {
+		t.Fatalf("cannot make writer: %v", err)
+	}
+	n, err := w.Write([]byte("아a☃b"))
+	if _, ok := err.(*charset.UnmappableError); !ok || n != 4 {
+		t.Errorf("expected 4, *UnmappableError; got %d, %v", n, err)
+	}
+	if buf.String() != "\xbe\xc6a" {
+		t.Errorf("expected %q, got %q", "\xbe\xc6a", buf.Bytes())
+	}
+
+	// The unencodable character is split between writes.
+	buf.Reset()
+	w, err = charset.NewWriter("cp949?strict", &buf)
+	if err != nil {
+		t.Fatalf("cannot make writer: %v", err)
+	}
+	if n, err := w.Write([]byte("a\xe2")); n != 2 || err != nil {
+		t.Errorf("expected 2, nil; got %d, %v", n, err)
+	}
+	n, err = w.Write([]byte("\x98\x83b"))
+	if _, ok := err.(*charset.UnmappableError); !ok || n != 0 {
+		t.Errorf("expected 0, *UnmappableError; got %d, %v", n, err)
+	}
+	if buf.String() != "a" {
+		t.Errorf("expected %q, got %q", "a", buf.Bytes())
+	}
+}
+
+func TestCp949Errors(t *testing.T) {
+	tr, err := charset.TranslatorTo("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	et, ok := tr.(charset.ErrorsTranslator)
+	if !ok {
+		t.Fatalf("cp949 translator does not implement ErrorsTranslator")
+	}
+	var out []byte
+	for i, chunk := range []string{"a☃", "b\xffc☃"} {
+		_, cdata, err := tr.Translate([]byte(chunk), i == 1)
+		if err != nil {
+			t.Fatalf("translate error: %v", err)
+		}
+		out = append(out, cdata...)
+	}
+	if string(out) != "a?b?c?" {
+		t.Errorf("expected %q, got %q", "a?b?c?", out)
+	}
+	want := []error{
+		&charset.UnmappableError{Offset: 1, Rune: '☃'},
+		&charset.InvalidUTF8Error{Offset: 5},
+		&charset.UnmappableError{Offset: 7, Rune: '☃'},
+	}
+	errs := et.Errors()
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("expected %v, got %v", want, errs)
+	}
+	tr.(charset.ResetTranslator).Reset()
+	if errs := et.Errors(); len(errs) != 0 {
+		t.Errorf("expected no errors after Reset, got %v", errs)
+	}
+}
+
+var cp949BestFitTests = []struct {
+	in  string
+	out string
+}{
+	{"₩100", "\xa3\xdc100"},
+	{"¢£¥¬", "\xa1\xcb\xa1\xcc\xa1\xcd\xa1\xfe"},
+	{"a\u00a0b–c", "a b-c"},
+	{"«»", "\xa1\xb6\xa1\xb7"},
+	{"µ", "\xa5\xec"},
+	// As without bestfit.
+	{"가☃", "\xb0\xa1?"},
+}
+
+func TestCp949BestFit(t *testing.T) {
+	for _, test := range cp949BestFitTests {
+		if out := encodeString(t, "cp949?bestfit", test.in); out != test.out {
+			t.Errorf("encoding %q: expected %x got %x", test.in, test.out, out)
+		}
+	}
+	if out := encodeString(t, "cp949", "₩"); out != "?" {
+		t.Errorf("without bestfit: expected %q got %x", "?", out)
+	}
+	tr, err := charset.TranslatorTo("cp949?bestfit&strict")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if _, cdata, err := tr.Translate([]byte("₩☃"), true); string(cdata) != "\xa3\xdc" || err == nil {
+		t.Errorf("expected %x, error; got %x, %v", "\xa3\xdc", cdata, err)
+	}
+}
+
+func TestCp949Translit(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{"cp949", "café", "caf?"},
+		{"cp949?translit", "café", "cafe"},
+		{"cp949?translit", "Zürich 아", "Zurich \xbe\xc6"},
+		{"cp949?translit", "\u0100\u0101", "Aa"},
+		{"cp949?translit", "☃", "?"},
+		{"cp949?translit&drop", "☃é", "e"},
+	} {
+		if out := encodeString(t, test.name, test.in); out != test.out {
+			t.Errorf("%s: encoding %q: expected %q got %q", test.name, test.in, test.out, out)
+		}
+	}
+	tr, err := charset.TranslatorTo("cp949?translit&strict")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if _, cdata, err := tr.Translate([]byte("é☃"), true); string(cdata) != "e" || err == nil {
+		t.Errorf("expected %q and an error, got %q, %v", "e", cdata, err)
+	}
+}
+
+func TestStrictInvalidUTF8(t *testing.T) {
+	// "ab" followed by the first two bytes of "가".
+	const in = "ab\xea\xb0"
+	for _, name := range []string{"cp949?strict", "latin1?strict", "shift_jis?strict"} {
+		tr, err := charset.TranslatorTo(name)
+		if err != nil {
+			t.Fatalf("%q: cannot make translator: %v", name, err)
+		}
+		// Before eof, the truncated character may yet be completed.
+		n, cdata, err := tr.Translate([]byte(in), false)
+		if n != 2 || string(cdata) != "ab" || err != nil {
+			t.Errorf("%q: expected 2, %q, nil; got %d, %q, %v", name, "ab", n, cdata, err)
+		}
+		n, cdata, err = tr.Translate([]byte(in), true)
+		ierr, ok := err.(*charset.InvalidUTF8Error)
+		if !ok {
+			t.Fatalf("%q: expected *InvalidUTF8Error, got %v", name, err)
+		}
+		if ierr.Offset != 2 || n != 2 || string(cdata) != "ab" {
+			t.Errorf("%q: expected offset 2, 2, %q; got %d, %d, %q", name, "ab", ierr.Offset, n, cdata)
+		}
+	}
+	// A genuine U+FFFD is unmappable rather than invalid.
+	tr, err := charset.TranslatorTo("cp949?strict")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if _, _, err := tr.Translate([]byte("\ufffd"), true); err == nil {
+		t.Errorf("expected error for U+FFFD")
+	} else if _, ok := err.(*charset.UnmappableError); !ok {
+		t.Errorf("expected *UnmappableError for U+FFFD, got %v", err)
+	}
+	// Without strict, each malformed byte is replaced.
+	if out := encodeString(t, "cp949", in); out != "ab??" {
+		t.Errorf("expected %q got %q", "ab??", out)
+	}
+}
+
+func TestCp949MaxBytes(t *testing.T) {
+	tr, err := charset.TranslatorFrom("cp949?maxbytes=1000")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	// Each pair of input bytes becomes three bytes of output.
+	in := bytes.Repeat([]byte("\xb0\xa1"), 1000)
+	total := 0
+	for len(in) > 0 {
+		chunk := in
+		if len(chunk) > 100 {
+			chunk = chunk[:100]
+		}
+		n, cdata, err := tr.Translate(chunk, false)
+		total += len(cdata)
+		in = in[n:]
+		if err == charset.ErrOutputLimit {
+			if total != 999 || len(in) != 2000-666 {
+				t.Errorf("expected 999 bytes from 666, got %d from %d", total, 2000-len(in))
+			}
+			// Further calls are refused too.
+			if n, cdata, err := tr.Translate(in, true); n != 0 || len(cdata) != 0 || err != charset.ErrOutputLimit {
+				t.Errorf("expected limit again, got %d, %q, %v", n, cdata, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("translate error: %v", err)
+		}
+	}
+	t.Fatalf("the limit was not reached")
+}
+
+func TestCp949MaxBytesASCII(t *testing.T) {
+	tr, err := charset.TranslatorFrom("cp949?maxbytes=3")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	n, cdata, err := tr.Translate([]byte("abcdef"), true)
+	if n != 3 || string(cdata) != "abc" || err != charset.ErrOutputLimit {
+		t.Errorf("expected 3, %q, ErrOutputLimit; got %d, %q, %v", "abc", n, cdata, err)
+	}
+}
+
+const cp949Sample = "\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee \xbf\xec\xb8\xae\xb8\xbb"
+
+func TestCp949Stats(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		tr    func(string) (charset.Translator, error)
+		in    string
+		chars []int
+	}{
+		// "ab 아름다운" split after half a character.
+		{"from", charset.TranslatorFrom, "ab \xbe\xc6\xb8|\xa7\xb4\xd9\xbf\xee", []int{4, 3}},
+		{"to", charset.TranslatorTo, "ab 아름|다운!", []int{5, 3}},
+	} {
+		tr, err := test.tr("cp949")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		st, ok := tr.(charset.StatsTranslator)
+		if !ok {
+			t.Fatalf("%s: translator does not implement StatsTranslator", test.name)
+		}
+		chunks := strings.Split(test.in, "|")
+		total := 0
+		var pending []byte
+		for i, chunk := range chunks {
+			pending = append(pending, chunk...)
+			n, _, err := tr.Translate(pending, i == len(chunks)-1)
+			if err != nil {
+				t.Fatalf("%s: translate error: %v", test.name, err)
+			}
+			pending = pending[n:]
+			total += test.chars[i]
+			if s := st.Stats(); s.Chars != test.chars[i] || s.TotalChars != total {
+				t.Errorf("%s: chunk %d: expected %d, %d; got %+v", test.name, i, test.chars[i], total, s)
+			}
+		}
+	}
+}
+
+func TestCp949OffsetMap(t *testing.T) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	om, ok := tr.(charset.OffsetMapTranslator)
+	if !ok {
+		t.Fatalf("cp949 translator does not implement OffsetMapTranslator")
+	}
+	// "a아 b가말" with a stray byte before 가,
+	// which decodes to a replacement character.
+	in := []byte("a\xbe\xc6 b\x80\xb0\xa1\xb8\xbb")
+	_, out, err := tr.Translate(in, true)
+	if err != nil {
+		t.Fatalf("translate error: %v", err)
+	}
+	offsets := om.OffsetMap()
+	want := []int{0, 1, 3, 4, 5, 6, 8}
+	if n := utf8.RuneCount(out); n != len(want) {
+		t.Fatalf("expected %d runes, got %d (%q)", len(want), n, out)
+	}
+	if len(offsets) != len(want) {
+		t.Fatalf("expected %v, got %v", want, offsets)
+	}
+	i := 0
+	for _, r := range string(out) {
+		if offsets[i] != want[i] {
+			t.Errorf("rune %d (%q): expected offset %d, got %d", i, r, want[i], offsets[i])
+		}
+		i++
+	}
+}
+
+func TestCp949TranslateInto(t *testing.T) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	tri, ok := tr.(charset.TranslatorInto)
+	if !ok {
+		t.Fatalf("cp949 translator does not implement TranslatorInto")
+	}
+	in := []byte(cp949Sample)
+	n, out, err := tri.TranslateInto([]byte("> "), in, true)
+	if err != nil || n != len(in) || string(out) != "> 아름다운 우리말" {
+		t.Fatalf("got %d, %q, %v", n, out, err)
+	}
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		_, buf, _ = tri.TranslateInto(buf[:0], in, true)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+// cp949Text returns about 1MB of Korean text.
+func cp949Text() []byte {
+	return bytes.Repeat([]byte(cp949Sample), 1<<20/len(cp949Sample))
+}
+
+func BenchmarkCp949Translate(b *testing.B) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		b.Fatalf("cannot make translator: %v", err)
+	}
+	in := cp949Text()
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		tr.Translate(in, true)
+	}
+}
+
+func BenchmarkCp949TranslateInto(b *testing.B) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		b.Fatalf("cannot make translator: %v", err)
+	}
+	tri := tr.(charset.TranslatorInto)
+	in := cp949Text()
+	var buf []byte
+	b.SetBytes(int64(len(in)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, buf, _ = tri.TranslateInto(buf[:0], in, true)
+	}
+}
+
+// BenchmarkCp949Encode encodes about 1MB of Korean text.
+func BenchmarkCp949Encode(b *testing.B) {
+	in, err := charset.Decode("cp949", cp949Text())
+	if err != nil {
+		b.Fatalf("cannot decode: %v", err)
+	}
+	tr, err := charset.TranslatorTo("cp949")
+	if err != nil {
+		b.Fatalf("cannot make translator: %v", err)
+	}
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		tr.Translate(in, true)
+	}
+}
+
+// BenchmarkCp949TranslateASCII translates text that is mostly
+// ASCII, with an occasional Korean word.
+func BenchmarkCp949TranslateASCII(b *testing.B) {
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		b.Fatalf("cannot make translator: %v", err)
+	}
+	line := "The quick brown fox jumps over the lazy dog. \xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee\n"
+	in := bytes.Repeat([]byte(line), 1<<20/len(line))
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		tr.Translate(in, true)
+	}
+}
+
+func TestEUCKR(t *testing.T) {
+	// 0x8141 is the first of the cp949 extended codes.
+	const ext = "\x81\x41"
+	if out := decodeString(t, "cp949", ext); out != "갂" {
+		t.Errorf("cp949: expected %q got %q", "갂", out)
+	}
+	if out := decodeString(t, "euc-kr", ext); out != "갂" {
+		t.Errorf("euc-kr: expected %q got %q", "갂", out)
+	}
+	if out := decodeString(t, "euc-kr?strict", "a"+ext+"\xb0\xa1"); out != "a"+string(utf8.RuneError)+"가" {
+		t.Errorf("euc-kr?strict: expected %q got %q", "a"+string(utf8.RuneError)+"가", out)
+	}
+	if out := encodeString(t, "ksc5601", "갂가"); out != ext+"\xb0\xa1" {
+		t.Errorf("ksc5601: expected %x got %x", ext+"\xb0\xa1", out)
+	}
+	tr, err := charset.TranslatorTo("euc-kr?strict")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if _, _, err := tr.Translate([]byte("가갂"), true); err == nil {
+		t.Errorf("expected error encoding an extended character")
+	}
+	if _, err := charset.TranslatorFrom("cp949?strict"); err == nil {
+		t.Errorf("expected error for strict decoding of cp949")
+	}
+}
+
+func TestCp949ReaderErrors(t *testing.T) {
+	// Three bytes cannot be decoded: a stray 0x80,
+	// a lead byte before a space, and one at the end.
+	in := "\xbe\xc6\x80\xb8\xa7 \xb4 \xb4\xd9\xbf\xee\xb0"
+	r, err := charset.NewReader("cp949", iotest.OneByteReader(strings.NewReader(in)))
+	if err != nil {
+		t.Fatalf("cannot make reader: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	want := "아�름 � 다운�"
+	if err != nil || string(out) != want {
+		t.Fatalf("expected %q, got %q, %v", want, out, err)
+	}
+	er, ok := r.(charset.ErrorsReader)
+	if !ok {
+		t.Fatalf("reader does not implement ErrorsReader")
+	}
+	errs := er.Errors()
+	wantErrs := []error{
+		&charset.InvalidByteError{Offset: 2, Byte: 0x80},
+		&charset.InvalidByteError{Offset: 6, Byte: 0xb4},
+		&charset.InvalidByteError{Offset: 12, Byte: 0xb0},
+	}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("expected %v, got %v", wantErrs, errs)
+	}
+}
+
+var cp949UserDefinedTests = []struct {
+	native  string
+	unicode string
+}{
+	{"\xc9\xa1", "\ue000"},
+	{"\xc9\xfe", "\ue05d"},
+	{"\xfe\xa1", "\ue05e"},
+	{"a\xfe\xfe\xb0\xa1", "a\ue0bb가"},
+}
+
+func TestCp949UserDefined(t *testing.T) {
+	for _, test := range cp949UserDefinedTests {
+		if out := decodeString(t, "cp949?user-defined", test.native); out != test.unicode {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "cp949?user-defined", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.unicode, test.native, out)
+		}
+	}
+	// Without the option, the user-defined rows are unknown.
+	if out := decodeString(t, "cp949", "\xc9\xa1"); out != "��" {
+		t.Errorf("expected %q, got %q", "��", out)
+	}
+	if out := encodeString(t, "cp949", "\ue000"); out != "?" {
+		t.Errorf("expected %q, got %q", "?", out)
+	}
+}
+
+// TestCp949Patches checks a code missing from cp949.dat
+// and added when it is loaded.
+func TestCp949Patches(t *testing.T) {
+	if out := decodeString(t, "cp949", "a\xa2\xe8b"); out != "a㉾b" {
+		t.Errorf("decoding: expected %q, got %q", "a㉾b", out)
+	}
+	if out := encodeString(t, "cp949", "a㉾b"); out != "a\xa2\xe8b" {
+		t.Errorf("encoding: expected %x, got %x", "a\xa2\xe8b", out)
+	}
+	// The codes around it are unchanged.
+	if out := decodeString(t, "cp949", "\xa2\xe7"); out != "®" {
+		t.Errorf("expected %q, got %q", "®", out)
+	}
+}
+
+func TestDecodeWithErrors(t *testing.T) {
+	// 아름다 운, with a stray 0x80 at 2, 0xff at 5,
+	// a lead byte before the space at 8 and one
+	// at the end, at 12.
+	in := "\xbe\xc6\x80\xb8\xa7\xff\xb4\xd9\xb4 \xbf\xee\xb0"
+	out, bad, err := charset.DecodeWithErrors("cp949", []byte(in))
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if want := "아�름�다� 운�"; string(out) != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+	wantBad := []int{2, 5, 8, 12}
+	if !reflect.DeepEqual(bad, wantBad) {
+		t.Errorf("expected %v, got %v", wantBad, bad)
+	}
+	// Under euc-kr?strict, a cp949 extension code is
+	// refused as a pair.
+	out, bad, err = charset.DecodeWithErrors("euc-kr?strict", []byte("a\x81\x41b\x81\x41"))
+	if err != nil || string(out) != "a�b�" || !reflect.DeepEqual(bad, []int{1, 4}) {
+		t.Errorf("euc-kr: expected %q, [1 4]; got %q, %v, %v", "a�b�", out, bad, err)
+	}
+	if _, _, err := charset.DecodeWithErrors("latin1", []byte(in)); err == nil {
+		t.Errorf("latin1: expected error")
+	}
+}
+
+func TestCp949MaxErrors(t *testing.T) {
+	defer func(n int) { charset.MaxErrors = n }(charset.MaxErrors)
+	charset.MaxErrors = 2
+	tr, err := charset.TranslatorFrom("cp949")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if _, err := translate(tr, "a\x80b\x80c\x80\x80\x80"); err != nil {
+		t.Fatalf("translate error: %v", err)
+	}
+	errs := tr.(charset.ErrorsTranslator).Errors()
+	wantErrs := []error{
+		&charset.InvalidByteError{Offset: 1, Byte: 0x80},
+		&charset.InvalidByteError{Offset: 3, Byte: 0x80},
+	}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("expected %v, got %v", wantErrs, errs)
+	}
+	if n := tr.(charset.StatsTranslator).Stats().Substitutions; n != 5 {
+		t.Errorf("expected 5 substitutions, got %d", n)
+	}
+}
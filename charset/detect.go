@@ -0,0 +1,163 @@
+package charset
+
+import (
+	"bytes"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The multibyte character sets considered by Detect,
+// in order of preference when they score equally.
+var detectCandidates = []string{"windows-949", "gb18030", "big5", "shift_jis", "euc-jp"}
+
+// Common characters in Korean, Chinese and Japanese text,
+// roughly in order of frequency. Text in the right character set
+// decodes largely to these; text in the wrong one decodes to
+// characters picked more or less at random.
+const (
+	commonHangul = "이다는의에을를하고가한지서로기사자어리나도있들시대그수게인적일정면해" +
+		"만부라저주보우요전상내거니아것았습니까었으며였과와록제원회경국장동방계성" +
+		"중화업무문실물학생연발관분통신공결말위개금소비모행체진예여날세작야더때간" +
+		"알년없안되된할합민권모든람우리입또잘못오늘께서님"
+	commonHanzi = "的一是不了在人有我他这个们中来上大为和国地到以说时要就出会可也你对生能" +
+		"而子那得于着下自之年过发后作里用道行所然家种事成方多经么去法学如都同现当" +
+		"没动面起看定天分还进好小部其些主样理心她本前开但因只从想实日军者意无力它" +
+		"与长把机十民第公此已工使情明性知全三又关点正业外将两高间由问很最重并物手" +
+		"应战向头文体政美相见被利什二等产或新己制身果加西斯月话合回特代内信表化老" +
+		"给世位次度门任常先海通教儿原东声提立及比员解水名真论处走义各入几口认条平" +
+		"系气题活尔更别打女变四神总何电数安少报才结反受目太量再感建务做接必场件计" +
+		"管期市直德资命山金指克许统区保至队形社便空决治展马科司五基眼书非则听白却" +
+		"界达光放强即像难且权思王象完设式色路记南品住告类求据程北边死张该交规万取" +
+		"级阶领导社会基础联盟专政制度根"
+	commonTraditional = "這個們來為國說時會對過發後裡種經麼學現當沒動開從實軍與長機" +
+		"關點業將兩間問應戰頭體見產新話內給門東聲員義氣題爾別變總電數報結" +
+		"務場計區隊書則聽卻達強難權設記類據邊張規萬覺術領確傳師觀讓識帶導" +
+		"爭運風收幹聯組濟車親辦議證轉準遠單愛擊備連調團價黨華級離況亞請際" +
+		"華於屬無分男宗教族階派律平等享治區總統灣臺"
+	commonKanji = "日一国人年大十二本中長出三時行見月分後前生五間上東四今金九入学高円子" +
+		"外八六下来気小七山話女北午百書先名川千水半男西電校語土木聞食車何南万毎" +
+		"白天母火右読友左休父雨会同事自社発者地業方新場員立開手力問代明動京目通" +
+		"言理体田主題意不作用度強公持野以思家世多正安院心界教文元重近考画海売知" +
+		"道集別物使品計死特私始朝運終台広住無真有口少町料工建空急止送切転研足究" +
+		"民選挙代表行動子孫協和成果全土自由確保恵沢諸"
+)
+
+var (
+	commonRunesOnce sync.Once
+	commonRunes     map[rune]bool
+)
+
+func initCommonRunes() {
+	commonRunes = make(map[rune]bool)
+	for _, s := range []string{commonHangul, commonHanzi, commonTraditional, commonKanji} {
+		for _, r := range s {
+			commonRunes[r] = true
+		}
+	}
+}
+
+// isCommon reports whether r is likely to appear often in East Asian text.
+func isCommon(r rune) bool {
+	switch {
+	case r >= 0x3041 && r <= 0x30ff: // hiragana and katakana
+		return true
+	case r >= 0x3000 && r <= 0x3011: // CJK punctuation
+		return true
+	case r >= 0xff01 && r <= 0xff1f: // fullwidth punctuation and digits
+		return true
+	}
+	return commonRunes[r]
+}
+
+// Detect guesses the character set of data. It returns the name
+// of the character set and a confidence between 0 and 1.
+// Data starting with a byte order mark or holding only valid UTF-8
+// is reported with high confidence. Otherwise each of several
+// multibyte character sets is scored by how much of the
+// decoded text consists of common characters and how little is
+// undecodable, and the best is returned. If none is plausible,
+// Detect returns "windows-1252" with low confidence.
+func Detect(data []byte) (name string, confidence float64) {
+	if cs, _ := DetectBOM(data); cs != "" {
+		return cs, 1
+	}
+	if cs, ok := detectUTF16(data); ok {
+		return cs, 0.8
+	}
+	if utf8.Valid(data) {
+		if isASCII(data) {
+			return "utf-8", 1
+		}
+		return "utf-8", 0.99
+	}
+	commonRunesOnce.Do(initCommonRunes)
+	best, bestScore := "", 0.0
+	for _, cs := range detectCandidates {
+		out, err := Decode(cs, data)
+		if err != nil {
+			continue
+		}
+		if score := detectScore(out); score > bestScore {
+			best, bestScore = cs, score
+		}
+	}
+	if best == "" {
+		return "windows-1252", 0.1
+	}
+	return best, 0.95 * bestScore
+}
+
+// detectScore scores decoded text by the proportion of its non-ASCII
+// characters that are common, with a penalty for undecodable bytes.
+func detectScore(text []byte) float64 {
+	n, common, bad := 0, 0, 0
+	for _, r := range string(text) {
+		switch {
+		case r < utf8.RuneSelf:
+			continue
+		case r == utf8.RuneError:
+			bad++
+		case isCommon(r):
+			common++
+		case !unicode.IsPrint(r):
+			bad++
+		}
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	score := float64(common-4*bad) / float64(n)
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// detectUTF16 looks for UTF-16 without a byte order mark, in which
+// text mostly in the Latin alphabet has a zero byte in every
+// other position.
+func detectUTF16(data []byte) (string, bool) {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return "", false
+	}
+	var zeros [2]int
+	for i, b := range data {
+		if b == 0 {
+			zeros[i%2]++
+		}
+	}
+	half := len(data) / 2
+	switch {
+	case zeros[1] > half*3/4 && zeros[0] == 0:
+		return "utf-16le", true
+	case zeros[0] > half*3/4 && zeros[1] == 0:
+		return "utf-16be", true
+	}
+	return "", false
+}
+
+func isASCII(data []byte) bool {
+	return bytes.IndexFunc(data, func(r rune) bool { return r >= utf8.RuneSelf }) < 0
+}
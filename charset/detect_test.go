@@ -0,0 +1,40 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var detectTests = []struct {
+	charset string
+	in      string
+}{
+	{"utf-8", "plain ASCII text"},
+	{"utf-8", "대한민국은 민주공화국이다."},
+	{"utf-16le", "\xff\xfea\x00b\x00"},
+	{"utf-16le", "h\x00e\x00l\x00l\x00o\x00"},
+	{"utf-8", "\xef\xbb\xbf\xe6\x97\xa5\xe6\x9c\xac"},
+	{"windows-949", "\xb4\xeb\xc7\xd1\xb9\xce\xb1\xb9\xc0\xba \xb9\xce\xc1\xd6\xb0\xf8\xc8\xad\xb1\xb9\xc0\xcc\xb4\xd9. \xb4\xeb\xc7\xd1\xb9\xce\xb1\xb9\xc0\xc7 \xc1\xd6\xb1\xc7\xc0\xba \xb1\xb9\xb9\xce\xbf\xa1\xb0\xd4 \xc0\xd6\xb0\xed, \xb8\xf0\xb5\xe7 \xb1\xc7\xb7\xc2\xc0\xba \xb1\xb9\xb9\xce\xc0\xb8\xb7\xce\xba\xce\xc5\xcd \xb3\xaa\xbf\xc2\xb4\xd9."},
+	{"windows-949", "\xbf\xc0\xb4\xc3 \xc8\xb8\xc0\xc7\xb4\xc2 3\xbd\xc3\xbf\xa1 \xbd\xc3\xc0\xdb\xc7\xd5\xb4\xcf\xb4\xd9. \xc0\xda\xb7\xe1\xb4\xc2 \xb9\xcc\xb8\xae \xc1\xd8\xba\xf1\xc7\xd8 \xc1\xd6\xbd\xc3\xb1\xe2 \xb9\xd9\xb6\xf8\xb4\xcf\xb4\xd9."},
+	{"gb18030", "\xd6\xd0\xbb\xaa\xc8\xcb\xc3\xf1\xb9\xb2\xba\xcd\xb9\xfa\xca\xc7\xb9\xa4\xc8\xcb\xbd\xd7\xbc\xb6\xc1\xec\xb5\xbc\xb5\xc4\xa1\xa2\xd2\xd4\xb9\xa4\xc5\xa9\xc1\xaa\xc3\xcb\xce\xaa\xbb\xf9\xb4\xa1\xb5\xc4\xc8\xcb\xc3\xf1\xc3\xf1\xd6\xf7\xd7\xa8\xd5\xfe\xb5\xc4\xc9\xe7\xbb\xe1\xd6\xf7\xd2\xe5\xb9\xfa\xbc\xd2\xa1\xa3"},
+	{"gb18030", "\xce\xd2\xc3\xc7\xc3\xf7\xcc\xec\xc8\xa5\xb1\xb1\xbe\xa9\xbf\xb4\xc5\xf3\xd3\xd1\xa3\xac\xc4\xe3\xd2\xaa\xb2\xbb\xd2\xaa\xd2\xbb\xc6\xf0\xc0\xb4\xa3\xbf"},
+	{"shift_jis", "\x93\xfa\x96{\x8d\x91\x96\xaf\x82\xcd\x81A\x90\xb3\x93\x96\x82\xc9\x91I\x8b\x93\x82\xb3\x82\xea\x82\xbd\x8d\x91\x89\xef\x82\xc9\x82\xa8\x82\xaf\x82\xe9\x91\xe3\x95\x5c\x8e\xd2\x82\xf0\x92\xca\x82\xb6\x82\xc4\x8ds\x93\xae\x82\xb5\x81A\x82\xed\x82\xea\x82\xe7\x82\xc6\x82\xed\x82\xea\x82\xe7\x82\xcc\x8eq\x91\xb7\x82\xcc\x82\xbd\x82\xdf\x82\xc9"},
+	{"euc-jp", "\xc6\xfc\xcb\xdc\xb9\xf1\xcc\xb1\xa4\xcf\xa1\xa2\xc0\xb5\xc5\xf6\xa4\xcb\xc1\xaa\xb5\xf3\xa4\xb5\xa4\xec\xa4\xbf\xb9\xf1\xb2\xf1\xa4\xcb\xa4\xaa\xa4\xb1\xa4\xeb\xc2\xe5\xc9\xbd\xbc\xd4\xa4\xf2\xc4\xcc\xa4\xb8\xa4\xc6\xb9\xd4\xc6\xb0\xa4\xb7\xa1\xa2\xa4\xef\xa4\xec\xa4\xe9\xa4\xc8\xa4\xef\xa4\xec\xa4\xe9\xa4\xce\xbb\xd2\xc2\xb9\xa4\xce\xa4\xbf\xa4\xe1\xa4\xcb"},
+	{"big5", "\xa4\xa4\xb5\xd8\xa5\xc1\xb0\xea\xb0\xf2\xa9\xf3\xa4T\xa5\xc1\xa5D\xb8q\xa1A\xac\xb0\xa5\xc1\xa6\xb3\xa5\xc1\xaav\xa5\xc1\xa8\xc9\xa4\xa7\xa5\xc1\xa5D\xa6@\xa9M\xb0\xea\xa1C"},
+}
+
+func TestDetect(t *testing.T) {
+	for i, test := range detectTests {
+		name, confidence := charset.Detect([]byte(test.in))
+		if name != test.charset {
+			t.Errorf("test %d: expected %q got %q (confidence %.2f)", i, test.charset, name, confidence)
+		}
+		if confidence <= 0 || confidence > 1 {
+			t.Errorf("test %d: confidence %v out of range", i, confidence)
+		}
+	}
+	if name, confidence := charset.Detect([]byte("caf\xe9")); name != "windows-1252" || confidence > 0.5 {
+		t.Errorf("latin text: got %q, %.2f", name, confidence)
+	}
+}
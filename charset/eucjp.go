@@ -0,0 +1,221 @@
+package charset
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("euc-jp", fromEUCJP, toEUCJP)
+}
+
+// encoding details
+//
+// 00..7f		ASCII
+// a1..fe a1..fe	JIS X 0208
+// 8e a1..df		JIS X 0201 katakana (SS2)
+// 8f a1..fe a1..fe	JIS X 0212 (SS3)
+//
+// The JIS X 0208 characters are decoded with the
+// shift-jis tables; JIS X 0212 is held in jisx0212.dat.
+
+const jisx0212Data = "jisx0212.dat"
+
+type eucJPTables struct {
+	jis  *jisTables
+	x212 cp949Table // JIS X 0212 sorted by native code (without the SS3 byte)
+}
+
+type translateFromEUCJP struct {
+	tables      *eucJPTables
+	replacement []byte // substituted for untranslatable bytes
+	scratch     []byte
+}
+
+func (p *translateFromEUCJP) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromEUCJP) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		b := data[0]
+		if b < 0x80 {
+			p.scratch = append(p.scratch, b)
+			data = data[1:]
+			n++
+			continue
+		}
+		need := 2
+		if b == 0x8f {
+			need = 3
+		}
+		if len(data) < need && !eof {
+			break
+		}
+		r, size := utf8.RuneError, 1
+		switch {
+		case len(data) < need:
+		case b == 0x8e:
+			if c := data[1]; c >= 0xa1 && c <= 0xdf {
+				r, size = 0xff61+rune(c-0xa1), 2
+			}
+		case b == 0x8f:
+			if isEUCByte(data[1]) && isEUCByte(data[2]) {
+				r, size = p.tables.jis0212(uint16(data[1])<<8|uint16(data[2])), 3
+			}
+		case isEUCByte(b) && isEUCByte(data[1]):
+			r, size = p.tables.jis.jis0208(b&0x7f, data[1]&0x7f), 2
+		}
+		p.scratch = appendDecoded(p.scratch, r, p.replacement)
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+func isEUCByte(b byte) bool {
+	return b >= 0xa1 && b <= 0xfe
+}
+
+func (t *eucJPTables) jis0212(c uint16) rune {
+	i := sort.Search(len(t.x212), func(i int) bool {
+		return c <= t.x212[i].native
+	})
+	if i < len(t.x212) && t.x212[i].native == c {
+		return t.x212[i].unicode
+	}
+	return utf8.RuneError
+}
+
+type translateToEUCJP struct {
+	rune2code   map[rune]uint32 // codes are 1, 2 or 3 bytes long
+	replacement []byte
+	strict      bool
+	scratch     []byte
+}
+
+func (p *translateToEUCJP) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToEUCJP) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		code, ok := p.rune2code[r]
+		switch {
+		case !ok && p.strict:
+			return n, p.scratch, strictError(n, r, size)
+		case !ok:
+			p.scratch = append(p.scratch, p.replacement...)
+		case code < 0x10000:
+			p.scratch = append(p.scratch, byte(code>>8), byte(code))
+		default:
+			p.scratch = append(p.scratch, byte(code>>16), byte(code>>8), byte(code))
+		}
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+type eucJPKey bool
+type eucJPKeyTo bool
+
+func getEUCJPTables() (*eucJPTables, error) {
+	jis, err := getJISTables(true)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := cache(eucJPKey(true), func() (interface{}, error) {
+		x212, err := loadCodeTable(jisx0212Data)
+		if err != nil {
+			return nil, err
+		}
+		if !sort.IsSorted(cp949TableSortByNative{x212}) {
+			panic("jisx0212.dat is not sorted by native code!")
+		}
+		return &eucJPTables{jis: jis, x212: x212}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables.(*eucJPTables), nil
+}
+
+// fromEUCJP accepts the same "replacement" and "drop"
+// options as fromCp949.
+func fromEUCJP(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getEUCJPTables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromEUCJP{tables: tables, replacement: repl}, nil
+}
+
+// toEUCJP accepts the same "replacement", "drop" and "strict"
+// options as toCp949.
+func toEUCJP(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop", "strict")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getEUCJPTables()
+	if err != nil {
+		return nil, err
+	}
+	m, err := cache(eucJPKeyTo(true), func() (interface{}, error) {
+		// JIS X 0208 is preferred to JIS X 0212
+		// where both have a character.
+		m := make(map[rune]uint32)
+		add := func(r rune, code uint32) {
+			if _, ok := m[r]; !ok && r != utf8.RuneError {
+				m[r] = code
+			}
+		}
+		for j1 := byte(0x21); j1 <= 0x7e; j1++ {
+			for j2 := byte(0x21); j2 <= 0x7e; j2++ {
+				add(tables.jis.jis0208(j1, j2), uint32(j1|0x80)<<8|uint32(j2|0x80))
+			}
+		}
+		for c := uint32(0xa1); c <= 0xdf; c++ {
+			add(0xff61+rune(c-0xa1), 0x8e00|c)
+		}
+		for _, c := range tables.x212 {
+			add(c.unicode, 0x8f0000|uint32(c.native))
+		}
+		return m, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &translateToEUCJP{
+		rune2code:   m.(map[rune]uint32),
+		replacement: repl,
+		strict:      opts.has("strict"),
+	}, nil
+}
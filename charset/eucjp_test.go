@@ -0,0 +1,53 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var eucJPTests = []struct {
+	native  string
+	unicode string
+}{
+	{"abc", "abc"},
+	{"\xc6\xfc\xcb\xdc", "日本"},
+	{"\x8e\xb1\x8e\xb2", "ｱｲ"},
+	{"\x8f\xb0\xa1", "丂"},
+	{"a\x8e\xb1\xc6\xfc\xcb\xdc\x8f\xb0\xa1", "aｱ日本丂"},
+}
+
+func TestEUCJP(t *testing.T) {
+	for _, test := range eucJPTests {
+		if out := decodeString(t, "euc-jp", test.native); out != test.unicode {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "euc-jp", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.unicode, test.native, out)
+		}
+	}
+}
+
+func TestEUCJPSplit(t *testing.T) {
+	in := "a\x8e\xb1\x8f\xb0\xa1b"
+	want := "aｱ丂b"
+	for split := 1; split < len(in); split++ {
+		tr, err := charset.TranslatorFrom("euc-jp")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate([]byte(in[:split]), false)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		out := string(cdata)
+		_, cdata, err = tr.Translate([]byte(in[n:]), true)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		out += string(cdata)
+		if out != want {
+			t.Errorf("split %d: expected %q got %q", split, want, out)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package charset
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("euc-tw", fromEUCTW, toEUCTW)
+}
+
+// encoding details
+//
+// 00..7f			ASCII
+// a1..fe a1..fe		CNS 11643 plane 1
+// 8e a1..a7 a1..fe a1..fe	CNS 11643 planes 1 to 7 (SS2)
+//
+// euctw.dat holds planes 1 to 7, each character keyed
+// by its index (plane-1)*94*94 + (row-1)*94 + (cell-1).
+
+const (
+	euctwData      = "euctw.dat"
+	euctwPlanes    = 7
+	euctwPlaneSize = 94 * 94
+)
+
+type eucTWTables struct {
+	byNative cp949Table
+}
+
+type translateFromEUCTW struct {
+	tables      *eucTWTables
+	replacement []byte // substituted for untranslatable bytes
+	scratch     []byte
+}
+
+func (p *translateFromEUCTW) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromEUCTW) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		b := data[0]
+		if b < 0x80 {
+			p.scratch = append(p.scratch, b)
+			data = data[1:]
+			n++
+			continue
+		}
+		need := 2
+		if b == 0x8e {
+			need = 4
+		}
+		if len(data) < need && !eof {
+			break
+		}
+		r, size := utf8.RuneError, 1
+		switch {
+		case len(data) < need:
+		case b == 0x8e:
+			plane := int(data[1]) - 0xa0
+			if plane >= 1 && plane <= euctwPlanes && isEUCByte(data[2]) && isEUCByte(data[3]) {
+				r, size = p.tables.cns(plane, data[2], data[3]), 4
+			}
+		case isEUCByte(b) && isEUCByte(data[1]):
+			r, size = p.tables.cns(1, b, data[1]), 2
+		}
+		p.scratch = appendDecoded(p.scratch, r, p.replacement)
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+// cns returns the rune for the character at the given
+// row and cell bytes of CNS 11643 plane.
+func (t *eucTWTables) cns(plane int, b1, b2 byte) rune {
+	c := uint16((plane-1)*euctwPlaneSize + int(b1-0xa1)*94 + int(b2-0xa1))
+	i := sort.Search(len(t.byNative), func(i int) bool {
+		return c <= t.byNative[i].native
+	})
+	if i < len(t.byNative) && t.byNative[i].native == c {
+		return t.byNative[i].unicode
+	}
+	return utf8.RuneError
+}
+
+type translateToEUCTW struct {
+	rune2code   map[rune]uint16 // index as in euctw.dat
+	replacement []byte
+	strict      bool
+	scratch     []byte
+}
+
+func (p *translateToEUCTW) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToEUCTW) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		code, ok := p.rune2code[r]
+		switch {
+		case !ok && p.strict:
+			return n, p.scratch, strictError(n, r, size)
+		case !ok:
+			p.scratch = append(p.scratch, p.replacement...)
+		default:
+			plane := int(code) / euctwPlaneSize
+			c := int(code) % euctwPlaneSize
+			if plane > 0 {
+				p.scratch = append(p.scratch, 0x8e, byte(0xa1+plane))
+			}
+			p.scratch = append(p.scratch, byte(0xa1+c/94), byte(0xa1+c%94))
+		}
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+type eucTWKey bool
+type eucTWKeyTo bool
+
+func getEUCTWTables() (*eucTWTables, error) {
+	tables, err := cache(eucTWKey(true), func() (interface{}, error) {
+		byNative, err := loadCodeTable(euctwData)
+		if err != nil {
+			return nil, err
+		}
+		if !sort.IsSorted(cp949TableSortByNative{byNative}) {
+			panic("euctw.dat is not sorted by native code!")
+		}
+		return &eucTWTables{byNative: byNative}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables.(*eucTWTables), nil
+}
+
+// fromEUCTW accepts the same "replacement" and "drop"
+// options as fromCp949.
+func fromEUCTW(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getEUCTWTables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromEUCTW{tables: tables, replacement: repl}, nil
+}
+
+// toEUCTW accepts the same "replacement", "drop" and "strict"
+// options as toCp949. Characters in plane 1 are written
+// in the two byte form.
+func toEUCTW(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop", "strict")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getEUCTWTables()
+	if err != nil {
+		return nil, err
+	}
+	m, err := cache(eucTWKeyTo(true), func() (interface{}, error) {
+		// The table is in plane order, so lower
+		// planes are preferred.
+		m := make(map[rune]uint16)
+		for _, c := range tables.byNative {
+			if _, ok := m[c.unicode]; !ok {
+				m[c.unicode] = c.native
+			}
+		}
+		return m, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &translateToEUCTW{
+		rune2code:   m.(map[rune]uint16),
+		replacement: repl,
+		strict:      opts.has("strict"),
+	}, nil
+}
@@ -0,0 +1,44 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+var euctwTests = []struct {
+	native  string
+	unicode string
+}{
+	{"abc", "abc"},
+	{"\xc4\xe3\xc5\xc6\xea\xd7\xfd\xa4", "中文臺灣"},
+	{"\x8e\xa2\xa1\xa1\x8e\xa2\xa1\xa2", "乂乜"},
+	{"\x8e\xa3\xa1\xa1", "丨"},
+	{"\x8e\xa7\xa1\xa1", "𠁕"},
+	{"a\xc4\xe3b\x8e\xa2\xa1\xa1c", "a中b乂c"},
+}
+
+func TestEUCTW(t *testing.T) {
+	for _, test := range euctwTests {
+		if out := decodeString(t, "euc-tw", test.native); out != test.unicode {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "euc-tw", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.unicode, test.native, out)
+		}
+	}
+}
+
+func TestEUCTWDecode(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		// The four byte form of plane 1.
+		"\x8e\xa1\xc4\xe3": "中",
+		"\x8e\xb0a\xa1":    bad + bad + "a" + bad,
+		"\xc4":             bad,
+		"\x8e\xa2":         bad + bad,
+	} {
+		if got := decodeString(t, "euc-tw", in); got != out {
+			t.Errorf("decoding %x: expected %q got %q", in, out, got)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package charset
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 var files = make(map[string]func() (io.ReadCloser, error))
@@ -23,18 +27,54 @@ func RegisterDataFile(name string, open func() (io.ReadCloser, error)) {
 // been registered with RegisterDataFile.
 var CharsetDir = "/usr/local/lib/go-charset/datafiles"
 
-func readFile(name string) (data []byte, err error) {
+var (
+	dataFSMutex sync.Mutex
+	dataFS      fs.FS
+)
+
+// SetDataFS sets a file system from which data files are read
+// in preference to those registered with RegisterDataFile or
+// found in CharsetDir. Files that do not exist in fsys are
+// looked for in the usual way. SetDataFS(nil) restores the default.
+// Tables already loaded are not affected, so SetDataFS should
+// be called before any character set is used.
+func SetDataFS(fsys fs.FS) {
+	dataFSMutex.Lock()
+	dataFS = fsys
+	dataFSMutex.Unlock()
+}
+
+// readFile returns the contents of the named data file.
+// If it cannot be read, the error says where it was looked for.
+func readFile(name string) ([]byte, error) {
+	dataFSMutex.Lock()
+	fsys := dataFS
+	dataFSMutex.Unlock()
+	if fsys != nil {
+		data, err := fs.ReadFile(fsys, name)
+		if !errors.Is(err, fs.ErrNotExist) {
+			if err != nil {
+				return nil, fmt.Errorf("charset: cannot load %s from the data file system: %w", name, err)
+			}
+			return data, nil
+		}
+	}
 	var r io.ReadCloser
+	var err error
+	where := "the registered data files"
 	if open := files[name]; open != nil {
 		r, err = open()
-		if err != nil {
-			return
-		}
 	} else {
+		where = CharsetDir
 		r, err = os.Open(filepath.Join(CharsetDir, name))
-		if err != nil {
-			return
-		}
 	}
-	return ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("charset: cannot load %s from %s: %w", name, where, err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("charset: cannot load %s from %s: %w", name, where, err)
+	}
+	return data, nil
 }
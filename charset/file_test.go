@@ -0,0 +1,77 @@
+package charset
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSetDataFS(t *testing.T) {
+	// A table holding the single code 0xb0a1, mapped to 'Z'
+	// rather than to U+AC00 as in the real cp949.dat.
+	const dat = "\x00\x01\x00\x01\xb0\xa1\x00\x01Z"
+	SetDataFS(fstest.MapFS{"cp949.dat": {Data: []byte(dat)}})
+	defer SetDataFS(nil)
+
+	// Make sure the table is loaded afresh, and restore
+	// the usual one afterwards.
+	cacheMutex.Lock()
+	saved := cacheStore[cp949KeyFrom(false)]
+	delete(cacheStore, cp949KeyFrom(false))
+	cacheMutex.Unlock()
+	defer func() {
+		cacheMutex.Lock()
+		if saved != nil {
+			cacheStore[cp949KeyFrom(false)] = saved
+		} else {
+			delete(cacheStore, cp949KeyFrom(false))
+		}
+		cacheMutex.Unlock()
+	}()
+
+	out, err := DecodeString("cp949", "a\xb0\xa1")
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if out != "aZ" {
+		t.Errorf("expected %q got %q", "aZ", out)
+	}
+}
+
+func TestMissingDataFile(t *testing.T) {
+	// Hide the registered gbk.dat and any loaded table.
+	saved := files[gbkData]
+	delete(files, gbkData)
+	dir := CharsetDir
+	CharsetDir = t.TempDir()
+	cacheMutex.Lock()
+	savedTables := cacheStore[gbkKey(true)]
+	delete(cacheStore, gbkKey(true))
+	cacheMutex.Unlock()
+	defer func() {
+		if saved != nil {
+			files[gbkData] = saved
+		}
+		CharsetDir = dir
+		cacheMutex.Lock()
+		if savedTables != nil {
+			cacheStore[gbkKey(true)] = savedTables
+		} else {
+			delete(cacheStore, gbkKey(true))
+		}
+		cacheMutex.Unlock()
+	}()
+
+	_, err := TranslatorFrom("gbk")
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if msg := err.Error(); !strings.Contains(msg, gbkData) || !strings.Contains(msg, CharsetDir) {
+		t.Errorf("error %q does not mention %s in %s", msg, gbkData, CharsetDir)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected an error wrapping fs.ErrNotExist, got %v", err)
+	}
+}
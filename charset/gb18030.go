@@ -0,0 +1,271 @@
+package charset
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("gb18030", fromGB18030, toGB18030)
+}
+
+// encoding details
+//
+// 00..7f		ASCII
+// 81..fe 40..7e	two-byte code
+// 81..fe 80..fe	two-byte code
+// 81..fe 30..39 81..fe 30..39	four-byte code
+//
+// The four-byte codes are numbered consecutively by a linear index.
+// Indexes 0 to gb18030MaxBMP map, in order, those runes in the
+// Basic Multilingual Plane that have no two-byte code.
+// Indexes from gb18030SuppBase map the supplementary planes,
+// starting at U+10000.
+const (
+	gb18030Data     = "gb18030.dat"
+	gb18030MaxBMP   = 39419
+	gb18030SuppBase = 189000
+)
+
+// gb18030Range is a run of consecutive runes
+// with consecutive four-byte indexes.
+type gb18030Range struct {
+	first rune // first rune in the run
+	index int  // four-byte index of first
+	n     int  // number of runes in the run
+}
+
+type gb18030Tables struct {
+	byNative  cp949Table     // two-byte codes sorted by native code
+	byUnicode cp949Table     // two-byte codes sorted by unicode
+	ranges    []gb18030Range // four-byte codes in the BMP
+}
+
+// fourByte returns the rune with the given four-byte index,
+// or utf8.RuneError and false if there is none.
+func (t *gb18030Tables) fourByte(index int) (rune, bool) {
+	if index > gb18030MaxBMP {
+		r := rune(index-gb18030SuppBase) + 0x10000
+		if index < gb18030SuppBase || r > utf8.MaxRune {
+			return utf8.RuneError, false
+		}
+		return r, true
+	}
+	i := sort.Search(len(t.ranges), func(i int) bool {
+		return t.ranges[i].index > index
+	}) - 1
+	return t.ranges[i].first + rune(index-t.ranges[i].index), true
+}
+
+// fourByteIndex returns the four-byte index of
+// the given rune, which has no two-byte code.
+func (t *gb18030Tables) fourByteIndex(r rune) int {
+	if r >= 0x10000 {
+		return int(r-0x10000) + gb18030SuppBase
+	}
+	i := sort.Search(len(t.ranges), func(i int) bool {
+		return t.ranges[i].first > r
+	}) - 1
+	if i < 0 || int(r-t.ranges[i].first) >= t.ranges[i].n {
+		return -1
+	}
+	return t.ranges[i].index + int(r-t.ranges[i].first)
+}
+
+type translateFromGB18030 struct {
+	tables  *gb18030Tables
+	strict  bool // return an error at invalid input
+	scratch []byte
+}
+
+func (p *translateFromGB18030) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromGB18030) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		b := data[0]
+		if b < 0x80 {
+			p.scratch = append(p.scratch, b)
+			data = data[1:]
+			n++
+			continue
+		}
+		r, size, ok := utf8.RuneError, 1, false
+		switch {
+		case b == 0x80 || b == 0xff:
+		case len(data) < 2:
+			if !eof {
+				return n, p.scratch, nil
+			}
+		case data[1] >= 0x30 && data[1] <= 0x39:
+			if len(data) < 4 {
+				if !eof {
+					return n, p.scratch, nil
+				}
+				break
+			}
+			b3, b4 := data[2], data[3]
+			if b3 < 0x81 || b3 > 0xfe || b4 < 0x30 || b4 > 0x39 {
+				break
+			}
+			index := ((int(b-0x81)*10+int(data[1]-0x30))*126+int(b3-0x81))*10 + int(b4-0x30)
+			r, ok = p.tables.fourByte(index)
+			size = 4
+		case data[1] >= 0x40 && data[1] <= 0xfe && data[1] != 0x7f:
+			c := uint16(b)<<8 | uint16(data[1])
+			t := p.tables.byNative
+			i := sort.Search(len(t), func(i int) bool {
+				return c <= t[i].native
+			})
+			if i < len(t) && t[i].native == c {
+				r, ok = t[i].unicode, true
+			}
+			size = 2
+		}
+		if !ok && p.strict {
+			return n, p.scratch, &InvalidByteError{Offset: n, Byte: b}
+		}
+		p.scratch = appendRune(p.scratch, r)
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+type translateToGB18030 struct {
+	tables  *gb18030Tables
+	strict  bool // return an error at invalid UTF-8
+	scratch []byte
+}
+
+func (p *translateToGB18030) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToGB18030) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 && p.strict {
+			return n, p.scratch, strictError(n, r, size)
+		}
+		t := p.tables.byUnicode
+		i := sort.Search(len(t), func(i int) bool {
+			return r <= t[i].unicode
+		})
+		if i < len(t) && t[i].unicode == r {
+			p.scratch = append(p.scratch, byte(t[i].native>>8), byte(t[i].native))
+		} else if index := p.tables.fourByteIndex(r); index >= 0 {
+			p.scratch = appendGB18030Index(p.scratch, index)
+		} else {
+			p.scratch = append(p.scratch, '?')
+		}
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+func appendGB18030Index(buf []byte, index int) []byte {
+	b4 := index % 10
+	index /= 10
+	b3 := index % 126
+	index /= 126
+	b2 := index % 10
+	b1 := index / 10
+	return append(buf, byte(b1+0x81), byte(b2+0x30), byte(b3+0x81), byte(b4+0x30))
+}
+
+type gb18030Key bool
+
+func getGB18030Tables() (*gb18030Tables, error) {
+	tables, err := cache(gb18030Key(true), func() (interface{}, error) {
+		byNative, err := loadCodeTable(gb18030Data)
+		if err != nil {
+			return nil, err
+		}
+		if !sort.IsSorted(cp949TableSortByNative{byNative}) {
+			panic("gb18030.dat is not sorted by native code!")
+		}
+		byUnicode := make(cp949Table, len(byNative))
+		copy(byUnicode, byNative)
+		sort.Sort(cp949TableSortByUnicode{byUnicode})
+
+		// The four-byte BMP codes are the runes missing
+		// from the two-byte table, excluding surrogates.
+		var ranges []gb18030Range
+		index := 0
+		next := rune(0x80)
+		add := func(first, end rune) {
+			if first < end {
+				ranges = append(ranges, gb18030Range{first, index, int(end - first)})
+				index += int(end - first)
+			}
+		}
+		for _, c := range byUnicode {
+			if c.unicode < next {
+				continue
+			}
+			if next < 0xd800 && c.unicode > 0xdfff {
+				add(next, 0xd800)
+				next = 0xe000
+			}
+			add(next, c.unicode)
+			next = c.unicode + 1
+		}
+		add(next, 0x10000)
+		return &gb18030Tables{
+			byNative:  byNative,
+			byUnicode: byUnicode,
+			ranges:    ranges,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables.(*gb18030Tables), nil
+}
+
+// factory to create translateFromGB18030. Invalid input
+// decodes to utf8.RuneError, or with the "strict" option
+// causes an *InvalidByteError.
+func fromGB18030(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "strict")
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getGB18030Tables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromGB18030{tables: tables, strict: opts.has("strict")}, nil
+}
+
+// factory to create translateToGB18030. Every rune can be
+// encoded, but with the "strict" option invalid UTF-8
+// causes an *InvalidUTF8Error rather than being
+// encoded as utf8.RuneError.
+func toGB18030(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "strict")
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getGB18030Tables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateToGB18030{tables: tables, strict: opts.has("strict")}, nil
+}
@@ -0,0 +1,68 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var gb18030Tests = []struct {
+	native  string
+	unicode string
+}{
+	{"abc", "abc"},
+	{"\xd6\xd0\xce\xc4", "中文"},
+	{"\xa2\xe3", "€"},
+	{"\x81\x30\x81\x30", "\u0080"},
+	{"\x84\x31\xa4\x39", "￿"},
+	{"\x95\x32\x82\x36", "\U00020000"},
+	{"\x94\x39\xfc\x36", "😀"},
+	{"\xe3\x32\x9a\x35", "\U0010ffff"},
+	{"a\x95\x32\x82\x36b\xa8\xa6", "a\U00020000bé"},
+}
+
+func TestGB18030(t *testing.T) {
+	for _, test := range gb18030Tests {
+		if out := decodeString(t, "gb18030", test.native); out != test.unicode {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "gb18030", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.unicode, test.native, out)
+		}
+	}
+}
+
+func TestGB18030Invalid(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		"\x80a":             bad + "a",
+		"\xffa":             bad + "a",
+		"\x81":              bad,
+		"\x81\x30":          bad + "0",
+		"\x81\x30\x81":      bad + "0" + bad,
+		"\x84\x31\xa5\x30a": bad + "a",
+	} {
+		if got := decodeString(t, "gb18030", in); got != out {
+			t.Errorf("decoding %x: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestGB18030Split(t *testing.T) {
+	in := []byte("\x95\x32\x82\x36")
+	for split := 1; split < len(in); split++ {
+		tr, err := charset.TranslatorFrom("gb18030")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate(in[:split], false)
+		if err != nil || n != 0 || len(cdata) != 0 {
+			t.Fatalf("split %d: expected nothing, got %d, %q, %v", split, n, cdata, err)
+		}
+		n, cdata, err = tr.Translate(in, true)
+		if err != nil || n != len(in) || string(cdata) != "\U00020000" {
+			t.Fatalf("split %d: got %d, %q, %v", split, n, cdata, err)
+		}
+	}
+}
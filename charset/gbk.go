@@ -0,0 +1,201 @@
+package charset
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("gbk", fromGBK, toGBK)
+}
+
+// encoding details
+//
+// 00..7f		ASCII
+// 80		euro sign (code page 936)
+// 81..fe 40..7e	two-byte code
+// 81..fe 80..fe	two-byte code
+//
+// GB 2312 is the subset of the two-byte codes with both bytes
+// in a1..fe, and lead bytes no higher than f7.
+const gbkData = "gbk.dat"
+
+type gbkTables struct {
+	pages     *cp949Pages // two-byte codes by native code
+	byUnicode cp949Table  // two-byte codes sorted by unicode
+}
+
+type translateFromGBK struct {
+	tables      *gbkTables
+	gb2312      bool   // decode only the GB 2312 codes
+	replacement []byte // substituted for untranslatable bytes
+	scratch     []byte
+}
+
+func (p *translateFromGBK) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromGBK) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		b := data[0]
+		if b < 0x80 {
+			p.scratch = append(p.scratch, b)
+			data = data[1:]
+			n++
+			continue
+		}
+		// A lead byte followed by a byte that cannot trail it
+		// is consumed alone, so that an ASCII character
+		// after it is not lost.
+		r, size := utf8.RuneError, 1
+		switch {
+		case b == 0x80:
+			if !p.gb2312 {
+				r = '€'
+			}
+		case b == 0xff:
+		case len(data) < 2:
+			if !eof {
+				return n, p.scratch, nil
+			}
+		case data[1] >= 0x40 && data[1] <= 0xfe && data[1] != 0x7f:
+			code := uint16(b)<<8 | uint16(data[1])
+			if c, ok := p.tables.pages.lookup(code); ok && (!p.gb2312 || isGB2312(code)) {
+				r = c
+			}
+			size = 2
+		}
+		p.scratch = appendDecoded(p.scratch, r, p.replacement)
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+type translateToGBK struct {
+	tables      *gbkTables
+	gb2312      bool   // encode only the GB 2312 codes
+	strict      bool   // return an error instead of substituting
+	replacement []byte // substituted for runes with no encoding
+	scratch     []byte
+}
+
+func (p *translateToGBK) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToGBK) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		t := p.tables.byUnicode
+		i := sort.Search(len(t), func(i int) bool {
+			return r <= t[i].unicode
+		})
+		switch {
+		case r == '€' && !p.gb2312:
+			p.scratch = append(p.scratch, 0x80)
+		case i < len(t) && t[i].unicode == r && (!p.gb2312 || isGB2312(t[i].native)):
+			p.scratch = append(p.scratch, byte(t[i].native>>8), byte(t[i].native))
+		case p.strict:
+			return n, p.scratch, strictError(n, r, size)
+		default:
+			p.scratch = append(p.scratch, p.replacement...)
+		}
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+// isGB2312 reports whether the two-byte code c is
+// in GB 2312 rather than among the codes added by GBK.
+func isGB2312(c uint16) bool {
+	return c>>8 >= 0xa1 && c>>8 <= 0xf7 && c&0xff >= 0xa1 && c&0xff <= 0xfe
+}
+
+type gbkKey bool
+
+func getGBKTables() (*gbkTables, error) {
+	tables, err := cache(gbkKey(true), func() (interface{}, error) {
+		byNative, err := loadCodeTable(gbkData)
+		if err != nil {
+			return nil, err
+		}
+		byUnicode := make(cp949Table, len(byNative))
+		copy(byUnicode, byNative)
+		sort.Sort(cp949TableSortByUnicode{byUnicode})
+		return &gbkTables{
+			pages:     newCp949Pages(byNative),
+			byUnicode: byUnicode,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables.(*gbkTables), nil
+}
+
+// factory to create translateFromGBK. Text in GB 2312
+// is decoded as GBK, of which it is a subset, unless the
+// "strict" option is given, as in "gb2312?strict", when the
+// codes that GBK adds are decoded as utf8.RuneError.
+// The "replacement" and "drop" options are as for fromCp949.
+func fromGBK(arg string) (Translator, error) {
+	arg, opts, err := splitArg(arg, "strict", "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getGBKTables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromGBK{
+		tables:      tables,
+		gb2312:      arg == "gb2312" && opts.has("strict"),
+		replacement: repl,
+	}, nil
+}
+
+// factory to create translateToGBK. Runes with no
+// encoding are translated to '?', or to the byte given by
+// the "replacement" option, or are omitted with the "drop"
+// option, or with the "strict" option cause an *UnmappableError.
+// With the argument "gb2312", only the GB 2312 codes are used.
+func toGBK(arg string) (Translator, error) {
+	arg, opts, err := splitArg(arg, "strict", "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getGBKTables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateToGBK{
+		tables:      tables,
+		gb2312:      arg == "gb2312",
+		strict:      opts.has("strict"),
+		replacement: repl,
+	}, nil
+}
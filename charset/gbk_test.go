@@ -0,0 +1,106 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var gbkTests = []struct {
+	charset string
+	native  string
+	unicode string
+}{
+	{"gbk", "abc", "abc"},
+	{"gbk", "\xd6\xd0\xce\xc4", "中文"},
+	{"gbk", "\x81\x40", "丂"},
+	{"gbk", "\xfd\x89", "龎"},
+	{"gbk", "\x80", "€"},
+	{"cp936", "a\xc4\xe3\xba\xc3b", "a你好b"},
+	{"gb2312", "\xd6\xd0\xce\xc4", "中文"},
+}
+
+func TestGBK(t *testing.T) {
+	for _, test := range gbkTests {
+		if out := decodeString(t, test.charset, test.native); out != test.unicode {
+			t.Errorf("%s: decoding %x: expected %q got %q", test.charset, test.native, test.unicode, out)
+		}
+		if out := encodeString(t, test.charset, test.unicode); out != test.native {
+			t.Errorf("%s: encoding %q: expected %x got %x", test.charset, test.unicode, test.native, out)
+		}
+	}
+}
+
+// TestGBKRoundTrip decodes and re-encodes the first
+// few hundred characters of GB 2312 level 1.
+func TestGBKRoundTrip(t *testing.T) {
+	var in []byte
+	for lead := 0xb0; lead <= 0xb3; lead++ {
+		for trail := 0xa1; trail <= 0xfe; trail++ {
+			in = append(in, byte(lead), byte(trail))
+		}
+	}
+	for _, cs := range []string{"gbk", "gb2312"} {
+		out := decodeString(t, cs, string(in))
+		if n := utf8.RuneCountInString(out); n != len(in)/2 {
+			t.Errorf("%s: expected %d runes, got %d", cs, len(in)/2, n)
+		}
+		for _, r := range out {
+			if r < 0x4e00 || r > 0x9fff {
+				t.Errorf("%s: unexpected rune %q", cs, r)
+			}
+		}
+		if back := encodeString(t, cs, out); back != string(in) {
+			t.Errorf("%s: round trip failed: got %x", cs, back)
+		}
+	}
+}
+
+func TestGBKInvalid(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		// 0x7f cannot trail, so it is left to decode as ASCII.
+		"\x81\x7f":  bad + "\x7f",
+		"\x81\x7fa": bad + "\x7fa",
+		"\x81\x3fa": bad + "?a",
+		"\xffa":     bad + "a",
+		"\x81":      bad,
+		"\xa1\x40":  bad,
+	} {
+		if got := decodeString(t, "gbk", in); got != out {
+			t.Errorf("decoding %x: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestGB2312Subset(t *testing.T) {
+	// 丂 and the euro sign are in GBK but not GB 2312.
+	if out := encodeString(t, "gb2312", "丂€中"); out != "??\xd6\xd0" {
+		t.Errorf("expected %x, got %x", "??\xd6\xd0", out)
+	}
+	tr, err := charset.TranslatorTo("gb2312?strict")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	_, cdata, err := tr.Translate([]byte("中丂"), true)
+	if _, ok := err.(*charset.UnmappableError); !ok || string(cdata) != "\xd6\xd0" {
+		t.Errorf("expected %x, *UnmappableError; got %x, %v", "\xd6\xd0", cdata, err)
+	}
+}
+
+func TestGB2312StrictDecode(t *testing.T) {
+	// 丂 (0x8140), 癅 (0xb040) and the euro sign
+	// are GBK additions; 中 is in GB 2312.
+	in := "\x81\x40\xb0\x40\x80\xd6\xd0"
+	if out := decodeString(t, "gbk", in); out != "丂癅€中" {
+		t.Errorf("gbk: expected %q, got %q", "丂癅€中", out)
+	}
+	bad := string(utf8.RuneError)
+	if out := decodeString(t, "gb2312?strict", in); out != bad+bad+bad+"中" {
+		t.Errorf("gb2312?strict: expected %q, got %q", bad+bad+bad+"中", out)
+	}
+	if out := decodeString(t, "gb2312", in); out != "丂癅€中" {
+		t.Errorf("gb2312: expected %q, got %q", "丂癅€中", out)
+	}
+}
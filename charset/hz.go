@@ -0,0 +1,210 @@
+package charset
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("hz", fromHZ, toHZ)
+}
+
+// encoding details
+//
+// HZ (RFC 1843) is a 7-bit encoding of GB2312. The text
+// starts in ASCII mode, where these escapes are recognized:
+//
+// ~{	switch to GB mode
+// ~~	'~'
+// ~\n	line continuation, ignored
+//
+// In GB mode, each pair of bytes in the range 21..7e is a GB2312
+// character with the high bit of each byte cleared, and "~}"
+// switches back to ASCII mode. GB2312 characters are translated
+// with the two-byte gb18030 table.
+
+type translateFromHZ struct {
+	tables  *gb18030Tables
+	gb      bool
+	scratch []byte
+}
+
+func (p *translateFromHZ) Reset() {
+	p.gb = false
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromHZ) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		b := data[0]
+		if b == '~' {
+			if len(data) < 2 {
+				if !eof {
+					break
+				}
+				p.scratch = appendRune(p.scratch, utf8.RuneError)
+				data = data[1:]
+				n++
+				continue
+			}
+			size := 2
+			switch data[1] {
+			case '{':
+				p.gb = true
+			case '}':
+				p.gb = false
+			case '~':
+				p.scratch = append(p.scratch, '~')
+			case '\n':
+			default:
+				p.scratch = appendRune(p.scratch, utf8.RuneError)
+				size = 1
+			}
+			data = data[size:]
+			n += size
+			continue
+		}
+		if b >= 0x80 {
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+			data = data[1:]
+			n++
+			continue
+		}
+		if !p.gb || b < 0x21 || b == 0x7f {
+			p.scratch = append(p.scratch, b)
+			data = data[1:]
+			n++
+			continue
+		}
+		if len(data) < 2 {
+			if !eof {
+				break
+			}
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+			data = data[1:]
+			n++
+			continue
+		}
+		c := data[1]
+		if c == '~' && len(data) < 3 && !eof {
+			// This may be the start of a "~}" after a truncated pair.
+			break
+		}
+		if c < 0x21 || c > 0x7e || c == '~' && len(data) > 2 && data[2] == '}' {
+			// A truncated pair. The byte after it, such as
+			// the '~' of "~}" or a newline, is decoded afresh.
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+			data = data[1:]
+			n++
+			continue
+		}
+		p.scratch = appendRune(p.scratch, p.tables.gb2312(uint16(b|0x80)<<8|uint16(c|0x80)))
+		data = data[2:]
+		n += 2
+	}
+	return n, p.scratch, nil
+}
+
+// gb2312 returns the rune for the GB2312 character c (in EUC form).
+func (t *gb18030Tables) gb2312(c uint16) rune {
+	i := sort.Search(len(t.byNative), func(i int) bool {
+		return c <= t.byNative[i].native
+	})
+	if i < len(t.byNative) && t.byNative[i].native == c {
+		return t.byNative[i].unicode
+	}
+	return utf8.RuneError
+}
+
+// gb2312Code returns the GB2312 code (in EUC form) of r, or 0.
+func (t *gb18030Tables) gb2312Code(r rune) uint16 {
+	i := sort.Search(len(t.byUnicode), func(i int) bool {
+		return r <= t.byUnicode[i].unicode
+	})
+	if i == len(t.byUnicode) || t.byUnicode[i].unicode != r {
+		return 0
+	}
+	c := t.byUnicode[i].native
+	if c < 0xa1a1 || c > 0xf7fe || c&0xff < 0xa1 || c&0xff == 0xff {
+		return 0
+	}
+	return c
+}
+
+type translateToHZ struct {
+	tables  *gb18030Tables
+	gb      bool
+	scratch []byte
+}
+
+func (p *translateToHZ) Reset() {
+	p.gb = false
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToHZ) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		var c uint16
+		if r >= utf8.RuneSelf {
+			c = p.tables.gb2312Code(r)
+		}
+		switch {
+		case c != 0:
+			if !p.gb {
+				p.scratch = append(p.scratch, "~{"...)
+				p.gb = true
+			}
+			p.scratch = append(p.scratch, byte(c>>8)&0x7f, byte(c)&0x7f)
+		default:
+			if p.gb {
+				p.scratch = append(p.scratch, "~}"...)
+				p.gb = false
+			}
+			switch {
+			case r == '~':
+				p.scratch = append(p.scratch, "~~"...)
+			case r < utf8.RuneSelf:
+				p.scratch = append(p.scratch, byte(r))
+			default:
+				p.scratch = append(p.scratch, '?')
+			}
+		}
+		data = data[size:]
+		n += size
+	}
+	if eof && p.gb {
+		p.scratch = append(p.scratch, "~}"...)
+		p.gb = false
+	}
+	return n, p.scratch, nil
+}
+
+func fromHZ(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	tables, err := getGB18030Tables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromHZ{tables: tables}, nil
+}
+
+func toHZ(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	tables, err := getGB18030Tables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateToHZ{tables: tables}, nil
+}
@@ -0,0 +1,75 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var hzTests = []struct {
+	native  string
+	unicode string
+}{
+	{"Hello ~{VPND~} world", "Hello 中文 world"},
+	{"~{VPND~}~~", "中文~"},
+	{"a~~b", "a~b"},
+	{"~{Dc:C~}\n~{J@=g~}", "你好\n世界"},
+}
+
+func TestHZ(t *testing.T) {
+	for _, test := range hzTests {
+		if out := decodeString(t, "hz-gb-2312", test.native); out != test.unicode {
+			t.Errorf("decoding %q: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "hz-gb-2312", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %q got %q", test.unicode, test.native, out)
+		}
+	}
+}
+
+func TestHZDecode(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		// The example from RFC 1843.
+		"This sentence is in ASCII.\nThe next sentence is in GB.~{<:Ky2;S{#,~}~\n~{NpJ)l6HK!#~}Bye.": "This sentence is in ASCII.\nThe next sentence is in GB.己所不欲，勿施於人。Bye.",
+		"~":    bad,
+		"~xa":  bad + "xa",
+		"~{V":  bad,
+		"\xd6": bad,
+		// A truncated pair before "~}" or a newline.
+		"~{<~}abcd":   bad + "abcd",
+		"~{<\n:~}":    bad + "\n" + bad,
+		"~{VP<~}abcd": "中" + bad + "abcd",
+	} {
+		if got := decodeString(t, "hz-gb-2312", in); got != out {
+			t.Errorf("decoding %q: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestHZSplit(t *testing.T) {
+	for in, want := range map[string]string{
+		"a~{VPND~}b": "a中文b",
+		"a~{VP<~}b":  "a中" + string(utf8.RuneError) + "b",
+	} {
+		for split := 1; split < len(in); split++ {
+			tr, err := charset.TranslatorFrom("hz-gb-2312")
+			if err != nil {
+				t.Fatalf("cannot make translator: %v", err)
+			}
+			n, cdata, err := tr.Translate([]byte(in[:split]), false)
+			if err != nil {
+				t.Fatalf("split %d: %v", split, err)
+			}
+			out := string(cdata)
+			_, cdata, err = tr.Translate([]byte(in[n:]), true)
+			if err != nil {
+				t.Fatalf("split %d: %v", split, err)
+			}
+			if out += string(cdata); out != want {
+				t.Errorf("%q split %d: expected %q got %q", in, split, want, out)
+			}
+		}
+	}
+}
@@ -109,6 +109,12 @@ func (iconvFactory) Info(name string) *charset.Charset {
 	}
 }
 
+// Reset returns the conversion descriptor to its initial shift state.
+func (p *iconvTranslator) Reset() {
+	C.iconv(p.cd, nil, nil, nil, nil)
+	p.scratch = p.scratch[:0]
+}
+
 func (p *iconvTranslator) Translate(data []byte, eof bool) (rn int, rd []byte, rerr error) {
 	n := 0
 	p.scratch = p.scratch[:0]
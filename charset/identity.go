@@ -0,0 +1,27 @@
+package charset
+
+func init() {
+	registerTransform("identity", newIdentity, newIdentity)
+}
+
+// translateIdentity passes its input through unchanged,
+// in either direction.
+type translateIdentity struct {
+	scratch []byte
+}
+
+func (p *translateIdentity) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateIdentity) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = append(p.scratch[:0], data...)
+	return len(data), p.scratch, nil
+}
+
+func newIdentity(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	return new(translateIdentity), nil
+}
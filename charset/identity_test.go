@@ -0,0 +1,29 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+func TestIdentity(t *testing.T) {
+	var in []byte
+	for i := 0; i < 256; i++ {
+		in = append(in, byte(i))
+	}
+	in = append(in, "\xef\xbb\xbf아\xed\xa0\x80"...)
+	for _, name := range []string{"identity", "binary"} {
+		for _, newTr := range []func(string) (charset.Translator, error){charset.TranslatorFrom, charset.TranslatorTo} {
+			tr, err := newTr(name)
+			if err != nil {
+				t.Fatalf("%s: cannot make translator: %v", name, err)
+			}
+			for _, eof := range []bool{false, true} {
+				n, cdata, err := tr.Translate(in, eof)
+				if err != nil || n != len(in) || string(cdata) != string(in) {
+					t.Errorf("%s: eof %v: got %d, %x, %v", name, eof, n, cdata, err)
+				}
+			}
+		}
+	}
+}
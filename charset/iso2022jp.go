@@ -0,0 +1,287 @@
+package charset
+
+import (
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("iso2022jp", fromISO2022JP, toISO2022JP)
+}
+
+// encoding details
+//
+// ISO-2022-JP (RFC 1468) is a 7-bit encoding which switches
+// between character sets with escape sequences:
+//
+// ESC ( B	ASCII
+// ESC ( J	JIS X 0201 Roman
+// ESC ( I	JIS X 0201 katakana (not in RFC 1468 but widely used)
+// ESC $ @	JIS X 0208-1978
+// ESC $ B	JIS X 0208-1983
+//
+// JIS X 0208 characters are two bytes, each in the range 21..7e.
+// The text starts in ASCII, and must be back in ASCII
+// at the end of each line and at the end of the text.
+
+const (
+	jisASCII = iota
+	jisRoman
+	jisKatakana
+	jis0208
+)
+
+type translateFromISO2022JP struct {
+	tables  *jisTables
+	mode    int
+	scratch []byte
+}
+
+func (p *translateFromISO2022JP) Reset() {
+	p.mode = jisASCII
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromISO2022JP) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		b := data[0]
+		if b == 0x1b {
+			if len(data) < 3 && !eof {
+				break
+			}
+			if mode, ok := jisEscape(data); ok {
+				p.mode = mode
+				data = data[3:]
+				n += 3
+				continue
+			}
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+			data = data[1:]
+			n++
+			continue
+		}
+		if b >= 0x80 {
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+			data = data[1:]
+			n++
+			continue
+		}
+		// Control characters and space are passed
+		// through unchanged in any mode.
+		r, size := rune(b), 1
+		switch {
+		case b <= 0x20 || b == 0x7f:
+		case p.mode == jisRoman:
+			r = jisRomanRune(b)
+		case p.mode == jisKatakana:
+			if b <= 0x5f {
+				r = 0xff61 + rune(b-0x21)
+			} else {
+				r = utf8.RuneError
+			}
+		case p.mode == jis0208:
+			if len(data) < 2 {
+				if !eof {
+					return n, p.scratch, nil
+				}
+				r = utf8.RuneError
+				break
+			}
+			if c := data[1]; c < 0x21 || c >= 0x7f {
+				// A truncated pair. The byte after it, such as
+				// ESC or a newline, is decoded afresh.
+				r = utf8.RuneError
+				break
+			}
+			r, size = p.tables.jis0208(b, data[1]), 2
+		}
+		p.scratch = appendRune(p.scratch, r)
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+// jisEscape returns the mode selected by the
+// escape sequence at the start of data.
+func jisEscape(data []byte) (int, bool) {
+	if len(data) < 3 {
+		return 0, false
+	}
+	switch string(data[1:3]) {
+	case "(B":
+		return jisASCII, true
+	case "(J":
+		return jisRoman, true
+	case "(I":
+		return jisKatakana, true
+	case "$@", "$B":
+		return jis0208, true
+	}
+	return 0, false
+}
+
+// jisRomanRune returns the rune for byte b in JIS X 0201 Roman,
+// which differs from ASCII only at 0x5c and 0x7e.
+func jisRomanRune(b byte) rune {
+	switch b {
+	case '\\':
+		return '¥'
+	case '~':
+		return '‾'
+	}
+	return rune(b)
+}
+
+// jis0208 returns the rune for the JIS X 0208 character
+// with the given row and cell bytes, converting
+// it to shift-jis to use the shift-jis tables.
+func (t *jisTables) jis0208(j1, j2 byte) rune {
+	if j1 < 0x21 || j1 > 0x7e || j2 < 0x21 || j2 > 0x7e {
+		return utf8.RuneError
+	}
+	s1 := int(j1+1)/2 + 0x70
+	if j1 > 0x5e {
+		s1 += 0x40
+	}
+	s2 := int(j2) + 0x7e
+	if j1&1 != 0 {
+		s2 = int(j2) + 0x1f
+		if j2 >= 0x60 {
+			s2++
+		}
+	}
+	if t.page0[s1] != -1 {
+		return utf8.RuneError
+	}
+	return t.cp932[t.dbcsoff[s1]*cp932PageSize+s2-cp932Char0]
+}
+
+// jisEscapes holds the escape sequence used
+// by the encoder to select each mode.
+var jisEscapes = [...]string{
+	jisASCII:    "\x1b(B",
+	jisRoman:    "\x1b(J",
+	jisKatakana: "\x1b(I",
+	jis0208:     "\x1b$B",
+}
+
+type translateToISO2022JP struct {
+	rune2code   map[rune]uint16 // shift-jis codes
+	mode        int
+	replacement []byte
+	strict      bool
+	scratch     []byte
+}
+
+// setMode switches the output to the given mode.
+func (p *translateToISO2022JP) setMode(mode int) {
+	if p.mode != mode {
+		p.scratch = append(p.scratch, jisEscapes[mode]...)
+		p.mode = mode
+	}
+}
+
+func (p *translateToISO2022JP) Reset() {
+	p.mode = jisASCII
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToISO2022JP) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.setMode(jisASCII)
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		code, ok := p.rune2code[r]
+		switch {
+		case r == '¥' || r == '‾':
+			p.setMode(jisRoman)
+			if r == '¥' {
+				p.scratch = append(p.scratch, '\\')
+			} else {
+				p.scratch = append(p.scratch, '~')
+			}
+		case ok && code >= 0x100:
+			p.setMode(jis0208)
+			j1, j2 := sjisToJIS(code)
+			p.scratch = append(p.scratch, j1, j2)
+		case p.strict:
+			// Half-width katakana are not in RFC 1468,
+			// so they are unmappable too.
+			return n, p.scratch, strictError(n, r, size)
+		default:
+			p.setMode(jisASCII)
+			p.scratch = append(p.scratch, p.replacement...)
+		}
+		data = data[size:]
+		n += size
+	}
+	if eof {
+		p.setMode(jisASCII)
+	}
+	return n, p.scratch, nil
+}
+
+// sjisToJIS returns the JIS X 0208 row and cell
+// bytes for a double-byte shift-jis code.
+func sjisToJIS(code uint16) (j1, j2 byte) {
+	s1, s2 := byte(code>>8), byte(code)
+	if s1 >= 0xe0 {
+		s1 -= 0x40
+	}
+	j1 = (s1-0x70)*2 - 1
+	switch {
+	case s2 >= 0x9f:
+		j1++
+		j2 = s2 - 0x7e
+	case s2 >= 0x80:
+		j2 = s2 - 0x20
+	default:
+		j2 = s2 - 0x1f
+	}
+	return j1, j2
+}
+
+func fromISO2022JP(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	tables, err := getJISTables(true)
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromISO2022JP{tables: tables}, nil
+}
+
+// toISO2022JP accepts the same "replacement", "drop" and "strict"
+// options as toCp949.
+func toISO2022JP(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop", "strict")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	m, err := getJISEncoding(true)
+	if err != nil {
+		return nil, err
+	}
+	return &translateToISO2022JP{
+		rune2code:   m,
+		replacement: repl,
+		strict:      opts.has("strict"),
+	}, nil
+}
@@ -0,0 +1,108 @@
+package charset_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var iso2022jpTests = []struct {
+	in  string
+	out string
+}{
+	{"Hello \x1b$BF|K\\8l$G$9!#\x1b(B ok", "Hello 日本語です。 ok"},
+	{"\x1b(Jab\\~\x1b(Bc\\~", "ab¥‾c\\~"},
+	{"\x1b(I1\x1b(B", "ｱ"},
+	{"\x1b$B$3$l\n\x1b(B", "これ\n"},
+	{"\x1b$x", "�$x"},
+	// A truncated kanji before the ASCII escape or a newline.
+	{"\x1b$B\x30\x1b(Babc", "�abc"},
+	{"\x1b$B0\n0!", "�\n亜"},
+}
+
+func TestISO2022JP(t *testing.T) {
+	for _, test := range iso2022jpTests {
+		if out := decodeString(t, "iso-2022-jp", test.in); out != test.out {
+			t.Errorf("decoding %q: expected %q got %q", test.in, test.out, out)
+		}
+	}
+}
+
+func TestISO2022JPSplit(t *testing.T) {
+	in := "a\x1b$BF|K\\\x1b(Bb"
+	want := "a日本b"
+	for split := 1; split < len(in); split++ {
+		tr, err := charset.TranslatorFrom("iso-2022-jp")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate([]byte(in[:split]), false)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		out := string(cdata)
+		n, cdata, err = tr.Translate([]byte(in[n:]), true)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		out += string(cdata)
+		if out != want {
+			t.Errorf("split %d: expected %q got %q", split, want, out)
+		}
+	}
+}
+
+var iso2022jpEncodeTests = []struct {
+	in  string
+	out string
+}{
+	{"Hello 日本語です。 ok", "Hello \x1b$BF|K\\8l$G$9!#\x1b(B ok"},
+	{"これ\n", "\x1b$B$3$l\x1b(B\n"},
+	{"¥100", "\x1b(J\\\x1b(B100"},
+	{"日本", "\x1b$BF|K\\\x1b(B"},
+	{"aｱb", "a?b"},
+}
+
+func TestISO2022JPEncode(t *testing.T) {
+	for _, test := range iso2022jpEncodeTests {
+		if out := encodeString(t, "iso-2022-jp", test.in); out != test.out {
+			t.Errorf("encoding %q: expected %q got %q", test.in, test.out, out)
+		}
+	}
+}
+
+func TestISO2022JPWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := charset.NewWriter("iso-2022-jp", &buf)
+	if err != nil {
+		t.Fatalf("cannot make writer: %v", err)
+	}
+	for _, s := range []string{"a日", "本"} {
+		if _, err := io.WriteString(w, s); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if want := "a\x1b$BF|K\\\x1b(B"; buf.String() != want {
+		t.Errorf("expected %q got %q", want, buf.String())
+	}
+}
+
+func TestStatelessWriterClose(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := charset.NewWriter("cp949", &buf)
+	if err != nil {
+		t.Fatalf("cannot make writer: %v", err)
+	}
+	io.WriteString(w, "아")
+	if err := w.Close(); err != nil {
+		t.Errorf("close failed: %v", err)
+	}
+	if buf.String() != "\xbe\xc6" {
+		t.Errorf("expected %q got %q", "\xbe\xc6", buf.String())
+	}
+}
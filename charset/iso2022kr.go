@@ -0,0 +1,96 @@
+package charset
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("iso2022kr", fromISO2022KR, nil)
+}
+
+// encoding details
+//
+// ISO-2022-KR (RFC 1557) is a 7-bit encoding of KS C 5601.
+// The designator ESC $ ) C appears once, usually at the
+// start of the text. After it, SO (0e) shifts to KS C 5601,
+// where each pair of bytes in the range 21..7e is a character
+// with the high bit of each byte cleared, and SI (0f) shifts
+// back to ASCII. The characters are translated with the
+// cp949 decoding table.
+
+const (
+	iso2022krSO = 0x0e
+	iso2022krSI = 0x0f
+)
+
+var iso2022krDesignator = []byte("\x1b$)C")
+
+type translateFromISO2022KR struct {
+	pages   *cp949Pages
+	shifted bool
+	scratch []byte
+}
+
+func (p *translateFromISO2022KR) Reset() {
+	p.shifted = false
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromISO2022KR) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		b := data[0]
+		switch {
+		case b == 0x1b:
+			if len(data) < len(iso2022krDesignator) && !eof &&
+				bytes.HasPrefix(iso2022krDesignator, data) {
+				return n, p.scratch, nil
+			}
+			if bytes.HasPrefix(data, iso2022krDesignator) {
+				data = data[len(iso2022krDesignator):]
+				n += len(iso2022krDesignator)
+				continue
+			}
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+		case b == iso2022krSO:
+			p.shifted = true
+		case b == iso2022krSI:
+			p.shifted = false
+		case b >= 0x80:
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+		case !p.shifted || b <= 0x20 || b == 0x7f:
+			p.scratch = append(p.scratch, b)
+		case len(data) < 2:
+			if !eof {
+				return n, p.scratch, nil
+			}
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+		case data[1] < 0x21 || data[1] > 0x7e:
+			// A truncated pair. The byte after it, such as
+			// SI or a newline, is decoded afresh.
+			p.scratch = appendRune(p.scratch, utf8.RuneError)
+		default:
+			r, _ := p.pages.lookup(uint16(b|0x80)<<8 | uint16(data[1]|0x80))
+			p.scratch = appendRune(p.scratch, r)
+			data = data[2:]
+			n += 2
+			continue
+		}
+		data = data[1:]
+		n++
+	}
+	return n, p.scratch, nil
+}
+
+func fromISO2022KR(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	pages, err := getCp949Pages(false)
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromISO2022KR{pages: pages}, nil
+}
@@ -0,0 +1,70 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+// An email body as sent by a Korean mail client.
+const (
+	iso2022krMail = "\x1b$)C\n" +
+		"\x0e>H3gGO<<?d\x0f\n" +
+		"\n" +
+		"\x0e?@4C\x0f \x0eH8@G4B\x0f 3\x0e=C@T4O4Y\x0f.\n" +
+		"Thanks, \x0e1hC6<v\x0f\n"
+	iso2022krMailText = "\n" +
+		"안녕하세요\n" +
+		"\n" +
+		"오늘 회의는 3시입니다.\n" +
+		"Thanks, 김철수\n"
+)
+
+func TestISO2022KR(t *testing.T) {
+	if out := decodeString(t, "iso-2022-kr", iso2022krMail); out != iso2022krMailText {
+		t.Errorf("expected %q got %q", iso2022krMailText, out)
+	}
+}
+
+func TestISO2022KRInvalid(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		"\x1b$)":      bad + "$)",
+		"\x1b(Ba":     bad + "(Ba",
+		"\x0e>":       bad,
+		"\x0e>\x7f":   bad + "\x7f",
+		"\xb0\xa1":    bad + bad,
+		"\x0e>H\x0fa": "안a",
+		// A truncated pair before SI, ESC or a newline.
+		"\x1b$)C\x0e\x30\x0fabcd": bad + "abcd",
+		"\x0e0\n0!":               bad + "\n가",
+		"\x0e0\x1b$)C0!":          bad + "가",
+	} {
+		if got := decodeString(t, "iso-2022-kr", in); got != out {
+			t.Errorf("decoding %q: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestISO2022KRSplit(t *testing.T) {
+	in := []byte(iso2022krMail)
+	for split := 1; split < len(in); split++ {
+		tr, err := charset.TranslatorFrom("iso-2022-kr")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate(in[:split], false)
+		if err != nil {
+			t.Fatalf("split %d: %v", split, err)
+		}
+		out := string(cdata)
+		_, cdata, err = tr.Translate(in[n:], true)
+		if err != nil {
+			t.Fatalf("split %d: %v", split, err)
+		}
+		if out += string(cdata); out != iso2022krMailText {
+			t.Errorf("split %d: expected %q got %q", split, iso2022krMailText, out)
+		}
+	}
+}
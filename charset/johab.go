@@ -0,0 +1,231 @@
+package charset
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("johab", fromJohab, toJohab)
+}
+
+// encoding details
+//
+// 00..7f		ASCII
+// 84..d3 41..ff	Hangul: 1 iiiii mmmmm fffff
+// d8..f9 31..fe	symbols and hanja
+//
+// A Hangul code packs 5-bit values for the initial consonant,
+// medial vowel and final consonant of a syllable. Precomposed
+// syllables are translated algorithmically; all other codes,
+// including lone jamo, are held in johab.dat.
+
+const (
+	johabData  = "johab.dat"
+	hangulBase = 0xac00
+	hangulLast = 0xd7a3
+)
+
+// The 5-bit johab values of the jamo, indexed
+// in the order used by precomposed Unicode syllables.
+var (
+	johabInitial = []uint16{2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	johabMedial  = []uint16{3, 4, 5, 6, 7, 10, 11, 12, 13, 14, 15, 18, 19, 20, 21, 22, 23, 26, 27, 28, 29}
+	johabFinal   = []uint16{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29}
+)
+
+func johabIndex(values []uint16, v uint16) int {
+	for i, x := range values {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// johabSyllable returns the precomposed Hangul
+// syllable for code c, if there is one.
+func johabSyllable(c uint16) (rune, bool) {
+	if c&0x8000 == 0 {
+		return 0, false
+	}
+	i := johabIndex(johabInitial, c>>10&0x1f)
+	m := johabIndex(johabMedial, c>>5&0x1f)
+	f := johabIndex(johabFinal, c&0x1f)
+	if i < 0 || m < 0 || f < 0 {
+		return 0, false
+	}
+	return hangulBase + rune((i*21+m)*28+f), true
+}
+
+type johabTables struct {
+	byNative  cp949Table
+	byUnicode cp949Table
+}
+
+type translateFromJohab struct {
+	tables      *johabTables
+	replacement []byte // substituted for untranslatable bytes
+	scratch     []byte
+}
+
+func (p *translateFromJohab) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromJohab) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < 0x80 {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if len(data) < 2 {
+			if !eof {
+				break
+			}
+			p.scratch = append(p.scratch, p.replacement...)
+			data = data[1:]
+			n++
+			continue
+		}
+		if t := data[1]; t < 0x31 || t > 0xfe {
+			// A byte that cannot trail the lead byte,
+			// such as a newline, is decoded afresh.
+			p.scratch = append(p.scratch, p.replacement...)
+			data = data[1:]
+			n++
+			continue
+		}
+		c := uint16(data[0])<<8 | uint16(data[1])
+		r, ok := johabSyllable(c)
+		if !ok {
+			t := p.tables.byNative
+			i := sort.Search(len(t), func(i int) bool {
+				return c <= t[i].native
+			})
+			r = utf8.RuneError
+			if i < len(t) && t[i].native == c {
+				r = t[i].unicode
+			}
+		}
+		p.scratch = appendDecoded(p.scratch, r, p.replacement)
+		data = data[2:]
+		n += 2
+	}
+	return n, p.scratch, nil
+}
+
+type translateToJohab struct {
+	tables      *johabTables
+	replacement []byte
+	strict      bool
+	scratch     []byte
+}
+
+func (p *translateToJohab) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToJohab) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		if r >= hangulBase && r <= hangulLast {
+			s := int(r - hangulBase)
+			c := 0x8000 | johabInitial[s/(21*28)]<<10 | johabMedial[s/28%21]<<5 | johabFinal[s%28]
+			p.scratch = append(p.scratch, byte(c>>8), byte(c))
+		} else {
+			t := p.tables.byUnicode
+			i := sort.Search(len(t), func(i int) bool {
+				return r <= t[i].unicode
+			})
+			switch {
+			case i < len(t) && t[i].unicode == r:
+				p.scratch = append(p.scratch, byte(t[i].native>>8), byte(t[i].native))
+			case p.strict:
+				return n, p.scratch, strictError(n, r, size)
+			default:
+				p.scratch = append(p.scratch, p.replacement...)
+			}
+		}
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+type johabKey bool
+
+func getJohabTables() (*johabTables, error) {
+	tables, err := cache(johabKey(true), func() (interface{}, error) {
+		byNative, err := loadCodeTable(johabData)
+		if err != nil {
+			return nil, err
+		}
+		if !sort.IsSorted(cp949TableSortByNative{byNative}) {
+			panic("johab.dat is not sorted by native code!")
+		}
+		byUnicode := make(cp949Table, len(byNative))
+		copy(byUnicode, byNative)
+		sort.Sort(cp949TableSortByUnicode{byUnicode})
+		return &johabTables{byNative: byNative, byUnicode: byUnicode}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables.(*johabTables), nil
+}
+
+// fromJohab accepts the same "replacement" and "drop"
+// options as fromCp949.
+func fromJohab(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement(errorBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getJohabTables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromJohab{tables: tables, replacement: repl}, nil
+}
+
+// toJohab accepts the same "replacement", "drop" and "strict"
+// options as toCp949.
+func toJohab(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "replacement", "drop", "strict")
+	if err != nil {
+		return nil, err
+	}
+	repl, err := opts.replacement([]byte{'?'}, true)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getJohabTables()
+	if err != nil {
+		return nil, err
+	}
+	return &translateToJohab{
+		tables:      tables,
+		replacement: repl,
+		strict:      opts.has("strict"),
+	}, nil
+}
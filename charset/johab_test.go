@@ -0,0 +1,51 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+var johabTests = []struct {
+	native  string
+	unicode string
+}{
+	{"abc", "abc"},
+	{"\xd0\x65\x8b\x69", "한글"},
+	{"\x88\x61\x88\x62\xd3\xbd", "가각힣"},
+	{"\x88\x41", "ㄱ"},
+	{"\xf1\x79", "一"},
+	{"\xd9\x68", "※"},
+}
+
+func TestJohab(t *testing.T) {
+	for _, test := range johabTests {
+		if out := decodeString(t, "johab", test.native); out != test.unicode {
+			t.Errorf("decoding %x: expected %q got %q", test.native, test.unicode, out)
+		}
+		if out := encodeString(t, "cp1361", test.unicode); out != test.native {
+			t.Errorf("encoding %q: expected %x got %x", test.unicode, test.native, out)
+		}
+	}
+}
+
+// TestJohabSyllables checks that every precomposed
+// Hangul syllable round trips through johab.
+func TestJohabSyllables(t *testing.T) {
+	var buf []byte
+	for r := rune(0xac00); r <= 0xd7a3; r++ {
+		buf = append(buf, string(r)...)
+	}
+	in := string(buf)
+	native := encodeString(t, "johab", in)
+	if len(native) != 2*(0xd7a3-0xac00+1) {
+		t.Fatalf("unexpected encoded length %d", len(native))
+	}
+	if u := decodeString(t, "johab", native); u != in {
+		for i, r := range u {
+			if r == utf8.RuneError {
+				t.Fatalf("syllable at %d did not round trip", i)
+			}
+		}
+		t.Fatalf("syllables did not round trip")
+	}
+}
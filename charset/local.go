@@ -4,14 +4,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 )
 
+// localCharsets holds all the local character sets,
+// indexed by the lookupKey of each name and alias.
+// localMutex guards it once it has been read.
 var (
 	readLocalCharsetsOnce sync.Once
+	localMutex            sync.RWMutex
 	localCharsets         = make(map[string]*localCharset)
 )
 
+// lookupKey returns the key used to find a character set by name.
+// Case, hyphens and underscores are ignored, so that
+// "Shift_JIS", "shift-jis" and "shiftjis" are the same.
+func lookupKey(name string) string {
+	return strings.Map(func(c rune) rune {
+		if c == '-' || c == '_' {
+			return -1
+		}
+		return normalizedChar(c)
+	}, name)
+}
+
 type localCharset struct {
 	Charset
 	arg string
@@ -21,52 +38,83 @@ type localCharset struct {
 // A class of character sets.
 // Each class can be instantiated with an argument specified in the config file.
 // Many character sets can use a single class.
+// Any options given after a '?' in the character set name are
+// appended to the argument (see joinArg) and should be
+// extracted by the class with splitArg.
 type class struct {
-	from, to func(arg string) (Translator, error)
+	from, to  func(arg string) (Translator, error)
+	transform bool // UTF-8 in and out, rather than a character set
 }
 
 // The set of classes, indexed by class name.
 var classes = make(map[string]*class)
 
+// registerClass registers a class of character sets. Either from or to
+// may be nil if the class cannot translate in that direction;
+// NoFrom or NoTo is then set in the Info of its character sets,
+// and asking for a translator in that direction is an error.
 func registerClass(charset string, from, to func(arg string) (Translator, error)) {
-	classes[charset] = &class{from, to}
+	classes[charset] = &class{from: from, to: to}
+}
+
+// registerTransform registers a class like registerClass, for
+// translators such as newlines that transform UTF-8 text rather
+// than translate it to and from a character set.
+func registerTransform(name string, from, to func(arg string) (Translator, error)) {
+	classes[name] = &class{from: from, to: to, transform: true}
+}
+
+// isEncoding reports whether the named local character set is
+// an encoding of its own: it is neither a transform nor added by
+// RegisterCharset, whose translators could be either.
+func isEncoding(name string) bool {
+	cs := localFactory{}.lookup(name)
+	return cs != nil && !cs.transform
 }
 
 type localFactory struct{}
 
-func (f localFactory) TranslatorFrom(name string) (Translator, error) {
+// lookup returns the local character set with the given name or alias.
+func (f localFactory) lookup(name string) *localCharset {
 	f.init()
-	name = NormalizedName(name)
-	cs := localCharsets[name]
+	localMutex.RLock()
+	defer localMutex.RUnlock()
+	return localCharsets[lookupKey(name)]
+}
+
+func (f localFactory) TranslatorFrom(name string) (Translator, error) {
+	name, opts := splitName(NormalizedName(name))
+	cs := f.lookup(name)
 	if cs == nil {
 		return nil, fmt.Errorf("character set %q not found", name)
 	}
 	if cs.from == nil {
-		return nil, fmt.Errorf("cannot translate from %q", name)
+		return nil, fmt.Errorf("charset %q does not support decoding", name)
 	}
-	return cs.from(cs.arg)
+	return cs.from(joinArg(cs.arg, opts))
 }
 
 func (f localFactory) TranslatorTo(name string) (Translator, error) {
-	f.init()
-	name = NormalizedName(name)
-	cs := localCharsets[name]
+	name, opts := splitName(NormalizedName(name))
+	cs := f.lookup(name)
 	if cs == nil {
 		return nil, fmt.Errorf("character set %q not found", name)
 	}
 	if cs.to == nil {
-		return nil, fmt.Errorf("cannot translate to %q", name)
+		return nil, fmt.Errorf("charset %q does not support encoding", name)
 	}
-	return cs.to(cs.arg)
+	return cs.to(joinArg(cs.arg, opts))
 }
 
 func (f localFactory) Names() []string {
 	f.init()
+	localMutex.RLock()
+	defer localMutex.RUnlock()
 	var names []string
 	for name, cs := range localCharsets {
 		// add names only for non-aliases.
-		if localCharsets[cs.Name] == cs {
-			names = append(names, name)
+		if name == lookupKey(cs.Name) {
+			names = append(names, cs.Name)
 		}
 	}
 	return names
@@ -74,12 +122,16 @@ func (f localFactory) Names() []string {
 
 func (f localFactory) Info(name string) *Charset {
 	f.init()
-	lcs := localCharsets[NormalizedName(name)]
+	name, _ = splitName(NormalizedName(name))
+	localMutex.RLock()
+	defer localMutex.RUnlock()
+	lcs := localCharsets[lookupKey(name)]
 	if lcs == nil {
 		return nil
 	}
 	// copy the charset info so that callers can't mess with it.
 	cs := lcs.Charset
+	cs.Aliases = append([]string(nil), cs.Aliases...)
 	return &cs
 }
 
@@ -87,6 +139,80 @@ func (f localFactory) init() {
 	readLocalCharsetsOnce.Do(readLocalCharsets)
 }
 
+// RegisterAlias makes alias another name for the character set
+// with the given canonical name or alias, so that it can be used
+// anywhere that name can. It returns an error if there is no
+// such character set, or if alias already names a different one.
+func RegisterAlias(alias, canonical string) error {
+	f := localFactory{}
+	f.init()
+	if strings.ContainsRune(alias, '?') {
+		return fmt.Errorf("charset: alias %q contains options", alias)
+	}
+	localMutex.Lock()
+	defer localMutex.Unlock()
+	cs := localCharsets[lookupKey(NormalizedName(canonical))]
+	if cs == nil {
+		return fmt.Errorf("charset: character set %q not found", canonical)
+	}
+	key := lookupKey(NormalizedName(alias))
+	if other := localCharsets[key]; other != nil {
+		if other == cs {
+			return nil
+		}
+		return fmt.Errorf("charset: %q already names %q", alias, other.Name)
+	}
+	localCharsets[key] = cs
+	cs.Aliases = append(cs.Aliases, alias)
+	return nil
+}
+
+// RegisterCharset adds a character set with the given name,
+// so that NewReader, NewWriter, Names and the rest know it.
+// It is simpler than implementing a Factory for a single
+// character set. Either from or to may be nil, as for
+// registerClass. Each is called with any options given after
+// a '?' in the name by which the character set is asked for,
+// without the '?', or the empty string if there are none.
+// RegisterCharset panics if the name is already taken
+// or from and to are both nil.
+func RegisterCharset(name string, from, to func(arg string) (Translator, error)) {
+	if from == nil && to == nil {
+		panic("charset: RegisterCharset of " + name + " with no translators")
+	}
+	f := localFactory{}
+	f.init()
+	name = NormalizedName(name)
+	localMutex.Lock()
+	defer localMutex.Unlock()
+	key := lookupKey(name)
+	if localCharsets[key] != nil {
+		panic("charset: RegisterCharset called twice for " + name)
+	}
+	localCharsets[key] = &localCharset{
+		Charset: Charset{
+			Name:   name,
+			NoFrom: from == nil,
+			NoTo:   to == nil,
+		},
+		// The translators could do anything, so they are
+		// treated as a transform.
+		class: &class{from: withoutQuery(from), to: withoutQuery(to), transform: true},
+	}
+}
+
+// withoutQuery adapts f to be given the options in
+// a class argument without the leading '?' that joinArg
+// puts before them when the argument is empty.
+func withoutQuery(f func(arg string) (Translator, error)) func(arg string) (Translator, error) {
+	if f == nil {
+		return nil
+	}
+	return func(arg string) (Translator, error) {
+		return f(strings.TrimPrefix(arg, "?"))
+	}
+}
+
 // charsetEntry is the data structure for one entry in the JSON config file.
 // If Alias is non-empty, it should be the canonical name of another
 // character set; otherwise Class should be the name
@@ -104,7 +230,7 @@ type charsetEntry struct {
 func readLocalCharsets() {
 	csdata, err := readFile("charsets.json")
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "charset: cannot open \"charsets.json\": %v\n", err)
+		fmt.Fprintln(os.Stderr, err)
 		return
 	}
 
@@ -118,10 +244,6 @@ func readLocalCharsets() {
 		if class == nil {
 			continue
 		}
-		name = NormalizedName(name)
-		for i, a := range e.Aliases {
-			e.Aliases[i] = NormalizedName(a)
-		}
 		cs := &localCharset{
 			Charset: Charset{
 				Name:    name,
@@ -133,30 +255,49 @@ func readLocalCharsets() {
 			arg:   e.Arg,
 			class: class,
 		}
-		localCharsets[cs.Name] = cs
+		localCharsets[lookupKey(cs.Name)] = cs
 		for _, a := range cs.Aliases {
-			localCharsets[a] = cs
+			localCharsets[lookupKey(a)] = cs
 		}
 	}
 }
 
 // A general cache store that local character set translators
-// can use for persistent storage of data.
+// can use for persistent storage of data. Each key has its own
+// entry, so that a slow load for one key does not hold up others.
 var (
 	cacheMutex sync.Mutex
-	cacheStore = make(map[interface{}]interface{})
+	cacheStore = make(map[interface{}]*cacheEntry)
 )
 
+type cacheEntry struct {
+	mu sync.Mutex
+	x  interface{}
+}
+
+// cache returns the value stored for key, calling f to make
+// it if there is none. Concurrent callers with the same key
+// wait for a single call of f and share its result. If f fails,
+// nothing is stored and a later call will try again.
+// f may itself call cache with a different key.
 func cache(key interface{}, f func() (interface{}, error)) (interface{}, error) {
 	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
-	if x := cacheStore[key]; x != nil {
-		return x, nil
+	e := cacheStore[key]
+	if e == nil {
+		e = new(cacheEntry)
+		cacheStore[key] = e
+	}
+	cacheMutex.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.x != nil {
+		return e.x, nil
 	}
 	x, err := f()
 	if err != nil {
 		return nil, err
 	}
-	cacheStore[key] = x
-	return x, err
+	e.x = x
+	return x, nil
 }
@@ -0,0 +1,69 @@
+package charset
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFromOnly(t *testing.T) {
+	localFactory{}.init()
+	registerClass("test-from-only", toUTF8, nil)
+	cs := &localCharset{
+		Charset: Charset{Name: "x-test-from-only", NoTo: true},
+		class:   classes["test-from-only"],
+	}
+	localCharsets[lookupKey(cs.Name)] = cs
+	defer delete(localCharsets, lookupKey(cs.Name))
+
+	if info := Info("x-test-from-only"); info == nil || info.NoFrom || !info.NoTo {
+		t.Fatalf("expected from-only info, got %+v", info)
+	}
+	if _, err := TranslatorFrom("x-test-from-only"); err != nil {
+		t.Errorf("cannot make translator from: %v", err)
+	}
+	_, err := TranslatorTo("x-test-from-only")
+	if err == nil || !strings.Contains(err.Error(), "does not support encoding") {
+		t.Errorf("expected encoding error, got %v", err)
+	}
+	if _, err := NewWriter("x-test-from-only", nil); err == nil {
+		t.Errorf("expected error making writer")
+	}
+}
+
+func TestRegisterAlias(t *testing.T) {
+	cs := localFactory{}.lookup("windows-949")
+	aliases := cs.Aliases
+	defer func() {
+		localMutex.Lock()
+		delete(localCharsets, lookupKey("x-test-korean"))
+		cs.Aliases = aliases
+		localMutex.Unlock()
+	}()
+
+	if err := RegisterAlias("X-Test_Korean", "cp949"); err != nil {
+		t.Fatalf("cannot register alias: %v", err)
+	}
+	for _, name := range []string{"windows-949", "x-test-korean"} {
+		r, err := NewReader(name, strings.NewReader("\xbe\xc6"))
+		if err != nil {
+			t.Fatalf("%s: cannot make reader: %v", name, err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil || string(out) != "아" {
+			t.Errorf("%s: expected %q, got %q, %v", name, "아", out, err)
+		}
+	}
+	if info := Info("x-test-korean?strict"); info == nil || info.Name != "windows-949" {
+		t.Errorf("expected windows-949, got %+v", info)
+	}
+	if err := RegisterAlias("x-test-korean", "windows-949"); err != nil {
+		t.Errorf("registering again: %v", err)
+	}
+	if err := RegisterAlias("x-test-korean", "latin1"); err == nil {
+		t.Errorf("expected error for alias of another character set")
+	}
+	if err := RegisterAlias("x-test-other", "no-such-charset"); err == nil {
+		t.Errorf("expected error for unknown character set")
+	}
+}
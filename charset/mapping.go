@@ -0,0 +1,82 @@
+package charset
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// mappingLister is implemented by from-translators
+// that decode from a table of codes.
+type mappingLister interface {
+	eachMapping(fn func(native []byte, r rune))
+}
+
+// EachMapping calls fn for every code in the named character set
+// and the rune it decodes to, in order of native code.
+// Double-byte codes are given as two bytes, lead byte first.
+// The native slice is only valid during the call to fn.
+// Character sets that do not decode from a table, such as
+// utf-8 or iso-2022-kr, cause an error.
+func EachMapping(name string, fn func(native []byte, r rune)) error {
+	tr, err := TranslatorFrom(name)
+	if err != nil {
+		return err
+	}
+	ml, ok := tr.(mappingLister)
+	if !ok {
+		return fmt.Errorf("charset: cannot list the mappings of %q", name)
+	}
+	ml.eachMapping(fn)
+	return nil
+}
+
+// eachASCIIMapping calls fn for the single-byte codes
+// below 0x80, which stand for themselves.
+func eachASCIIMapping(fn func(native []byte, r rune)) {
+	var buf [1]byte
+	for b := 0; b < utf8.RuneSelf; b++ {
+		buf[0] = byte(b)
+		fn(buf[:], rune(b))
+	}
+}
+
+// eachPageMapping calls fn for the double-byte codes
+// in pages that are accepted by keep.
+func eachPageMapping(pages *cp949Pages, keep func(code uint16) bool, fn func(native []byte, r rune)) {
+	var buf [2]byte
+	for lead, page := range pages {
+		for trail, r := range page {
+			if r != 0 && keep(uint16(lead)<<8|uint16(trail)) {
+				buf[0], buf[1] = byte(lead), byte(trail)
+				fn(buf[:], r)
+			}
+		}
+	}
+}
+
+func (p *translateFromCp949) eachMapping(fn func(native []byte, r rune)) {
+	eachASCIIMapping(fn)
+	eachPageMapping(p.pages, func(code uint16) bool {
+		return !p.ksc || isKSX1001(code)
+	}, fn)
+}
+
+func (p *translateFromGBK) eachMapping(fn func(native []byte, r rune)) {
+	eachASCIIMapping(fn)
+	if !p.gb2312 {
+		fn([]byte{0x80}, '€')
+	}
+	eachPageMapping(p.tables.pages, func(code uint16) bool {
+		return !p.gb2312 || isGB2312(code)
+	}, fn)
+}
+
+func (p *translateFromCodePage) eachMapping(fn func(native []byte, r rune)) {
+	var buf [1]byte
+	for b, r := range p.byte2rune {
+		if r != utf8.RuneError {
+			buf[0] = byte(b)
+			fn(buf[:], r)
+		}
+	}
+}
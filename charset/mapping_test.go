@@ -0,0 +1,84 @@
+package charset_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var eachMappingTests = []struct {
+	charset string
+	count   int
+	native  string
+	unicode rune
+}{
+	{"cp949", 128 + 17049, "\xb0\xa1", '가'},
+	{"cp949", 128 + 17049, "\x81\x41", '갂'},
+	{"cp949", 128 + 17049, "a", 'a'},
+	{"gbk", 128 + 1 + 21791, "\x80", '€'},
+	{"gbk", 128 + 1 + 21791, "\xd6\xd0", '中'},
+	{"latin1", 256, "\xe9", 'é'},
+	{"windows-1252", 256 - 5, "\x80", '€'},
+}
+
+func TestEachMapping(t *testing.T) {
+	for _, test := range eachMappingTests {
+		var prev []byte
+		count, found := 0, false
+		err := charset.EachMapping(test.charset, func(native []byte, r rune) {
+			if prev != nil && bytes.Compare(prev, native) >= 0 {
+				t.Errorf("%s: %x comes after %x", test.charset, native, prev)
+			}
+			prev = append(prev[:0], native...)
+			if string(native) == test.native {
+				found = r == test.unicode
+			}
+			count++
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", test.charset, err)
+		}
+		if count != test.count {
+			t.Errorf("%s: expected %d mappings, got %d", test.charset, test.count, count)
+		}
+		if !found {
+			t.Errorf("%s: %x does not map to %q", test.charset, test.native, test.unicode)
+		}
+	}
+	if err := charset.EachMapping("utf-8", func([]byte, rune) {}); err == nil {
+		t.Errorf("utf-8: expected error")
+	}
+}
+
+// TestRoundTrip checks that every character of cp949
+// survives being encoded and decoded.
+func TestRoundTrip(t *testing.T) {
+	failed := 0
+	err := charset.EachMapping("cp949", func(native []byte, r rune) {
+		if !charset.RoundTrip("cp949", r) {
+			failed++
+			t.Errorf("%x: %q does not survive a round trip", native, r)
+		}
+	})
+	if err != nil {
+		t.Fatalf("cannot list mappings: %v", err)
+	}
+	if failed > 0 {
+		t.Errorf("%d characters failed", failed)
+	}
+	for _, test := range []struct {
+		charset string
+		r       rune
+		ok      bool
+	}{
+		{"cp949", '☃', false},
+		{"latin1", 'é', true},
+		{"latin1", '€', false},
+		{"no-such-charset", 'a', false},
+	} {
+		if ok := charset.RoundTrip(test.charset, test.r); ok != test.ok {
+			t.Errorf("%s: %q: expected %v got %v", test.charset, test.r, test.ok, ok)
+		}
+	}
+}
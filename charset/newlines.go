@@ -0,0 +1,69 @@
+package charset
+
+import (
+	"fmt"
+)
+
+func init() {
+	registerTransform("newlines", newNewlines, newNewlines)
+}
+
+// translateNewlines translates UTF-8 (or ASCII-compatible) text
+// with line endings of any kind, CR, LF or CRLF, to text with
+// line endings all of one kind.
+type translateNewlines struct {
+	newline string
+	scratch []byte
+}
+
+func (p *translateNewlines) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateNewlines) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for n < len(data) {
+		switch b := data[n]; b {
+		case '\r':
+			if n+1 == len(data) && !eof {
+				// An LF may follow in the next call.
+				return n, p.scratch, nil
+			}
+			p.scratch = append(p.scratch, p.newline...)
+			n++
+			if n < len(data) && data[n] == '\n' {
+				n++
+			}
+		case '\n':
+			p.scratch = append(p.scratch, p.newline...)
+			n++
+		default:
+			p.scratch = append(p.scratch, b)
+			n++
+		}
+	}
+	return n, p.scratch, nil
+}
+
+// factory to create translateNewlines. It translates the
+// same way in both directions, so that it can end a chain
+// of translators. The "to" option gives the line ending:
+// "lf" (the default), "crlf" or "cr".
+func newNewlines(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "to")
+	if err != nil {
+		return nil, err
+	}
+	newline := "\n"
+	switch to := opts["to"]; to {
+	case "", "lf":
+	case "crlf":
+		newline = "\r\n"
+	case "cr":
+		newline = "\r"
+	default:
+		return nil, fmt.Errorf("charset: unknown line ending %q", to)
+	}
+	return &translateNewlines{newline: newline}, nil
+}
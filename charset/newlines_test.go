@@ -0,0 +1,55 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var newlinesTests = []struct {
+	name string
+	in   string
+	out  string
+}{
+	{"newlines", "a\r\nb\rc\nd", "a\nb\nc\nd"},
+	{"newlines?to=lf", "a\r\r\nb\n\r", "a\n\nb\n\n"},
+	{"newlines?to=crlf", "a\r\nb\rc\nd\r", "a\r\nb\r\nc\r\nd\r\n"},
+	{"newlines?to=cr", "a\r\nb\n", "a\rb\r"},
+}
+
+func TestNewlines(t *testing.T) {
+	for _, test := range newlinesTests {
+		tr, err := charset.TranslatorFrom(test.name)
+		if err != nil {
+			t.Fatalf("%s: cannot make translator: %v", test.name, err)
+		}
+		if out, err := translate(tr, test.in); err != nil || out != test.out {
+			t.Errorf("%s: %q: expected %q got %q, %v", test.name, test.in, test.out, out, err)
+		}
+	}
+	if _, err := charset.TranslatorFrom("newlines?to=lfcr"); err == nil {
+		t.Errorf("expected error for unknown line ending")
+	}
+}
+
+func TestNewlinesSplitCRLF(t *testing.T) {
+	// "아\r\n름" in cp949, with the CRLF split between calls.
+	tr, err := charset.NewTranslatorChain("cp949", "newlines?to=crlf")
+	if err != nil {
+		t.Fatalf("cannot make chain: %v", err)
+	}
+	var out []byte
+	pending := []byte{}
+	for i, chunk := range []string{"\xbe\xc6\r", "\n\xb8\xa7"} {
+		pending = append(pending, chunk...)
+		n, cdata, err := tr.Translate(pending, i == 1)
+		if err != nil {
+			t.Fatalf("translate error: %v", err)
+		}
+		out = append(out, cdata...)
+		pending = pending[n:]
+	}
+	if string(out) != "아\r\n름" {
+		t.Errorf("expected %q got %q", "아\r\n름", out)
+	}
+}
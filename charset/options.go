@@ -0,0 +1,117 @@
+package charset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// options holds the options given after a '?' in a character
+// set name, for example "cp949?replacement=0x1a".
+// Options are separated by '&'; an option without
+// a value is stored with an empty value.
+type options map[string]string
+
+// splitName splits a character set name into the name
+// itself and any options following a '?'.
+func splitName(name string) (string, string) {
+	if i := strings.IndexByte(name, '?'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// joinArg appends the options from a character set name to
+// the argument from the config file, to be passed to a class.
+func joinArg(arg, opts string) string {
+	if opts == "" {
+		return arg
+	}
+	return arg + "?" + opts
+}
+
+// splitArg splits a class argument into the argument
+// given in the config file and the options appended to it
+// by joinArg. It returns an error if an option is not
+// among those in known.
+func splitArg(arg string, known ...string) (string, options, error) {
+	arg, s := splitName(arg)
+	opts := make(options)
+	if s == "" {
+		return arg, opts, nil
+	}
+	for _, f := range strings.Split(s, "&") {
+		if f == "" {
+			continue
+		}
+		key, val := f, ""
+		if i := strings.IndexByte(f, '='); i >= 0 {
+			key, val = f[:i], f[i+1:]
+		}
+		if !isKnown(key, known) {
+			return "", nil, fmt.Errorf("charset: unknown option %q", key)
+		}
+		opts[key] = val
+	}
+	return arg, opts, nil
+}
+
+func isKnown(key string, known []string) bool {
+	for _, k := range known {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether the named option was given.
+func (o options) has(key string) bool {
+	_, ok := o[key]
+	return ok
+}
+
+// replacement returns the bytes to be substituted for a character
+// that cannot be translated, as given by the "replacement" and
+// "drop" options, or def if neither was given.
+// If encode is true, the replacement is a single byte
+// in the target character set; otherwise it is a rune
+// and the UTF-8 encoding of the rune is returned.
+func (o options) replacement(def []byte, encode bool) ([]byte, error) {
+	if o.has("drop") {
+		return []byte{}, nil
+	}
+	s, ok := o["replacement"]
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("charset: bad replacement %q", s)
+	}
+	if encode {
+		if v > 0xff {
+			return nil, fmt.Errorf("charset: replacement %q is not a single byte", s)
+		}
+		return []byte{byte(v)}, nil
+	}
+	if !utf8.ValidRune(rune(v)) {
+		return nil, fmt.Errorf("charset: replacement %q is not a valid rune", s)
+	}
+	return []byte(string(rune(v))), nil
+}
+
+// maxBytes returns the limit on the total output given
+// by the "maxbytes" option, or 0 if there is no limit.
+func (o options) maxBytes() (int, error) {
+	s, ok := o["maxbytes"]
+	if !ok {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(s, 0, 31)
+	if err != nil || v == 0 {
+		return 0, fmt.Errorf("charset: bad maxbytes %q", s)
+	}
+	return int(v), nil
+}
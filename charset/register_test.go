@@ -0,0 +1,77 @@
+package charset_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+// rot13 is a trivial character set, in which ASCII letters
+// are rotated by 13 places. It is symmetrical, so the
+// same Translator serves in both directions.
+type rot13 struct {
+	scratch []byte
+}
+
+func (p *rot13) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	for _, b := range data {
+		switch {
+		case b >= 'a' && b <= 'z':
+			b = 'a' + (b-'a'+13)%26
+		case b >= 'A' && b <= 'Z':
+			b = 'A' + (b-'A'+13)%26
+		}
+		p.scratch = append(p.scratch, b)
+	}
+	return len(data), p.scratch, nil
+}
+
+func (p *rot13) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func newRot13(arg string) (charset.Translator, error) {
+	if arg != "" {
+		return nil, errors.New("rot13: unknown options " + arg)
+	}
+	return new(rot13), nil
+}
+
+func init() {
+	charset.RegisterCharset("x-rot13", newRot13, newRot13)
+}
+
+func TestRegisterCharset(t *testing.T) {
+	r, err := charset.NewReader("X-ROT13", strings.NewReader("Uryyb, jbeyq!"))
+	if err != nil {
+		t.Fatalf("cannot make reader: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil || string(out) != "Hello, world!" {
+		t.Errorf("expected %q, got %q, %v", "Hello, world!", out, err)
+	}
+	if out, err := charset.EncodeString("x-rot13", "Hello"); err != nil || out != "Uryyb" {
+		t.Errorf("expected %q, got %q, %v", "Uryyb", out, err)
+	}
+	if _, err := charset.TranslatorFrom("x-rot13?strict"); err == nil || !strings.Contains(err.Error(), "strict") {
+		t.Errorf("expected the options to be passed on, got %v", err)
+	}
+	found := false
+	for _, name := range charset.Names() {
+		found = found || name == "x-rot13"
+	}
+	if !found {
+		t.Errorf("x-rot13 not in Names")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic registering cp949 again")
+		}
+	}()
+	charset.RegisterCharset("cp949", newRot13, newRot13)
+}
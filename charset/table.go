@@ -0,0 +1,107 @@
+package charset
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TableEntry is one code in a table of double-byte codes,
+// as written by WriteTable.
+type TableEntry struct {
+	Native  uint16 // The code in the character set.
+	Unicode rune   // The rune it stands for.
+}
+
+// WriteTable writes a table of double-byte codes to w in the
+// format of the .dat files read by the double-byte character sets
+// such as cp949. The file starts with a header giving the number
+// of codes and of chunks. Each chunk holds a starting native code,
+// the length in bytes of the rest of the chunk, and the UTF-8
+// encoded runes for consecutive codes from that one. All numbers
+// are big-endian uint16. The entries need not be in order,
+// but no code may appear twice.
+func WriteTable(w io.Writer, table []TableEntry) error {
+	if len(table) > 0xffff {
+		return errors.New("charset: too many codes for a table")
+	}
+	sorted := make([]TableEntry, len(table))
+	copy(sorted, table)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Native < sorted[j].Native
+	})
+
+	type chunk struct {
+		code  uint16
+		runes []byte
+	}
+	var chunks []chunk
+	for i, e := range sorted {
+		if i > 0 && e.Native == sorted[i-1].Native {
+			return fmt.Errorf("charset: code %#04x appears twice", e.Native)
+		}
+		if !utf8.ValidRune(e.Unicode) {
+			return fmt.Errorf("charset: code %#04x has invalid rune %#x", e.Native, e.Unicode)
+		}
+		last := len(chunks) - 1
+		if last < 0 || e.Native != sorted[i-1].Native+1 || len(chunks[last].runes)+utf8.UTFMax > 0xffff {
+			chunks = append(chunks, chunk{code: e.Native})
+			last++
+		}
+		chunks[last].runes = appendRune(chunks[last].runes, e.Unicode)
+	}
+
+	buf := make([]byte, 4, 4+len(chunks)*4+len(sorted)*3)
+	binary.BigEndian.PutUint16(buf, uint16(len(sorted)))
+	binary.BigEndian.PutUint16(buf[2:], uint16(len(chunks)))
+	for _, c := range chunks {
+		buf = append(buf, byte(c.code>>8), byte(c.code), byte(len(c.runes)>>8), byte(len(c.runes)))
+		buf = append(buf, c.runes...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadMapping reads a mapping file in the format published by
+// the Unicode consortium, such as CP949.TXT, in which each line
+// gives a native code and a rune in hexadecimal separated by
+// white space, as in "0x8141 0xAC02 #HANGUL ...". Comments starting
+// with '#' and blank lines are ignored, as are lines with no
+// rune, which mark undefined codes, and codes below 0x100,
+// which are single bytes. The result can be passed to WriteTable.
+func ReadMapping(r io.Reader) ([]TableEntry, error) {
+	var table []TableEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		native, err := strconv.ParseUint(fields[0], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("charset: line %d: bad code %q", line, fields[0])
+		}
+		if len(fields) < 2 || native < 0x100 {
+			continue
+		}
+		u, err := strconv.ParseUint(fields[1], 0, 32)
+		if err != nil || !utf8.ValidRune(rune(u)) {
+			return nil, fmt.Errorf("charset: line %d: bad rune %q", line, fields[1])
+		}
+		table = append(table, TableEntry{uint16(native), rune(u)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
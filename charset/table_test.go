@@ -0,0 +1,229 @@
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// verifyTable checks the double-byte code table in the named data file:
+// that it is sorted by native code without duplicates, that no rune
+// is mapped from two codes, which would break encoding by binary
+// search, and that every code survives a round trip through that
+// search. It reports the first offending entry.
+func verifyTable(name string) error {
+	table, err := loadCodeTable(name)
+	if err != nil {
+		return err
+	}
+	if len(table) == 0 {
+		return fmt.Errorf("%s: empty table", name)
+	}
+	natives := make(map[rune]uint16)
+	for i, c := range table {
+		if i > 0 {
+			prev := table[i-1].native
+			if c.native == prev {
+				return fmt.Errorf("%s: entry %d: duplicate code %#04x", name, i, c.native)
+			}
+			if c.native < prev {
+				return fmt.Errorf("%s: entry %d: code %#04x follows %#04x", name, i, c.native, prev)
+			}
+		}
+		if n, ok := natives[c.unicode]; ok {
+			return fmt.Errorf("%s: entry %d: %U is mapped from both %#04x and %#04x", name, i, c.unicode, n, c.native)
+		}
+		natives[c.unicode] = c.native
+	}
+	byUnicode := make(cp949Table, len(table))
+	copy(byUnicode, table)
+	sort.Sort(cp949TableSortByUnicode{byUnicode})
+	for i, c := range table {
+		j := sort.Search(len(byUnicode), func(j int) bool {
+			return c.unicode <= byUnicode[j].unicode
+		})
+		if j == len(byUnicode) || byUnicode[j].native != c.native {
+			return fmt.Errorf("%s: entry %d: code %#04x (%U) does not round trip", name, i, c.native, c.unicode)
+		}
+	}
+	return nil
+}
+
+func TestVerifyTables(t *testing.T) {
+	// The johab and euc-tw tables are not included, because they
+	// deliberately map some runes from more than one code, and
+	// their encoders choose between them.
+	for _, name := range []string{"cp949.dat", gb18030Data, gbkData, jisx0212Data, hkscsData} {
+		if err := verifyTable(name); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestVerifyTableErrors(t *testing.T) {
+	for name, dat := range map[string]string{
+		"unsorted.dat":  "\x00\x02\x00\x02\xb0\xa2\x00\x01a\xb0\xa1\x00\x01b",
+		"duplicate.dat": "\x00\x02\x00\x02\xb0\xa1\x00\x01a\xb0\xa1\x00\x01b",
+		"twice.dat":     "\x00\x02\x00\x01\xb0\xa1\x00\x02aa",
+	} {
+		dat := dat
+		RegisterDataFile(name, func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(dat)), nil
+		})
+		if err := verifyTable(name); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		delete(files, name)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	in := []TableEntry{
+		{0xb0a2, '각'},
+		{0xb0a1, '가'},
+		{0x8141, '갂'},
+		{0xb0a3, '\U00020000'},
+	}
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, in); err != nil {
+		t.Fatalf("cannot write table: %v", err)
+	}
+	dat := buf.String()
+	RegisterDataFile("written.dat", func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(dat)), nil
+	})
+	defer delete(files, "written.dat")
+	table, err := loadCodeTable("written.dat")
+	if err != nil {
+		t.Fatalf("cannot load table: %v", err)
+	}
+	want := cp949Table{
+		{0x8141, '갂'},
+		{0xb0a1, '가'},
+		{0xb0a2, '각'},
+		{0xb0a3, '\U00020000'},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("expected %v, got %v", want, table)
+	}
+
+	// The data files round trip.
+	for _, name := range []string{"cp949.dat", gbkData} {
+		table, err := loadCodeTable(name)
+		if err != nil {
+			t.Fatalf("%s: cannot load table: %v", name, err)
+		}
+		entries := make([]TableEntry, len(table))
+		for i, c := range table {
+			entries[i] = TableEntry{c.native, c.unicode}
+		}
+		buf.Reset()
+		if err := WriteTable(&buf, entries); err != nil {
+			t.Fatalf("%s: cannot write table: %v", name, err)
+		}
+		orig, err := readFile(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(buf.Bytes(), orig) {
+			t.Errorf("%s: written table differs from the original", name)
+		}
+	}
+}
+
+func TestWriteTableErrors(t *testing.T) {
+	for _, table := range [][]TableEntry{
+		{{0xb0a1, '가'}, {0xb0a1, '각'}},
+		{{0xb0a1, 0xd800}},
+	} {
+		if err := WriteTable(ioutil.Discard, table); err == nil {
+			t.Errorf("%v: expected error", table)
+		}
+	}
+}
+
+const mappingSnippet = `#
+#    Name:     cp949 to Unicode table
+#
+0x41	0x0041	#LATIN CAPITAL LETTER A
+0x80	#UNDEFINED
+
+0x8141	0xAC02	#HANGUL SYLLABLE GIYEOG A SSANGGIYEOG
+0x8142	0xAC03	#HANGUL SYLLABLE GIYEOG A RIEULGIYEOG
+  0xB0A1 0xAC00
+`
+
+func TestReadMapping(t *testing.T) {
+	table, err := ReadMapping(strings.NewReader(mappingSnippet))
+	if err != nil {
+		t.Fatalf("cannot read mapping: %v", err)
+	}
+	want := []TableEntry{
+		{0x8141, '갂'},
+		{0x8142, '갃'},
+		{0xb0a1, '가'},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("expected %v, got %v", want, table)
+	}
+	for _, bad := range []string{
+		"0x8141\t0xAC02+0x0304\n",
+		"0x8141\tzz\n",
+		"0x18141\t0xAC02\n",
+		"0x8141\t0xD800\n",
+	} {
+		if _, err := ReadMapping(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestLoadCodeTableErrors(t *testing.T) {
+	cp949, err := readFile("cp949.dat")
+	if err != nil {
+		t.Fatalf("cannot read cp949.dat: %v", err)
+	}
+	// Headers for one chunk of two codes, and of one code.
+	const two, one = "\x00\x02\x00\x01", "\x00\x01\x00\x01"
+	for _, test := range []struct {
+		dat string
+		err string
+	}{
+		{string(cp949[:len(cp949)/2]), "charset: bad.dat: truncated in chunk"},
+		{"\x00", "charset: bad.dat: truncated header"},
+		{two + "\xb0\xa1", "charset: bad.dat: truncated in chunk 1 of 1"},
+		{two + "\xb0\xa1\x00\x06가", "charset: bad.dat: truncated in chunk 1 of 1"},
+		{two + "\xb0\xa1\x00\x03가", "charset: bad.dat: expected 2 codes, got 1"},
+		{one + "\xb0\xa1\x00\x03가Z", "charset: bad.dat: unexpected data after the last chunk"},
+		{one + "\xb0\xa1\x00\x02\xea\xb0", "charset: bad.dat: invalid UTF-8 in chunk 1"},
+	} {
+		dat := test.dat
+		RegisterDataFile("bad.dat", func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(dat)), nil
+		})
+		_, err := loadCodeTable("bad.dat")
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%x: expected error %q, got %v", test.dat, test.err, err)
+		}
+	}
+	delete(files, "bad.dat")
+}
+
+func TestPatchCodeTable(t *testing.T) {
+	table := cp949Table{{0xb0a1, '가'}, {0xb0a2, '각'}, {0xb0a4, 'X'}}
+	got := patchCodeTable(table, map[uint16]rune{
+		0xb0a2: 0,   // removed
+		0xb0a3: '간', // added
+		0xb0a4: '갇', // corrected
+		0x8141: '갂', // added before the others
+	})
+	want := cp949Table{{0x8141, '갂'}, {0xb0a1, '가'}, {0xb0a3, '간'}, {0xb0a4, '갇'}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
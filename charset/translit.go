@@ -0,0 +1,82 @@
+package charset
+
+// Transliterations into ASCII for characters that a
+// character set cannot represent, used by the "translit" option.
+// Each string holds the characters that fold to the given ASCII text.
+var translitFolds = []struct {
+	from, to string
+}{
+	{"ÀÁÂÃÄÅĀĂĄ", "A"},
+	{"àáâãäåāăą", "a"},
+	{"ÇĆĈĊČ", "C"},
+	{"çćĉċč", "c"},
+	{"ĎĐ", "D"},
+	{"ďđ", "d"},
+	{"ÈÉÊËĒĔĖĘĚ", "E"},
+	{"èéêëēĕėęě", "e"},
+	{"ĜĞĠĢ", "G"},
+	{"ĝğġģ", "g"},
+	{"ĤĦ", "H"},
+	{"ĥħ", "h"},
+	{"ÌÍÎÏĨĪĬĮİ", "I"},
+	{"ìíîïĩīĭįı", "i"},
+	{"Ĵ", "J"},
+	{"ĵ", "j"},
+	{"Ķ", "K"},
+	{"ķ", "k"},
+	{"ĹĻĽĿŁ", "L"},
+	{"ĺļľŀł", "l"},
+	{"ÑŃŅŇ", "N"},
+	{"ñńņň", "n"},
+	{"ÒÓÔÕÖØŌŎŐ", "O"},
+	{"òóôõöøōŏő", "o"},
+	{"ŔŖŘ", "R"},
+	{"ŕŗř", "r"},
+	{"ŚŜŞŠ", "S"},
+	{"śŝşš", "s"},
+	{"ŢŤŦ", "T"},
+	{"ţťŧ", "t"},
+	{"ÙÚÛÜŨŪŬŮŰŲ", "U"},
+	{"ùúûüũūŭůűų", "u"},
+	{"Ŵ", "W"},
+	{"ŵ", "w"},
+	{"ÝŶŸ", "Y"},
+	{"ýÿŷ", "y"},
+	{"ŹŻŽ", "Z"},
+	{"źżž", "z"},
+	{"Æ", "AE"},
+	{"æ", "ae"},
+	{"Œ", "OE"},
+	{"œ", "oe"},
+	{"ß", "ss"},
+	{"‘’‚′", "'"},
+	{"“”„″", "\""},
+	{"‐‑‒–—―", "-"},
+	{"…", "..."},
+	{"\u00a0\u2002\u2003\u2009\u3000", " "},
+}
+
+var translitMap = makeFoldMap(translitFolds)
+
+// makeFoldMap returns a map from each rune in the
+// from strings of folds to the corresponding to string.
+func makeFoldMap(folds []struct{ from, to string }) map[rune]string {
+	m := make(map[rune]string)
+	for _, f := range folds {
+		for _, r := range f.from {
+			m[r] = f.to
+		}
+	}
+	return m
+}
+
+// translit returns an ASCII approximation of r,
+// or false if there is none. Fullwidth forms
+// fold to the corresponding ASCII characters.
+func translit(r rune) (string, bool) {
+	if r >= 0xff01 && r <= 0xff5e {
+		return string(r - 0xff01 + '!'), true
+	}
+	s, ok := translitMap[r]
+	return s, ok
+}
@@ -3,6 +3,7 @@ package charset
 import (
 	"encoding/binary"
 	"errors"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -13,35 +14,76 @@ func init() {
 type translateFromUTF16 struct {
 	first   bool
 	endian  binary.ByteOrder
+	given   binary.ByteOrder // endianness given in the name, or nil
 	scratch []byte
 }
 
+func (p *translateFromUTF16) Reset() {
+	p.first = true
+	p.endian = p.given
+	p.scratch = p.scratch[:0]
+}
+
 func (p *translateFromUTF16) Translate(data []byte, eof bool) (int, []byte, error) {
+	odd := len(data)&1 != 0
 	data = data[0 : len(data)&^1] // round to even number of bytes.
-	if len(data) < 2 {
-		return 0, nil, nil
-	}
+	p.scratch = p.scratch[:0]
 	n := 0
-	if p.first && p.endian == nil {
+	if p.first && len(data) >= 2 {
+		// A byte order mark gives the endianness if it is
+		// not known, and is removed if it agrees with it.
+		var bom binary.ByteOrder
 		switch binary.BigEndian.Uint16(data) {
 		case 0xfeff:
-			p.endian = binary.BigEndian
-			data = data[2:]
-			n += 2
+			bom = binary.BigEndian
 		case 0xfffe:
-			p.endian = binary.LittleEndian
+			bom = binary.LittleEndian
+		}
+		if p.endian == nil {
+			p.endian = bom
+			if bom == nil {
+				p.endian = guessEndian(data)
+			}
+		}
+		if bom != nil && bom == p.endian {
 			data = data[2:]
 			n += 2
-		default:
-			p.endian = guessEndian(data)
 		}
 		p.first = false
 	}
 
-	p.scratch = p.scratch[:0]
-	for ; len(data) > 0; data = data[2:] {
-		p.scratch = appendRune(p.scratch, rune(p.endian.Uint16(data)))
-		n += 2
+	for len(data) > 0 {
+		r, size := rune(p.endian.Uint16(data)), 2
+		switch {
+		case r >= 0xd800 && r < 0xdc00:
+			// A high surrogate, which must be followed by a low one.
+			if len(data) < 4 {
+				if !eof {
+					// Leave it for the next call.
+					return n, p.scratch, nil
+				}
+				r = utf8.RuneError
+				break
+			}
+			r2 := rune(p.endian.Uint16(data[2:]))
+			if r2 >= 0xdc00 && r2 < 0xe000 {
+				r, size = utf16.DecodeRune(r, r2), 4
+			} else {
+				// Leave r2 to be decoded in its own right.
+				r = utf8.RuneError
+			}
+		case r >= 0xdc00 && r < 0xe000:
+			// A low surrogate without a high one.
+			r = utf8.RuneError
+		}
+		p.scratch = appendRune(p.scratch, r)
+		data = data[size:]
+		n += size
+	}
+	if odd && eof {
+		// A truncated final character.
+		p.scratch = appendRune(p.scratch, utf8.RuneError)
+		n++
 	}
 	return n, p.scratch, nil
 }
@@ -53,10 +95,16 @@ func guessEndian(data []byte) binary.ByteOrder {
 
 type translateToUTF16 struct {
 	first   bool
+	bom     bool // start with a byte order mark
 	endian  binary.ByteOrder
 	scratch []byte
 }
 
+func (p *translateToUTF16) Reset() {
+	p.first = p.bom
+	p.scratch = p.scratch[:0]
+}
+
 func (p *translateToUTF16) Translate(data []byte, eof bool) (int, []byte, error) {
 	p.scratch = ensureCap(p.scratch[:0], (len(data)+1)*2)
 	if p.first {
@@ -70,11 +118,17 @@ func (p *translateToUTF16) Translate(data []byte, eof bool) (int, []byte, error)
 			break
 		}
 		r, size := utf8.DecodeRune(data)
-		// TODO if r > 65535?
-
-		slen := len(p.scratch)
-		p.scratch = p.scratch[0 : slen+2]
-		p.endian.PutUint16(p.scratch[slen:], uint16(r))
+		if r > 0xffff {
+			hi, lo := utf16.EncodeRune(r)
+			slen := len(p.scratch)
+			p.scratch = p.scratch[0 : slen+4]
+			p.endian.PutUint16(p.scratch[slen:], uint16(hi))
+			p.endian.PutUint16(p.scratch[slen+2:], uint16(lo))
+		} else {
+			slen := len(p.scratch)
+			p.scratch = p.scratch[0 : slen+2]
+			p.endian.PutUint16(p.scratch[slen:], uint16(r))
+		}
 		data = data[size:]
 		n += size
 	}
@@ -82,6 +136,10 @@ func (p *translateToUTF16) Translate(data []byte, eof bool) (int, []byte, error)
 }
 
 func getEndian(arg string) (binary.ByteOrder, error) {
+	arg, _, err := splitArg(arg)
+	if err != nil {
+		return nil, err
+	}
 	switch arg {
 	case "le":
 		return binary.LittleEndian, nil
@@ -90,7 +148,7 @@ func getEndian(arg string) (binary.ByteOrder, error) {
 	case "":
 		return nil, nil
 	}
-	return nil, errors.New("charset: unknown utf16 endianness")
+	return nil, errors.New("charset: unknown endianness")
 }
 
 func fromUTF16(arg string) (Translator, error) {
@@ -98,13 +156,20 @@ func fromUTF16(arg string) (Translator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &translateFromUTF16{first: true, endian: endian}, nil
+	return &translateFromUTF16{first: true, endian: endian, given: endian}, nil
 }
 
+// factory to create translateToUTF16. Without an endianness,
+// as in "utf-16", the text is little-endian, which is also what
+// fromUTF16 assumes, and starts with a byte order mark.
 func toUTF16(arg string) (Translator, error) {
 	endian, err := getEndian(arg)
 	if err != nil {
 		return nil, err
 	}
-	return &translateToUTF16{first: false, endian: endian}, nil
+	bom := endian == nil
+	if bom {
+		endian = binary.LittleEndian
+	}
+	return &translateToUTF16{first: bom, bom: bom, endian: endian}, nil
 }
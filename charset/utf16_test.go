@@ -0,0 +1,85 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var utf16SurrogateTests = []struct {
+	charset string
+	in      string
+	out     string
+}{
+	{"utf-16le", "=\xd8\x00\xde", "😀"},
+	{"utf-16be", "\xd8=\xde\x00", "😀"},
+	{"utf-16le", "a\x00=\xd8\x00\xdeb\x00", "a😀b"},
+	// A lone low surrogate.
+	{"utf-16le", "a\x00\x00\xdeb\x00", "a" + string(utf8.RuneError) + "b"},
+	// A high surrogate followed by something else,
+	// which is decoded as usual.
+	{"utf-16le", "=\xd8b\x00", string(utf8.RuneError) + "b"},
+	{"utf-16le", "=\xd8=\xd8\x00\xde", string(utf8.RuneError) + "😀"},
+	// A high surrogate at the end of the input.
+	{"utf-16le", "a\x00=\xd8", "a" + string(utf8.RuneError)},
+	// An odd byte at the end of the input.
+	{"utf-16le", "A\x00B", "A" + string(utf8.RuneError)},
+	{"utf-16be", "\x00A=", "A" + string(utf8.RuneError)},
+	{"utf-16le", "=\xd8B", string(utf8.RuneError) + string(utf8.RuneError)},
+	{"utf-16", "\xff", string(utf8.RuneError)},
+}
+
+func TestUTF16Surrogates(t *testing.T) {
+	for _, test := range utf16SurrogateTests {
+		if out := decodeString(t, test.charset, test.in); out != test.out {
+			t.Errorf("%s: decoding %x: expected %q got %q", test.charset, test.in, test.out, out)
+		}
+	}
+}
+
+func TestUTF16SplitSurrogates(t *testing.T) {
+	in := []byte("a\x00=\xd8\x00\xdeb\x00")
+	for split := 0; split <= len(in); split++ {
+		tr, err := charset.TranslatorFrom("utf-16le")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate(in[:split], false)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		out := string(cdata)
+		_, cdata, err = tr.Translate(in[n:], true)
+		if err != nil {
+			t.Fatalf("split %d: translate error: %v", split, err)
+		}
+		out += string(cdata)
+		if out != "a😀b" {
+			t.Errorf("split %d: expected %q got %q", split, "a😀b", out)
+		}
+	}
+}
+
+var utf16EncodeTests = []struct {
+	charset string
+	in      string
+	out     string
+}{
+	{"utf-16le", "a가", "a\x00\x00\xac"},
+	{"utf-16be", "a가", "\x00a\xac\x00"},
+	{"utf-16", "a가", "\xff\xfea\x00\x00\xac"},
+	{"utf-16le", "a😀b", "a\x00=\xd8\x00\xdeb\x00"},
+	{"utf-16be", "😀", "\xd8=\xde\x00"},
+}
+
+func TestUTF16Encode(t *testing.T) {
+	for _, test := range utf16EncodeTests {
+		if out := encodeString(t, test.charset, test.in); out != test.out {
+			t.Errorf("%s: encoding %q: expected %x got %x", test.charset, test.in, test.out, out)
+		}
+		if out := decodeString(t, test.charset, test.out); out != test.in {
+			t.Errorf("%s: decoding %x: expected %q got %q", test.charset, test.out, test.in, out)
+		}
+	}
+}
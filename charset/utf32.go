@@ -0,0 +1,125 @@
+package charset
+
+import (
+	"encoding/binary"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("utf32", fromUTF32, toUTF32)
+}
+
+type translateFromUTF32 struct {
+	first   bool
+	endian  binary.ByteOrder
+	given   binary.ByteOrder // endianness given in the name, or nil
+	scratch []byte
+}
+
+func (p *translateFromUTF32) Reset() {
+	p.first = true
+	p.endian = p.given
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromUTF32) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	if p.first {
+		if len(data) < 4 && !eof {
+			return 0, nil, nil
+		}
+		// A byte order mark gives the endianness if it is
+		// not known, and is removed if it agrees with it.
+		// Without one, the text is big endian (Unicode 3.10, D101).
+		var bom binary.ByteOrder
+		if len(data) >= 4 {
+			switch binary.BigEndian.Uint32(data) {
+			case 0xfeff:
+				bom = binary.BigEndian
+			case 0xfffe0000:
+				bom = binary.LittleEndian
+			}
+		}
+		if p.endian == nil {
+			p.endian = bom
+			if bom == nil {
+				p.endian = binary.BigEndian
+			}
+		}
+		if bom != nil && bom == p.endian {
+			data = data[4:]
+			n += 4
+		}
+		p.first = false
+	}
+	for len(data) >= 4 {
+		r := rune(p.endian.Uint32(data))
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+		p.scratch = appendRune(p.scratch, r)
+		data = data[4:]
+		n += 4
+	}
+	if eof && len(data) > 0 {
+		p.scratch = appendRune(p.scratch, utf8.RuneError)
+		n += len(data)
+	}
+	return n, p.scratch, nil
+}
+
+type translateToUTF32 struct {
+	first   bool
+	bom     bool // whether the text starts with a byte order mark
+	endian  binary.ByteOrder
+	scratch []byte
+}
+
+func (p *translateToUTF32) Reset() {
+	p.first = p.bom
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToUTF32) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = ensureCap(p.scratch[:0], (len(data)+1)*4)
+	if p.first {
+		p.scratch = p.scratch[0:4]
+		p.endian.PutUint32(p.scratch, 0xfeff)
+		p.first = false
+	}
+	n := 0
+	for len(data) > 0 {
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		slen := len(p.scratch)
+		p.scratch = p.scratch[0 : slen+4]
+		p.endian.PutUint32(p.scratch[slen:], uint32(r))
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+func fromUTF32(arg string) (Translator, error) {
+	endian, err := getEndian(arg)
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromUTF32{first: true, endian: endian, given: endian}, nil
+}
+
+// toUTF32 writes big endian text starting with a
+// byte order mark if no endianness is given.
+func toUTF32(arg string) (Translator, error) {
+	endian, err := getEndian(arg)
+	if err != nil {
+		return nil, err
+	}
+	if endian == nil {
+		return &translateToUTF32{first: true, bom: true, endian: binary.BigEndian}, nil
+	}
+	return &translateToUTF32{endian: endian}, nil
+}
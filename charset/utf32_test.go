@@ -0,0 +1,69 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var utf32Tests = []struct {
+	charset string
+	native  string
+	unicode string
+}{
+	{"utf-32be", "\x00\x00\x00a\x00\x00\x00\xe9", "aé"},
+	{"utf-32le", "a\x00\x00\x00\xe9\x00\x00\x00", "aé"},
+	{"utf-32be", "\x00\x01\xf6\x00", "😀"},
+	{"utf-32le", "\xff\xff\x10\x00", "\U0010ffff"},
+	{"utf-32", "\x00\x00\xfe\xff\x00\x02\x00\x00", "\U00020000"},
+}
+
+func TestUTF32(t *testing.T) {
+	for _, test := range utf32Tests {
+		if out := decodeString(t, test.charset, test.native); out != test.unicode {
+			t.Errorf("%s: decoding %x: expected %q got %q", test.charset, test.native, test.unicode, out)
+		}
+		if out := encodeString(t, test.charset, test.unicode); out != test.native {
+			t.Errorf("%s: encoding %q: expected %x got %x", test.charset, test.unicode, test.native, out)
+		}
+	}
+}
+
+func TestUTF32BOM(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		"\x00\x00\xfe\xff\x00\x00\x00a": "a",
+		"\xff\xfe\x00\x00a\x00\x00\x00": "a",
+		"\x00\x00\x00a":                 "a",
+		"\x00\x00\xd8\x00":              bad,
+		"\x00\x11\x00\x00":              bad,
+		"\x00\x00\x00a\x00":             "a" + bad,
+	} {
+		if got := decodeString(t, "utf-32", in); got != out {
+			t.Errorf("decoding %x: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestUTF32Split(t *testing.T) {
+	in := []byte("\x00\x00\xfe\xff\x00\x01\xf6\x00")
+	for split := 1; split < len(in); split++ {
+		tr, err := charset.TranslatorFrom("utf-32")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate(in[:split], false)
+		if err != nil {
+			t.Fatalf("split %d: %v", split, err)
+		}
+		out := string(cdata)
+		_, cdata, err = tr.Translate(in[n:], true)
+		if err != nil {
+			t.Fatalf("split %d: %v", split, err)
+		}
+		if out += string(cdata); out != "😀" {
+			t.Errorf("split %d: expected %q got %q", split, "😀", out)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package charset
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+func init() {
+	registerClass("utf7", fromUTF7, toUTF7)
+}
+
+// encoding details
+//
+// UTF-7 (RFC 2152) represents ASCII characters directly.
+// Other characters are encoded as UTF-16 code units in a
+// modified base64, introduced by '+' and ended by '-' or
+// by any character that is not in the base64 alphabet.
+// The sequence "+-" represents '+'.
+//
+// The IMAP variant (RFC 3501, argument "imap") uses '&'
+// instead of '+', ',' instead of '/' in the base64 alphabet,
+// and always ends a base64 run with '-'.
+
+const utf7Base64 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+type utf7Variant struct {
+	shift    byte      // introduces a base64 run
+	alphabet string    // base64 alphabet
+	index    [256]int8 // index in alphabet of each byte, or -1.
+	imap     bool
+}
+
+func newUTF7Variant(imap bool) *utf7Variant {
+	v := &utf7Variant{shift: '+', alphabet: utf7Base64, imap: imap}
+	if imap {
+		v.shift = '&'
+		v.alphabet = utf7Base64[:63] + ","
+	}
+	for i := range v.index {
+		v.index[i] = -1
+	}
+	for i := 0; i < len(v.alphabet); i++ {
+		v.index[v.alphabet[i]] = int8(i)
+	}
+	return v
+}
+
+var (
+	utf7Plain = newUTF7Variant(false)
+	utf7IMAP  = newUTF7Variant(true)
+)
+
+type translateFromUTF7 struct {
+	*utf7Variant
+	shifted bool   // in a base64 run
+	start   bool   // at the start of a base64 run
+	bits    uint32 // pending bits, of which nbits are valid.
+	nbits   uint
+	high    rune // pending high surrogate, or 0
+	scratch []byte
+}
+
+func (p *translateFromUTF7) Reset() {
+	p.shifted, p.start = false, false
+	p.bits, p.nbits = 0, 0
+	p.high = 0
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromUTF7) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	for _, b := range data {
+		if !p.shifted {
+			if b == p.shift {
+				p.shifted, p.start = true, true
+				p.bits, p.nbits = 0, 0
+				continue
+			}
+			if b >= utf8.RuneSelf {
+				p.emit(utf8.RuneError)
+			} else {
+				p.emit(rune(b))
+			}
+			continue
+		}
+		x := p.index[b]
+		if x >= 0 {
+			p.start = false
+			p.bits = p.bits<<6 | uint32(x)
+			p.nbits += 6
+			if p.nbits >= 16 {
+				p.nbits -= 16
+				p.unit(rune(p.bits >> p.nbits & 0xffff))
+			}
+			continue
+		}
+		// End of the base64 run.
+		if p.start && b == '-' {
+			p.emit(rune(p.shift))
+		}
+		p.endRun()
+		switch {
+		case b >= utf8.RuneSelf:
+			p.emit(utf8.RuneError)
+		case b != '-':
+			p.emit(rune(b))
+		}
+	}
+	if eof && p.shifted {
+		p.endRun()
+	}
+	return len(data), p.scratch, nil
+}
+
+// unit adds a UTF-16 code unit, combining surrogate pairs.
+func (p *translateFromUTF7) unit(u rune) {
+	if p.high != 0 {
+		r := utf16.DecodeRune(p.high, u)
+		p.high = 0
+		if r != utf8.RuneError {
+			p.scratch = appendRune(p.scratch, r)
+			return
+		}
+		p.scratch = appendRune(p.scratch, utf8.RuneError)
+	}
+	if utf16.IsSurrogate(u) && u < 0xdc00 {
+		p.high = u
+		return
+	}
+	p.emit(u)
+}
+
+func (p *translateFromUTF7) emit(r rune) {
+	if r >= utf8.RuneSelf || utf16.IsSurrogate(r) {
+		if utf16.IsSurrogate(r) {
+			r = utf8.RuneError
+		}
+		p.scratch = appendRune(p.scratch, r)
+		return
+	}
+	p.scratch = append(p.scratch, byte(r))
+}
+
+func (p *translateFromUTF7) endRun() {
+	if p.high != 0 {
+		p.scratch = appendRune(p.scratch, utf8.RuneError)
+		p.high = 0
+	}
+	p.shifted, p.start = false, false
+	p.bits, p.nbits = 0, 0
+}
+
+type translateToUTF7 struct {
+	*utf7Variant
+	shifted bool
+	bits    uint32 // pending bits, of which nbits are valid.
+	nbits   uint
+	scratch []byte
+}
+
+// direct reports whether b can be represented
+// as itself outside a base64 run.
+func (p *translateToUTF7) direct(b byte) bool {
+	if b >= 0x20 && b <= 0x7e {
+		return p.imap || b != '\\' && b != '~'
+	}
+	return !p.imap && (b == '\t' || b == '\r' || b == '\n')
+}
+
+func (p *translateToUTF7) Reset() {
+	p.shifted = false
+	p.bits, p.nbits = 0, 0
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToUTF7) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if b := data[0]; b < utf8.RuneSelf && p.direct(b) {
+			if p.shifted {
+				p.endRun()
+			}
+			p.scratch = append(p.scratch, b)
+			if b == p.shift {
+				p.scratch = append(p.scratch, '-')
+			}
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		if !p.shifted {
+			p.scratch = append(p.scratch, p.shift)
+			p.shifted = true
+		}
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			p.unit(r1)
+			p.unit(r2)
+		} else {
+			p.unit(r)
+		}
+		data = data[size:]
+		n += size
+	}
+	if eof && p.shifted {
+		p.endRun()
+	}
+	return n, p.scratch, nil
+}
+
+func (p *translateToUTF7) unit(u rune) {
+	p.bits = p.bits<<16 | uint32(u)
+	p.nbits += 16
+	for p.nbits >= 6 {
+		p.nbits -= 6
+		p.scratch = append(p.scratch, p.alphabet[p.bits>>p.nbits&0x3f])
+	}
+}
+
+func (p *translateToUTF7) endRun() {
+	if p.nbits > 0 {
+		p.scratch = append(p.scratch, p.alphabet[p.bits<<(6-p.nbits)&0x3f])
+	}
+	p.scratch = append(p.scratch, '-')
+	p.shifted = false
+	p.bits, p.nbits = 0, 0
+}
+
+func getUTF7Variant(arg string) (*utf7Variant, error) {
+	arg, _, err := splitArg(arg)
+	if err != nil {
+		return nil, err
+	}
+	if arg == "imap" {
+		return utf7IMAP, nil
+	}
+	return utf7Plain, nil
+}
+
+func fromUTF7(arg string) (Translator, error) {
+	v, err := getUTF7Variant(arg)
+	if err != nil {
+		return nil, err
+	}
+	return &translateFromUTF7{utf7Variant: v}, nil
+}
+
+func toUTF7(arg string) (Translator, error) {
+	v, err := getUTF7Variant(arg)
+	if err != nil {
+		return nil, err
+	}
+	return &translateToUTF7{utf7Variant: v}, nil
+}
@@ -0,0 +1,72 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var utf7Tests = []struct {
+	charset string
+	native  string
+	unicode string
+}{
+	{"utf-7", "Hi Mom -+Jjo--!", "Hi Mom -☺-!"},
+	{"utf-7", "+ZeVnLIqe-", "日本語"},
+	{"utf-7", "A+ImIDkQ-.", "A≢Α."},
+	{"utf-7", "1 +- 2", "1 + 2"},
+	{"utf-7", "+2D3eAA-x", "😀x"},
+	{"utf-7", "a+AH4-b+AFw-c", "a~b\\c"},
+	{"utf-7", "+AOk-", "é"},
+	{"utf-7-imap", "~peter/mail/&U,BTFw-/&ZeVnLIqe-", "~peter/mail/台北/日本語"},
+	{"utf-7-imap", "Tom &- Jerry", "Tom & Jerry"},
+}
+
+func TestUTF7(t *testing.T) {
+	for _, test := range utf7Tests {
+		if out := decodeString(t, test.charset, test.native); out != test.unicode {
+			t.Errorf("%s: decoding %q: expected %q got %q", test.charset, test.native, test.unicode, out)
+		}
+		if out := encodeString(t, test.charset, test.unicode); out != test.native {
+			t.Errorf("%s: encoding %q: expected %q got %q", test.charset, test.unicode, test.native, out)
+		}
+	}
+}
+
+func TestUTF7Decode(t *testing.T) {
+	// Encodings that are valid but not those we produce.
+	for in, out := range map[string]string{
+		"A+ImIDkQ.":   "A≢Α.",
+		"+ZeVnLIqe":   "日本語",
+		"+AGEAYgBj x": "abc x",
+		// A byte that is not ASCII ends the run as usual.
+		"+AGE\xe9x": "a\ufffdx",
+		"\xe9+AGE":  "\ufffda",
+	} {
+		if got := decodeString(t, "utf-7", in); got != out {
+			t.Errorf("decoding %q: expected %q got %q", in, out, got)
+		}
+	}
+}
+
+func TestUTF7Split(t *testing.T) {
+	in := []byte("a+2D3eAA-b")
+	for split := 1; split < len(in); split++ {
+		tr, err := charset.TranslatorFrom("utf-7")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		n, cdata, err := tr.Translate(in[:split], false)
+		if err != nil || n != split {
+			t.Fatalf("split %d: got %d, %q, %v", split, n, cdata, err)
+		}
+		out := string(cdata)
+		_, cdata, err = tr.Translate(in[split:], true)
+		if err != nil {
+			t.Fatalf("split %d: %v", split, err)
+		}
+		if out += string(cdata); out != "a😀b" {
+			t.Errorf("split %d: expected %q got %q", split, "a😀b", out)
+		}
+	}
+}
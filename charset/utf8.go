@@ -1,11 +1,12 @@
 package charset
 
 import (
+	"bytes"
 	"unicode/utf8"
 )
 
 func init() {
-	registerClass("utf8", toUTF8, toUTF8)
+	registerClass("utf8", fromUTF8, toUTF8)
 }
 
 type translateToUTF8 struct {
@@ -16,6 +17,10 @@ var errorBytes = []byte(string(utf8.RuneError))
 
 const errorRuneLen = len(string(utf8.RuneError))
 
+func (p *translateToUTF8) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
 func (p *translateToUTF8) Translate(data []byte, eof bool) (int, []byte, error) {
 	p.scratch = ensureCap(p.scratch, (len(data))*errorRuneLen)
 	buf := p.scratch[:0]
@@ -28,7 +33,7 @@ func (p *translateToUTF8) Translate(data []byte, eof bool) (int, []byte, error)
 		}
 		_, size := utf8.DecodeRune(data[i:])
 		if size == 1 {
-			if !eof && !utf8.FullRune(data) {
+			if !eof && !utf8.FullRune(data[i:]) {
 				// When DecodeRune has converted only a single
 				// byte, we know there must be some kind of error
 				// because we know the byte's not ASCII.
@@ -47,5 +52,43 @@ func (p *translateToUTF8) Translate(data []byte, eof bool) (int, []byte, error)
 }
 
 func toUTF8(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
 	return new(translateToUTF8), nil
 }
+
+// translateFromUTF8 is like translateToUTF8, but removes
+// a byte order mark from the start of the text.
+type translateFromUTF8 struct {
+	first bool
+	translateToUTF8
+}
+
+func (p *translateFromUTF8) Reset() {
+	p.first = true
+	p.translateToUTF8.Reset()
+}
+
+func (p *translateFromUTF8) Translate(data []byte, eof bool) (int, []byte, error) {
+	n := 0
+	if p.first {
+		if len(data) < len(utf8BOM) && !eof && bytes.HasPrefix(utf8BOM, data) {
+			return 0, nil, nil
+		}
+		if bytes.HasPrefix(data, utf8BOM) {
+			data = data[len(utf8BOM):]
+			n = len(utf8BOM)
+		}
+		p.first = false
+	}
+	nc, cdata, err := p.translateToUTF8.Translate(data, eof)
+	return n + nc, cdata, err
+}
+
+func fromUTF8(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	return &translateFromUTF8{first: true}, nil
+}
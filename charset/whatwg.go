@@ -0,0 +1,119 @@
+package charset
+
+import (
+	"unicode/utf8"
+)
+
+// The "replacement" and "x-user-defined" encodings
+// of the WHATWG Encoding Standard.
+
+func init() {
+	registerClass("replacement", fromReplacement, nil)
+	registerClass("x-user-defined", fromUserDefined, toUserDefined)
+}
+
+// translateFromReplacement decodes any non-empty input
+// to a single utf8.RuneError. It stands for encodings
+// that are unsafe to decode, such as ISO-2022-CN.
+type translateFromReplacement struct {
+	done    bool
+	scratch []byte
+}
+
+func (p *translateFromReplacement) Reset() {
+	p.done = false
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromReplacement) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	if len(data) > 0 && !p.done {
+		p.scratch = append(p.scratch, errorBytes...)
+		p.done = true
+	}
+	return len(data), p.scratch, nil
+}
+
+func fromReplacement(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	return new(translateFromReplacement), nil
+}
+
+// x-user-defined maps the bytes 80..ff into the private
+// use area at U+F780..U+F7FF, and is otherwise ASCII.
+const userDefined0 = 0xf780 - 0x80
+
+type translateFromUserDefined struct {
+	scratch []byte
+}
+
+func (p *translateFromUserDefined) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateFromUserDefined) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	for _, b := range data {
+		if b < utf8.RuneSelf {
+			p.scratch = append(p.scratch, b)
+		} else {
+			p.scratch = appendRune(p.scratch, rune(b)+userDefined0)
+		}
+	}
+	return len(data), p.scratch, nil
+}
+
+type translateToUserDefined struct {
+	strict  bool
+	scratch []byte
+}
+
+func (p *translateToUserDefined) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateToUserDefined) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[0])
+			data = data[1:]
+			n++
+			continue
+		}
+		if !utf8.FullRune(data) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		switch {
+		case r >= 0xf780 && r <= 0xf7ff:
+			p.scratch = append(p.scratch, byte(r-userDefined0))
+		case p.strict:
+			return n, p.scratch, strictError(n, r, size)
+		default:
+			p.scratch = append(p.scratch, '?')
+		}
+		data = data[size:]
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+func fromUserDefined(arg string) (Translator, error) {
+	if _, _, err := splitArg(arg); err != nil {
+		return nil, err
+	}
+	return new(translateFromUserDefined), nil
+}
+
+// toUserDefined accepts the "strict" option, as for code pages.
+func toUserDefined(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "strict")
+	if err != nil {
+		return nil, err
+	}
+	return &translateToUserDefined{strict: opts.has("strict")}, nil
+}
@@ -0,0 +1,55 @@
+package charset_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+func TestReplacement(t *testing.T) {
+	bad := string(utf8.RuneError)
+	for in, out := range map[string]string{
+		"":                 "",
+		"a":                bad,
+		"\x1b$)A\x0e<>\x0f": bad,
+	} {
+		if got := decodeString(t, "replacement", in); got != out {
+			t.Errorf("decoding %q: expected %q got %q", in, out, got)
+		}
+	}
+	if info := charset.Info("iso-2022-cn"); info == nil || info.Name != "replacement" || !info.NoTo {
+		t.Errorf("unexpected info for iso-2022-cn: %+v", info)
+	}
+
+	// The input is replaced once however it is split.
+	tr, err := charset.TranslatorFrom("replacement")
+	if err != nil {
+		t.Fatalf("cannot make translator: %v", err)
+	}
+	if out, err := translate(tr, "some longer text"); err != nil || out != bad {
+		t.Errorf("expected %q got %q, %v", bad, out, err)
+	}
+}
+
+func TestUserDefined(t *testing.T) {
+	var native []byte
+	var unicode []rune
+	for i := 0; i < 256; i++ {
+		native = append(native, byte(i))
+		if i < 0x80 {
+			unicode = append(unicode, rune(i))
+		} else {
+			unicode = append(unicode, 0xf780+rune(i-0x80))
+		}
+	}
+	if out := decodeString(t, "x-user-defined", string(native)); out != string(unicode) {
+		t.Errorf("decoding: expected %q got %q", string(unicode), out)
+	}
+	if out := encodeString(t, "x-user-defined", string(unicode)); out != string(native) {
+		t.Errorf("encoding: expected %x got %x", native, out)
+	}
+	if out := encodeString(t, "x-user-defined", "aé"); out != "a?" {
+		t.Errorf("encoding é: expected %q got %q", "a?", out)
+	}
+}
@@ -0,0 +1,114 @@
+package charset
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+func init() {
+	registerTransform("width", newWidth, newWidth)
+}
+
+// halfwidthKana holds the fullwidth forms of the halfwidth
+// katakana and punctuation from U+FF61 to U+FF9F, as given by
+// NFKC. The last two are the combining sound marks.
+var halfwidthKana = []rune("。「」、・ヲァィゥェォャュョッーアイウエオ" +
+	"カキクケコサシスセソタチツテトナニヌネノハヒフヘホ" +
+	"マミムメモヤユヨラリルレロワン\u3099\u309a")
+
+// The katakana that combine with a following halfwidth
+// voiced (U+FF9E) or semi-voiced (U+FF9F) sound mark.
+var (
+	voicedKana     = pairRunes("ウカキクケコサシスセソタチツテトハヒフヘホワヲ", "ヴガギグゲゴザジズゼゾダヂヅデドバビブベボヷヺ")
+	semiVoicedKana = pairRunes("ハヒフヘホ", "パピプペポ")
+)
+
+// pairRunes returns a map from each rune of from
+// to the rune at the same position in to.
+func pairRunes(from, to string) map[rune]rune {
+	m := make(map[rune]rune)
+	t := []rune(to)
+	for i, r := range []rune(from) {
+		m[r] = t[i]
+	}
+	return m
+}
+
+// translateWidth folds fullwidth ASCII (U+FF01 to U+FF5E)
+// to ASCII and halfwidth katakana to fullwidth katakana,
+// as NFKC does, and passes everything else through.
+type translateWidth struct {
+	scratch []byte
+}
+
+func (p *translateWidth) Reset() {
+	p.scratch = p.scratch[:0]
+}
+
+func (p *translateWidth) Translate(data []byte, eof bool) (int, []byte, error) {
+	p.scratch = p.scratch[:0]
+	n := 0
+	for n < len(data) {
+		if data[n] < utf8.RuneSelf {
+			p.scratch = append(p.scratch, data[n])
+			n++
+			continue
+		}
+		if !utf8.FullRune(data[n:]) && !eof {
+			break
+		}
+		r, size := utf8.DecodeRune(data[n:])
+		switch {
+		case r >= 0xff01 && r <= 0xff5e:
+			p.scratch = append(p.scratch, byte(r-0xff01+'!'))
+		case r >= 0xff61 && r <= 0xff9f:
+			k := halfwidthKana[r-0xff61]
+			_, voiced := voicedKana[k]
+			if voiced {
+				rest := data[n+size:]
+				if !utf8.FullRune(rest) && !eof {
+					// A sound mark may follow in the next call.
+					return n, p.scratch, nil
+				}
+				mark, msize := utf8.DecodeRune(rest)
+				if c, ok := composeKana(k, mark); ok {
+					k = c
+					size += msize
+				}
+			}
+			p.scratch = appendRune(p.scratch, k)
+		default:
+			p.scratch = append(p.scratch, data[n:n+size]...)
+		}
+		n += size
+	}
+	return n, p.scratch, nil
+}
+
+// composeKana returns the katakana k combined with
+// the halfwidth sound mark, if there is one.
+func composeKana(k, mark rune) (rune, bool) {
+	var c rune
+	var ok bool
+	switch mark {
+	case 0xff9e:
+		c, ok = voicedKana[k]
+	case 0xff9f:
+		c, ok = semiVoicedKana[k]
+	}
+	return c, ok
+}
+
+// factory to create translateWidth, which translates
+// the same way in both directions. The "fold" option
+// gives the folding; only "half", the default, is known.
+func newWidth(arg string) (Translator, error) {
+	_, opts, err := splitArg(arg, "fold")
+	if err != nil {
+		return nil, err
+	}
+	if fold := opts["fold"]; fold != "" && fold != "half" {
+		return nil, fmt.Errorf("charset: unknown width folding %q", fold)
+	}
+	return &translateWidth{}, nil
+}
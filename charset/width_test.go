@@ -0,0 +1,60 @@
+package charset_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+var widthTests = []struct {
+	in  string
+	out string
+}{
+	{"Ａ", "A"},
+	{"ＡＢＣ１２３！～", "ABC123!~"},
+	{"ｶﾀｶﾅ", "カタカナ"},
+	{"ｶﾞｷﾞﾊﾟｳﾞ", "ガギパヴ"},
+	{"ｱﾞ", "ア゙"},
+	{"ﾟ", "゚"},
+	{"｡｢｣", "。「」"},
+	{"가나　中文é\xff", "가나　中文é\xff"},
+	{"カﾞ", "ガ"},
+}
+
+func TestWidth(t *testing.T) {
+	for _, test := range widthTests {
+		tr, err := charset.TranslatorFrom("width?fold=half")
+		if err != nil {
+			t.Fatalf("cannot make translator: %v", err)
+		}
+		if out, err := translate(tr, test.in); err != nil || out != test.out {
+			t.Errorf("%q: expected %q got %q, %v", test.in, test.out, out, err)
+		}
+	}
+	if _, err := charset.TranslatorFrom("width?fold=full"); err == nil {
+		t.Errorf("expected error for unknown folding")
+	}
+}
+
+func TestWidthSplit(t *testing.T) {
+	// ガ in Shift_JIS halfwidth katakana, followed by a fullwidth Ａ,
+	// split between calls after each byte.
+	tr, err := charset.NewTranslatorChain("shift_jis", "width")
+	if err != nil {
+		t.Fatalf("cannot make chain: %v", err)
+	}
+	in := "\xb6\xde\x82\x60"
+	var out, pending []byte
+	for i := 0; i < len(in); i++ {
+		pending = append(pending, in[i])
+		n, cdata, err := tr.Translate(pending, i == len(in)-1)
+		if err != nil {
+			t.Fatalf("translate error: %v", err)
+		}
+		out = append(out, cdata...)
+		pending = pending[n:]
+	}
+	if string(out) != "ガA" || len(pending) != 0 {
+		t.Errorf("expected %q, got %q with %x left", "ガA", out, pending)
+	}
+}
@@ -0,0 +1,104 @@
+// The xtext package adapts the character sets of the charset package
+// to the interfaces of golang.org/x/text/encoding, so that they
+// can be used with packages that expect an encoding.Encoding.
+// Example:
+//
+//	enc, err := xtext.NewEncoding("cp949")
+//	if err != nil {
+//		...
+//	}
+//	r := transform.NewReader(f, enc.NewDecoder())
+package xtext
+
+import (
+	"fmt"
+
+	"github.com/suapapa/go-charset/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+type charsetEncoding struct {
+	name string
+}
+
+// NewEncoding returns an encoding.Encoding for the named
+// character set. If the character set cannot be translated
+// in some direction, the Transformers for that direction
+// return an error.
+func NewEncoding(name string) (encoding.Encoding, error) {
+	if charset.Info(name) == nil {
+		return nil, fmt.Errorf("character set %q not found", name)
+	}
+	return charsetEncoding{name}, nil
+}
+
+func (e charsetEncoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: newTransformer(e.name, charset.TranslatorFrom)}
+}
+
+func (e charsetEncoding) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: newTransformer(e.name, charset.TranslatorTo)}
+}
+
+// transformer implements transform.Transformer with a charset.Translator.
+type transformer struct {
+	name    string
+	newTr   func(name string) (charset.Translator, error)
+	tr      charset.Translator
+	err     error  // error from newTr
+	pending []byte // translated data that did not fit in dst.
+}
+
+func newTransformer(name string, newTr func(string) (charset.Translator, error)) *transformer {
+	t := &transformer{name: name, newTr: newTr}
+	t.Reset()
+	return t
+}
+
+func (t *transformer) Reset() {
+	t.tr, t.err = t.newTr(t.name)
+	t.pending = t.pending[:0]
+}
+
+func (t *transformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if t.err != nil {
+		return 0, 0, t.err
+	}
+	nDst = copy(dst, t.pending)
+	t.pending = t.pending[:copy(t.pending, t.pending[nDst:])]
+	if len(t.pending) > 0 {
+		return nDst, 0, transform.ErrShortDst
+	}
+	for {
+		n, cdata, err := t.tr.Translate(src[nSrc:], atEOF)
+		nSrc += n
+		nc := copy(dst[nDst:], cdata)
+		nDst += nc
+		if nc < len(cdata) {
+			// The Translator has consumed the input, so keep
+			// the rest of its output for the next call. Any
+			// error will be returned again by the Translator
+			// once the output has been delivered.
+			t.pending = append(t.pending, cdata[nc:]...)
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		if err != nil {
+			// The output before the error is valid.
+			return nDst, nSrc, err
+		}
+		if nSrc == len(src) {
+			return nDst, nSrc, nil
+		}
+		if !atEOF {
+			// The Translator needs more input
+			// to translate the rest of src.
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		if n == 0 && len(cdata) == 0 {
+			// As with charset.NewTranslatingReader, drop input
+			// that the Translator refuses at eof.
+			return nDst, len(src), nil
+		}
+	}
+}
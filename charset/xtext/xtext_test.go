@@ -0,0 +1,121 @@
+package xtext_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/suapapa/go-charset/charset"
+	"github.com/suapapa/go-charset/charset/xtext"
+	_ "github.com/suapapa/go-charset/data"
+	"golang.org/x/text/transform"
+)
+
+const (
+	korean    = "아름다운 우리말"
+	koreanEnc = "\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee \xbf\xec\xb8\xae\xb8\xbb"
+)
+
+func TestDecodeReader(t *testing.T) {
+	enc, err := xtext.NewEncoding("cp949")
+	if err != nil {
+		t.Fatalf("cannot make encoding: %v", err)
+	}
+	r := transform.NewReader(iotest.OneByteReader(strings.NewReader(koreanEnc)), enc.NewDecoder())
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(out) != korean {
+		t.Errorf("expected %q got %q", korean, out)
+	}
+}
+
+func TestEncodeString(t *testing.T) {
+	enc, err := xtext.NewEncoding("cp949")
+	if err != nil {
+		t.Fatalf("cannot make encoding: %v", err)
+	}
+	out, _, err := transform.String(enc.NewEncoder(), korean)
+	if err != nil || out != koreanEnc {
+		t.Errorf("expected %q, got %q, %v", koreanEnc, out, err)
+	}
+}
+
+func TestShortSrc(t *testing.T) {
+	enc, err := xtext.NewEncoding("cp949")
+	if err != nil {
+		t.Fatalf("cannot make encoding: %v", err)
+	}
+	dec := enc.NewDecoder()
+	dst := make([]byte, 16)
+	nDst, nSrc, err := dec.Transform(dst, []byte("a\xbe"), false)
+	if err != transform.ErrShortSrc || nDst != 1 || nSrc != 1 {
+		t.Errorf("expected 1, 1, ErrShortSrc; got %d, %d, %v", nDst, nSrc, err)
+	}
+	nDst, nSrc, err = dec.Transform(dst, []byte("\xbe\xc6"), true)
+	if err != nil || nSrc != 2 || string(dst[:nDst]) != "아" {
+		t.Errorf("expected %q; got %q, %d, %v", "아", dst[:nDst], nSrc, err)
+	}
+}
+
+func TestShortDst(t *testing.T) {
+	enc, err := xtext.NewEncoding("cp949")
+	if err != nil {
+		t.Fatalf("cannot make encoding: %v", err)
+	}
+	dec := enc.NewDecoder()
+	var out []byte
+	dst := make([]byte, 2)
+	src := []byte(koreanEnc)
+	for {
+		nDst, nSrc, err := dec.Transform(dst, src, true)
+		out = append(out, dst[:nDst]...)
+		src = src[nSrc:]
+		if err == nil {
+			break
+		}
+		if err != transform.ErrShortDst {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if string(out) != korean {
+		t.Errorf("expected %q got %q", korean, out)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	if _, err := xtext.NewEncoding("no-such-charset"); err == nil {
+		t.Errorf("expected error")
+	}
+	enc, err := xtext.NewEncoding("big5")
+	if err != nil {
+		t.Fatalf("cannot make encoding: %v", err)
+	}
+	if _, _, err := transform.String(enc.NewEncoder(), "x"); err == nil {
+		t.Errorf("expected error encoding to big5")
+	}
+}
+
+func TestStrictPrefix(t *testing.T) {
+	enc, err := xtext.NewEncoding("cp949?strict")
+	if err != nil {
+		t.Fatalf("cannot make encoding: %v", err)
+	}
+	out, n, err := transform.String(enc.NewEncoder(), "abcédef")
+	if err == nil || out != "abc" || n != 3 {
+		t.Errorf("expected %q, 3, error; got %q, %d, %v", "abc", out, n, err)
+	}
+	// The prefix is kept when it does not fit in dst either.
+	e := enc.NewEncoder()
+	dst := make([]byte, 2)
+	nDst, nSrc, err := e.Transform(dst, []byte("abcédef"), true)
+	if err != transform.ErrShortDst || nDst != 2 || nSrc != 3 {
+		t.Errorf("expected 2, 3, ErrShortDst; got %d, %d, %v", nDst, nSrc, err)
+	}
+	nDst, nSrc, err = e.Transform(dst, []byte("édef"), true)
+	if _, ok := err.(*charset.UnmappableError); !ok || string(dst[:nDst]) != "c" || nSrc != 0 {
+		t.Errorf("expected %q, 0, *UnmappableError; got %q, %d, %v", "c", dst[:nDst], nSrc, err)
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 func init() {
 	charset.RegisterDataFile("charsets.json", func() (io.ReadCloser, error) {
-		r := strings.NewReader("{\n\"8bit\": {\n\t\"Desc\": \"raw 8-bit data\",\n\t\"Class\": \"8bit\",\n\t\"Comment\": \"special class for raw 8bit data that has been converted to utf-8\"\n},\n\"big5\": {\n\t\"Desc\": \"Big 5 (HKU)\",\n\t\"Class\": \"big5\",\n\t\"Comment\": \"Traditional Chinese\"\n},\n\"euc-jp\": {\n\t\"Aliases\":[\"x-euc-jp\"],\n\t\"Desc\": \"Japanese Extended UNIX Code\",\n\t\"Class\": \"euc-jp\"\n},\n\"gb2312\": {\n\t\"Aliases\":[\"iso-ir-58\", \"chinese\", \"gb_2312-80\"],\n\t\"Desc\": \"Chinese mixed one byte\",\n\t\"Class\": \"gb2312\"\n},\n\"ibm437\": {\n\t\"Aliases\":[\"437\", \"cp437\"],\n\t\"Desc\": \"IBM PC: CP 437\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"ibm437.cp\",\n\t\"Comment\": \"originally from jhelling@cs.ruu.nl (Jeroen Hellingman)\"\n},\n\"ibm850\": {\n\t\"Aliases\":[\"850\", \"cp850\"],\n\t\"Desc\": \"IBM PS/2: CP 850\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"ibm850.cp\",\n\t\"Comment\": \"originally from jhelling@cs.ruu.nl (Jeroen Hellingman)\"\n},\n\"ibm866\": {\n\t\"Aliases\":[\"cp866\", \"866\"],\n\t\"Desc\": \"Russian MS-DOS CP 866\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"ibm866.cp\"\n},\n\"iso-8859-1\": {\n\t\"Aliases\":[\"iso-ir-100\", \"ibm819\", \"l1\", \"iso8859-1\", \"iso-latin-1\", \"iso_8859-1:1987\", \"cp819\", \"iso_8859-1\", \"iso8859_1\", \"latin1\"],\n\t\"Desc\": \"Latin-1\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-1.cp\"\n},\n\"iso-8859-10\": {\n\t\"Aliases\":[\"iso_8859-10:1992\", \"l6\", \"iso-ir-157\", \"latin6\"],\n\t\"Desc\": \"Latin-6\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-10.cp\",\n\t\"Comment\": \"originally from dkuug.dk:i18n/charmaps/ISO_8859-10:1993\"\n},\n\"iso-8859-15\": {\n\t\"Aliases\":[\"l9-iso-8859-15\", \"latin9\"],\n\t\"Desc\": \"Latin-9\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-15.cp\"\n},\n\"iso-8859-2\": {\n\t\"Aliases\":[\"iso-ir-101\", \"iso_8859-2:1987\", \"l2\", \"iso_8859-2\", \"latin2\"],\n\t\"Desc\": \"Latin-2\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-2.cp\"\n},\n\"iso-8859-3\": {\n\t\"Aliases\":[\"iso-ir-109\", \"l3\", \"iso_8859-3:1988\", \"iso_8859-3\", \"latin3\"],\n\t\"Desc\": \"Latin-3\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-3.cp\"\n},\n\"iso-8859-4\": {\n\t\"Aliases\":[\"iso-ir-110\", \"iso_8859-4:1988\", \"l4\", \"iso_8859-4\", \"latin4\"],\n\t\"Desc\": \"Latin-4\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-4.cp\"\n},\n\"iso-8859-5\": {\n\t\"Aliases\":[\"cyrillic\", \"iso_8859-5\", \"iso-ir-144\", \"iso_8859-5:1988\"],\n\t\"Desc\": \"Part 5 (Cyrillic)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-5.cp\"\n},\n\"iso-8859-6\": {\n\t\"Aliases\":[\"ecma-114\", \"iso_8859-6:1987\", \"arabic\", \"iso_8859-6\", \"asmo-708\", \"iso-ir-127\"],\n\t\"Desc\": \"Part 6 (Arabic)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-6.cp\"\n},\n\"iso-8859-7\": {\n\t\"Aliases\":[\"greek8\", \"elot_928\", \"ecma-118\", \"greek\", \"iso_8859-7\", \"iso_8859-7:1987\", \"iso-ir-126\"],\n\t\"Desc\": \"Part 7 (Greek)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-7.cp\"\n},\n\"iso-8859-8\": {\n\t\"Aliases\":[\"iso_8859-8:1988\", \"hebrew\", \"iso_8859-8\", \"iso-ir-138\"],\n\t\"Desc\": \"Part 8 (Hebrew)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-8.cp\"\n},\n\"iso-8859-9\": {\n\t\"Aliases\":[\"l5\", \"iso_8859-9:1989\", \"iso_8859-9\", \"iso-ir-148\", \"latin5\"],\n\t\"Desc\": \"Latin-5\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-9.cp\"\n},\n\"koi8-r\": {\n\t\"Desc\": \"KOI8-R (RFC1489)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"koi8-r.cp\"\n},\n\"shift_jis\": {\n\t\"Aliases\":[\"sjis\", \"ms_kanji\", \"x-sjis\"],\n\t\"Desc\": \"Shift-JIS Japanese\",\n\t\"Class\": \"cp932\",\n\t\"Arg\": \"shiftjis\"\n},\n\"utf-16\": {\n\t\"Aliases\":[\"utf16\"],\n\t\"Desc\": \"Unicode UTF-16\",\n\t\"Class\": \"utf16\"\n},\n\"utf-16be\": {\n\t\"Aliases\":[\"utf16be\"],\n\t\"Desc\": \"Unicode UTF-16 big endian\",\n\t\"Class\": \"utf16\",\n\t\"Arg\": \"be\"\n},\n\"utf-16le\": {\n\t\"Aliases\":[\"utf16le\"],\n\t\"Desc\": \"Unicode UTF-16 little endian\",\n\t\"Class\": \"utf16\",\n\t\"Arg\": \"le\"\n},\n\"utf-8\": {\n\t\"Aliases\":[\"utf8\", \"ascii\", \"us-ascii\"],\n\t\"Desc\": \"Unicode UTF-8\",\n\t\"Class\": \"utf8\"\n},\n\"windows-1250\": {\n\t\"Desc\": \"MS Windows CP 1250 (Central Europe)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1250.cp\"\n},\n\"windows-1251\": {\n\t\"Desc\": \"MS Windows CP 1251 (Cyrillic)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1251.cp\"\n},\n\"windows-1252\": {\n\t\"Desc\": \"MS Windows CP 1252 (Latin 1)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1252.cp\"\n},\n\"windows-31j\": {\n\t\"Aliases\":[\"cp932\"],\n\t\"Desc\": \"MS Windows CP 932 (Japanese)\",\n\t\"Class\": \"cp932\",\n\t\"Arg\": \"cp932\"\n},\n\"windows-949\": {\n\t\"Aliases\":[\"cp949\", \"ms949\", \"uhc\"],\n\t\"Desc\": \"MS Windows CP 949 (Korean)\",\n\t\"Class\": \"cp949\"\n}\n}\n")
+		r := strings.NewReader("{\n\"8bit\": {\n\t\"Desc\": \"raw 8-bit data\",\n\t\"Class\": \"8bit\",\n\t\"Comment\": \"special class for raw 8bit data that has been converted to utf-8\"\n},\n\"big5\": {\n\t\"Desc\": \"Big 5 (HKU)\",\n\t\"Class\": \"big5\",\n\t\"Comment\": \"Traditional Chinese\"\n},\n\"big5-hkscs\": {\n\t\"Aliases\":[\"big5hkscs\", \"hkscs\"],\n\t\"Desc\": \"Big 5 with Hong Kong Supplementary Character Set\",\n\t\"Class\": \"big5-hkscs\",\n\t\"Comment\": \"Traditional Chinese (Hong Kong)\"\n},\n\"case\": {\n\t\"Desc\": \"ASCII letters folded to lower case, or with fold=upper to upper case\",\n\t\"Class\": \"case\"\n},\n\"cesu-8\": {\n\t\"Aliases\":[\"cesu8\", \"cscesu-8\"],\n\t\"Desc\": \"Unicode CESU-8 (UTF-8 with runes above U+FFFF as surrogate pairs)\",\n\t\"Class\": \"cesu8\"\n},\n\"euc-jp\": {\n\t\"Aliases\":[\"x-euc-jp\", \"eucjp\"],\n\t\"Desc\": \"Japanese Extended UNIX Code\",\n\t\"Class\": \"euc-jp\"\n},\n\"euc-kr\": {\n\t\"Aliases\":[\"cseuckr\", \"ksc5601\", \"ks_c_5601-1987\", \"korean\"],\n\t\"Desc\": \"Korean EUC-KR (KS X 1001)\",\n\t\"Class\": \"cp949\",\n\t\"Arg\": \"euc-kr\"\n},\n\"euc-tw\": {\n\t\"Aliases\":[\"euctw\", \"cns11643\", \"x-euc-tw\"],\n\t\"Desc\": \"Traditional Chinese EUC-TW (CNS 11643)\",\n\t\"Class\": \"euc-tw\"\n},\n\"gb18030\": {\n\t\"Desc\": \"Chinese GB 18030\",\n\t\"Class\": \"gb18030\"\n},\n\"gb2312\": {\n\t\"Aliases\":[\"iso-ir-58\", \"chinese\", \"gb_2312-80\", \"csgb2312\", \"euc-cn\", \"x-euc-cn\"],\n\t\"Desc\": \"Chinese mixed one byte\",\n\t\"Class\": \"gbk\",\n\t\"Arg\": \"gb2312\"\n},\n\"gbk\": {\n\t\"Aliases\":[\"cp936\", \"windows-936\", \"ms936\", \"csgbk\", \"x-gbk\"],\n\t\"Desc\": \"Chinese GBK (Windows code page 936)\",\n\t\"Class\": \"gbk\"\n},\n\"hz-gb-2312\": {\n\t\"Aliases\":[\"hz\", \"hz-gb2312\"],\n\t\"Desc\": \"Simplified Chinese HZ (RFC 1843)\",\n\t\"Class\": \"hz\"\n},\n\"identity\": {\n\t\"Aliases\":[\"binary\", \"x-binary\"],\n\t\"Desc\": \"Bytes passed through unchanged, in either direction\",\n\t\"Class\": \"identity\"\n},\n\"ibm437\": {\n\t\"Aliases\":[\"437\", \"cp437\"],\n\t\"Desc\": \"IBM PC: CP 437\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"ibm437.cp\",\n\t\"Comment\": \"originally from jhelling@cs.ruu.nl (Jeroen Hellingman)\"\n},\n\"ibm850\": {\n\t\"Aliases\":[\"850\", \"cp850\"],\n\t\"Desc\": \"IBM PS/2: CP 850\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"ibm850.cp\",\n\t\"Comment\": \"originally from jhelling@cs.ruu.nl (Jeroen Hellingman)\"\n},\n\"ibm866\": {\n\t\"Aliases\":[\"cp866\", \"866\"],\n\t\"Desc\": \"Russian MS-DOS CP 866\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"ibm866.cp\"\n},\n\"iso-2022-jp\": {\n\t\"Aliases\":[\"csiso2022jp\"],\n\t\"Desc\": \"Japanese ISO-2022-JP (RFC 1468)\",\n\t\"Class\": \"iso2022jp\"\n},\n\"iso-2022-kr\": {\n\t\"Aliases\":[\"csiso2022kr\"],\n\t\"Desc\": \"Korean ISO-2022-KR (RFC 1557)\",\n\t\"Class\": \"iso2022kr\"\n},\n\"iso-8859-1\": {\n\t\"Aliases\":[\"iso-ir-100\", \"ibm819\", \"l1\", \"iso8859-1\", \"iso-latin-1\", \"iso_8859-1:1987\", \"cp819\", \"iso_8859-1\", \"iso8859_1\", \"latin1\"],\n\t\"Desc\": \"Latin-1\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-1.cp\"\n},\n\"iso-8859-10\": {\n\t\"Aliases\":[\"iso_8859-10:1992\", \"l6\", \"iso-ir-157\", \"latin6\"],\n\t\"Desc\": \"Latin-6\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-10.cp\",\n\t\"Comment\": \"originally from dkuug.dk:i18n/charmaps/ISO_8859-10:1993\"\n},\n\"iso-8859-11\": {\n\t\"Aliases\":[\"iso_8859-11\", \"iso8859-11\", \"iso_8859-11:2001\"],\n\t\"Desc\": \"Part 11 (Thai)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-11.cp\"\n},\n\"iso-8859-13\": {\n\t\"Aliases\":[\"iso_8859-13\", \"iso-ir-179\", \"l7\", \"latin7\"],\n\t\"Desc\": \"Latin-7\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-13.cp\"\n},\n\"iso-8859-14\": {\n\t\"Aliases\":[\"iso_8859-14\", \"iso-ir-199\", \"iso_8859-14:1998\", \"l8\", \"latin8\", \"iso-celtic\"],\n\t\"Desc\": \"Latin-8\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-14.cp\"\n},\n\"iso-8859-15\": {\n\t\"Aliases\":[\"l9-iso-8859-15\", \"latin9\"],\n\t\"Desc\": \"Latin-9\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-15.cp\"\n},\n\"iso-8859-16\": {\n\t\"Aliases\":[\"iso_8859-16\", \"iso-ir-226\", \"iso_8859-16:2001\", \"l10\", \"latin10\"],\n\t\"Desc\": \"Latin-10\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-16.cp\"\n},\n\"iso-8859-2\": {\n\t\"Aliases\":[\"iso-ir-101\", \"iso_8859-2:1987\", \"l2\", \"iso_8859-2\", \"latin2\"],\n\t\"Desc\": \"Latin-2\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-2.cp\"\n},\n\"iso-8859-3\": {\n\t\"Aliases\":[\"iso-ir-109\", \"l3\", \"iso_8859-3:1988\", \"iso_8859-3\", \"latin3\"],\n\t\"Desc\": \"Latin-3\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-3.cp\"\n},\n\"iso-8859-4\": {\n\t\"Aliases\":[\"iso-ir-110\", \"iso_8859-4:1988\", \"l4\", \"iso_8859-4\", \"latin4\"],\n\t\"Desc\": \"Latin-4\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-4.cp\"\n},\n\"iso-8859-5\": {\n\t\"Aliases\":[\"cyrillic\", \"iso_8859-5\", \"iso-ir-144\", \"iso_8859-5:1988\"],\n\t\"Desc\": \"Part 5 (Cyrillic)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-5.cp\"\n},\n\"iso-8859-6\": {\n\t\"Aliases\":[\"ecma-114\", \"iso_8859-6:1987\", \"arabic\", \"iso_8859-6\", \"asmo-708\", \"iso-ir-127\"],\n\t\"Desc\": \"Part 6 (Arabic)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-6.cp\"\n},\n\"iso-8859-7\": {\n\t\"Aliases\":[\"greek8\", \"elot_928\", \"ecma-118\", \"greek\", \"iso_8859-7\", \"iso_8859-7:1987\", \"iso-ir-126\"],\n\t\"Desc\": \"Part 7 (Greek)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-7.cp\"\n},\n\"iso-8859-8\": {\n\t\"Aliases\":[\"iso_8859-8:1988\", \"hebrew\", \"iso_8859-8\", \"iso-ir-138\"],\n\t\"Desc\": \"Part 8 (Hebrew)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-8.cp\"\n},\n\"iso-8859-9\": {\n\t\"Aliases\":[\"l5\", \"iso_8859-9:1989\", \"iso_8859-9\", \"iso-ir-148\", \"latin5\"],\n\t\"Desc\": \"Latin-5\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"iso-8859-9.cp\"\n},\n\"johab\": {\n\t\"Aliases\":[\"cp1361\", \"ms1361\"],\n\t\"Desc\": \"Korean Johab (KS C 5601-1992 annex 3)\",\n\t\"Class\": \"johab\"\n},\n\"koi8-r\": {\n\t\"Aliases\":[\"cskoi8r\"],\n\t\"Desc\": \"KOI8-R (RFC1489)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"koi8-r.cp\"\n},\n\"koi8-u\": {\n\t\"Desc\": \"KOI8-U (RFC2319)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"koi8-u.cp\",\n\t\"Comment\": \"Ukrainian\"\n},\n\"macintosh\": {\n\t\"Aliases\":[\"mac-roman\", \"macroman\", \"mac\", \"csmacintosh\"],\n\t\"Desc\": \"Apple Mac OS Roman\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"macintosh.cp\",\n\t\"Comment\": \"the Apple logo at f0 has no standard Unicode mapping\"\n},\n\"modified-utf-8\": {\n\t\"Aliases\":[\"java-modified-utf-8\", \"mutf-8\", \"mutf8\"],\n\t\"Desc\": \"Java modified UTF-8 (CESU-8 with NUL as c0 80)\",\n\t\"Class\": \"cesu8\",\n\t\"Arg\": \"modified\"\n},\n\"newlines\": {\n\t\"Desc\": \"Line endings made uniform: LF, or CRLF or CR with the to option\",\n\t\"Class\": \"newlines\"\n},\n\"replacement\": {\n\t\"Aliases\":[\"iso-2022-cn\", \"iso-2022-cn-ext\"],\n\t\"Desc\": \"WHATWG replacement encoding, which decodes anything to U+FFFD\",\n\t\"Class\": \"replacement\"\n},\n\"shift_jis\": {\n\t\"Aliases\":[\"sjis\", \"ms_kanji\", \"x-sjis\"],\n\t\"Desc\": \"Shift-JIS Japanese\",\n\t\"Class\": \"cp932\",\n\t\"Arg\": \"shiftjis\"\n},\n\"strip-bom\": {\n\t\"Desc\": \"UTF-8 byte order mark removed from the start\",\n\t\"Class\": \"strip-bom\"\n},\n\"tis-620\": {\n\t\"Aliases\":[\"tis620\", \"tis620.2533\"],\n\t\"Desc\": \"Thai Industrial Standard 620-2533\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"tis-620.cp\"\n},\n\"us-ascii\": {\n\t\"Aliases\":[\"ascii\", \"ansi_x3.4-1968\", \"iso646-us\", \"csascii\"],\n\t\"Desc\": \"US-ASCII (7 bit)\",\n\t\"Class\": \"ascii\"\n},\n\"utf-16\": {\n\t\"Aliases\":[\"utf16\"],\n\t\"Desc\": \"Unicode UTF-16\",\n\t\"Class\": \"utf16\"\n},\n\"utf-16be\": {\n\t\"Aliases\":[\"utf16be\"],\n\t\"Desc\": \"Unicode UTF-16 big endian\",\n\t\"Class\": \"utf16\",\n\t\"Arg\": \"be\"\n},\n\"utf-16le\": {\n\t\"Aliases\":[\"utf16le\"],\n\t\"Desc\": \"Unicode UTF-16 little endian\",\n\t\"Class\": \"utf16\",\n\t\"Arg\": \"le\"\n},\n\"utf-32\": {\n\t\"Aliases\":[\"utf32\"],\n\t\"Desc\": \"Unicode UTF-32\",\n\t\"Class\": \"utf32\"\n},\n\"utf-32be\": {\n\t\"Aliases\":[\"utf32be\"],\n\t\"Desc\": \"Unicode UTF-32 big endian\",\n\t\"Class\": \"utf32\",\n\t\"Arg\": \"be\"\n},\n\"utf-32le\": {\n\t\"Aliases\":[\"utf32le\"],\n\t\"Desc\": \"Unicode UTF-32 little endian\",\n\t\"Class\": \"utf32\",\n\t\"Arg\": \"le\"\n},\n\"utf-7\": {\n\t\"Aliases\":[\"utf7\", \"csutf7\", \"unicode-1-1-utf-7\"],\n\t\"Desc\": \"Unicode UTF-7 (RFC 2152)\",\n\t\"Class\": \"utf7\"\n},\n\"utf-7-imap\": {\n\t\"Aliases\":[\"x-imap4-modified-utf7\"],\n\t\"Desc\": \"IMAP modified UTF-7 (RFC 3501)\",\n\t\"Class\": \"utf7\",\n\t\"Arg\": \"imap\"\n},\n\"utf-8\": {\n\t\"Aliases\":[\"utf8\"],\n\t\"Desc\": \"Unicode UTF-8\",\n\t\"Class\": \"utf8\"\n},\n\"viscii\": {\n\t\"Aliases\":[\"csviscii\"],\n\t\"Desc\": \"Vietnamese VISCII (RFC1456)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"viscii.cp\",\n\t\"Comment\": \"uses 02, 05, 06, 14, 19 and 1e for letters\"\n},\n\"width\": {\n\t\"Desc\": \"Fullwidth ASCII folded to ASCII, and halfwidth katakana to fullwidth\",\n\t\"Class\": \"width\"\n},\n\"windows-1250\": {\n\t\"Desc\": \"MS Windows CP 1250 (Central Europe)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1250.cp\"\n},\n\"windows-1251\": {\n\t\"Aliases\":[\"cp1251\"],\n\t\"Desc\": \"MS Windows CP 1251 (Cyrillic)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1251.cp\"\n},\n\"windows-1252\": {\n\t\"Aliases\":[\"cp1252\"],\n\t\"Desc\": \"MS Windows CP 1252 (Latin 1)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1252.cp\"\n},\n\"windows-1255\": {\n\t\"Aliases\":[\"cp1255\"],\n\t\"Desc\": \"MS Windows CP 1255 (Hebrew)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1255.cp\",\n\t\"Comment\": \"0xca is U+05BA, as in the WHATWG index\"\n},\n\"windows-1256\": {\n\t\"Aliases\":[\"cp1256\"],\n\t\"Desc\": \"MS Windows CP 1256 (Arabic)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"windows-1256.cp\"\n},\n\"windows-874\": {\n\t\"Aliases\":[\"cp874\", \"ms874\"],\n\t\"Desc\": \"MS Windows CP 874 (Thai)\",\n\t\"Class\": \"cp\",\n\t\"Arg\": \"tis-620.cp windows-874\",\n\t\"Comment\": \"TIS-620 with Windows extensions in 80..a0\"\n},\n\"windows-31j\": {\n\t\"Aliases\":[\"cp932\"],\n\t\"Desc\": \"MS Windows CP 932 (Japanese)\",\n\t\"Class\": \"cp932\",\n\t\"Arg\": \"cp932\"\n},\n\"windows-949\": {\n\t\"Aliases\":[\"cp949\", \"ms949\", \"uhc\"],\n\t\"Desc\": \"MS Windows CP 949 (Korean)\",\n\t\"Class\": \"cp949\"\n},\n\"x-user-defined\": {\n\t\"Desc\": \"WHATWG x-user-defined, mapping bytes 80..ff to U+F780..U+F7FF\",\n\t\"Class\": \"x-user-defined\"\n}\n}\n")
 		return ioutil.NopCloser(r), nil
 	})
 }
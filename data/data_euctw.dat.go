@@ -0,0 +1,18 @@
+// This file is automatically generated by generate-charset-data.
+// Do not hand-edit.
+
+package data
+
+import (
+	"github.com/suapapa/go-charset/charset"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+func init() {
+	charset.RegisterDataFile("euctw.dat", func() (io.ReadCloser, error) {
+		r := strings.NewReader("\xbeQ\x00,\x00\x00\x00J\u3000，、。．・；：？！︰…‥﹐﹑﹒·﹔﹕﹖﹗︱—︲–\x00\x1d\x00\xcb（）︵︶｛｝︷︸〔〕︹︺【】︻︼《》︽︾〈〉︿﹀「」﹁﹂『』﹃﹄﹙﹚﹛﹜﹝﹞‘’“”〝〞′‵＃＆＊※§〃○●△▲◎☆★◇◆□■▽▼㊣℅‾\x00b\x00\x03＿\x00d\x01\x8e﹉﹊﹍﹎﹋﹌﹟﹠﹡＋－×÷±√＜＞＝≦≧≠∞≒≡﹢﹣﹤﹦﹥∼∩∪⊥∠∟⊿㏒㏑∫∮∵∴♀♂♁☉↑↓→←↖↗↙↘‖｜／＼∕﹨＄￥〒￠￡％＠℃℉﹩﹪﹫㏕㎜㎝㎞㏎㎡㎎㎏㏄°兙兛兞兝兡兣嗧瓩糎▁▂▃▄▅▆▇█▏▎▍▌▋▊▉┼┴┬┤├▔─│▕┌┐└┘╭╮╰╯═╞╪╡◢◣◥◤╱╲╳\x01\x1a\x00W０１２３４５６７８９ⅠⅡⅢⅣⅤⅥⅦⅧⅨⅩ〡〢〣〤〥〦〧〨〩\x018\x00\x03卄\x01:\x01uＡＢＣＤＥＦＧＨＩＪＫＬＭＮＯＰＱＲＳＴＵＶＷＸＹＺａｂｃｄｅｆｇｈｉｊｋｌｍｎｏｐｑｒｓｔｕｖｗｘｙｚΑΒΓΔΕΖΗΘΙΚΛΜΝΞΟΠΡΣΤΥΦΧΨΩαβγδεζηθικλμνξοπρστυφχψωㄅㄆㄇㄈㄉㄊㄋㄌㄍㄎㄏㄐㄑㄒㄓㄔㄕㄖㄗㄘㄙㄚㄛㄜㄝㄞㄟㄠㄡㄢㄣㄤㄥㄦㄧㄨㄩ˙ˉˊˇˋ\x01\xd6\x00Z①②③④⑤⑥⑦⑧⑨⑩⑴⑵⑶⑷⑸⑹⑺⑻⑼⑽ⅰⅱⅲⅳⅴⅵⅶⅷⅸⅹ\x02;\x00\x03亠\x02B\x00\x03冫\x02G\x00\x03勹\f\x1e\x00c␀␁␂␃␄␅␆␇␈␉␊␋␌␍␎␏␐␑␒␓␔␕␖␗␘␙␚␛␜␝␞␟␡\f\xda?K一乙丁七乃九了二人儿入八几刀刁力匕十卜又三下丈上丫丸凡久么也乞于亡兀刃勺千叉口土士夕大女子孑孓寸小尢尸山川工己已巳巾干廾弋弓才丑丐不中丰丹之尹予云井互五亢仁什仃仆仇仍今介仄元允內六兮公冗凶分切刈勻勾勿化匹午升卅卞厄友及反壬天夫太夭孔少尤尺屯巴幻廿弔引心戈戶手扎支文斗斤方日曰月木欠止歹毋比毛氏水火爪父爻片牙牛犬王丙世丕且丘主乍乏乎以付仔仕他仗代令仙仞充兄冉冊冬凹出凸刊加功包匆北匝仟半卉卡占卯卮去可古右召叮叩叨叼司叵叫另只史叱台句叭叻四囚外央失奴奶孕它尼巨巧左市布平幼弁弘弗必戊打扔扒扑斥旦朮本未末札正母民氐永汁汀氾犯玄玉瓜瓦甘生用甩田由甲申疋白皮皿目矛矢石示禾穴立丞丟乒乓乩亙交亦亥仿伉伙伊伕伍伐休伏仲件任仰仳份企伋光兇兆先全共再冰列刑划刎刖劣匈匡匠印危吉吏同吊吐吁吋各向名合吃后吆吒因回囝圳地在圭圬圯圩夙多夷夸妄奸妃好她如妁字存宇守宅安寺尖屹州帆并年式弛忙忖戎戌戍成扣扛托收早旨旬旭曲曳有朽朴朱朵次此死氖汝汗汙江池汐汕污汛汍汎灰牟牝百竹米糸缶羊羽老考而耒耳聿肉肋肌臣自至臼舌舛舟艮色艾虫血行衣西阡串亨位住佇佗佞伴佛何估佐佑伽伺伸佃佔似但佣作你伯低伶余佝佈佚兌克免兵冶冷別判利刪刨劫助努劬匣即卵吝吭吞吾否呎吧呆呃吳呈呂君吩告吹吻吸吮吵吶吠吼呀吱含吟听囪困囤囫坊坑址坍均坎圾坐坏圻壯夾妝妒妨妞妣妙妖妍妤妓妊妥孝孜孚孛完宋宏尬局屁尿尾岐岑岔岌巫希序庇床廷弄弟彤形彷役忘忌志忍忱快忸忪戒我抄抗抖技扶抉扭把扼找批扳抒扯折扮投抓抑抆改攻攸旱更束李杏材村杜杖杞杉杆杠杓杗步每求汞沙沁沈沉沅沛汪決沐汰沌汨沖沒汽沃汲汾汴沆汶沍沔沘沂灶灼災灸牢牡牠狄狂玖甬甫男甸皂盯矣私秀禿究系罕肖肓肝肘肛肚育良芒芋芍見角言谷豆豕貝赤走足身車辛辰迂迆迅迄巡邑邢邪邦那酉釆里防阮阱阪阬並乖乳事些亞享京佯依侍佳使佬供例來侃佰併侈佩佻侖佾侏侑佺兔兒兕兩具其典冽函刻券刷刺到刮制剁劾劻卒協卓卑卦卷卸卹取叔受味呵咖呸咕咀呻呷咄咒咆呼咐呱呶和咚呢周咋命咎固垃坷坪坩坡坦坤坼夜奉奇奈奄奔妾妻委妹妮姑姆姐姍始姓姊妯妳姒姅孟孤季宗定官宜宙宛尚屈居屆岷岡岸岩岫岱岳帘帚帖帕帛帑幸庚店府底庖延弦弧弩往征彿彼忝忠忽念忿怏怔怯怵怖怪怕怡性怩怫怛或戕房戾所承拉拌拄抿拂抹拒招披拓拔拋拈抨抽押拐拙拇拍抵拚抱拘拖拗拆抬拎放斧於旺昔易昌昆昂明昀昏昕昊昇服朋杭枋枕東果杳杷枇枝林杯杰板枉松析杵枚枓杼杪杲欣武歧歿氓氛泣注泳沱泌泥河沽沾沼波沫法泓沸泄油況沮泗泅泱沿治泡泛泊沬泯泜泖泠炕炎炒炊炙爬爭爸版牧物狀狎狙狗狐玩玨玟玫玥甽疝疙疚的盂盲直知矽社祀祁秉秈空穹竺糾罔羌羋者肺肥肢肱股肫肩肴肪肯臥臾舍芳芝芙芭芽芟芹花芬芥芯芸芣芰芾芷虎虱初表軋迎返近邵邸邱邶采金長門阜陀阿阻附陂隹雨青非亟亭亮信侵侯便俠俑俏保促侶俘俟俊俗侮俐俄係俚俎俞侷兗冒冑冠剎剃削前剌剋則勇勉勃勁匍南卻厚叛咬哀咨哎哉咸咦咳哇哂咽咪品哄哈咯咫咱咻咩咧咿囿垂型垠垣垢城垮垓奕契奏奎奐姜姘姿姣姨娃姥姪姚姦威姻孩宣宦室客宥封屎屏屍屋峙峒巷帝帥帟幽庠度建弈弭彥很待徊律徇後徉怒思怠急怎怨恍恰恨恢恆恃恬恫恪恤扁拜挖按拼拭持拮拽指拱拷拯括拾拴挑挂政故斫施既春昭映昧是星昨昱昤曷柿染柱柔某柬架枯柵柩柯柄柑枴柚查枸柏柞柳枰柙柢柝柒歪殃殆段毒毗氟泉洋洲洪流津洌洱洞洗活洽派洶洛泵洹洧洸洩洮洵洎洫炫為炳炬炯炭炸炮炤爰牲牯牴狩狠狡玷珊玻玲珍珀玳甚甭畏界畎畋疫疤疥疢疣癸皆皇皈盈盆盃盅省盹相眉看盾盼眇矜砂研砌砍祆祉祈祇禹禺科秒秋穿突竿竽籽紂紅紀紉紇約紆缸美羿耐耍耑耶胖胥胚胃胄背胡胛胎胞胤胝致舢苧范茅苣苛苦茄若茂茉苒苗英茁苜苔苑苞苓苟苯茆虐虹虻虺衍衫要觔計訂訃貞負赴赳趴軍軌述迦迢迪迥迭迫迤迨郊郎郁郃酋酊重閂限陋陌降面革韋韭音頁風飛食首香乘亳倌倍倣俯倦倥俸倩倖倆值借倚倒們俺倀倔倨俱倡個候倘俳修倭倪俾倫倉兼冤冥冢凍凌准凋剖剜剔剛剝匪卿原厝叟哨唐唁唷哼哥哲唆哺唔哩哭員唉哮哪哦唧唇哽唏圃圄埂埔埋埃堉夏套奘奚娑娘娜娟娛娓姬娠娣娩娥娌娉孫屘宰害家宴宮宵容宸射屑展屐峭峽峻峪峨峰島崁峴差席師庫庭座弱徒徑徐恙恣恥恐恕恭恩息悄悟悚悍悔悌悅悖扇拳挈拿捎挾振捕捂捆捏捉挺捐挽挪挫挨捍捌效敉料旁旅時晉晏晃晒晌晅晁書朔朕朗校核案框桓根桂桔栩梳栗桌桑栽柴桐桀格桃株桅栓栘桁殊殉殷氣氧氨氦氤泰浪涕消涇浦浸海浙涓浬涉浮浚浴浩涌涊浹涅浥涔烊烘烤烙烈烏爹特狼狹狽狸狷玆班琉珮珠珪珞畔畝畜畚留疾病症疲疳疽疼疹痂疸皋皰益盍盎眩真眠眨矩砰砧砸砝破砷砥砭砠砟砲祕祐祠祟祖神祝祗祚秤秣秧租秦秩秘窄窈站笆笑粉紡紗紋紊素索純紐紕級紜納紙紛缺罟羔翅翁耆耄耘耕耙耗耽耿胱脂胰脅胭胴脆胸胳脈能脊胼胯臭臬舀舐航舫舨般芻茫荒荔荊茸荐草茵茴荏茲茹茶茗荀茱茨荃虔蚊蚪蚓蚤蚩蚌蚣蚜衰衷袁袂衽衹記訐討訌訕訊託訓訖訏訑豈豺豹財貢起躬軒軔軏辱送逆迷退迺迴逃追逅迸邕郡郝郢酒配酌釘針釗釜釙閃院陣陡陛陝除陘陞隻飢馬骨高鬥鬲鬼乾偺偽停假偃偌做偉健偶偎偕偵側偷偏倏偯偭兜冕凰剪副勒務勘動匐匏匙匿區匾參曼商啪啦啄啞啡啃啊唱啖問啕唯啤唸售啜唬啣唳啁啗圈國圉域堅堊堆埠埤基堂堵執培夠奢娶婁婉婦婪婀娼婢婚婆婊孰寇寅寄寂宿密尉專將屠屜屝崇崆崎崛崖崢崑崩崔崙崤崧崗巢常帶帳帷康庸庶庵庾張強彗彬彩彫得徙從徘御徠徜恿患悉悠您惋悴惦悽情悻悵惜悼惘惕惆惟悸惚惇戚戛扈掠控捲掖探接捷捧掘措捱掩掉掃掛捫推掄授掙採掬排掏掀捻捩捨捺敝敖救教敗啟敏敘敕敔斜斛斬族旋旌旎晝晚晤晨晦晞曹勗望梁梯梢梓梵桿桶梱梧梗械梃棄梭梆梅梔條梨梟梡梂欲殺毫毬氫涎涼淳淙液淡淌淤添淺清淇淋涯淑涮淞淹涸混淵淅淒渚涵淚淫淘淪深淮淨淆淄涪淬涿淦烹焉焊烽烯爽牽犁猜猛猖猓猙率琅琊球理現琍瓠瓶瓷甜產略畦畢異疏痔痕疵痊痍皎盔盒盛眷眾眼眶眸眺硫硃硎祥票祭移窒窕笠笨笛第符笙笞笮粒粗粕絆絃統紮紹紼絀細紳組累終紲紱缽羞羚翌翎習耜聊聆脯脖脣脫脩脰脤舂舵舷舶船莎莞莘荸莢莖莽莫莒莊莓莉莠荷荻荼莆莧處彪蛇蛀蚶蛄蚵蛆蛋蚱蚯蛉術袞袈被袒袖袍袋覓規訪訝訣訥許設訟訛訢豉豚販責貫貨貪貧赧赦趾趺軛軟這逍通逗連速逝逐逕逞造透逢逖逛途部郭都酗野釵釦釣釧釭釩閉陪陵陳陸陰陴陶陷陬雀雪雩章竟頂頃魚鳥鹵鹿麥麻傢傍傅備傑傀傖傘傚最凱割剴創剩勞勝勛博厥啻喀喧啼喊喝喘喂喜喪喔喇喋喃喳單喟唾喲喚喻喬喱啾喉喫喙圍堯堪場堤堰報堡堝堠壹壺奠婷媚婿媒媛媧孳孱寒富寓寐尊尋就嵌嵐崴嵇巽幅帽幀幃幾廊廁廂廄弼彭復循徨惑惡悲悶惠愜愣惺愕惰惻惴慨惱愎惶愉愀愒戟扉掣掌描揀揩揉揆揍插揣提握揖揭揮捶援揪換摒揚揹敞敦敢散斑斐斯普晰晴晶景暑智晾晷曾替期朝棺棕棠棘棗椅棟棵森棧棹棒棲棣棋棍植椒椎棉棚楮棻款欺欽殘殖殼毯氮氯氬港游湔渡渲湧湊渠渥渣減湛湘渤湖湮渭渦湯渴湍渺測湃渝渾滋溉渙湎湣湄湲湩湟焙焚焦焰無然煮焜牌犄犀猶猥猴猩琺琪琳琢琥琵琶琴琯琛琦琨甥甦畫番痢痛痣痙痘痞痠登發皖皓皴盜睏短硝硬硯稍稈程稅稀窘窗窖童竣等策筆筐筒答筍筋筏筑粟粥絞結絨絕紫絮絲絡給絢絰絳善翔翕耋聒肅腕腔腋腑腎脹腆脾腌腓腴舒舜菩萃菸萍菠菅萋菁華菱菴著萊菰萌菌菽菲菊萸萎萄菜萇菔菟虛蛟蛙蛭蛔蛛蛤蛐蛞街裁裂袱覃視註詠評詞証詁詔詛詐詆訴診訶詖象貂貯貼貳貽賁費賀貴買貶貿貸越超趁跎距跋跚跑跌跛跆軻軸軼辜逮逵週逸進逶鄂郵鄉郾酣酥量鈔鈕鈣鈉鈞鈍鈐鈇鈑閔閏開閑間閒閎隊階隋陽隅隆隍陲隄雁雅雄集雇雯雲韌項順須飧飪飯飩飲飭馮馭黃黍黑亂傭債傲傳僅傾催傷傻傯僇剿剷剽募勦勤勢勣匯嗟嗨嗓嗦嗎嗜嗇嗑嗣嗤嗯嗚嗡嗅嗆嗥嗉園圓塞塑塘塗塚塔填塌塭塊塢塒塋奧嫁嫉嫌媾媽媼媳嫂媲嵩嵯幌幹廉廈弒彙徬微愚意慈感想愛惹愁愈慎慌慄慍愾愴愧愍愆愷戡戢搓搾搞搪搭搽搬搏搜搔損搶搖搗搆敬斟新暗暉暇暈暖暄暘暍會榔業楚楷楠楔極椰概楊楨楫楞楓楹榆楝楣楛歇歲毀殿毓毽溢溯滓溶滂源溝滇滅溥溘溼溺溫滑準溜滄滔溪溧溴煎煙煩煤煉照煜煬煦煌煥煞煆煨煖爺牒猷獅猿猾瑯瑚瑕瑟瑞瑁琿瑙瑛瑜當畸瘀痰瘁痲痱痺痿痴痳盞盟睛睫睦睞督睹睪睬睜睥睨睢矮碎碰碗碘碌碉硼碑碓硿祺祿禁萬禽稜稚稠稔稟稞窟窠筷節筠筮筧粱粳粵經絹綑綁綏絛置罩罪署義羨群聖聘肆肄腱腰腸腥腮腳腫腹腺腦舅艇蒂葷落萱葵葦葫葉葬葛萼萵葡董葩葭葆虞虜號蛹蜓蜈蜇蜀蛾蛻蜂蜃蜆蜊衙裟裔裙補裘裝裡裊裕裒覜解詫該詳試詩詰誇詼詣誠話誅詭詢詮詬詹詻訾詨豢貊貉賊資賈賄貲賃賂賅跡跟跨路跳跺跪跤跦躲較載軾輊辟農運遊道遂達逼違遐遇遏過遍遑逾遁鄒鄗酬酪酩釉鈷鉗鈸鈽鉀鈾鉛鉋鉤鉑鈴鉉鉍鉅鈹鈿鉚閘隘隔隕雍雋雉雊雷電雹零靖靴靶預頑頓頊頒頌飼飴飽飾馳馱馴髡鳩麂鼎鼓鼠僧僮僥僖僭僚僕像僑僱僎僩兢凳劃劂匱厭嗾嘀嘛嘗嗽嘔嘆嘉嘍嘎嗷嘖嘟嘈嘐嗶團圖塵塾境墓墊塹墅塽壽夥夢夤奪奩嫡嫦嫩嫗嫖嫘嫣孵寞寧寡寥實寨寢寤察對屢嶄嶇幛幣幕幗幔廓廖弊彆彰徹慇愿態慷慢慣慟慚慘慵截撇摘摔撤摸摟摺摑摧搴摭摻敲斡旗旖暢暨暝榜榨榕槁榮槓構榛榷榻榫榴槐槍榭槌榦槃榣歉歌氳漳演滾漓滴漩漾漠漬漏漂漢滿滯漆漱漸漲漣漕漫漯澈漪滬漁滲滌滷熔熙煽熊熄熒爾犒犖獄獐瑤瑣瑪瑰瑭甄疑瘧瘍瘋瘉瘓盡監瞄睽睿睡磁碟碧碳碩碣禎福禍種稱窪窩竭端管箕箋筵算箝箔箏箸箇箄粹粽精綻綰綜綽綾綠緊綴網綱綺綢綿綵綸維緒緇綬罰翠翡翟聞聚肇腐膀膏膈膊腿膂臧臺與舔舞艋蓉蒿蓆蓄蒙蒞蒲蒜蓋蒸蓀蓓蒐蒼蓑蓊蜿蜜蜻蜢蜥蜴蜘蝕蜷蜩裳褂裴裹裸製裨褚裯誦誌語誣認誡誓誤說誥誨誘誑誚誧豪貍貌賓賑賒赫趙趕跼輔輒輕輓辣遠遘遜遣遙遞遢遝遛鄙鄘鄞酵酸酷酴鉸銬銀銅銘銖鉻銓銜銨鉼銑閡閨閩閣閥閤隙障際雌雒需靼鞅韶頗領颯颱餃餅餌餉駁骯骰髦魁魂鳴鳶鳳麼鼻齊億儀僻僵價儂儈儉儅凜劇劈劉劍劊勰厲嘮嘻嘹嘲嘿嘴嘩噓噎噗噴嘶嘯嘰墀墟增墳墜墮墩墦奭嬉嫻嬋嫵嬌嬈寮寬審寫層履嶝嶔幢幟幡廢廚廟廝廣廠彈影德徵慶慧慮慝慕憂慼慰慫慾憧憐憫憎憬憚憤憔憮戮摩摯摹撞撲撈撐撰撥撓撕撩撒撮播撫撚撬撙撢撳敵敷數暮暫暴暱樣樟槨樁樞標槽模樓樊槳樂樅槭樑歐歎殤毅毆漿潼澄潑潦潔澆潭潛潸潮澎潺潰潤澗潘滕潯潠潟熟熬熱熨牖犛獎獗瑩璋璃瑾璀畿瘠瘩瘟瘤瘦瘡瘢皚皺盤瞎瞇瞌瞑瞋磋磅確磊碾磕碼磐稿稼穀稽稷稻窯窮箭箱範箴篆篇篁箠篌糊締練緯緻緘緬緝編緣線緞緩綞緙緲緹罵罷羯翩耦膛膜膝膠膚膘蔗蔽蔚蓮蔬蔭蔓蔑蔣蔡蔔蓬蔥蓿蔆螂蝴蝶蝠蝦蝸蝨蝙蝗蝌蝓衛衝褐複褒褓褕褊誼諒談諄誕請諸課諉諂調誰論諍誶誹諛豌豎豬賠賞賦賤賬賭賢賣賜質賡赭趟趣踫踐踝踢踏踩踟踡踞躺輝輛輟輩輦輪輜輞輥適遮遨遭遷鄰鄭鄧鄱醇醉醋醃鋅銻銷鋪鋤鋁銳銼鋒鋇鋰銲閭閱霄霆震霉靠鞍鞋鞏頡頫頜颳養餓餒餘駝駐駟駛駑駕駒駙骷髮髯鬧魅魄魷魯鴆鴉鴃麩麾黎墨齒儒儘儔儐儕冀冪凝劑劓勳噙噫噹噩噤噸噪器噥噱噯噬噢噶壁墾壇壅奮嬝嬴學寰導彊憲憑憩憊懍憶憾懊懈戰擅擁擋撻撼據擄擇擂操撿擒擔撾整曆曉暹曄曇暸樽樸樺橙橫橘樹橄橢橡橋橇樵機橈歙歷氅濂澱澡濃澤濁澧澳激澹澶澦澠澴熾燉燐燒燈燕熹燎燙燜燃燄獨璜璣璘璟璞瓢甌甍瘴瘸瘺盧盥瞠瞞瞟瞥磨磚磬磧禦積穎穆穌穋窺篙簑築篤篛篡篩篦糕糖縊縑縈縛縣縞縝縉縐罹羲翰翱翮耨膳膩膨臻興艘艙蕊蕙蕈蕨蕩蕃蕉蕭蕪蕞螃螟螞螢融衡褪褲褥褫褡親覦諦諺諫諱謀諜諧諮諾謁謂諷諭諳諶諼豫豭貓賴蹄踱踴蹂踹踵輻輯輸輳辨辦遵遴選遲遼遺鄴醒錠錶鋸錳錯錢鋼錫錄錚錐錦錡錕錮錙閻隧隨險雕霎霑霖霍霓霏靛靜靦鞘頰頸頻頷頭頹頤餐館餞餛餡餚駭駢駱骸骼髻髭鬨鮑鴕鴣鴦鴨鴒鴛默黔龍龜優償儡儲勵嚎嚀嚐嚅嚇嚏壕壓壑壎嬰嬪嬤孺尷屨嶼嶺嶽嶸幫彌徽應懂懇懦懋戲戴擎擊擘擠擰擦擬擱擢擭斂斃曙曖檀檔檄檢檜櫛檣橾檗檐檠歜殮毚氈濘濱濟濠濛濤濫濯澀濬濡濩濕濮濰燧營燮燦燥燭燬燴燠爵牆獰獲璩環璦璨癆療癌盪瞳瞪瞰瞬瞧瞭矯磷磺磴磯礁禧禪穗窿簇簍篾篷簌篠糠糜糞糢糟糙糝縮績繆縷縲繃縫總縱繅繁縴縹繈縵縿縯罄翳翼聱聲聰聯聳臆臃膺臂臀膿膽臉膾臨舉艱薪薄蕾薜薑薔薯薛薇薨薊薦虧蟀蟑螳蟒蟆螫螻螺蟈蟋褻褶襄褸褽覬謎謗謙講謊謠謝謄謐豁谿豳賺賽購賸賻趨蹉蹋蹈蹊轄輾轂轅輿避遽還邁邂邀鄹醣醞醜鍍鎂錨鍵鍊鍥鍋錘鍾鍬鍛鍰鍚鍔闊闋闌闈闆隱隸雖霜霞鞠韓顆颶餵騁駿鮮鮫鮪鮭鴻鴿麋黏點黜黝黛鼾齋叢嚕嚮壙壘嬸彞懣戳擴擲擾攆擺擻擷斷曜朦檳檬櫃檻檸櫂檮檯歟歸殯瀉瀋濾瀆濺瀑瀏燻燼燾燸獷獵璧璿甕癖癘癒瞽瞿瞻瞼礎禮穡穢穠竄竅簫簧簪簞簣簡糧織繕繞繚繡繒繙罈翹翻職聶臍臏舊藏薩藍藐藉薰薺薹蟯蟬蟲蟠覆覲觴謨謹謬謫豐贅蹙蹣蹦蹤蹟蹕軀轉轍邇邃邈醫醬釐鎔鎊鎖鎢鎳鎮鎬鎰鎘鎚鎗闔闖闐闕離雜雙雛雞霤鞣鞦鞭韹額顏題顎顓颺餾餿餽餮馥騎髁鬃鬆魏魎魍鯊鯉鯽鯈鯀鵑鵝鵠黠鼕鼬儳嚥嚨壞壟壢寵龐廬懲懷懶懵攀攏曠曝櫥櫝櫚櫓瀛瀟瀨瀚瀝瀕瀘爆爍牘犢獸獺璽瓊瓣疇疆癟癡矇礙禱穫穩簾簿簸簽簷籀繫繭繹繩繪繳羅羶羹羸臘藩藝藪藕藤藥藷蟻蠅蠍蟹蟾襠襟襖襞譁譜識證譚譎譏譆譙贈贊蹼蹲躇蹶蹬蹺蹴轔轎辭邊邋醱醮鏡鏑鏟鏃鏈鏜鏝鏖鏢鏍鏘鏤鏗鏨關隴難霪霧靡韜韻類願顛颼饅饉騖騙鬍鯨鯧鯖鯛鶉鵡鵲鵪鵬麒麗麓麴勸嚷嚶嚴嚼壤孀孃孽寶巉懸懺攘攔攙曦朧櫬瀾瀰瀲爐獻瓏癢癥礦礪礬礫竇競籌籃籍糯糰辮繽繼纂罌耀臚艦藻藹蘑藺蘆蘋蘇蘊蠔蠕襤覺觸議譬警譯譟譫贏贍躉躁躅躂醴釋鐘鐃鏽闡霰飄饒饑馨騫騰騷騵鰓鰍鹹麵黨鼯齟齣齡儷儸囁囀囂夔屬巍懼懾攝攜斕曩櫻欄櫺殲灌爛犧瓖瓔癩矓籐纏續羼蘗蘭蘚蠣蠢蠡蠟襪襬覽譴護譽贓躊躍躋轟辯醺鐮鐳鐵鐺鐸鐲鐫闢霸霹露響顧顥饗驅驃驀騾髏魔魑鰭鰥鶯鶴鷂鶸麝黯鼙齜齦齧儼儻囈囊囉孿巔巒彎懿攤權歡灑灘玀瓤疊癮癬禳籠籟聾聽臟襲襯觼讀贖贗躑躓轡酈鑄鑑鑒霽霾韃韁顫饕驕驍髒鬚鱉鰱鰾鰻鷓鷗鼴齬齪龔囌巖戀攣攫攪曬欐瓚竊籤籣籥纓纖纔臢蘸蘿蠱變邐邏鑣鑠鑤靨顯饜驚驛驗髓體髑鱔鱗鱖鷥麟黴囑壩攬灞癱癲矗罐羈蠶蠹衢讓讒讖艷贛釀鑪靂靈靄韆顰驟鬢魘鱟鷹鷺鹼鹽鼇齷齲廳欖灣籬籮蠻觀躡釁鑲鑰顱饞髖鬣黌灤矚讚鑷韉驢驥纜讜躪釅鑽鑾鑼鱷鱸黷豔鑿鸚爨驪鬱鸛鸞籲\"\x84Y\xa6乂乜凵匚厂万丌乇亍囗屮彳丏冇与丮亓仂仉仈冘勼卬厹圠夃夬尐巿旡殳毌气爿丱丼仨仜仩仡仝仚刌刉匜卌圢圣夗夯宁宄尒尻屴屳帄庀庂忉戉扐氕氶汃氿氻犮犰玊禸肊阞伎优伬仵伔仱伀价伈伝伂伅伢伓伄仴伒冱刓刐劦匢匟卍厊吇囡囟圮圪圴夼妀奼妅奻奾奷奿孖尕尥屼屺屻屾巟幵庄异弚彴忕忔忏扜扞扤扡扦扢扙扠扚扥攷旯旮朾朹朸朻机朿朼朳氘汆汒汜汏汊汔汋汌灱牞犴犵玎甪癿穵网艸艼芀艽艿虍襾邙邗邘邛邔阢阤阠阣佖伻佢佉体佤伾佧佒佟佁佘伭伳伿佡冏冹刜刞刡劭劮匉卣卲厎厏吰吷吪呔呅吙吜吥吘吽呏呁吨吤呇囮囧囥坁坅坌坉坋坒夆奀妦妘妠妗妎妢妐妏妧妡宎宒尨尪岍岏岈岋岉岒岊岆岓岕巠帊帎庋庉庌庈庍弅弝彸彶忒忑忐忭忨忮忳忡忤忣忺忯忷忻怀忴戺抃抌抎抏抔抇扱扻扺扰抁抈扷扽扲扴旰旴旳旲旵杅杇杙杕杌杈杝杍杚杋毐氙氚汸汧汫沄沋沏汱汯汩沚汭沇沕沜汦汳汥汻沎灴灺牣犿犽狃狆狁犺狅玕玗玓玔玒町甹疔疕皁礽耴肕肙肐肒肜芐芏芅芎芑芓芊芃芄豸迉辿邟邡邥邞邧邠阰阨阯阭丳侘佼侅佽侀侇佶佴侉侄佷佌侗佪侚佹侁佸侐侜侔侞侒侂侕佫佮冞冼冾刵刲刳剆刱劼匊匋匼厒厔咇呿咁咑咂咈呫呺呾呥呬呴呦咍呯呡呠咘呣呧呤囷囹坯坲坭坫坱坰坶垀坵坻坳坴坢坨坽夌奅妵妺姏姎妲姌姁妶妼姃姖妱妽姀姈妴姇孢孥宓宕屄屇岮岤岠岵岯岨岬岟岣岭岢岪岧岝岥岶岰岦帗帔帙弨弢弣弤彔徂彾彽忞忥怭怦怙怲怋怴怊怗怳怚怞怬怢怍怐怮怓怑怌怉怜戔戽抭抴拑抾抪抶拊抮抳抯抻抩抰抸攽斨斻昉旼昄昒昈旻昃昋昍昅旽昑昐曶朊枅杬枎枒杶杻枘枆构杴枍枌杺枟枑枙枃杽极杸杹枔欥殀歾毞氝沓泬泫泮泙沶泔沭泧沷泐泂沺泃泆泭泲泒泝沴沊沝沀泞泀洰泍泇沰泹泏泩泑炔炘炅炓炆炄炑炖炂炚炃牪狖狋狘狉狜狒狔狚狌狑玤玡玭玦玢玠玬玝瓝瓨甿畀甾疌疘皯盳盱盰盵矸矼矹矻矺矷祂礿秅穸穻竻籵糽耵肏肮肣肸肵肭舠芠苀芫芚芘芛芵芧芮芼芞芺芴芨芡芩苂芤苃芶芢虰虯虭虮豖迒迋迓迍迖迕迗邲邴邯邳邰阹阽阼阺陃俍俅俓侲俉俋俁俔俜俙侻侳俛俇俖侺俀侹俬剄剉勀勂匽卼厗厖厙厘咺咡咭咥哏哃茍咷咮哖咶哅哆咠呰咼咢咾呲哞咰垵垞垟垤垌垗垝垛垔垘垏垙垥垚垕壴复奓姡姞姮娀姱姝姺姽姼姶姤姲姷姛姩姳姵姠姾姴姭宨屌峐峘峌峗峋峛峞峚峉峇峊峖峓峔峏峈峆峎峟峸巹帡帢帣帠帤庰庤庢庛庣庥弇弮彖徆怷怹恔恲恞恅恓恇恉恛恌恀恂恟怤恄恘恦恮扂扃拏挍挋拵挎挃拫拹挏挌拸拶挀挓挔拺挕拻拰敁敃斪斿昶昡昲昵昜昦昢昳昫昺昝昴昹昮朏朐柁柲柈枺柜枻柸柘柀枷柅柫柤柟枵柍枳柷柶柮柣柂枹柎柧柰枲柼柆柭柌枮柦柛柺柉柊柃柪柋欨殂殄殶毖毘毠氠氡洨洴洭洟洼洿洒洊泚洳洄洙洺洚洑洀洝浂洁洘洷洃洏浀洇洠洬洈洢洉洐炷炟炾炱炰炡炴炵炩牁牉牊牬牰牳牮狊狤狨狫狟狪狦狣玅珌珂珈珅玹玶玵玴珫玿珇玾珃珆玸珋瓬瓮甮畇畈疧疪癹盄眈眃眄眅眊盷盻盺矧矨砆砑砒砅砐砏砎砉砃砓祊祌祋祅祄秕种秏秖秎窀穾竑笀笁籺籸籹籿粀粁紃紈紁罘羑羍羾耇耎耏耔耷胘胇胠胑胈胂胐胅胣胙胜胊胕胉胏胗胦胍臿舡芔苙苾苹茇苨茀苕茺苫苖苴苬苡苲苵茌苻苶苰苪苤苠苺苳苭虷虴虼虳衁衎衧衪衩觓訄訇赲迣迡迮迠郱邽邿郕郅邾郇郋郈釔釓陔陏陑陓陊陎倞倅倇倓倢倰倛俵俴倳倷倬俶俷倗倜倠倧倵倯倱倎党冔冓凊凄凅凈凎剡剚剒剞剟剕剢勍匎厞唦哢唗唒哧哳哤唚哿唄唈哫唑唅哱唊哻哷哸哠唎唃唋圁圂埌堲埕埒垺埆垽垼垸垶垿埇埐垹埁夎奊娙娖娭娮娕娏娗娊娞娳孬宧宭宬尃屖屔峬峿峮峱峷崀峹帩帨庨庮庪庬弳弰彧恝恚恧恁悢悈悀悒悁悝悃悕悛悗悇悜悎戙扆拲挐捖挬捄捅挶捃揤挹捋捊挼挩捁挴捘捔捙挭捇挳捚捑挸捗捀捈敊敆旆旃旄旂晊晟晇晑朒朓栟栚桉栲栳栻桋桏栖栱栜栵栫栭栯桎桄栴栝栒栔栦栨栮桍栺栥栠欬欯欭欱欴歭肂殈毦毤毨毣毢毧氥浺浣浤浶洍浡涒浘浢浭浯涑涍淯浿涆浞浧浠涗浰浼浟涂涘洯浨涋浾涀涄洖涃浻浽浵涐烜烓烑烝烋缹烢烗烒烞烠烔烍烅烆烇烚烎烡牂牸牷牶猀狺狴狾狶狳狻猁珓珙珥珖玼珧珣珩珜珒珛珔珝珚珗珘珨瓞瓟瓴瓵甡畛畟疰痁疻痄痀疿疶疺皊盉眝眛眐眓眒眣眑眕眙眚眢眧砣砬砢砵砯砨砮砫砡砩砳砪砱祔祛祏祜祓祒祑秫秬秠秮秭秪秜秞秝窆窉窅窋窌窊窇竘笐笄笓笅笏笈笊笎笉笒粄粑粊粌粈粍粅紞紝紑紎紘紖紓紟紒紏紌罜罡罞罠罝罛羖羒翃翂翀耖耾耹胺胲胹胵脁胻脀舁舯舥茳茭荄茙荑茥荖茿荁茦茜茢荂荎茛茪茈茼荍茖茤茠茷茯茩荇荅荌荓茞茬荋茧荈虓虒蚢蚨蚖蚍蚑蚞蚇蚗蚆蚋蚚蚅蚥蚙蚡蚧蚕蚘蚎蚝蚐蚔衃衄衭衵衶衲袀衱衿衯袃衾衴衼訒豇豗豻貤貣赶赸趵趷趶軑軓迾迵适迿迻逄迼迶郖郠郙郚郣郟郥郘郛郗郜郤酐酎酏釕釢釚陜陟隼飣髟鬯乿偰偪偡偞偠偓偋偝偲偈偍偁偛偊偢倕偅偟偩偫偣偤偆偀偮偳偗偑凐剫剭剬剮勖勓匭厜啵啶唼啍啐唴唪啑啢唶唵唰啒啅唌唲啥啎唹啈唭唻啀啋圊圇埻堔埢埶埜埴堀埭埽堈埸堋埳埏堇埮埣埲埥埬埡堎埼堐埧堁堌埱埩埰堍堄奜婠婘婕婧婞娸娵婭婐婟婥婬婓婤婗婃婝婒婄婛婈媎娾婍娹婌婰婩婇婑婖婂婜孲孮寁寀屙崞崋崝崚崠崌崨崍崦崥崏崰崒崣崟崮帾帴庱庴庹庲庳弶弸徛徖徟悊悐悆悾悰悺惓惔惏惤惙惝惈悱惛悷惊悿惃惍惀挲捥掊掂捽掽掞掭掝掗掫掎捯掇掐据掯捵掜捭掮捼掤挻掟捸掅掁掑掍捰敓旍晥晡晛晙晜晢朘桹梇梐梜桭桮梮梫楖桯梣梬梩桵桴梲梏桷梒桼桫桲梪梀桱桾梛梖梋梠梉梤桸桻梑梌梊桽欶欳欷欸殑殏殍殎殌氪淀涫涴涳湴涬淩淢涷淶淔渀淈淠淟淖涾淥淜淝淛淴淊涽淭淰涺淕淂淏淉淐淲淓淽淗淍淣涻烺焍烷焗烴焌烰焄烳焐烼烿焆焓焀烸烶焋焂焎牾牻牼牿猝猗猇猑猘猊猈狿猏猞玈珶珸珵琄琁珽琇琀珺珼珿琌琋珴琈畤畣痎痒痏痋痌痑痐皏皉盓眹眯眭眱眲眴眳眽眥眻眵硈硒硉硍硊硌砦硅硐祤祧祩祪祣祫祡离秺秸秶秷窏窔窐笵笻笴笥笰笢笤笳笘笪笝笱笫笭笯笲笸笚笣粔粘粖粣紵紽紸紶紺絅紬紩絁絇紾紿絊紻紨罣羕羜羝羛翊翋翍翐翑翇翏翉耟耞耛聇聃聈脘脥脙脛脭脟脬脞脡脕脧脝脢舑舸舳舺舴舲艴莐莣莨莍荺荳莤荴莏莁莕莙荵莔莩荽莃莌莝莛莪莋荾莥莯莈莗莰荿莦莇莮荶虙虖蚿蚷蛂蛁蛅蚺蚰蛈蚹蚳蚸蛌蚴蚻蚼蛃蚽蚾衒袉袕袨袢袪袚袑袡袟袘袧袙袛袗袤袬袌袓袎覂觖觙觕訰訧訬訞谹谻豜豝豽貥赽赻赹趼跂趹趿跁軘軞軝軜軗軠軡逤逋逑逜逌逡郯郪郰郴郲郳郔郫郬郩酖酘酚酓酕釬釴釱釳釸釤釹釪釫釷釨釮镺閆閈陼陭陫陱陯隿靪頄飥馗傛傕傔傞傋傣傃傌傎傝偨傜傒傂傇兟凔匒匑厤厧喑喨喥喭啷噅喢喓喈喏喵喁喣喒喤啽喌喦啿喕喡喎圌堩堷堙堞堧堣堨埵塈堥堜堛堳堿堶堮堹堸堭堬堻奡媯媔媟婺媢媞婸媦婼媥媬媕媮娷媄媊媗媃媋媩婻婽媌媜媏媓媝寪寍寋寔寑寊寎尌尰崷嵃嵫嵁嵋崿崵嵑嵎嵕崳崺嵒崽崱嵙嵂崹嵉崸崼崲崶嵀嵅幄幁彘徦徥徫惉悹惌惢惎惄愔惲愊愖愅惵愓惸惼惾惁愃愘愝愐惿愄愋扊掔掱掰揎揥揨揯揃撝揳揊揠揶揕揲揵摡揟掾揝揜揄揘揓揂揇揌揋揈揰揗揙攲敧敪敤敜敨敥斌斝斞斮旐旒晼晬晻暀晱晹晪晲朁椌棓椄棜椪棬棪棱椏棖棷棫棤棶椓椐棳棡椇棌椈楰梴椑棯棆椔棸棐棽棼棨椋椊椗棎棈棝棞棦棴棑椆棔棩椕椥棇欹欻欿欼殔殗殙殕殽毰毲毳氰淼湆湇渟湉溈渼渽湅湢渫渿湁湝湳渜渳湋湀湑渻渃渮湞湨湜湡渱渨湠湱湫渹渢渰湓湥渧湸湤湷湕湹湒湦渵渶湚焠焞焯烻焮焱焣焥焢焲焟焨焺焛牋牚犈犉犆犅犋猒猋猰猢猱猳猧猲猭猦猣猵猌琮琬琰琫琖琚琡琭琱琤琣琝琩琠琲瓻甯畯畬痧痚痡痦痝痟痤痗皕皒盚睆睇睄睍睅睊睎睋睌矞矬硠硤硥硜硭硱硪确硰硩硨硞硢祴祳祲祰稂稊稃稌稄窙竦竤筊筇筄筈筌筎筀筘筅粢粞粨粡絘絯絣絓絖絧絪絏絭絜絫絒絔絩絑絟絎缾缿罥罦羢羠羡翗聑聏聐胾胔腃腊腒腏腇脽腍脺臦臮臷臸臹舄舼舽舿艵茻菏菹萣菀菨萒菧菤菼菶萐菆菈菫菣莿萁菝菥菘菿菡菋菎菖菵菉萉萏菞萑萆菂菳菕菺菇菑菪萓莚菃菬菮菄菻菗菢萛菛菾蛘蛢蛦蛓蛣蛚蛪蛝蛫蛜蛬蛩蛗蛨蛑衈衖衕袺裗袹袸裀袾袶袼袷袽袲褁裉覕覘覗觝觚觛詎詍訹詙詀詗詘詄詅詒詈詑詊詌詏豟貁貀貺貾貰貹貵趄趀趉跘跓跍跇跖跜跏跕跙跈跗跅軯軷軺軹軦軮軥軵軧軨軶軫軱軬軴軩逭逴逯鄆鄬鄄郿郼鄈郹郻鄁鄀鄇鄅鄃酡酤酟酢酠鈁鈊鈥鈃鈚鈦鈏鈌鈀鈒釿釽鈆鈄鈧鈂鈜鈤鈙鈗鈅鈖镻閍閌閐隇陾隈隉隃隀雂雈雃雱雰靬靰靮頇颩飫鳦黹亃亄亶傽傿僆傮僄僊傴僈僂傰僁傺傱僋僉傶傸凗剺剸剻剼嗃嗛嗌嗐嗋嗊嗝嗀嗔嗄嗩喿嗒喍嗏嗕嗢嗖嗈嗲嗍嗙嗂圔塓塨塤塏塍塉塯塕塎塝塙塥塛堽塣塱壼嫇嫄嫋媺媸媱媵媰媿嫈媻嫆媷嫀嫊媴媶嫍媹媐寖寘寙尟尳嵱嵣嵊嵥嵲嵬嵞嵨嵧嵢巰幏幎幊幍幋廅廌廆廋廇彀徯徭惷慉慊愫慅愶愲愮慆愯慏愩慀戠酨戣戥戤揅揱揫搐搒搉搠搤搳摃搟搕搘搹搷搢搣搌搦搰搨摁搵搯搊搚摀搥搧搋揧搛搮搡搎敯斒旓暆暌暕暐暋暊暙暔晸朠楦楟椸楎楢楱椿楅楪椹楂楗楙楺楈楉椵楬椳椽楥棰楸椴楩楀楯楄楶楘楁楴楌椻楋椷楜楏楑椲楒椯楻椼歆歅歃歂歈歁殛毻毼毹毷毸溛滖滈溏滀溟溓溔溠溱溹滆滒溽滁溞滉溷溰滍溦滏溲溾滃滜滘溙溒溎溍溤溡溿溳滐滊溗溮溣煇煔煒煣煠煁煝煢煲煸煪煡煂煘煃煋煰煟煐煓煄煍煚牏犍犌犑犐犎猼獂猻猺獀獊獉瑄瑊瑋瑒瑑瑗瑀瑏瑐瑎瑂瑆瑍瑔瓡瓿瓾瓽甝畹畷榃痯瘏瘃痷痾痼痹痸瘐痻痶痭痵痽皙皵盝睕睟睠睒睖睚睩睧睔睙睭矠碇碚碔碏碄碕碅碆碡碃硹碙碀碖硻祼禂祽祹稑稘稙稒稗稕稢稓稛稐窣窢窞竫筦筤筭筴筩筲筥筳筱筰筡筸筶筣粲粴粯綈綆綀綍絿綅絺綎絻綃絼綌綔綄絽綒罭罫罧罨罬羦羥羧翛翜耡腤腠腷腜腩腛腢腲朡腞腶腧腯腄腡舝艉艄艀艂艅蓱萿葖葶葹蒏蒍葥葑葀蒆葧萰葍葽葚葙葴葳葝蔇葞萷萺萴葺葃葸萲葅萩菙葋萯葂萭葟葰萹葎葌葒葯蓅蒎萻葇萶萳葨葾葄萫葠葔葮葐蜋蜄蛷蜌蛺蛖蛵蝍蛸蜎蜉蜁蛶蜍蜅裖裋裍裎裞裛裚裌裐覅覛觟觥觤觡觠觢觜触詶誆詿詡訿詷誂誄詵誃誁詴詺谼豋豊豥豤豦貆貄貅賌赨赩趑趌趎趏趍趓趔趐趒跰跠跬跱跮跐跩跣跢跧跲跫跴輆軿輁輀輅輇輈輂輋遒逿遄遉逽鄐鄍鄏鄑鄖鄔鄋鄎酮酯鉈鉒鈰鈺鉦鈳鉥鉞銃鈮鉊鉆鉭鉬鉏鉠鉧鉯鈶鉡鉰鈱鉔鉣鉐鉲鉎鉓鉌鉖鈲閟閜閞閛隒隓隑隗雎雺雽雸雵靳靷靸靲頏頍頎颬飶飹馯馲馰馵骭骫魛鳪鳭鳧麀黽僦僔僗僨僳僛僪僝僤僓僬僰僯僣僠凘劀劁勩勫匰厬嘧嘕嘌嘒嗼嘏嘜嘁嘓嘂嗺嘝嘄嗿嗹墉塼墐墘墆墁塿塴墋塺墇墑墎塶墂墈塻墔墏壾奫嫜嫮嫥嫕嫪嫚嫭嫫嫳嫢嫠嫛嫬嫞嫝嫙嫨嫟孷寠寣屣嶂嶀嵽嶆嵺嶁嵷嶊嶉嶈嵾嵼嶍嵹嵿幘幙幓廘廑廗廎廜廕廙廒廔彄彃彯徶愬愨慁慞慱慳慒慓慲慬憀慴慔慺慛慥愻慪慡慖戩戧戫搫摍摛摝摴摶摲摳摽摵摦撦摎撂摞摜摋摓摠摐摿搿摬摫摙摥摷敳斠暡暠暟朅朄朢榱榶槉榠槎榖榰榬榼榑榙榎榧榍榩榾榯榿槄榽榤槔榹槊榚槏榳榓榪榡榞槙榗榐槂榵榥槆歊歍歋殞殟殠毃毄毾滎滵滱漃漥滸漷滻漮漉潎漙漚漧漘漻漒滭漊漶潳滹滮漭潀漰漼漵滫漇漎潃漅滽滶漹漜滼漺漟漍漞漈漡熇熐熉熀熅熂熏煻熆熁熗牄牓犗犕犓獃獍獑獌瑢瑳瑱瑵瑲瑧瑮甀甂甃畽疐瘖瘈瘌瘕瘑瘊瘔皸瞁睼瞅瞂睮瞀睯睾瞃碲碪碴碭碨硾碫碞碥碠碬碢碤禘禊禋禖禕禔禓禗禈禒禐稫穊稰稯稨稦窨窫窬竮箈箜箊箑箐箖箍箌箛箎箅箘劄箙箤箂粻粿粼粺綧綷緂綣綪緁緀緅綝緎緄緆緋緌綯綹綖綼綟綦綮綩綡緉罳翢翣翥翞耤聝聜膉膆膃膇膍膌膋舕蒗蒤蒡蒟蒺蓎蓂蒬蒮蒫蒹蒴蓁蓍蒪蒚蒱蓐蒝蒧蒻蒢蒔蓇蓌蒛蒩蒯蒨蓖蒘蒶蓏蒠蓗蓔蓒蓛蒰蒑虡蜳蜣蜨蝫蝀蜮蜞蜡蜙蜛蝃蜬蝁蜾蝆蜠蜲蜪蜭蜼蜒蜺蜱蜵蝂蜦蜧蜸蜤蜚蜰蜑裷裧裱裲裺裾裮裼裶裻裰裬裫覝覡覟覞觩觫觨誫誙誋誒誏誖谽豨豩賕賏賗趖踉踂跿踍跽踊踃踇踆踅跾踀踄輐輑輎輍鄣鄜鄠鄢鄟鄝鄚鄤鄡鄛酺酲酹酳銥銤鉶銛鉺銠銔銪銍銦銚銫鉹銗鉿銣鋮銎銂銕銢鉽銈銡銊銆銌銙銧鉾銇銩銝銋鈭隞隡雿靘靽靺靾鞃鞀鞂靻鞄鞁靿韎韍頖颭颮餂餀餇馝馜駃馹馻馺駂馽駇骱髣髧鬾鬿魠魡魟鳱鳲鳵麧僿儃儰僸儆儇僶僾儋儌僽儊劋劌勱勯噈噂噌嘵噁噊噉噆噘噚噀嘳嘽嘬嘾嘸嘪嘺圚墫墝墱墠墣墯墬墥墡壿嫿嫴嫽嫷嫶嬃嫸嬂嫹嬁嬇嬅嬏屧嶙嶗嶟嶒嶢嶓嶕嶠嶜嶡嶚嶞幩幝幠幜緳廛廞廡彉徲憋憃慹憱憰憢憉憛憓憯憭憟憒憪憡憍慦憳戭摮摰撖撠撅撗撜撏撋撊撌撣撟摨撱撘敶敺敹敻斲斳暵暰暩暲暷暪暯樀樆樗槥槸樕槱槤樠槿槬槢樛樝槾樧槲槮樔槷槧橀樈槦槻樍槼槫樉樄樘樥樏槶樦樇槴樖歑殥殣殢殦氁氀毿氂潁漦潾澇濆澒澍澉澌潢潏澅潚澖潶潬澂潕潲潒潐潗澔澓潝漀潡潫潽潧澐潓澋潩潿澕潣潷潪潻熲熯熛熰熠熚熩熵熝熥熞熤熡熪熜熧熳犘犚獘獒獞獟獠獝獛獡獚獙獢璇璉璊璆璁瑽璅璈瑼瑹甈甇畾瘥瘞瘙瘝瘜瘣瘚瘨瘛皜皝皞皛瞍瞏瞉瞈磍碻磏磌磑磎磔磈磃磄磉禚禡禠禜禢禛歶稹窲窴窳箷篋箾箬篎箯箹篊箵糅糈糌糋緷緛緪緧緗緡縃緺緦緶緱緰緮緟罶羬羰羭翭翫翪翬翦翨聤聧膣膟膞膕膢膙膗舖艏艓艒艐艎艑蔤蔻蔏蔀蔩蔎蔉蔍蔟蔊蔧蔜蓻蔫蓺蔈蔌蓴蔪蓲蔕蓷蓫蓳蓼蔒蓪蓩蔖蓾蔨蔝蔮蔂蓽蔞蓶蔱蔦蓧蓨蓰蓯蓹蔘蔠蔰蔋蔙蔯虢蝖蝣蝤蝷蟡蝳蝘蝔蝛蝒蝡蝚蝑蝞蝭蝪蝐蝎蝟蝝蝯蝬蝺蝮蝜蝥蝏蝻蝵蝢蝧蝩衚褅褌褔褋褗褘褙褆褖褑褎褉覢覤覣觭觰觬諏諆誸諓諑諔諕誻諗誾諀諅諘諃誺誽諙谾豍貏賥賟賙賨賚賝賧趠趜趡趛踠踣踥踤踮踕踛踖踑踙踦踧踔踒踘踓踜踗踚輬輤輘輚輠輣輖輗遳遰遯遧遫鄯鄫鄩鄪鄲鄦鄮醅醆醊醁醂醄醀鋐鋃鋄鋀鋙銶鋏鋱鋟鋘鋩鋗鋝鋌鋯鋂鋨鋊鋈鋎鋦鋍鋕鋉鋠鋞鋧鋑鋓銵鋡錥鋆銴镼閬閫閮閰隤隢雓霅霈霂靚鞊鞎鞈韐韏頞頝頦頩頨頠頛頧颲餈飺餑餔餖餗餕駜駍駏駓駔駎駉駖駘駋駗駌骳髬髫髳髲髱魆魃魧魴魱魦魶魵魰魨魤魬鳼鳺鳽鳿鳷鴇鴀鳹鳻鴈鴅鴄麃黓鼏鼐儜儓儗儚儑凞匴叡噰噠噮噳噦噣噭噲噞噷圜圛壈墽壉墿墺壂墼壆嬗嬙嬛嬡嬔嬓嬐嬖嬨嬚嬠嬞寯嶬嶱嶩嶧嶵嶰嶮嶪嶨嶲嶭嶯嶴幧幨幦幯廩廧廦廨廥彋徼徻憝憨憖懅憴懆懁懌憺憿憸憌擗擖擐擏擉撽撉擃擛擳擙攳敿敼斢曈暾曀曊曋曏暽暻暺曌朣樴橦橉橧樲橨樾橝橭橶橛橑樨橚樻樿橁橪橤橐橏橔橯橩橠樼橞橖橕橍橎橆歕歔歖殧殪殫毈毇氄氃氆澭濋澣濇澼濎濈潞濄澽澞濊澨瀄澥澮澺澬澪濏澿澸澢濉澫濍澯澲澰燅燂熿熸燖燀燁燋燔燊燇燏熽燘熼燆燚燛犝犞獩獦獧獬獥獫獪瑿璚璠璔璒璕璡甋疀瘯瘭瘱瘽瘳瘼瘵瘲瘰皻盦瞚瞝瞡瞜瞛瞢瞣瞕瞙瞗磝磩磥磪磞磣磛磡磢磭磟磠禤穄穈穇窶窸窵窱窷篞篣篧篝篕篥篚篨篹篔篪篢篜篫篘篟糒糔糗糐糑縒縡縗縌縟縠縓縎縜縕縚縢縋縏縖縍縔縥縤罃罻罼罺羱翯耪耩聬膱膦膮膹膵膫膰膬膴膲膷膧臲艕艖艗蕖蕅蕫蕍蕓蕡蕘蕀蕆蕤蕁蕢蕄蕑蕇蕣蔾蕛蕱蕎蕮蕵蕕蕧蕠薌蕦蕝蕔蕥蕬虣虥虤螤螛螏螗螓螒螈螁螖螘蝹螇螣螅螐螑螝螄螔螜螚螉褞褦褰褭褮褧褱褢褩褣褯褬褟觱諠諢諲諴諵諝謔諤諟諰諈諞諡諨諿諯諻貑貒貐賵賮賱賰賳赬赮趥趧踳踾踸蹀蹅踶踼踽蹁踰踿躽輶輮輵輲輹輷輴遶遹遻邆郺鄳鄵鄶醓醐醑醍醏錧錞錈錟錆錏鍺錸錼錛錣錒錁鍆錭錎錍鋋錝鋺錓鋹鋷錴錂錤鋿錩錹錵錪錔錌錋鋾錉錀鋻錖閼闍閾閹閺閶閿閵閽隩雔霋霒霐鞙鞗鞔韰韸頵頯頲餤餟餧餩馞駮駬駥駤駰駣駪駩駧骹骿骴骻髶髺髹髷鬳鮀鮅鮇魼魾魻鮂鮓鮒鮐魺鮕魽鮈鴥鴗鴠鴞鴔鴩鴝鴘鴢鴐鴙鴟麈麆麇麮麭黕黖黺鼒鼽儦儥儢儤儠儩勴嚓嚌嚍嚆嚄嚃噾嚂噿嚁壖壔壏壒嬭嬥嬲嬣嬬嬧嬦嬯嬮孻寱寲嶷幬幪徾懃憵憼懧懠懥懤懨懞擯擩擣擫擤擨斁斀斶旚曒檍檖檁檥檉檟檛檡檞檇檓檎檕檃檨檤檑橿檦檚檅檌檒歛殭氉濌澩濴濔濣濜濭濧濦濞濲濝濢濨燡燱燨燲燤燰燢獳獮獯璗璲璫璐璪璭璱璥璯甐甑甒甏疄癃癈癉癇皤盩瞵瞫瞲瞷瞶瞴瞱瞨矰磳磽礂磻磼磿磲礅磹磾礄禫禨穜穛穖穘穔穚窾竀竁簅簏篲簀篿篻簎篴簋篳簂簉簃簁篸篽簆篰篱簐簊糨縭縼繂縳顈縸縪繉繀繇縩繌縰縻縶繄縺罅罿罾罽翴翲耬膻臄臌臊臅臇膼臩艛艚艜薃薀薏薧薕薠薋薣蕻薤薚薞蕷蕼薉薡蕺蕸蕗薎薖薆薍薙薝薁薢薂薈薅蕹蕶薘薐薟虨螾螪螭蟅螰螬螹螵螼螮蟉蟃蟂蟌螷螯蟄蟊螴螶螿螸螽蟞螲褵褳褼褾襁襒褷襂覭覯覮觲觳謞謘謖謑謅謋謢謏謒謕謇謍謈謆謜謓謚豏豰豲豱豯貕貔賹赯蹎蹍蹓蹐蹌蹇轃轀邅遾鄸醚醢醛醙醟醡醝醠鎡鎃鎯鍤鍖鍇鍼鍘鍜鍶鍉鍐鍑鍠鍭鎏鍌鍪鍹鍗鍕鍒鍏鍱鍷鍻鍡鍞鍣鍧鍎鍙闇闀闉闃闅閷隮隰隬霠霟霘霝霙鞚鞡鞜鞞鞝韕韔韱顁顄顊顉顅顃餥餫餬餪餳餲餯餭餱餰馘馣馡騂駺駴駷駹駸駶駻駽駾駼騃骾髾髽鬁髼魈鮚鮨鮞鮛鮦鮡鮥鮤鮆鮢鮠鮯鴳鵁鵧鴶鴮鴯鴱鴸鴰鵅鵂鵃鴾鴷鵀鴽翵鴭麊麉麍麰黈黚黻黿鼤鼣鼢齔龠儱儭儮嚘嚜嚗嚚嚝嚙奰嬼屩屪巀幭幮懘懟懭懮懱懪懰懫懖懩擿攄擽擸攁攃擼斔旛曚曛曘櫅檹檽櫡櫆檺檶檷櫇檴檭歞毉氋瀇瀌瀍瀁瀅瀔瀎濿瀀濻瀦濼濷瀊爁燿燹爃燽獶璸瓀璵瓁璾璶璻瓂甔甓癜癤癙癐癓癗癚皦皽盬矂瞺礌礓礔礉礐礒礑禭禬穟簜簩簙簠簟簭簝簦簨簢簥簰繜繐繖繣繘繢繟繑繠繗繓羵羳翷翸聵臑臒臐艟艞薴藆藀藃藂薳薵薽藇藄薿藋藎藈藅薱薶藒蘤薸薷薾虩蟧蟦蟢蟛蟫蟪蟥蟟蟳蟤蟔蟜蟓蟭蟘蟣蟗蟙蠁蟴蟨蟝襓襋襏襌襆襐襑襉謪謧謣謳謰謵譇謯謼謾謱謥謷謦謶謮謤謻謽謺豂豵貙貘貗賾贄贂贀蹜蹢蹠蹗蹖蹞蹥蹧蹛蹚蹡蹝蹩蹔轆轇轈轋鄨鄺鄻鄾醨醥醧醯醪鎵鎌鎒鎷鎛鎝鎉鎧鎎鎪鎞鎦鎕鎈鎙鎟鎀鎍鎱鎑鎲鎤鎨鎴鎣闒闓闑隳雗雚巂雟雘雝霣霢霥鞬鞮鞨鞫鞤鞪鞢鞥韗韙韖韘韺顐顑顒颸饁餼餺騏騋騉騍騄騑騊騅騇騆髀髜鬈鬄鬅鬩鬵魊魌魋鯇鯆鯃鮿鯁鮵鮸鯓鮶鯄鮹鮽鵜鵓鵏鵊鵛鵋鵙鵖鵌鵗鵒鵔鵟鵘鵚麎麌黟鼁鼀鼖鼥鼫鼪鼩鼨齌齕儴儵劖勷厴嚫嚭嚦嚧嚪嚬壚壝壛夒嬽嬾嬿巃幰徿懻攇攐攍攉攌攎斄旞旝曞櫧櫠櫌櫑櫙櫋櫟櫜櫐櫫櫏櫍櫞歠殰氌瀙瀧瀠瀖瀫瀡瀢瀣瀩瀗瀤瀜爌爊爇爂爅犥犦犤犣犡瓋瓅璷瓃甖癠矉矊矄矱礝礛礡礜礗礞禰穧穨簳簼簹簬簻糬糪繶繵繸繰繷繯繺繲繴繨罋罊羃羆羷翽翾聸臗臕舋艤艡艣藫藱藭藙藡藨藚藗藬藲藸藘藟藣藜藑藰藦藯藞藢蠀蟺蠃蟶蟷蠉蠌蠋蠆蟼蠈蟿蠊蠂襢襚襛襗襡襜襘襝襙覈覷覶觶譐譈譊譀譓譖譔譋譕譑譂譒譗豃豷豶貚贆贇贉趬趪趭趫蹭蹸蹳蹪蹯蹻軂轒轑轏轐轓辴酀鄿醰醭鏞鏇鏏鏂鏚鏐鏹鏬鏌鏙鎩鏦鏊鏔鏮鏣鏕鏄鏎鏀鏒鎥鏧镽闚闛雡霩霫霬霨霦鞳鞷鞶韝韞韟顜顙顝顗颿颽颻颾饈饇饃馦馧騚騕騥騝騤騛騢騠騧騣騞騜騔髂鬋鬊鬎鬌鬷鯪鯫鯠鯞鯤鯦鯢鯰鯔鯗鯬鯜鯙鯥鯕鯡鯚鵷鶁鶊鶄鶈鵱鶀鵸鶆鶋鶌鵽鵫鵴鵵鵰鵩鶅鵳鵻鶂鵯鵹鵿鶇鵨麔麑黀黼鼭齀齁齍齖齗齘匷嚲嚵嚳壣孅巆巇廮廯忀忁懹攗攖攕攓旟曨曣曤櫳櫰櫪櫨櫹櫱櫮櫯瀼瀵瀯瀷瀴瀱灂瀸瀿瀺瀹瀪灀瀻瀳灁爓爔犨獽獼璺皫皪皾盭矌矎矏矍矲礥礣礧礨礤礩禲穮穬穭竷籉籈籊籇籅糮繻繾纁纀羺翿聹臛臙艨艩蘢藿蘁藾蘛蘀藶蘄蘉蘅蘌藽蠙蠐蠑蠗蠓蠖襣襦覹觷譠譪譝譨譣譥譧譭趮躆躈躄轙轖轗轕轘轚邍酃酁醷醵醲醳鐋鐓鏻鐠鐏鐔鏾鐕鐐鐨鐙鐍鏵鐀鏷鐇鐎鐖鐒鏺鐉鏸鐼鐊鏿鏼鐌鏶鐑鐆闞闠闟霮霯鞹鞻韽韾顠顢顣顟飁飂饐饎饙饌饋饓騲騴騱騬騪騶騩騮騸騭髇髊髆鬐鬒鬑鰋鰈鯷鰅鰒鯸鱀鰇鰎鰆鰗鰔鰉鶟鶙鶤鶝鶒鶘鶐鶛鶠鶔鶜鶪鶗鶡鶚鶢鶨鶞鶣鶿鶩鶖鶦鶧麙麛麚黥黤黧黦鼰鼮齛齠齞齝齙龑儺儹劘劗囃嚽嚾孈孇巋巏廱懽攛欂櫼欃櫸欀灃灄灊灈灉灅灆爝爚爙獾甗癪矐礭礱礯籔籓糲纊纇纈纋纆纍罍羻耰臝蘘蘪蘦蘟蘣蘜蘙蘧蘮蘡蘠蘩蘞蘥蠩蠝蠛蠠蠤蠜蠫衊襭襩襮襫觺譹譸譅譺譻贐贔趯躎躌轞轛轝酆酄酅醹鐿鐻鐶鐩鐽鐰鐹鐪鐷鐬鑀鐱闥闤闣霵霺鞿韡顤飉飆飀饘饖騹騽驆驄驂驁騺騿髍鬕鬗鬘鬖鬺魒鰫鰝鰜鰬鰣鰨鰩鰤鰡鶷鶶鶼鷁鷇鷊鷏鶾鷅鷃鶻鶵鷎鶹鶺鶬鷈鶱鶭鷌鶳鷍鶲鹺麜黫黮黭鼛鼘鼚鼱齎齥齤龒亹囆囅囋奱孋孌巕巑廲攡攠攦攢欋欈欉氍灕灖灗灒爞爟犩獿瓘瓕瓙瓗癭皭礵禴穰穱籗籜籙籛籚糴糱纑罏羇臞艫蘴蘵蘳蘬蘲蘶蠬蠨蠦蠪蠥襱覿覾觻譾讄讂讆讅譿贕躕躔躚躒躐躖躗轠轢酇鑌鑐鑊鑋鑏鑇鑅鑈鑉鑆霿韣顪顩飋饔饛驎驓驔驌驏驈驊驉驒驐髐鬙鬫鬻魖魕鱆鱈鰿鱄鰹鰳鱁鰼鰷鰴鰲鰽鰶鷛鷒鷞鷚鷋鷐鷜鷑鷟鷩鷙鷘鷖鷵鷕鷝麶黰鼵鼳鼲齂齫龕龢儽劙壨壧奲孍巘蠯彏戁戃戄攩攥斖曫欑欒欏毊灛灚爢玂玁玃癰矔籧籦纕艬蘺虀蘹蘼蘱蘻蘾蠰蠲蠮蠳襶襴襳觾讌讎讋讈豅贙躘轤轣醼鑢鑕鑝鑗鑞韄韅頀驖驙鬞鬟鬠鱒鱘鱐鱊鱍鱋鱕鱙鱌鱎鷻鷷鷯鷣鷫鷸鷤鷶鷡鷮鷦鷲鷰鷢鷬鷴鷳鷨鷭黂黐黲黳鼆鼜鼸鼷鼶齃齏齱齰齮齯囓囍孎屭攭曭曮欓灟灡灝灠爣瓛瓥矕礸禷禶籪纗羉艭虃蠸蠷蠵衋讔讕躞躟躠躝醾醽釂鑫鑨鑩雥靆靃靇韇韥驞髕魙鱣鱧鱦鱢鱞鱠鸂鷾鸇鸃鸆鸅鸀鸁鸉鷿鷽鸄麠鼞齆齴齵齶囔攮斸欘欙欗欚灢爦犪矘矙礹籩籫糶纚纘纛纙臠臡虆虇虈襹襺襼襻觿讘讙躥躤躣鑮鑭鑯鑱鑳靉顲饟鱨鱮鱭鸋鸍鸐鸏鸒鸑麡黵鼉齇齸齻齺齹圞灦籯蠼趲躦釃鑴鑸鑶鑵驠鬮鱴鱳鱱鱵鸔鸓黶鼊龤灨灥糷虪蠾蠽蠿讞貜躩軉靋顳顴飌饡馫驤驦驧鬤鸕鸗齈戇欞爧虌躨钂钀钁驩驨鸙虋讟钃鱹麷癵驫鱺鸝灩灪爩麤齾齉龘E\bG\x9c丨丶丿亅丅丄冂冖匸卩厶个亇义凢乆亏亼亾兦凣刄劜卄夂夊宀巛幺广廴彐彑彡阝𠀋乣乢亣内仅仏从仌冄円冗㓅凤刅办劝勽匀区㔹卆卝历厷㕕双㕛収圡㞢帀弌户戸攴攵无㸦玍亗仠㐲𠆩仧㐳仦㐴㚢㐱㒰囘冋册写凥切刋㓜㘞匄匃匇匆匞卟卭厉厺叐㕥叶号叹㕤叴㘝㘦圤处夘夲夰头㚎奵㝊宂对尔㞋㞦㞤㞥㞧㠯㠲庁広弍归㣔忊忇戹㧅扏旧术歺氹㲺㲹氷汄汅玌疒癶邓邒䢳䦺両丠丢乨争亘仹仯㐻㐼仸伆伃仼仮伖㐹伨伜伇会仺众兊㒲兲再冴决冲㓇凨㐫刔刏刕刘动卉卋协㔻卐㕄压㕂厾㕜叒吖叿㕧吀㕦吅吓吕吆㘟団圵圶圱圲壮夅乔㚏夶㚐㚨㚤奺㚣妆㜽㝌宆当尘㞌尽屸㞨㞭㞯𡵆岀㠩师㠶㡱㡰㡯廵弎㢩㢪㣻忓忚㣼忛㦮戏扝扟执扗齐㫃旫旪㬰朷㭁朲朶欢毎㲌汘汑汷氼㲻汚𣲆汓灯灲灮灰㶡灳犱犲㺨玏㺪㺫䂖礼䇂肍肎䒓艻䒔赱边邖邚䦻两乱况亜佅佊伹伷㑃伲佂㑂佄佋㑄佀伵伱兑免児兎㒳㒷冝㓈况冸凬刣刧刦刟别删労劳㔘匥医却㕇㕆㕅㕫㕩呍㕲㕱吺㕬㕯呄呌吣吚吲呋吡㕳吴呉呐吢吿呑呚启㕶囩囲図囯园囬囦坈㘧坄圿圼坓坖㘰坟坃坘块㘩㘪㘯坆㘬圽㘭坂坔坕壱売声夋麦囱夽㚓妔妌㚭妜㚬㚫妟㚩妛㚮妉妚㚪斈㝎宍㝐対寿寽尫㞲岄岎岜㞵㞶岅巵帉㠹㠻帋㡲庒庐弃㢬弞彣㤈忧忾忦応忎㤃忼忟忬忶𢗗忲忰忹㤋戓㦯成戻戼抂㧎㧋㧌扸抋㧍択报抛抜抙抍抅抝扵㪀㪯㫓时㫕㫔㫗曵杛㭂杔杒㭄杘杄杊条㳆汿沑沞㳀汮汼沟汵㲾汖沢汹㳊没㳄沪沠㳂灹灷灾㶤灵灻牤㸩犻犼狇犹犾状㹠㹞玘㺭㺮㺬㽗疓皀㿝皃盀盁䀎䂗矴矵䄧竌竍糺肟肑肗肔䒕芆芌芑芕䒘虬䖝辵达过䢊迁邩邤䢵䢶䢷邨阧阦阫阳阴阩丽㐨亝侓侊㑍㑉佭侎侠侢価侟侣𠈉佲㑑侌兖兔具冐㓋㓊㓌凭凾刯𠛬刼刴刹効劵势单㔽卶卺厓㕈叁参叕咅㕸呟咓咉呹黾咏呩呭㕺咔呪咊㕷国囻囸㘡囼囶坥垁坣坧㘱坮坸坿㘳㘴壳奋奌㛁㚰㚴妭㚸姄㚼姂妰㚿㚵㚱㚽妿㚻姉妸妬㚳㚶㚺妷姗㚷孠㝀宖实宔実宝㝒尀尙㞐㞑屉届㞾岻峁㟀㟁㞹岹岞岴岺巶帓帒幷㡹庘㡻庙庝廸㢠廹㐩弆弡㢮弥录㣍㣋㣙径徃怰怇㤌怶㤁㤅㤂忩怽怈㤓㤑抺抲㧤抷拤抧㧚㧕㧓拕拡拝抦拁担拀拟拠拞㧖斉㪴斦斺㫙旿㫘昗昘昁旾㬳朌㭈杫枖枂枊㭇枣㭋枢枏㭊柹枀欦欧歨歩㱚殁殴毑㲎㲴氜㳍㳌㳏㳋㳎沗汬泟㳒泪㳑泤泘沲泎泈㶦炋炈炐炏㶪炇炉炍炁㶨炌㸓㸚牀㸝㸞牥牦牨㹥㹦狍狓狛㹤狏玧玣玞㺰环㺳㺵玪玜㼙畂畄画畁𢌿疛疞㽵秄秃秆䄫䄬秇䄭秊𥤮䇃竏籶籴糿糼㒺䍐罙肨䏓䏐肰䏜肳䏒肤肶肧肬䏛肦卧舎苄茾芜䒥䒟䒚䒜䒢芪䒛苉䒣䒝芦芲䖈䘚䢍迌这迊还迏邷䢸邭邹阷䧃陁阾隶靑靣乗乹亲亰亯亱俒俕㑝侾侸侰侱俈㑚㑗俆俌俥俣侴兪㒸冟㓂凁㓏凂凃剅剈㓧㓩剏䑒㓨勅勊勄匧単卽㕊叝叜叚叙咹㖀哐咴哊㖇咣哚咤㖄咲咞咟咵㖂㖁响㖃哌㘢圀垬垑垎垍垒垡垐垦垨㘻垜垖垧㘺㘸㘹㘶壵㚅変夈㱔奒㚚㛄姟㛅姫姯姰姕娍娄姹娂姸姢姙姧孪㝔㝕宫㝖尛尯㞖昼㞕㞔㟄峑峜峦峡峍岍峢峝峥巻巺帞㡄庡㡽㡾廼廻弯㢲㢶彦形㣜㣟恜㤨恎恑㤙㤐怣怱恼恒㤬恠恊恡㤛战㧂㧯㧣㧥㧪㧦㧮挟挗拪挡挄挅㧨攱敄敀㪅敂斾㫠昩昛㫝昣昬昚昰昻昞昷昸㫡朎㭑㭕柨柖㭒柇枼桒枿柕柾荣査柺枱栁栅栀柗䂞欪歫㱒㱞㱠㱟毡㲒㳖洣泿洔洅洓洆洡㳞洕沯泴洂浅汧洦㳙浄㳝洤炥㶬炣㶰炢炻炠炪㶯㶮炨炶炲炧㶭㶫点爮㸖爼㸰牭㸳㸱狧狭㹮独狥狢㺱珄玽珏珉珁珐㺷珎瓭瓫㼚瓯畉畐畆畊㽙畍疦㽺㽸㽻疨㿞㿬盇盿䀞盽矦砊䂛砄砋砇䂝砕砈䃽秓秔秐秗穼䆔䆓穽窃窂䇆竔竕竓竐竒竾竼籷籼类䊸紀䊷䊹䍓𥄳䍒䍑羏胓䏣胋胒䏢脉胢胟胆䑣舤苷苘苝䒪䒦苸苼䒨䒩苩䒫苮䒬苐茎苢茋苽兹虶䖞虸䖟䖠虵虽䘏衂衦䘜覌訅䚮訆䚰貟䟔迱迧迩迯郂邼䢻郆郀䣁䣂郍郉䢾郄䣥閁䧆鳬倲俹倝俿倄俰倃䘮倂俼俲値倈倶倮㑥倐倸倹俽偖俻冣冦冡㓑㓐凇凉剠剤剙剥剧剗剣㓮剓㓯勏㔞勎勐勑勌㕋㕍㕖唍哴㖒唓哯哵哹唂唀㖓㖐唘哰哶哶㖗唙㘣圅埉㘿垷埍㙃㙂㙄垾垻㙅埛埈埄埅埑埊埀㛙㛞娡娪㛏娝㛍娋㛝㛎㛓㛖娢㛜㛑娤㛐娔娱㛕娯娚娒㛔㛛娦𡜮㝃宼宻宷尅将屒屓㟍㟑峺峫㟊峳峵峩峯㟔帪帰帮帬帯庯庩㢆廽弲徎従徏恏悙㤳恾㤷恴恋恳恵㤪㤟恖悩悋悦㤹悓悮悞悧扅㧲㧸挮㧷捒挰捛㧴挙挛㧱挱捝挷挵捓㧵捜挿敋㪇䍩斋㪶斚晐晆晎晀㫩晋晈㫫晠晄晍㬴样栤㭢㭜栛栶㭠栬栙桖桇栾栞桒栕栢栧桙桘桕栰栣欮欫欰歬㱡残㱿毩毪毥浫㳥㳦浳浗㳩㳳浝浖涏浛洜涚涛涙㳮涁浱㳭㳬浲浜涖烄烛烌烐烣烟㶳烖烮烕㶵㸧㸵㸶牺㹱狵猂㺸㻂珢珬珕珹珟珦㻈㻀㻁㻄珤珡瓳㼟㼜㼝㽍畖畕畘畗畞痃㾇㾂疴㽽疱㿟䀀盋盌眪䀠眖眏䀢䀤眿眫眎眤䀡眡眘矝䂤䂥砤砶䄁祘祙䄃祢秚秙䄷秡秥秨秛䄸秢称䆙䆘䆗䇉竚竝䇇竛竜䇙䇛笍笋笔粎粆䉻粇粃粋䉼䊽䊼䋃紤䋁䊿䋄紥罢羓羙羗䍾䍿翄翆耊䎴䎳聀耼耻胶胿胮脄脃脇脃䏦胷䑙䑚舭䑤舧舩茟荗䒴䒹䒷䒵茡䓁䒱茘荘䒳䒺䖌䖋䖍蚄蚟蚛蚉蚦蚒蚏蚠衏衸衺衮衻袄衳䙸䙷訍訋訙䚲䟖軐䡅䢒䢕迹选逈䣆郞酑䣧䣨釛釟釞釖釠閅陠陙䧋陖䧍䧱隽䬢㐡偂偙㑯偄偦偒偔偱偐偻偼偘㑰㑤偹偬偸冨凑减剨剶勔㔭厠叄㕘啘啌啨㖦啉㖤㖟啝唩𠶮啔啓㖣啚㖠唺唿唫埪㙊埯㙇㙈埝埾型埞埦㙉埨埿堃壷梦够㚞奛奝奞𡘷㛨娫㛬娻㛭㛦婡娺婮婋婫㛫㛧婅婎婨娽婱娿婯婵㛩婳娬婙婔婏婣㝜㝝寈㝠寃㝛屛屡㞙崡崊崉㟘崬崈㟚㟝崪崕㟠崐崓㟥崯崘㟗巣帹帵帺帲㡎㢌㢈庶庻庺弴弹㣎徚㣥㣦悘㤲惐㥀㥇㥌惗悪㤵悡悤㤰惮㥃惧㥍惨惞㦷㧳捾掋㨂㨋捹掆掓㨄捳捪拼捬㨈捿㧼掲掺㧿㨀捦捴㪋敚㪍敎敍斍斎断㫊旇旊㫋旉旣旣晗㫳晣晧朚朙朖㬶㭪梞梶梘桳㭫梚梎梷棛梥桬棁梄㭱㭨梕桺梙㭲梸梹桰㰮欵㱢殐殻毭㲘㲵㳫淁淃淎㳻㳸涭渄㳵涹㴈淧洴済渆㴊渊涰㴆淸渌㳽涱㳺渂渇渉渁渗淿渒㶺㶹焃焇焁烵烱焈烲焏㶻烾㸘㹀㸽猚猐猎琂珳㻍㻉珻㻋㻊㻎甛産畡畨㽞畧痓㾑㾌皐㿡䀂盕盗盖眰䀫眦䀪着㸔眞䂭䂣䂫硇䂯硋硑硏硂硆䄄祮祬秲䅄秱秵秳秹䄽䅇䆜窚窓窑竡䇠䇤笡笜笖笗笶笟䊀粓粙粚粜䋊絉䋔紴紷䋒紭䋋経䋎䋓絈䍄䍈缻䍅䎁䎆䎅耈耚䎣耝䎵聉聅聄粛䏺䏹脪脮䏻䏲脱䏯脚脴脗䑛䑦䓎茝荱莄莀荲莡㖴荹莑䓈莟莭䓑䓅茣䓒莅莜获虘虚䖏虗蚲䖧蚮䖥䖤䘑衅袜袔袣袏袥袩袦袊袠袐覒覑覔䚵訦訲訨䚴䚽訜䚻訫訠䚹訩䛂䚾䚺訡谺㪷豛豘豼䝘貦貮貭赥䞛赾䞜赼赿赺趽䟚䟞跀趻躭躯軖䡍䡆䡇䡋䡌軙䡈䢙递逎逥䢛逓郮䣎邫郷䣫酙酔釲釰釥釶䤜䦌閇䧑陚䧖䧓陮䧔䧐䧕陹䨋飡飦䯆黄黒亀㐤亁傏傗傈傉㑴㑺傁兠𠕤㓃幂凓㓔㓕凒凖剳㓻剰㓷㔲卿厨厦叅喗嗞㖺㖿喖喴㗇喅㖷喛喠㖹啙㗃喆㖽㗐喯啺㗁㖾㗍喞㗋喩圏圎堚㙏堟㙓堼堘堾堦㙕堺𡍤堢㙑壻奣奤㚟媨媣媂媈媡媅媘㛾媙㛻媉媁媖媀㛱㛵媑㛯㜄㜃㛴嫏㛳㜂㛷㛺㛽㛮媇媠㛰婹㛲媆媫媪媤媍婾㝄寕㝢㝷㷉尞屟属嵄崾㟨嵈崜㟯嵛嵍㟦㟭嵗㟧嵓嵏㟫崻嵆㠭㡕幆幇㡓幈廀庽庿㢐弑㢾弾弻㢽强㣏㣐㣬徧㥋惪㥈愇愢愌㥕㥑悳惖惒㥎惣惥惩㥫㥞愑惽㥠㥧愞愠愡戞㦸揢揞揦㨔㨏㨇㨓揸㨗揾揁掿揷揑敟敠敡㪚㪸旑㫷㫸晽晫晿㫽㫵晳暁晭晩㫺㬸朞棭㮇㮀㮈㭹椃㭼棾棥椞椂㮃㮅椉棊椀栟椁椘棢棏㭻椮棅棿㮆検棃㰴歯㱕歮㱨殾㲜毴氭㳼涶湵渞㴚渘㴗湈㴛湏㴓湂湪湰淾㴅㴇湭㴜湻湾湙㴑湗㴖満湐㴕温湶渪湌焪㷋焵焬焷焝㷅㷂焸㷍焴焫焳焧焤㷊焭爲犃犂犇猆猤猸猪猬猫㺃猯猨琗㻖㻕琙琸㻑琟琔琼珷琜琕琘琹瓹甤甤㽒㽟畮畭畱疎㾕痥㾝㾘㾞㾖皔皳䀃盙䀯睈睉睃䀱䀳睂矟䂴硣硶䂰硟硦祦祱䄈䄇祶禄祵秿稇䅎税稉䅐䅒䆡䆣䆥䇌竧竢筕䇯䇪筃筗筬筂筓䇭筁䊅䊄粧粠粦粤絴䋛絙䋙絠絗絬絾絤䋗絚絝䋚䋞䋘絶絍絵䋕翓翖䎜䎝聠聎腅脻䏾腈腂腉腀脔腁䐇臯臶舃䑪菭䓧菚萘菒萈䓢萀䓟䓡䓥萗荆萔菓萅萟萂萖菍菦䖑虝蛕蛡䖴䖰䖵䖮蛮衇衆䘭䘬袻䘫袿袴䘩袵䙿覚詃䛉䛋詚䛅䛆䛊訵訸詂詉詝詋訽䛒䛐䜭䜵豠豞豿豾貃䝬貱趇䞝䞠趈趃䞢䞟趆趋趂跊跒䟫䟢跔跉躰䠶䠲䡐軲軤軳軪䡏軽䡒辝逫逪䢞逨逩逬䢠䢜逺逰逷逻䣑鄊䣯䣰䣱釾鈠䤝鈋䤟鈘鈢䤞鈛鈈䤠鈎鈓䦎䦑閕𨳝䦐䧗隌陻陿隁䧙隂䧴䧶㕠䧵䧳雮雭䨌雬靟靯䩑靭䪦䪧䪨䬧䬤䬥飰䬪䭴䯇骩䯧䯭髠亷㐮㑻僌傼㑼傪傹㑽傫僃働兾剾剹㓽剷勡勠勧厀厫厪厩叠嗪㗚嗗㗖㗛嗁圕㙛塬㙜塜塐塡塪塩塖塠塦塟夣奦奨㜆㜋嫎㜍嫅嫃㜊㜓嫐㜐㜒㜉㜈㜑㜏孴孶寜寗寚寛㝧尠嵭嵦㟶嵤嵮嵮嵠彂彚彮㥤㥬愰愺愪慃愙愱㥭愽㥺㥵㨍搈搲搸㨞搱㨛搙㨪搑㨟㨢搩搝㨥㨧㨩搼㨤摆摇㨨㨦携搇㨣敫㪟数敭㪱旤暓㬃㬇㬌㬆暏㬄㬉暒㬅暎㬈楆㮖㮜㮟㮛㮘㮙㮔椱㮍㮣椾㮓楼楃㮒楳楂楕㮎㮌榅楍楐楇楧椶楤楡㮚楽歄歀歱歳殜㱪㱭㲄毁㲠毺氱氲㴝㴦溸溨㴳溑溻㴸溚㴰㴨溩㴧㴪溵湬滨㴱滝滚滦溬㴴㴮㴻滙滣滩滛㴩煯㷒㷘煈㷏煊煫㷙煏煗煴㷎㷓㷐煅煑煭㮡牑牐牎㹇獁㺋献獆㻔琧㻞瑈瑓琽㻟瑝瑖㻗琞瑇瑃瑘瑉㻡瑌瑅琾㼯甁甞㽢㽣畺痮瘂皗皘㿢䀄䁃睝睤睓睘碂碐碊䂾硺硽䂺碋硸䂼硎碍碈碒碁祾禃䄍䄎䄑禀䄏䄒䅕稏䅙䅛稝䅗窡窤䇎竩竪䇾筢筫䇼筞䇵筹䇹䇺筯筨䊈䊉粮䊊䊇䊋粰䋦綐䋭綊䋥綋継綕䋠綇絸綗綉綘䋡䍛䍜羣䎏䎋䎍翝聗䎹腣腪腬䐏䐙䐓腝腵䐘腟䐖腽腭䑓辞艁葏蒄䓶葿䓵葈萪葻葲葤萮葜葓䓷葁蓈䓰葢蒀萾蒃葱䓹葊葕葘䖒䖕䖖蛼蛽蜕䖹蜖蛿䖽䖸䘒衘裏裠䚀䚁䚚䚘觧詪誀䛔詯詥䛚詸詽誈䛖䛛詤詾䛜詧豣貈䝱賍䝲賉䞨跭䟰趼䟱䟽䠸䠷䠹躱䡕䡓輄辞辠遃遆遈遅遀遌䣕䢽鄌鄓鄕䣵酫酧鉝鉕鈵䤡鉙鈯鈼銏鉜鉪鉢鉟鉫鉮鈻鉄鉁閚䦒閙䧞䧛䧚随䧟隖䧹䧸䧺雴雼䨎靕䩃靹䩖䩓䪞韮韵䪩頋䫺飳䬱䬰飷飿飵䬲飬飱䬩䬮馚䭶髢鬽魝鳨䲥鳫鳯鳮麁鼔僐僙僜僘㒌僡僫㒋㒉僴僢僞㒍㒆㓗凴㔄㔇㔃勭勪㔢匲㕑厮厰㕡嗻嘃嘋㗩嘇㗥嗸㗤嘨嘞㗣嘘嘑噑嘅圗墒㙥墌㙣塸墄塳墭増墕塷塲墖墍夐㚌奬㜚嫧㜕嫤㜠㜡嫯嘦㜜㜛嫰嵻㟾嶃㠄㠁嶋嶌㠀嵸幒㡭㢓廐廏㢡徳徴㥶㥹㥲慻㦃慯憁愼慂愸愳慈慠慩慽憆戬戨㨸摌㨰摗摼㨴㨺摕摢摱摪摖搻撁㨵摏摾摤㨿㩀㨲㨱㨶敱𣂺㬏暥暚暜㬐暦㬍暤榏槅槈榒榲㮩㮴㮬槣槀槖槑榟榢槒㮸㮮𣗳槡槕榝㮳㮺榘㰿歴殡㴼滰滳㵂㵀漛漌漝漴漨漗㴾潄潂漄漑滺潊熑熎㷡熋熍煿熌煾煼熃㷦熈㷠㸕犔㺓獕獓獏獔瑬瑥瑦瑡瑫瑨瑶㻧瑠𤨏瑴甆瘎㾭瘇㾮㿣皹㿵皷盢䀆睴睷睶睵䁏睸睱䁔睺煛睻碦碮碸䃋䃣䃉碱碯䄓禉禇䄕䅡稩䅦稪䅤稭稬䅠稲䅣竬竰箁箞箃䈊箒䈄箣箟箓䈇箆粸粷䊍䊏䊐䊒䋬緈綶絣緑䋧綨綫䋲綳緍䋮総綤䋯䍌罁罯罱䍞䍟䍶䍴䍲䎐耣聛䎾聡聟膁䐦䐥膄舓䑴艊艌䓽䔅䔁蒽蒕䔉蒒蓃䔌蒖蒥蒵蒳蒣蓤蓘蓕䔍蒷蒭蓚蒓蜶䗀䗅䗉蜽蜫蝇蜹䗕蝅蜝䙁裿裪䘳裵䘺褀裭褃裩䙀䙂觪䛨誛誎誢䛤誜誔誐誩説䛧誟䛦䛡誝䜹豧䝜䝵賐賖賔趘趚趗䞮䞰䟺踋踈踁躴躳䡛辡辢䢥遚䢢遡遟䣛䣚鄥酻酶酼䤤銉鋶銱銭銒銁銄䤦銮銐銞䦘䦖䦚関閧䦗䧡䧠䧣䧢隠隝隟雐䧻雑䨒䨔静䩅靤䩜䩝䪸頙䪺頔頕頚䬃䬵䬶䬹餆䬭飸餁䭺馾馼馷馶駀䭸馸䭻䭼䭾駄䭽馿䯉髚髤髩髨髪髥䯳鬦䰚䰙魀䰟䲦䲧䲨䲫䲩䴮麽䵞㒕㒓㒒儎㒖僷㒗僼儍儁凚凙凛劆劎勮匳厱㗵噇嘭噔㗪噋噒嘱噄噖噐嘫㗳噍嘷噏墢墷㙩墲㙫墪墵墰㙬墴墤墧㙨夦嫾㜨嬄㜦嫼㜥嬆嬊嫺嬍嬀嬎㝫㠏㠈嶑㠌嶏嶐嶘嶤嶛嶣嶖巤幚㡠㡢幞㢘徸慗慜憦憕憈㦊憣憏㦄慤㥿慙慸㦂憅憇憞㦖㦉憘憜㦍㦼戯撀撛撎撍撔擆㩄撨撃㨼撹撆撴撯撑㩎撪撡撧暶暳㬔暼暬㬕暭樎㯎樜樚㯠㯂樋㮿槯樌槰樢㯄様㯏樒槺㯈横㯌樐㯍㯇権槹槪樬樤䲷槩歒歏歓㱃㲶漐潥潵㵑㵊㵍㵖濐㵓潜漽潱㵙澑漋潹澁澏㵎潙潴㷮熮㷬熣熭㷫㷯熫熦熢㷭勲牗牕犙獋瑺㻲㻯㻱璌璄㻭㻰璂璓瑻㻮甉䰛㽐㽦㚄㾸㿶瞊䁗瞐䁞磆磓磀磒磇磂䄙禟禝禞䅰穁䅮稸稺穂䅲䅶稾窰箮箳䈒䈟箿䈑䈖篍䈙箲䈤䈔䈥䊕䊖䊓䊔糄糍糂糆糃糉糇䊠緸䋻緭緢䋼䌀䋸緜縂縁䋹緖䌄緤緾䌃縀緼緽䋳緵緿䋺緫緥罸羮翧䎿聥聪聦聨聫膔膡膒膖膓䑹䔐蔁䔔蓸䔎蓵䔚蔄䔙䔡䔣䔟蔛蔃蔳蔴蓭䔥䔫蔵䔖蔅䔢䔛䗎䗏蝽䗋䗑䗌蝱䘗衜裦褏褛䙑覩覥觮誱䛭諌諎䛴誷䛱誵諩誴䛸誳諁誯諊諐䜯䝞䝝貎䝶䝷賩賛䝾賫趤䞶趢趝䞳䟼䠃䠀踪踨䠊䠂躸躶躹躷輢輫輧輙辤䢇辳䢦遱遪䢩䣞醈醌銿銸銾鋜鋥䤮䤭鋔鋖䤰鋚鋭鋳鋛䤫銹鋢鋫閯䦞閲閳䦟䧥䧤隣隥䧽䧼䨗䨙霊䨘鞇鞉鞌韯頪頢䪿頟䬼餙䭫駊駚䮃䮁䮀駞駈駠䯊骶骵䯎髴䯶魫魩魲魣䰹鴍䲮鴁鴌䲵鳸䲳䲴鳾鴂麄䴢麫麪儝儖㒙儫儞儛劐劔匔𠮉㘂噧㘁噻㚡噟噡㗽㙳㙴㙰㙶㙵㙲壃壊墻壀壄夁嬟嬘嬑嬕㜩嬒嬜㜪嬢孹嶦㠖嶳嶫廪㢙彛㣓㣵憷懎憹憽懏懀憙憗㦔憻懐懄戱㩖擓擀㩙㩘㩕㩒擜擑擈㩗擕曃㬛㬜暿曅㬙曍曁朆㬿朤橣橂橒㯛橃樳㯢橬橗㯗橌橮㯞㯥㯤㯦㯖樷㯟橱橊樶㯜橓㯚橅橰㯓㯐橜歘歗㲆㲇㲭㲷㵘㵪澻㵢㵡澾㵩㵬㵥澵濓澟㵦澷濵熷㷵熻㷴燑㷷熺㷸熶燌㷻㷼燓璛㻵㻸璖璢璝甊甎㼼疁瘬瘷瘶瘻㿅皡䁢䁣瞘䁧瞖䁦䃙䃛磖磫䃘磜䄛禥禩頴穓穅䅹穏䅽窽窻竱䈬䈷篬篭䈰䊚糓䨀糡䌅縙䌇䌉䌊縧縦䍹䏂聮膯䐸䐷膭䐡膪舘䑞䔽䔷䕀蕯蕌䔝蕂䔳蕜蕰蕟䔵蕚蕋蔿螊䗘螋螡螎䗜䗞螙衞衟褤覧諪諽諹諣諥䛺䛻諬䜽䜻䜿䝟賲䝿賯䞄趦䞼䠔䠏踻蹃䠎踲輱輰䡠輭輼辧辥䢫䢬䢪鄷醕醎䤄醖䤈鍅錊䤳䤴鋽錗錜錇錱録錬錰錃錅䦢闂䦤閸䦥闁䦧䦦䧧隫隷䧿䧾霕霔䨝䨜霌霗䩧鞓䪔韒䫅䫈頶頱頳頼頮頺䫉頽䫇颵餢餣餠餜餝䭬馠䮊駯駨駫䮈䮇䮆駡駦䯏䯐髸髵䰜䰣鮃鮔鮄䱉鮋鮏䱃鮌䱈鮉䱇鮎䱆䰿䱄鴤鴏䲾鴓鴊䳂䲺䲼䳆鴪鴚䳈鴧鴖鴡䴣麅麬黆黅黗齓㒝儨儧凟㔤匵厳㘇嚊噽嚉㙹壍壐㙸嬫嬳嬩嬵嬱嬷尶嶾㠙嶹㠜嶻㠘懢懛懝懜懚懞懙㦽㩝擟㩚㩞擡㩜擮㩛斵曔曕㬡曐曑曎曗㬠㬲橽檊㯲檧㯵檙檂檈㯮㯬檆㯪檘檝㯭㯳檏㯶檪㱈歝㱉氊㵵濗濖濥㵯㵻㵳濙濚濪㵰濶𤀹㵸燣㷾燩㸀燫燯燳燪㸁爵㹕㹖㺝獱㻹璮㻿璳㻽璬㽃㽁疃癅癎癄皣皥瞦瞤䁯瞩瞯䂊䃥䃦磰磸磱䅿穉䆵䆶䆻竂竲竳篺䉀篶篼簘簈簒䊝䊜糛糚䌏䌑䌍縬繍䌔䌓罆翶耫聴膸臈䒂䒃䕍薒薓䕏蕿薫薗䕌䕑薬䗮䗨䳋蟎䗫蟍䗢䗦蟁螱蟇䗬襀䙙褿褹䙝襃䙗䙛䙖覫䛾謟謉謌䜀䜁豀䝂貖䝠賶賷䠝蹆蹏䠾䡩䡥輽轁鍦鍸鍝鍩鍴鍽鍮鍲鍳鍢鍈鍓鍟鍨䤸鍿鍫䦮闄䦭隯隭隲䨂䨟霚霛䨢䨠霣䩬鞛鞟䩨䩭䩮䪠䫎䫐䫑䫋顀䫒顇䫓颷䬠䭎䭋䭲䭰馢䮒䮏騀駳䮐𩤷駵䯙䯕骽鬀鬂䯺鬴魉鮟䱋鮣鯍鮙鮝䳐䳎鴹䳏鵄䳄䳌鴵䳒䴵麯㸃儯㒞㒯龎㘌嚟嚛嚞嚖嚔嚠㙼嬻㜰㜱嬺巁彍彝懳㦡㦞㩨㩡擶擥擪擧攅㩥擹㩦㩪㪫檫櫭檲檰檵檾櫁櫈㯹櫀檼檱櫄檿㱘㲊瀈瀃濽瀐瀂瀓㸄燺爀璹璼㼂癛癑癏盫瞸䁹瞹矁矀瞾礋䃫礇礊礈䃩礍礆礕禯䄠䄡䆁竵䉍䉙簮䉘簛𥳑簚簤䊥䊩糦糣䌚䌙繝繏繦繛罇羴䎖䎘䎗翺耮𦗟䏈臎䑃䑅舙䕒䕗藌薲䕕薻藁䕖䕘䗲蟩蟮蟰蟖蠎蟚襈襊襎襍覱覰観䜇謸謲䜈謭䜊謴䌛謩豴䝡䝢贃䟂䟆趩蹘䠞䠥躿軁轊辬䢰邉鄽鄼醩鎠䤽鎐䤾鎓鎋鎭鎻鎸䥆䥅鎜闘闗䨃䨦䨧靝鞩韚䪘䪭䫕䫚顔䫙顕顋䫝頿颹䬕䬖䬗䬡餸餶饀䭌餻餹馤䮖䮘騈騌䮛騐䯿鬇䰥鯒䱏鯂鮻鯌鯋䱖䱔鵍鵣鵎䳘鵐鵕䳗鵞䴦麐麲麱䴸黊鼂鼧鼦䶊嚯壠壜㜴嬹㜵嬾孼寳寳㞡屫巄攋攈攊㩬㩭旜㬩㬪曡曟櫎櫔櫣櫗櫢㰀㰈㰆㰅櫘櫕櫒㲱㶈瀞瀬瀥濳爄爈爕㸉瓄瓉瓇瓈皩矆矅礘礚礠䄢穦穤穪䇕䉡䉝䉢䉏䉠簵簴䊪䌢繬䌡繮繱䑆臔臖舚艢艥艶藛藖䕢藧䕠䕤藴虩蟽蟕蠇䗶蠏蟸蠁䘙覇䚑覵觵䜍謿譃譌䝥贋蹫蹨蹰蹵蹷䠿軃䥋鏩鏪鏋鏅鏆䥈鏭鏓䥒鏥䥊䥑鏛鏫鏠鏁䥐䥌闝䧮䨄䨆䨭䨮䨯䩻䩸䩷鞲鞴鞱鞵韲䪢䪡䫣顖䬚䬙䭗饄䮞騦騟騘騡騗䯝鬉䰅䰨鯅鯟䱠鯣䱦鯩鯮䱧䱨鵦鵺䳞鵮鶑鶃鵶鵾鵭䳡麕黣䵩䵷鼃鼄鼅鼗㒦儶㒥匶㘔孂㜶㜸孄孆孾巊巈巌廰㩰斅曥曧㬫㰑㰊櫴㰍櫩㰖㰐㰉㰎㱺瀽㶌瀶㶑爖㸊爒爏爗爑爋㸈㸋瓍瓐瓌瓎瓑㼇疉䃲穯籋籄籕䊮䌤䌦䌧繿譱䎙耯聺聻聼䕟䕪䕱藼蘐蘂䕬䕯藮蘃䕨蘍蘓蘈蘏蠘蠒䘁䙨襧襥覻譩譢䜓譮䜖譡䜘譤譍豑䞉䟈躃軆䢄鐟䥔鐄鐗鐈鐅䥖鏳鐞鐂鐚鐛鐧鐜䥚隵䨅䨇霳䨱霴霱鞸鞺䪣韼䫫䬜飃䭚饍䭛騯䮦䮪䮥髉䯡髈髄鬪鬸魐䰪鰊鯻鰄鯼鯾䱲䱯䱳鰂鯶鰛鯹鰕鰐鯿鰀鰌䱫䳦鶕鶥䳩䳬䳨䳰麘黁䶌齚兤嚻㘕壦孉寷㝱㠦巐㣆忂㦨攑㩳櫿櫾櫽㰕欁㶘㶗灋灍灇瓓癨癧㿗䂀矑礰礲礮䆈龝竉竈䉩籖籑䉫䌩䌨纅纉纎纄䑟艪蘫蘯蘨䕴蘖蘕蘝蠚䘂䙪䚔覼譵譼䞊贑贒轜醻䥤鏴䥞䥡鐭䥥䥦雤霷霶靧鞾鞽韢顨顦飈飊飇飜䭟饏馩騼䮯騻髎䰍鬔魓䱼鰮䱺鰟䱶䱹䱽䱷鶰鶽䳶䳱鶮鷄鹻䀋䴩麞䵯鼅䶚齩龡囇圝孊巓㡩彲戂㦫戵攧攞攟㬬㬭欇欆㰗㶚灔爜爠竸䉮籝籘䌬纒罎䏊䕸䘆蠧䘇讁讃䜠豄䝓䟎䡺䡹鑂鑍鑔鑎䥫鑃䥧䥭霼靀韀䪅䪰䫳䭣饚驑䮶髝䲂䱿鰸䲆鱅鰺䲄鰵䳼䳽䳺黱䶇龓劚㘚㘙虁巗㩵攨㰚㰛㰙灓灙㶜爡㸐癯礷禵籢籞糵䕻䕼蘽䘈蠭覉讍讇讐贚躙䡁䣣䥲鑟䥳䥨鑖鑡鑛鑚鑜鑥䨵靁䪈䪝顮顬顭䉵馪驜驘髞䲏鱏鱑䲎鱓䴅䴄䳿鷼鼹䶞儾㕔囒㚁孏欔䃺穲穳䌲䙰䙮讑贛䡼醿䥵鑬䥴䥷雦靅䨷韈韤饝䮺鬡䰑鬬魗䲒䲙鱝鱡鱥鸈䴊䴪䴫䵄䵹齅㒪㝈戅曯欛欝爥爤籭䊴虂虉䘍襸覊讗䝔貛贜䠰躢䨉䨸䨶䪋䫷䭦䮽䰞鱬䲖鱯䴍鸎䵳鼈鼝龣㜻㜼彠欜㶠䃻\\\xd0\x00\xb4糳䖁讛躧釄䥸鑺䪍䭳驡驣髗鱶䶦龥㦭灧㸑犫虊襽讝豓轥鑻䨹飍飝䮿鬰鸖䴏䶧䶵㿜䠱雧鸘麢䶨齼䄥纞钄䯁鸜䆐籱韊䚖䡿䴐麣䨊鱻龗䴒䨺靐䨻]\xc9\x00\f佦佨俧倿]\xce\x01\xd7傦儏刾匤卥厛兿哋啳啱啫嗴嘢嘠嚒嚺嚑嚰坙垊堏堒堓塇墛夀奍嫑嫓孧屗屷峕嵵嶎嶥嶶嶿幥廍廭徔徚徺愥慐抣捠捤掶搃撶攚稥尡曢朂枤枬栐栆桞桚椬椩樭橷橺櫊櫦櫵欍欎毜毝毶氞汣涥渏溊溄溋湼潌潈潉澝澙濸瀮瀭灜秌炿烪烥焑焒焿焹焻焽煷煵煶煱熖熓熴燝燞爎夑爘獇獈珯琒琻璤璍璴癳癷皼砙砛砽硓硔硘碷磘磮礢祍禆禙禣禼窧笷筙篐簯簲籂籎籨粩糭緐縇緓繤罀^l\x00u聁聣肻朑朥艈茐荢茒萡萞蓢蓞蓡蕳藔藵蘷虅虲蝊螩螥蟵蠴衐袇襔覄謃豒賘贌贘趰躼軕辪邜^\x94\x00W酜鈫鈡鉳鋴鋵錿鍂鎆鎾鎼鎽鏱鏲鏯鐢鐣鐤鑧鑦閗闎闧霻靍飤饂鬝鮘^\xb2\x00\x18鵉摉幱庅懓餎耂砞^\xbc\x00\x1e唨啲喺嗰嗮嚸嚹囄聓銰g\x8c\x02\x0e𠂆乀乁乚𡿨丂丩𠄎𠘧刂㔾巜𠔼𠙵勺卂卪孒𡤼尢屮忄扌犭丒丯亖仐兂冃仌𠚥𠚪𠤏𠨎厃厸𠮜𠮛𡈼夨𡯂尣币开𣎴㲸灬爫冈艹辶丗𠁥㐌𠆲㐰仢仛𠑷𠕂冭𠘳凷㓚𠚯𠠶㔓匛厈厇𠬢叏叽㕣叧囜𡆣囙圥圧夳尓𡰥𡰤屵𢁒𢆉𢒿㧄㧃曱𣎵𥝌罒𨸔𨸒㐁𠂣𠂢乑乪𠆶伌𠆵𠆴仾伛𠇂𠆸㐺𠑹㒫关𠔈冎𠕺𠖶𠚽𠚷𠚼㓝𠛄𠛉劥㔕劧劤𠤰𠦃𠦅𠨒𠨴厽𠫤𠫞𠬪吂𠮯𠮴𠮳𠮱圫夛夵𡗞㚧㚥𡚸𡚰㚦𡧂h%\x01W尗㝼𡰪屰屽㞩𡿩巩𢁗㠴㠵𢆶𢇗𢇞㢨弙弜彵𢓂𢖫㣿忈𢦏㧇㧆㧈𢩭𢺵㫐㫑𣏀𣎿㱙𣦹𣬜氒㲽𣲀𤆄𤆅㸨犳𤜤㺩玐甶𦍌䏌𦫶芁辸𨙰䢴𨙵𨸗丣㐖㐬𠇱伮𠇚兏𠖹冺㓟㓠刢𠛎𠛒𠛐㔗𠡃劯劰励𠡍𠤴㔯𠧙卤𠨘叓𠬸㕪𠯗㕰㕭𠯜𠯋𠯖𠯘㕮𠯑呈咞𠯒㘫㘨𡉙h\x89\x05.夿𡗥𡗦㚒𡗨妋𡚽妑妕𡚾𡥉㜿𡧍宊㝏㝴𡭞𡯐㝽𡴆㞷㞣㞰㞱岇㞫𡵼𡵱𡿯㠺㠷帍㠸㡀𢆍𢆷庎㢟𢌳𢍿彺𢗲㤄㤉㤆㣽㤊㤇𢨪𢪋㧊𢪃𢪊抐㧉𢪎抚𢺶攼攺㩿斘𣃘㫒𣅖𣏒来杞㭃𣏂𣢇𣢁𣧂𣦼𣬣㳁𣲓㳅㶣𤆍𤆎㸪㹟𤜻𤝍㹝𤜴𤜱𤣲𤣰㼗𤰕𤴨㽱㽲𤼥𥃧䀏𥃩𥐗秂䆑𦉬𦊀𦍋䎲䏏䏎肞𦣞𦣻臫𦥑芉䒖𦬁𦬂芖𦬅芇𦬃䒗𨑑迀䢋迃𨑓𨙺𨙸𨙶𨙹𨚏𨙼镸𨸛𨸜阥𣶒乵乴𠄮𠄬㐭𠈌侙𠈋㑐㑋𠈅㑏㑌侮佱兓冿𠗂𠗃净𠙈凮𠙆㓤㓣𠛪㓡𠛫㓢𠛮刽㔙劽匌𠣘𤰞𠧟𠧞㕁缷𠩏叀㕞𠰍𠰈㕹𠰉呮咃𠰒𠱥呞呝坾坹𡊉坺坬𡊄𡊞𡊋㘲垇𡕢夝㚔奆㚗𡗹奃𡗷㚖㚘𡘆𡛝㚲𡛙𡛟𡛢㚹孡学𠃱宐㝵㝿㝾尩㞏𡰿𡰾𡴋㞽岲𡶃㞻㞼𡶆峀𢁼㠽㠾㡴𢇷㡺㡶㡸㡵𢇻𢌻㢯㢰㣇㣌𢗹𢗊㤖𢗿㤒㤝𢘉𢘃㤕㤏㤔怟忢𢘐𢦚𢦟㦰㦿㧀𢪇㧒㧙𢺷㩺𢼈𢻹㪁㪂𢻴昖旹㫚𦙗枈𣏖枛㭉杮枞𣏔㰢㰟𣢐𣢍㰡欤𣥠𣧎𣧏㱜歽𣧑㱽㲍㲏泦𣳊泋㳐𣲷㳘𣲲𣲻𣲹沵㶧𤆞炗𤆲炛㸒𤕯𤖬㸯𤘣牫㸮㸬㹜𤝬狕𤜲𤝞㹢㹧㺴𤣻𤬩𤮽𤰟畃𢍁𤰝疜𤴱𤴯㿪𥁃㿻𥁁䀔𥃵䀓䀒䀐䀑𥃲䂆矤䂘𥐞𥝔䄪𥝖𥤩𥤧𥤨竎䊵𦉹𦉾𦒶𦒷耓䏖䏘肷䏙䏔䏗䏕肹j\n\x00]𦙍臤臽舏䑠𦨉𦨈䒞䒡𦬞𦬚𦬣𦬕𦬙𦬖芿苊𦬔𦬘苁䒠𧈟𧗝𧗟𨑩运j%\x010𨑬䢹𨚓邮𨚕𨚔𨚑𨚗𨸰䧁䧂阸黾㐜𠈱㑞侼㑙㑜𠈶㑟俩侽俢兘𠗉𠗊㓎𠜘𠜒𠜙𠜐𠜖勆𠡟勈𠣟𠤗匨𠤼匩𠧪𠧩𠨜𠩘𠩤𠫳㕟𠲗㖅𠱚𠱙㖆𠲎垴𡊼垩㚇𡘍𡗼𡘌𡜇𡜊㛊𡜂㛃𡜦𡜈𡜝㛂孨𡥎㝁𡧭𡧮𡧯㞀㞁𡱐𡱌㞓㞒𡱏㟅𡷔j{\x02I𡶰㡆㡁㡅㡂㠿𢂥𢈉㢂㢁㡿㢥弫㢳㢵𢏳𢏗徍㣚𢓜㣛㤍怘㤎𢘋㤞㤥㤦㤜㤡恗恈㤧㤚𢘺𢘍𢘑怸𢘠𢘁㤤𢘷悔恱𢦪㧁㧡𢫧𢫨挒㧫𢫵㧢㧗㧩𢬵𢫦挊𢫸挣𢫱㪄𢼔𢼕𢼉㪵㪼㫜㫞㫟𣅻昪𣅾𣍦𣍧𣐊㭔柠枾㭓㰤㰥欩㰨㰧𣢜𣥢𣧡𣧞𣧟𣧩㲋𣳤𣳷㳜浃𣳪𣳶𣴒海炦㶲烀𤇙爯𤕟㸛𤕳㸲牱𤘿𤝸㹫𤝽㹬㹭𤤒㺹㺺㼉㼊𤬯瓪㼛𤬰𤯛甠㽘𤰩㽚甾𤵀㽷𤴽𤴾㽹疩𤴸皅𤽈㿫𤿐㿽㿼眆䀕䀘盶䀖䀗䀙眀䀛𥄎𥄉眂𥄇䂇𥍟𥍠䂜𥐪䂚𥐩䃿䃾𥙍䄱䄮䄰䄲𥝬k(\a\x15䄯𥝧䆕䆖𥤹𥤴𥩙䇅䉺𦈣䍂𦊂䍔𦊃𦊨𦍕𦏹𦏸𦏼𦏺耉者䎟䎠䎢䏠𦙴䏡𦙫𦙱䏟𦙶𦨎䑢䑡𦨐𦨏䒧𦬸𦬹𦬺苚𦬷𦬾茊䖉𧆜𧈪𧈺𧈫𧈨𧖪𧘏䘝𧘐𧟦䜪𧴥𧺎䟓䡂䡃𨒋䢑𨒌𨒚䢐䢿𨚳䢼𨚲䣀䧇䧅陕𨹄陒丵𠅘𠊧倴倁𠉣倊㑦𠉪𠉥𠉫㒭𠓭𠗙𠜻𠜲剘𠜵𠜱𠜾𠝃𠜶𠡭㔝𠣤㔱匫𠧴㕌𠩩𠩫㖕㖑㖔𠲿𠳃𠲮㖏哬𠲱唕哾𠲻𠲯𠲰哣唖㙀𡋯㙁𡋭埓𡋰𡖓𡘴𡘫奙娧㛘㛒㛚娨娐㛗娎㝂㝗𡨄𡨃𡨂宯𡨅宺宱𡨀𡭴㝸㞂㞗𡱥𡱣㟉𡷗㟈㟖㟌𡷨㟐𡷛𡷘㟎㟇峼𡸉㟏㟋峲峾𡷥𡷧巸𢀿㡇𢃀㡈𢂹𢂺㢃㢅㢄𢈠𢍏弬㢸㢼𢒑𢒐𢓮𢓳𢓬𢓱𢓫𢓲𢓴𢓭𢙁𢙈𢙅㤱㤴㤶悂𢙿𢚋㤣𢘻𢙎㤺悑悏𢙼㦴㦵㧭㧬㧘𢫰𢬶𢬿𢭃𢬱𢬴𢭆㧶㧧𢬯𢻃㩼㩻𢼶㪈敇敌𢼡敏斊𣁳㪾𣃦㫧㫥㫮柡桊𣐿㭙㭞𣑫㭚栿㭡㭘㭛枽𣑱㭟梅㰪㰭𣢰㰣㰫㰬𣧳㱾殺㲓㲙𣭡𣭟㲳㳧㳪𣴨𣴞𣴩流𣳫𣵦㶴𤇶烉㸗𤕠𤕷𤖻㸡𤖼㸸㸷㹴㹳㹵𤞢𤞲𤞞㹶㹸𤤺𤤸玺㼌㼠㼞𤱍㽛𤱞㽜𤴗㾃痆㾀㾁㽾㾈㾅㾉㽿㾎𤵤𤵧㾄疷皌𤽙皍𤿚㿮㿭㿾𥁑㿿𥁕𥄻𥄴䀣𥄷眗𥄱眜䀟𥄿𥅙眔𥍡䂏䂐𥑘𥑢𥑐䂠𥑑䂦䂟𥑆䂧䂡𥘭䄂䄶𥥈𥥑𥥊𥥏𥥐𥥉𥩣䇈𥩦𥩠䇘笌䇝䇜𥫱䇗𥬇䇚䊺𥾛𥾣𥾝䊾䋂䊻䋀𥾩𥾧𥾤紣𥾺𢇁𦈦䍃䍕𦊔𦊓䍗䍨羘羐𦐇𦐍𦐂𦐌𦐉𦐈𦐓䎡𦓒耺𦔾𦔼𦙧𦚞䏪𦚭䏧𦛙𦙂𦚣𦚠䏨䏩𦙼𦤄䑔𦥘𦥠𦧉𦧈舦䑥𦨜𦨚䒊𦭴䒰𦭮𦭯𦭹䒼茮𦭷荕茚䒸茽𦭼䒲𦭰茰䒽𦭺𦮂䖊虑䖡𧉄䖢𧉃蚈𧉅䘕𧘥𧘟䘠䙳𧟨覎覍𧠉𧢷𧢹𧥤𧥣訉訔䚱𧥢𧥥䜫𧮬䝅𧴪䞗䞘𧺖䟕𧿂軔䢓䢖逇䢔𨒪𨒰䣇䣅𨛔𨛍𨛖䣄𨟰镹閄䧎䧊䧌陗䧉隺m0\n\x7f𩵋𠅢㑮𠊷偧𠊬𠊫㑱𠋁㒻㓓𠗨𠝩𠝝𠝟㓳㓭㓵𠝤𠝢㓱㓲㓶㔠𠣹㔨𠣮匘匬卙𠦫𠦬𠦪卨卾𠪆𠩺厡𠴨㖡啛㖪㖩𠴱㖵㖥㖨啂㖧啇𠴭啓唽𠴫𠴲啠埫𡌩𡌦𡍓㙍𡌳𡌴𡖝㚜奟𡙁㛪㛥𡞑𡝩孯𡨢㝞𡨴寃𡬮㞆㞄𡯴屚𡸨㟙𡸜㟞𡸑𡸤𡸕㟛𡸖𡹔𡸣𡿿㠱𢃏𢃐㡋𢃎㡉㡊㡌𢇇𢉜㢊𢈶㢉㢋𢉃㢻徢徝㣤𢔪𢔁徣𢔋𢔏㤻㤮㤾㥆㥓𢛟㥄𢛒㤿㥂㥏𢛍㥒㥊惂𢛛㥚悥㥉𢛏戜𢧀戝㨃𢮁𢮂𢮊𢮨𢮌掕掚㨁㨆㧻㧹𢮎㨅㧺𢮋掦𢮖㧾𢯌㩽㪌𢽏𢽊敐㪎𢽋𢼽敒𢽄𢽉𣂞𣃳旈𣃵㫰㫲𣆳晘㫱㫦𣇥𣆶朗梈𣒆㭯㭭㭩𣒶𣒅㭮𣒫㰯㰰㱣𣨍𣪋𣪌殸㲀殹㲚㲔㲖㲕㲗𣭲㳴㳾𣶏㴁𣵹㴂𣵷㳹𣶐𣶈𣶀𣶞㳶㳷𣶂𣴴𣶉㳿𣶅𣵾𣵺𣶦渋𣶵𤇴㶿㶼焅𤈩𤈦𤈪㶾㷀𤕾㸻㸹𤙛𤙡𤙠㸼𤞛猔猄𤟃㹽𤟍㹿㹺㹼猅㹾猕猍㺀㺿㻃𤥔𤥙㻌㼎㼍𤫵㼦㼤㼥㼣㼡㼩𤱩𤱥㾏㾍𤵼㾋㾒𤶀㾐𤽥㿯㿰𤿟𥁠䀁𥁞䀮䀭眮䀨䀩䀬𥆏𥅡𥅚睁䂈𥍧䂒䂑矪𥎻硄䂩䂬䂮䂪䄆䄅䅊䄾䄼䄻𥞛䅂䅁䅃𥞩䅅𥥦䆚䆛䆟䆞𥩲𥩶𥬔䇞䇢䇦𥬏笩䇣笧䇟𥹁𥹆粝𥹋𥹃䉾䋉䋌𥿆䋑𥿇䋐𥿎䋏䋈𥿉䋍𥿋𥿅䍉䍇䍘䍫䍬䍪𦍩䎀䎂䎄翈䎃𦐞𦒺䎶𦕒𦕑𦕓𦚟𦛛脦䏱𦛣䏶䏳䏰脜𦛗𦛩脨䏵䏴䏷𦛞𦛜𦤼舁䑕𦨢𦨲𦨣䒋䓋䓂𦯔𦯈𦮼䓊䓌𦯍䓇䓉荰𦮾䓆𦱀𦯖䓃莂𦮽莬𦮺䓄𦯀莭莽𦯁𧆦𧆥𧆨䖩蚭䖨䖦𧉟𧉰𧉪𧉥𧉧𧉯𧊇蛊衑𧘽𧙃䘢𧙀䘦䘤䘥袝䘪䙴𧠘䙹覐䙺𧠙𧣁觘觗䚗𧢼𧣈訮𧥱䚼𧥮䚳𧥸䚿䚷䚾𧥺𧮱𧮯𧮰䜴䝆䝈豙𧲨𧲦䝖䝧𧵈𧵅䝨䞙䞚𧺠𧺤䟘𧿔䟛䟜䟝䟗𧿒𨈢䡉䡊軚𨊷𨋌逳𨓦逘䢚𨓈䣍䣊𨛴䣋𨛭䣏𨙾郱䣌䣪䣭𨟹𨟽䣩𨠇䤚釯䦍䧒𨹸𨹺𨹹𨾊䧲𩁻𩇕𩇩䨽𩇫䨾䫸䬣髙亴𠅬傠傐㑵傆傄𠋺傊㑶㑳傟傡𠌘傓㒽𠗶凕𠞊𠞉㓹𠝿𠞈㓺𠞃𠞄𠞘𠝣𠞇㔡勤㔩𠣵𠥐㔸㕎𠪇𠪊𠭥𠶷𠷁𠷇㗈㖼𠷅𠷋喐𠷑㖻㗂㗀𠶸𠷊㗊𠷞㗉𠷎㖸圐堗𡍨㙎𡍫塄㙐堫𡍪塅𡍦𡍷𡎖𡍮堲㚊𡙗奥𡙖𡞜𡞞㛼㛹𡞡㝣𡩂寏𡩆𡨾𡬳㞇㞈𡯺㞚𡲗𡲛𡲪嵖𡹳𡹼㟩𡺆㟪嵔㡐𢃳𢃰㡖㡒㡏㡔㡑𢃬𢊯㢎㢏𢉤𢉨𢍚弽𢔟𢔥㣭㣫徤𢛆𢛖𢛃㥢𢜬㥝𢜮𢝞𢜳㥡㥥㥜𢜭𢝆㥟𢛁𢜺𢧌𢮝𢮃𢯶揬㨖㨊𢯸㨎㨒𢯾𢯳㨑𢯫𢯺㨐𢰇𢯼㨉揺㨕揔𢽲𢽳𢽴㪑㪕㪓㪒㪏𢽟𢽠𣂪𣄴㫶𣆴㫻𣇰晵㫹𣈍𣇳𣈏朜㭽𣓉㭺㭿𣓅㭸𣓗𣓌棙𣓧㭰㮂𣓁㮄𣓨㰳𣣋㰲㰶𣣈𣥺㱖𣥹𣥾㱧𣨛㱥㱤㱦𣨟𣨙㲁㲂㲃㲞𣮉毱㲝𣮋㴏㴒𣸉𣷾𣸀㴐㴘𣸋㴔㴙渷p:\r\xc9𣹊湽溆㷈㷄㷆𤉹㷇㷃𤉬𤊙𠔥𤉢𤔔㸙㸜𤗈𤗗𤘌㹁𤙰㹂㹃𤙱𤟟𤟤㺂𤟧㺁㺔㺄𤦐㻒㼏㼢𤭌㼧㼪瓺㼨𤭏㽠𤱽𤱾𤱼畲𤲍𤴙㾛㾜𤷀㾓㾔痜㾠㾙𤶶㾡𤶨㿱𤿫𤿧𥁯𥇇䀶𥆟䀷䀿䀼𥇄䀴䀹䀻䀵䀰䀲䀸䀾䀺𥆛𥆗𥇅𥍫𥏎䂶硧𥒱𥒮𥒰䂳䄉祷𥜾𥞵𥞺䅌𥞲𥞴䅓䅍䅑䅏窛𥥷䆢𥦆𥥻䇍𥩾𥬮䇧𥬯䇰𥬷䇩䇬𥬲䇨䇮䈂𥬶𥬹𥬵𥹜䊂䊃䊆𥹻𥹡𥿮𥿫𥿯𦀠紪絥䍊𦈰䍚罤𦊾䍮𦍧𦍼䍰䍯𦐤䎊䎇䎈𦐩𦓯耠䎷䏽脠𦜍䐅脼䐈䐃䐂䐄㬹䐉䏿𦝤脿𦜕䏼䐁䐊臰臵䑫䑬𦨵𦨶𦨴𦨷𦱇𦰫䓞𦱁𦱃䓛𦱂䓝䓣𦰲䓠䓙䓘䓤𦱄𦱊䓚䓯荓萕莾𦱔𦱮𦱒䓗䖳䖻蛥䖲蛒䖭𧊒䖯蚈蛠𧊿䘖䘨䘣袳𧙁𧙩𧙥䙵䙾䙼𧠢𧠝𧣒𧣚𧣛䛎䛍䛏詓𧦤𧦞訷詇詜𧦝䛄䛌䛈䛇𧦭𧦦𧦧𧮳𧯠𧰵𧰷䝚䝪𧵊䝫䝭䝯𧹞䞞𧻀䞤䞣䟤䟨䟩䟠䟣𧿹䟪䟡䟭䠴䠵䡑軰𨓰䢝𨓳𨔛𨓲𨓻𨜜𨜓䣐𨜏䣮𨠖𨠑𨠒䣲𨠎鈝䦈𨱧𨳲𨳚䦏𨳜䧘𨾔𨾛𨾒𨾚𩂈𩂆䨿𩇯䩒靫𩉞䩐𩑘䪱𩑖𩑔䫹𩖛𩚖𩚤䬦䬨𩚚𩨒㒀僀㒁𠍫㑾㑿𠌷𠌼僧𠓷㒾𠕦㓖𠞩𠞮𠞭𠟈𠞥𠞰𠢕勥𠤥㔴𠪙厯𠭴𠭰𠭯𠹁𠹔㗕㗘𠹀𠸸㗔嗘㗙𠹒𠸺𠹗嗂𠹙圑塧𡎴𡏀㙝塃㙞㙙𡍵㙚㚠𡙠𡙇㜅𡟰㜇𡟯㜎㜌𡟬𡦃㝅𡦄𡦏㝤𡩡㝥𡩩寝㝦𡩢㞉㟬㟱嵰嵪㟰㟸嵴嵟𡺮嵡嵳𡺲㟲㟴𢄋㡘𢄐𢄍㡚㡙㡗㡛幐𢉿𢊁㢦㣂㣁弿𢐋𢕎㣯徰𢟪𢝅㥨愵愭㥳㥮㥴愂㥦𢜨𢝝𢧜㦹戦𢧔㨌𢱤𢱨㨠𢱢㨝𢱡㨜𢱦㨡㨚㨙𢯲𢱩𢱧㩾𢾚𢾛𢾅𢾜㪝𢾤㪞㪜敮𢾙𢾆𢾐𣂁𣂮斱旔㫍𣄸㬋㬊暅𣔻㮏楲㮕㮐㮑𣕌椺㮹𣕇𣔵㮗㮞𣔳㮋𣖂𣕀㰻㰺㰼㰽㰹㰾㱫㱬𣨶𣬎㲢㲡㲟𣮭𣮪𣸗㴭𣹧㴶㴫𣹲㴷㴳𣹡𣺰溭㴥㴯㴬𣹮𣹤㴲𣺌𣹦溕𣹫𣹟𣹴𣹥𤊻𤊾㷕𤊹㷔㷖𤋯㷑牃𤗚㹆犏𤚐𤟩㺌㺊㺈𤠑𤠍𤟭𤠡㻝𤦁𤦱𤬁㼭𤭞𤭢㼮㼬㼫㼰𤭛㽎𤲖𤲓㽤㽡𤲒畵𢆟㽰㾦㾤𤷄𤷇𤷃㾨㾢𤷈㾧痬㾥𤷊㾩𤷅𤷏𤷜𤽼𥀁䀽𥇓𥇜䁄䁅䁆𥇔睗𥇌䁇䁈䁂𥇖𥇛䁁𥇕𥏙䂔𥏝𥏜𥏠𥓌䃀䂸䃁䃂䂻䂽䂿䂹䂷𥓒䃇𥓓䄌䄋祻䄐𥚍䅝稡䅚䅘䅖𥟘䅔稖𥞼䆨䆧䇏䇐𥪊竨𥪋𥪍䇑䇫𥭓䇻筪𥭙䇽䇸䇷䈀𥭖𥭗䇶筟𥹳𦀛䋟𦀡𦀖𦀘䋢䋤𦁄綂𦀦䋣𦈶䍙䍱䎎䎌𦐺䎤𦓴聕䐔𦝢𦝛𦝪䐑𦝨䐛䐒䐎䐕腨䐐𦞁䐗艆艃䑰䓭𦳧䓮𦳦𦳑𦴑蒁𦳐𦳙𦴊䓴𦳚𦳝𦳈𦳢𦳩𦳋葼𦳌𦳁葪𦳞蒈䓲䓸䓳𦵑𦴒䓺𦳠𦴫虜𧋘𧋑蜐𧋒𧋍蜟䖶䖷𧌽蜏𧋊䖺𧋋䖼𧋗𧗴䘻裓䘯𧚇𧚋𧚃䘷䚙𧧅𧧒𧧆𧧓䛙䛘䛗𧧰𧧍𧧌𧧜𧧖䜶𧯦𧱁𧰿貇𧳅𧳀𧳆𧲾𧲿賆䝰𧵣𧻓𧻚𧻕䞥䞦䞩䟮䡔𨋮䡗𨋼𨐔䢡𨔣𨔢𨔰𨚵䣓𨜸䣔𨜿䣗郌酭酰䣷䣶䣹䣸鉘鉂鉨鉩𨥨𨥧鉃𨥥鉇䦊𨱵𨳷𨳳𨳶䦔𨴀䦓𨳿𨳵閝䧝䧜䧷雏𩂒𩈆䩂𩈇𩉫䩔𩉬䩕𩎓䪏𩎔韴䪴䪳𩑢𩑣頉𩑟𩑤𩑛𩑙䫻䫽𩖤𩖬颫䫼𩚵䬬䬯𩚮䬫𩛅䬳䬴飻𩠻骬骮骪䯨𩪿𩰫𩲄𩲀𩵍𩵏魜𩵌𩾒䵝僒㒅㒈𠎮𠍹㒎㒄𠍱僟𠎳㒃𠌬𠍷𠔯𠟃㔂𠟍㔆㔅𠟉㓾㔁㔀𠟎𠢥𠢠𠣾𠥙𠪚㗧嘙𠻤𠻗𠻜㗦𠻧㗭㗢㗫𠻥㗨㗬嘊㘤墚𡐔㙢𡏱㙡㙠㙤㚋𡖶𡙮𡙬𡚁𡡂㜙㜖㜘㜢𡠚㜝㜗㜞𡠧𡠥𡠗𡠜𡠽𡠠㝩㝪𡪁㝬㝺㞊𡰉𡳆𡳄㞜𡳅㟽㟹𡻯㟿𡻟𡻞𡻮𡻵𡻬㟼𡻝嶅㟻㠂㡟幖㡞㡝㡜𢄢幑㢒𢊗𢊕㢔㢕㣑𢒩㣱𢕓徱𢕖𢕕㥷㥯𢟣𢟢㦇㥽㥼㥾㦅㦋㦆㦀t6\x01f𢞩㥸憎𢠳𢠊㦺𢩙㨳𢲼㨭𢳄𢳇𢳆㨷摚摣𢲸𢳚㨮𢲷𢳀㨽𢳍㪠𢾼𢾫㪣𣂆暛𣉚暣㬎暞𣍃㮶𣖬㮫㮭㮦𣖾榸㮪榺㮱𣖫㮨㮯㮰㮧㮲㮝㮥㮵𡬾槇槗𣣹㱁𣣳𣣷歰𣩅𣩈㱲殝㱯㱱𣩄㲅𣪯𣪱㲤㲥㲦𣯏㲨𣯋𣯍㲣𣱐𣻬𣼚漖漤𣻘㵃𣻐𣻑㴽㵅𣹻𣻒㵄𣻔𣻧𣻟𣻞潅𣻙𣻏𤌍㷣煹t\x9e'\x13㷟㷤煺㷥𤌸牔㸢㹊𤚩㹉㹄㹋㺇㺉㺒㺑㺐㺎𤠾𤡆𤧭瑱㼒㼐㼑𤭬㼲㼴㼷㼳㼶㼵㽥𤲬畼畻𤷉瘒㾰𤸒㾯㾪𤷽𤸁𤸑㾫𤺉𤸅𤷾𤸋𤸠𤸄𤾈㿳𥀂㿴𥀃皶盠䀈䁎䁕䁋𥈩𥈻䁍睳𥈆䁒睲䁌睰䁐䁓𥈃䁑𥍸𥍹𥍽䂉𥍿䂕䃌䃈䃎𥔲䃊𥔀碝𥔢䃍𥚩䅜䅧䅩䅥䅢𥠄稧稵穀䆪𥦸𥪛䇒箢䈃䈌𥮒䈉䈆䈅箉𥮏䈋䈈𥮘箥𥮮䊎𥺝粶䋨𦁉𦁐𦁆䋪𦁎綥緃𦁕䋩䋫𦁤䋰𦈸䍋羫䍳䍵䎒䎑䎓耥䎧䎦䎨䎪𦓾䎩聙䎻䎼䎺䎽䐧膅𦞦䐤䐞䐟䐝䐠䐣䐩䐢𦞜𦞙䐜膎䐨𦞵𦞣𦞠𦞧𦞛𦧟𦧡䑱䑳䑲𦩍䔂𦷳𦵯𦵩䓾蒾𦵫𦵸𦶇䔈𦶅𦶂𦶐䔇䔄𦶀𦵧䔀䓼𦵡䕄𦶓䓱𦷲𦶆蒦䔆𧇍𧇄䗆䗃䗁䗂𧌏䗄䗇𧌍䖿䗒𧍳䗊蛢𧌊蜯𧌈𧌓䘓𧗸䘰䘾䘹𧚫𧚨䘼䘿𧚥䘴䘽𧛃𧚤䘸𧛔𧚺𧟱覠䚂𧠿䚃𧣪䚛𧧷䛠䛝𧧺𧧵䛞𧧾䛣䛥𧨀誗䛢𧨂𧧻𧨇䛟𧨚䜮𧮸䜷䜸𧯩𧯫𧱐𧱏貋𧶆㕢𧶎䞔䞓䞫䞭𧻰𧻻䟵䟷𨁂䟶䟸𨂅䟻䟹䡘䡚𨌅䡙輏䢅䢤䣘䣙𨝋䣝䣈𨝣䣺䣻鉵䤧銟䤪䤥𨦙䤨𨲁𨴗𨴐𨴒閦䦕隚𨻺𨻳𨻲𨻵𨻶𨾻𨿂𩂧䨏䨑𩂨𩂣䨐𩂪䨕䨓靗𩇜䩇䩆𩈏䩙䩛𩉹䩞𩉿𩊅䩚䪑䪒䪐䪓𩐛韷䪾𩑷𩑶𩒘䪼䪻䪷䪹𩑺䬁䫾𩖼𩖺䬂𩖶䫿颰𩖴䬀𩛏䬷䬺䭯䭷䭹駆𩡻䯈𩨜𩨡𩨝𩫀䯲𩫿䯱䯰魢𩵖䰴𩾡𩾢𩾦𪉖𪉗䴬䴭𪌁𪏭鼻𪗲僺𠎸㒑𠏀㒔𠎷𠏡㓄㔊𠟨劅𠢲㕒𠪮㕙㙯𠽾㗲㗴噃𠽶㗱嘼𠾖㙪𡐠𡐡𡐣𡐩𡙷𡡑㜤𡡖㜣𡢈𡡙𡡕㜧𡦗𡦘㝭寭㝮㝯𡪡𡪣尵㞟㠊㠆㠎𡼊㠍㠅𡼽𡼌㠋㠐㠮𢄽𢄹𢊰𢊲𢊮㢖㢗㣄彇㣅𢐠㣒𢕮𢕭𢕪㦁𢠲㦎㦐㦏𢠹㦑㦕㦓𢞣慭𢡿𢠵𢠽𢧴𢧵㨻㩈𢵈𢴨㩆㩇𢴧𢴲𢴬㩌𢴮㩊㩐㩃𢴣𢴱撝㩉㪦㪥㪤𢿉𣂉㪹𣂻㫎㫏㬓𣎗𣎓𣘨㯆㯅㯊㯙㯁𣘘𣘻槵𣘧𣘤𣘛𣘱㯋𣘦𣚣𣘿𣘢𣘙樃𣙁𣘫橥㱂𣤌歵㱴㱳𣩎𣪹𣫺𣯩𣯨𣯤澊潖㵒𣽟𣽝𣽒㵐𣽴㵋𣽆㵌㵭𣾪澘𣽊𣽙𣽋㵏潨㷰𤍐爴牅㹍㹌𤛐㹏㹎㹐𤛌獜㺖獖𤡤𤡥㺗㺕㺘𤡜𤨕㻬㻫㼓㼔㼸㼺㼹𤭹㽨㽧𤳅𤲸𤸬㾾㾼𤸷𤸵𤸱㾻𤸯㾺𤸫㾹㾷𤸭𤸪㿁𤾕㿷𥈾䁠𥉇𥉅䁙䁜䁚䁘𥉒䁛𥉰𥉐𥉘𥉑𥈽䁝𥉁𥈌𥈼𥎆𥎃𥎉𥏳䃔䃓碿䃒碽𥔭䃗䃑磤䃕𥛅𥛅䅭䅯稴䅵䅬䅴𥡃䅳𥠷𥡅稶䆬𥧥𥪦𥯛䈐𥯚𥯦箺箼䈏篈䈣篃䈝箶䈎𥯸𥯩䈕篂䈓𥯨𥯶䈛篅䈢䈦𥯧箽箰䈡䈜䈗𥯕䈚箻𥻓𥻇𥻑䊙𥻃䊗𦂀𦂘䋽𦂄䋶䋾䋵䋿䋷𦂗𦂃𦂁䌁縄𦂌𦉂𦎫䍷𦎣𦎦𦑜𦑩𦑧䎫𦖋𦖨䏀𦟘䐱䐮𣎓𦟜䐬𦟠𦟥䐲䐯䐫䐭䐳𦤘𦤙臱䑗䑜䑻𦩟𦩞䑺𦸂蔢䔓䔞䔗䔠䔪䔑䔕䔏䔘蔸𦸒𦸶䔬蔲蓱蔐𦸣𦸃䔜𦸀䔩𦹆𦹡䔒蕏䖗𧎄蝲𧍫𧍢𧍖𧍪𧍒𧍕䗔䗐蝼𧍡蝹𧍘𧍷䗓䘔𧛗䙆䙅𧛞䙃𧛟褍䙄䙈𧛑䙇𧛢𧛡𧛝褈䙉䚅𧡋䚄𧡎䚝𧣼䚞䚠𧣺䚜𧣻䚟𧣾䛷䛪𧨱䛯䛩䛳䛰䛫𧨸䛬䛲䛵䛮𧨺𧮻䜺䝋𧱙䝊䝌𧳛𧳟𧳢䝹䝻䝸𧶞𧶡䞵𧼐䞴䞷趞䠉䟾䠈䠇𨁶𨁿𨁽䠆䠄䠅䟿䠋𨂂𨉗䠻䡝䡜䡟䡞輨𨌮輡䢃𨖋𨖍䢧遦遬𨖐䢨𨛬𨝯𨝫𨝱𨝸郶䣠䣟䣾䣼䣿䣽𨦭䤬鋬銺鋣銽䦝𨴯閴𨽸䩀𩇸𩈙䩟𩊛𩊔𩊗𩊙𩎦𩎤𩎧韑𩒕䫀頣𩒐䫁䫆𩒛𩗄䬄䬅𩗆餋䬾䬽䭀䬿𩛣䭂𩛟𩛝𩛧䮄䮂䭿䮅𩨭𩨬䯌骲䯋䯍𩨴𩨸髛𩬝䯵𩬚𩬛𩬔髰𩬗𩰡䰠䰡𩲪𩲡䰷䰾魳䰽魮𩵣𩵹𩵠魥𩵢䰼𩵡䰸魪𩵰魭𩵪䰻𩿈䲰𩿅𩾾𩿂䲭䲲䲸鴋𩿇䲯𩾰𩿊䴚鹶䴠䴡䴰麨䴯𪌍𪌇𪎖𪏯䵟䵠𪐞𪐝鼑𪖐𠏫㒊儙㒜𠏮㒚𠏯𠏬𠟺㔌㔋㔍劒𠤄𠿓㗾𠿑𠿺㗿㗻㗼㘉𠿈㘀𠿕噵𠿍𠿒𠿻𡈪𡒈𡑡㙱𡑣𡑢奯𠁗𡣈𡢘㜫𡫁𡼿𡽁㠔㠑㠒𢅒㡣𢅞㡮㢚𢋇㢛𢍰𢐧㣈𢑱𢒰㣶𢡂𢡃憥㦌㦗㦢憠𢡗𢢝𢶉𢶍𢶋𢶀𢶒𢶊𢷈㩔𢶏𢶓𢶑㪨𢿲𢿞㪩𣃈斴旘曂㬘㬗㬝𣊧𣊡㬱𣚌𣙿㯘㯕𣙻𣚕𣚙㯡𣚎㯔𣛚𣚊𣚜㯣𣙾㱄㱅𣤚𣩕㱶㱵𣩠殨㲈㲪𣯻㲫㲬𣯽㵗澃㵠㵝㵫𣿐㵣㵧𣿅㵞𣿒𤀑濅燍㷹㷳𤎭𤎤𤎩𤎰𤎝㷶𤖘犜㹑㹓㹒𤢒㺛𤢊㺜𤢍𤢖㻷璙璏璑𤩅𤩋𤬏𤬖㼻𤮊㼾㼽𤳉㽩𤳈𤳊𤹤㿃㿄㿇𤹝𤹣瘹㿆瘮㿈𤺈𤼺𤼹皟皠㿦㿸𥀗𥂓䀇𥂕䁤䁨𥉶瞔䁥瞮𥊀𥉻䁩𥉈𥊨𥊈𥎋𥎊𥎌𥏼䃚䃠磦𥕘䃞𥕑𥕕𥕓䄜䄝䄚𥛜䅻䅺䅼𥡜䅸䅷𥡥穊䆱𥧮䆲䆰𥧰窼𥧲䈶𥰵䈮𥰢篖𥰠䈴𥰭𥰨𥰦𥰼䈪𥰸䈳䈭䈯䈱䈫䈲𥰪䈵𥰚𥰰𥯣𥰙𥻩𥻧糏𦃖𦃄䌈𦂇䌆𦃇𦃙縘𦃟䍍䍡𦌊𦌁𦎱䍸𦏁䎔䎕𦑶𦔍𦔎䏁䏃聭𦠇䐹𦠉𦠖䐺䐻䐵䐶𦠎䐼膐𦠆𦤞𦥊䑘䑼䑽䒍䒌𦺦𦺑䔻䔿𦺸𦺖䔲䔭𦻅䔯䔹䔮䔺𦼊䔶䔱䔾䔸蕒䔴𦺲䕁𦺷𦺴𦺉𦺍䔰𦺇𦻂𦺜𦺒䕃𦻊蕐𦺊𧇠䖘𧇦𧇥螠𧎥𧎳螆䗚䗗𧎵𧎯螕𧎰𧏌螌𧎢𧎾𧏆𧎬𧎮䘘衠衠𧜈𧜅䙒褨𧛾䙎褠𧜀䙏䙐𧡤𧡨覨䚆䚇䚉𧡪𧡩𧤍𧤒䚢䚣𧤏䚡𧩧𧩹𧩲𧩦𧩼䛹䛽𧩨𧪅𧩶𧩴𧩱𧩤䜾䜼𧯸䝍𧱬䝎𧳫䞁䞃䞂𧶸䞀䞈𧼭𧼮𧼨𧼩𧼱䞹𧼯𧼪踺𨂤𨂷𨂿䠑䠍䠐䠓𨉣䠼䡢䡣𨍧𨖾𨗒𨞕𨞜䤃䤆䤂䤁𨡱𨡲䤅𨡯醔錑𨧱錷䤶𨧨䤵鍃鉼䦡䦣𨵉𨵤䦠𨵈䧪隦䧨𨼬𨿡𨿯𩃀䨡䨛𩃗𩃔䩉䩈𩊭鞕䩢䩡䩤䩠䩣𩊮𩊬鞖䩦䪬䪫𩐧𩒮䫃𩒰䫄頥𩓀𩓄䬈䬉䬊䬆䬇䭁𩜇䭅𩛽䭃䭄餦餴䭇餩𩠛𩠜䭱𩢲𩢴𩢷𩢱𩢳𩢮䮋䯔䯓䯑骺䯒𩨿𩬶䯷䯸𩬱𩬻𩬵𩬺𩬷𩰢𩰶𩰲䰢𩳀䱅䱁鮁䱀鮊𩶅魿䱂𩶉䳁䳅𩿡䲻䲹䲽䳉𪀊鴑𪀉䲿䳇𩿨䳃䴤䴱䴳𪌘䴲䴴䵒䵡黙䵺䶂鼼𪗅儣𠐍𠐌𠐡儬𠘖𠠎㔏劕𠠗㔣㔥𡁈㘆㘈嚈𡁕𡁉𡁌嚋𡁏𡀽㙺㙷𡒊𡒨𡚊㜮𡣋㜯𡣕𢇔㠛㠓𡽜𡽵㡦𢅡㡥𡚖㣷𢖊𢣐懡懗𢡉𢣏㦜𢷖𢵿㩓𢷒𢷏𣀆𣀉㪺斣㬢曓𣜆𣛱𣜄檋𣛺𣛴㯫㯝𣛹㯯𣚋𣜃㱇㱆𣦢㱸殬㲉𣰌𣰋㵨㵶㵴㵹𤀤㵺㵷瀞㵱𤀥㵲𤏶㷿𤏻㸅𤔲𤗻㹔𤛲𤛳㺞㺟獴𤢜𤩲㻺𤩴㽄㼿㽀𤮆㽂𤮎𤮐㽑𤯍𤯷𤳖𤺄𤺕𤺊癀㿊𤺗癁㿎㿉𤾠𤾡𥀢𥂦𥂤䀉盨䁬䁮䁰䁭䁫䁱䁲𥊯𥊰𥊽䂌䃤𥕶䃡𥕻𥕹磶䃧礀䃢䄟𥛮𥛱䅾𥢔䆀穙穕𥢑䆹䆷䆸𥨐䆺竴𥪯䇓䈿簄䉅䉁簕䉂䉃䈻䈸𥱻𥱷䈺篵𥲀𥳆䈼𥱼𥲐䈾𥲣𥱽𥲽䊞䊟䊡𥼓䊛䌒𦄑𦅄𦄍䌘䌌𦌔䍢𦎸䍺𦎷𦒃䎮䎯𦔔䎭𦔜䏄䏇䏆䏅𦗔𦗕臁䑀𦡃𦡂䐿䑁𣎜𦡁𦥎䑿𦪇䒆䒁䒀䒎䕊𦼹䕇𦾏𦼪䕋䕆䕎蕽𦼫薥𦽐䕈𦽮䕅𦼻𦽓𦽟𦼯𦼰𦼸𦽌𧇱䗛䗧䗤𧐇𧐖䗡𧐔䗩𧏻䗥䗠䗣𧏸𧐐𧐝𧏿𧐋𧐄䗪𧏾𧗿褺䙔䙘䙜𧜣䙕䚋䚌䚦䚥𧤪𧪠𧪘𧪜䛿𧪞𧪦𧪵𧪰䜰䝀䝁𧳹𧳵䞆𧷒䞿䞽𧽐𧽎𧽋𧽒𧽍䞾䠙𨃤䠜𨃚䠛䠗䠘蹑𨃟𨃨䠽䡪䡦𨍰𨍲䡧𨍷䡨䢭䢮𨗦𨞪䣖䤉𨢌醘𨢉䤌䤊𨨲䤻䤺鎄鎁𨩺鍯𨲞𨲠𨵰䦯䦪䦫𨵮𨵦䦱𨵥䦬䧬𨽿𨿠䨁𨿿𩃶𩃷𩃵䨣𩃼䨤䨞𩃹䩊䩥䩪𩋊𩋃𩋆䩩𩎸𩎽䪕𩎼𩐅𩓠𩓩顂𩓟𩓬䫌𩓥𩓞頩䫏𩓰𩓣䬏𩗬䬎䬋䬐䬍䬌𩗲𩗯𩗩𩛻䭆䭈餷䭉𩜷𩣞䮑䮎𩣚䯘䯖𩩋䯚𩩉䯗𩫕髿䯹𩭒𩭇䯼𩭈𩳌𩳐鮩䱊鮧䱎鮳鮬鮰𩶆𩶧𩶣鮜𩶶𩶭𪀓鴼鴜鴺䳓䳍䳑𪀗𪀢鴲鴴𪁉𪀦鳽𪀼𪀨𪊨黇𪏻䵢𪐲𪓖𪕈䶃鼿𪗆䶒㒟㒠𠐥𠐦𠐤𠐳𠕰㔧𠮐㘋𡂕𡂡𡂒𡂏𡂟𡂖𡂘𡂝𡂠㚍𡚗𡣫㝰𡾇廫𢐲㦚㦟㦝𢣘𢣻𢤆㦛𢣑懕懲㩠㩤㩩㩣㩧㩢𢷶𢸄𢷾攂𢷿𢷷𣀘㪬𣀔𣃍𣄠𣋞㬦㬣㬥𣝅𣝓㯸𣝋𣝕𣝁㯷㯻㯺𣝜𣝔𣩱𣫐㲰㲯𣱓𤀩𤁾㵾𤁹㵽㶀𤁰𤁪㵿㶆𤂅𤁤瀒𤁸㶁𤐲𤐧𤐯㸤㹘㹗㺠𤢺㻾𤩱𤪌㼕𤬚㽇㽆𤮘𤳤㽫㽬疅癔𤻂㿌𤺺癕𤻈㿋皧皨㿹𥂹𥂸𥂥𥋢䁸䁺䁵𥋙䁶䁷𥊬𥋝䃪䃮䃭𥖝䃬礏𥜑𥜃䆄䆅䆃𥣮䆼䆽䇔𥲤𥳘𥳝𥳞䉕𥳧䉐䉌䉈𥳔䉓𥳎䉗䉔䉎䉊䉑𥳱𥳓䉉䉋䉣𥳊𥳩䊧䊦䊤𥼚𥽁糨糤糥𦄽𦅀繥繎䌗𦅃䌖𦄿𦄼䍣𦌡𦌠羂𦏆䍻䍼𦏑𦏊𦒎𦒑䎰耭𦗣𦢈䑂𦡰䑄𦦝𦪝𦪑𦪘𦪙䒈䒏䕓䕛𦾿䕙𦾮𦾱𦿌𧀄藊𧀖𦿍䕔薼𦿠𦿋𦿔𦿉𧇿䖙𧇽𧑗𧑒𧑐𧑅𧑍䗱𧑓䗯𧒂𧑄𧗎𧗏𧗒𧘂𧝏𧝘䙢𧝃䙣𧝒䙠䙡䙟𧝉𧝓𧝍䚍𧢄䚎𧢃𧤲𧫢䜉𧫤𧫓䜅𧫕𧫝䜃䜆𧫬𧫦𧫒𧫺𧫚䜱𧰆𧰄䝏𧱻𧴄𧴂䝦賿䟄𧽠䟃䟁䟅𨄇𨄅𨄌䠡𨅚䠟䠢𨄚䠧䠠𨎊䡭䡬䡫䡯䡰𨎌𨘉𨢦醦𨢬𨢩䥂𨪋鎶𨪌䥄䥀𨪈𨪉䤿𨪓鎫䦋𨶃𨽏䨥䨨𩄠霡𩈶𩋢䩵䩲𩋮䩯𩋧𩋩䩶䩱鞧𩋟䪗𩏇䫗𩔉䫖𩔁䫘䫜䫛䫔頾䬓䬑䬔𩘅𩘌𩝸𩝝䭑䭐䭓䭔䭒𩝞𩝠𩝧䭭馧𩣴䮕䮙𩣱䮚𩣺䮓䮗𩣸𩣹𩤉𩣽𩣷䯜𩩙䯽𩭠𩭨䯾䰀䰂䰁䰃𩭪𩰔𩰾𩳤䰧䰦䰤䱌鮼䱐䱕䱓鮷䱒𩷒䱗鮾䱘䳖𪁘𪁐䳔𪁜䳚䳙𨄙鵢䳕䳤䳭䳜䴛麏䴷䴶䵋黋䵦䵶𪓟䵾䵽䵿䶄䶋𪗙䶔㒡㔑𠥦㘐嚩𡃡𡃯𡃨𡈳夓𡣾㜲寴𡫯𡫬𡳬𡾜㠠𡾙𢅰𢅮㢝𢑁懬懯𢤂𢤁㦥𢨛𢸣𢸥𢸤𢸦𢸳𢸯𣂏𣍖㰂㯾㰁櫖㰃㯿𣞙㰄櫛㱊𣫙㶇𤂷㶄𤂶㶅爉𤑔𤑗𤑺㹙獹𤪎瓆𤪐𤪹㼄㽉㽈𤳯㽭㿒㿓𤻢㿑𤻖𤻘𤻚𤻞㿧䀊矃𥌈𥌄𥌊𥌋𥌂䃰𥖪𥗁礟𥣗𥣙穥𥣞𥣚䆾䆿𥨪竆簺𥴱簶𥴫𥴬𥴩𥴮䉞𥴧𥴨䉛𥵟𥴰𥴤糫䊫𥼺䊬𥼹𥽀𦆀𦆄䌟䌝𦅵𦅶䌜𦅼䌞𦅸𦆛𦅾𦉚䍤𦌬羄𦒜𦡇𦢊䑈䑇𦤧𧁊䕞䕡𧀧藠藳𧀮𧀦𧈈䗷𧒽𧒖䗴䗸蠞𧒾𧒎𧝴䙤𧞀䚒䚏䚐覴䚓䚨𧤼𧤽䚩𧤺𧥂觹𧬤𧬆譄䜎𧬕䜏𧬂𧬌䜋𧬈𧬊𧬅𧬜䜐䝑𧲂䝐𧲆䝣𧸃𧽻䟇𧽺蹱䠤䠦蹮𨆀蹹𨅘軄䡴䡳䡲𨎪𨎫𨐶𨑊䢱邌𨟖䤎䤑䤐䤏䤒䥉鏉䥏䥍𨬃𨫏䥕𨶟𨶢𩀪𩀯𩅄𩅀䨬䨫𩌑𩌏䩹𩌍𩌇𩌃䩺鞰䪙䪚𩐵䪮䪯𩔄䫤䫡䫞䫦䫟𩔦䫧䫢䫠𩔞䫥顚𩘎䬘𩘝𩘞𩘟䭖𩞈饆𩞍𩞑𩞀䭮𩡔𩡕䮝䮠䮜𩤥𩤚䮡䮢䮟䯟䯞髃䯪𩮈䰆𩭺䰄鬏𩮀𩴞𩷼𩸥䱟鯴鯺䱜𩸋䱞𩸆𩸄鯝䱙𩷹𩸀䱤䱝䱢𩸂䱥𩸇鯭䱛鯯𪂵䳝𪂇䳟𪂓鵧䳢𪂾𪉦䴧𪋇𪋏麖䴺䴼𪌽䴹𪌼𪍇䴽䴻麳䵌𪏃䵨黢𪔛䶀䶅𪗉䶕𪗝䶖𪚓龏𠐽㒤㔒嚱㘥𡓦孁㜷𡫷𡾰𡾮\x7f\xe1\x03\x0f㡨㡧㢞𢖟𢖞𢤰㦪𢥏㦩㦤𢤧𢥑㩱㩯𢹑𢹔𢹖𣀧㪭𣀤櫶㰌櫲㰋𣟬𣟄㰏㱹𤃸𤃶𤃷𤃭㶍㶏𤃴𤃯㶎㸌𤑿𤒢𤑾㺦𤣅㺣㺤㺥疈㽮𤻱𤻲㿺𥀯䁼䁾䁻䁽𥌣𥌩䂍䃴䃳𥜛𥣫䆉𥣪𥨳䇀𥵣䉥𥶒𥵥䊭䌥𦆠繅𦉞䏉𦢪䑊𦢩艧䒉𧂜䕦䕰𧂒䕭䕩䕧𧂆䕲蘎䕮𧂃䖜䗼䗽䘄䗿䗹䗾䘀𧓎䙦䙩𧞓䚪䚫䜗𧬮𧬧𧬨䜕譞䜒贎𧸘𧸖𧾍䟊𧾎䟉䟋𧾐𧾏𨆁䠩䠨𨆊𨆌𨊍䡀䡵䡶𨎲䢈𨟙醶䥗鐁𨬍䥟𨬓鐝䥘䥛𨬛鐯𨶬䦳𨶳䧯𩅞䨰𩅠𩅨𩅣𩅩𩅧䩁䩋𩌨䩽𩌮𩌰䩼𩌦𩌱𩍁韠𩏚韛韠𩐋顡𩔴𩔳䫨䫪䫩𩔹𩔻𩕀𩘰䬛𩘲𩘷䭕饊䭙䭘𩞁𩞟𩞧䮤䮣𩥄𩥇𩥈𩤽騳䮧𩥉䯠𩪀𩮠𩮝䰈䰊䰉𩮜𩱇𩴇䱱鰏䱬𩹉鰑𩹄鰃鰁䱮𩻟鰖𩹌\x80\xc3\v\xa8𩹏䳠䳮𪃁䳫𪂹𪃋𪃏𪃄鶓䳪䳯䳧𪃊𪃃𪋐𪍑䵈䵉𪎨𪏇𪏆䵍𪏊𪐂䵕𪑝𪑚䵪䵬𪑙䵫𪓌𪔜𪗌䶘䶙䶗𪗨𪗬𪗪𪚭𪚰𠑟𠑘𠑗卛𠮓㘖𡄻嚿𡄴𡓲㜹𡫽𡰝㠥巎𢌄𢑈𢥘𢹏㩴𢹮𢹲㪮𣀮𣟼𣟴𣰶𤄎㶒㶔𤄔㶕𤄙㸍𤒦㸥𤫉𤫀𤫌𤬛𤮪𤮨𤼃㿕㿖皬㿨𥀵䁿矒䃵䃶䃷𥗙䄤䆍䆊𥨿𥨽䉬䉦䉪𥶋䉧䉭䉨籒𥵨𥶊𥽘𥽗𦇎𦆼𦇀𦇁𦆿𦆫𦉟䍥𦒦䎚𦔩䎱䑋䑍䑌䑎𦢸䕳䕵𧃝𧃖𧃒𧃕𧃧𧃘𧓬䘃𧔀𧔇𧓽𧓱𧓿𧞪𧞰𧭈䜞𧭓譳𧭍䜙䜜䜚䜝譶𧭃𧭐䝃䝒𧲌𧸧䞕𧾙䟌𨆱䠫𨆰𨆪𨆬䠪軇䡷𨏈𨏊𨙂𨟠𨣧𨣨𨣦䤓䤔鐴𨭛𨭖䥝𨭚䥠鐾䥢𨲸𨷅䦲䧰𩁈𩅽𩅼𩆁𩆂𩅢䩌𩍅䪂鞼䪁䪛𩏣䪤䫮䫬𩕐䫭𩕏䫰䫯𩘹䬝𩘺䭠䭞䭝𩟁𩥎𩥮䮱䮫䮬䮭𩥱䮮𩪌𩪉䯢𩫥𩮳𩮶𩮴𩱍鬹𩴓𩹸鰪䱻鰦䱸䱵鰧𩹲䳲䳴䳳鷀鷉𪉵𪋚𪋗䴿䴾䵀䵎䵗䵙䵘䵖𪑩𪑧䵮𪑦𪑯𪑱黬𪓏𪔣䶁䶆𪖥䶏𪗾𪗻𪗽齨䶛䶱䶳𡅭㜺𡬅𡰠㠧𡿈𡿉㡪㦬𣄧㰘𣠞𣤶㱌𤄽㶖𤣗㽊𤮭𤴀䂁𥌺䂃䃹䃸𥜦䆎䆏䇁𥶷𥶶𥷀䉰𥶛䉱𦇘䌪䌭䍽耲耱𦣀䑏𦢿𦪿䒐𧄠𧃐𧄎䕹𧄝𧄞𧄕𧄔𧔞𧔬䘅𧞹𧞸𧞶𧢞𧢜𧥍觽𧭮𧭠䜡𧭤𧭢𦇥䜲𧾣䟍䟏𨏒𨏕邎䤖䤕䦵䨈𩁕䨲𩆓䨳䨴䨼𩍖韂𩏩䪜𩑅䫴䫲𩙼䭢𩟓䭡䭤䮵驋䮴𩦎𩪗䯣𩪛𩪙鬜䰎𩯏鬛𩱙䰬䰫𩴠𩴣𩴪䲅䲁䱾䲃䲀𩺰鱂𪄯鷔䳻䳷𪄲𪅃𪄿𪄹䳸𪄰𪋝𪏚𪏛䵚𪒄𪑿𪑲𪒀𪓮䵸𪔪𪔢𪕱𪗍䶝䶜𪘏𪘘𠑪㒩㒿囐囏𡬌巚𢖦㩷㩶𣀷㪻曪𣫢㶛𤒼㸏㹛𤫕㽌㽯㿙𤼒䂂𥍋𥍁𥎡𥗬䉴䉲𥷔𥷘𥷙䉳𥷚𥽬𥽭䊱䌮𦇧𦇫𦇬𦉢䍎𦔫𦘍𦣍𦫃𧄿𧄼𧄾𧄽𧄺𧄸𧕄𧕅䘋𧕈𧕐䙬讉𧭸𧭹䞋𧸾䟐䠭𨙔䣤䥱𨮹鑘䥯𩁟䪇䪥𩕲𩕵䬞䭥䮹䮷䮸䯤𩪣𩪥𩯜𩯝𩱚䲌䲉䲊𩻛𩻜䲋𪆫𪆄𪅶䴁䳾鷧䴃䴆𪆃鷪鷱𪉿䴝䵃𪍳𪎭𪎰䵏𪐏𪐌䵛䵰𪕹䶈𪕷𪕺䶉齄𪘲𪘧𪘪𪘬齭𪘨𪘩𪘸𠠯𡅻𡬍巙𡿖𡿕𢺡𣡌㶞㶟㺧㽋㿛㿚𤿀矖䂎䉶𥷴䌰䌯𦇰䍦𦌿䕾𧕝𧕲𧕢蠺𧕦襵䙯𧮈䟑䠯𨇨𨇤䢲䤘䤗𨣿䥶𩆯𩆮䩏䪉𩏲𩑈𩖁𩕾䫵䬟𩙒𩟯驝䯥𩪭𩯦䰐𩯭䰏𩯩𩱡𩱠𩴳䰯䰰鱤𩼋𩼈䲓䲔𩼇䴇䴉䴈𪇊䴋𪇆鸊𪊄𪋫𪍽䵐䵱𪒛𪒢𪒡𪒠𪒜䵻䵼𪖀齳𪙀䶡𪘹𪘼䶠䶢𠣊㘛𡆉㚂𣀼𣥀𤅣𤅩㿩𥍓䂄矡𥤗𥤚𥸀䊳䌴䌳䖀𧕯𧢫䚭𧮑䝄䝕𧾱䟒𨇯𨷱𨷲𩆷𩆵䪊𩖆𩙚䭧𩧆𩧋䯦𩪲䰓𩯳鬭𩼧䲗鸌𪇕𪇘䴌𪇬𪊇𪋲䵆䵜䵴䵲𪒭𪒰𪓽鼟𪖇𪙊䶤䶣𪙎𪙉𪙑𪙍䶴𠑲㔶𡬖𣡭𣥁㲲𤅱𤅴㼖籰䉷𥽼䕿𧆊𧥖䡽鑹𨯺靊䪌𩙛䭨䮾䰕𪇵𪇰𪋳𪍿𪐓䵵䶥𡬙𢦅𢺰𤅷𥗿䉸䌵纝䖂𧆇䖃𧆋䙱𧮞䡾䪎𩖏饠䭩𩠹𩧔𩫱𩰂𩱫𩵀䲚䲛𪈐𪒾𪒹䶐𪙤龞𤫩𧆐䖆䖅𧈜䘎𧖒𧢮𧮣䤙𩇉䯀𩧘𩵄𪈤黸鼺齽𪙰𪛓䀍䰖𪓃䶩䶪䂅𥸡𦫊𧖜𩱳𪎆𪗁𩰉龖𪛕䯂䰱䴑䶫䲜𩇔\x8a\x10\x03\x8c𠃑𠃋𠃉𠄌𠀀𠂇𠄍𠄏凵𢎘𠃒𠄑𠁾𠫓𡕒𡳾𢖩𣥂𠀉𠃖𠓝𠓜𠔂𠕳𠙶𠚧𠚨𠠳𠣌𠨍𠨬𠬛㕚𠬝𠮙𡈾𡴭𡴯𢎙𣎳𣦶𣬛𤓯𤣩𠀔𠂔𠃡𠔆𠕊𠕄𠘱𠙺𠚭𠚮𠚱𠠸𠠷𠥽𠥾𠨭𠨲𠬣𠬦叱𠮠𡚨𡤿𡦼𡯄𡴻𢀙𢀘𢆳𢎪𢒾𢖬𢨥𢩦𢩫𣥃𣦵𣫬𣱶㲼𤘔𡗜𤜜𤜝𦓐𦘒𦫳𨙩䦹𨸑𨸕𨸐𨸓𠂤𠂥𠇐𠆺𠇋𠕻𠚆𠚻𠚺𠛃𠚹𠛀㓞𠠹𠠻𠡁𠣑𠣏𠣒𠤮𠥮𠦌𠦈𠨵㕃𠨺𠨻𠨸𠨹𠬮𠬬𠯈𠮵𠮭𠮰𠮫𡆳𡆩𡚻𡚹𡭚𡯍𡰫𡴅㞬㞪𡵑𡵋𡿪𡿭𢀶𢁢𢁖𢁕𢁤𢁘帇𢇚𢇘𢎭𢎫𢓀𢓃𢖳𢖷𢖺忋㣾𢗇𢖵𢦎𢩳𢩬𢩴扝𢩲𢩱𢪂𢩰𣄿𣎺𣎸𣦷𤜣𤜡𤜫𤜦𤜢𤴥𥃤𥐕𦓤𦘳𦘪𦘲𦘩𦫻𧥛𧥜𧰨𨙯𨙭𨙫𨙮𨸖𠀢𠄖𠅈𠇘𠈀㑁𠒀𠓤㒴𠓧𠔌𠕓𠖷𠫨𠛑𠛥𠛘𠛖𠛚𠛙𠛕𠛦𠛔𠛓𠡄𠣖𠣗𠤓\x8a\xf7wJ𠥰𠦒𠧛𠧚𠩁𠩃𠬳𠯶𠯔𠯝𠯐𠰄𠯙𠯛𠰅𠯓𠯡𡊀𡉷𡊁𡊂𡊃夆𡕡𡕠𡛘𡛖𡛗𤘅𡥍𡥈𡯖𡯏𡯙𡯘𡰽㞳𡵔𡵛𡵜㞴𡵙𡵘𡵚𡵕𡵖𡶂𡿰𢀜𢁪𢁩𢁱𢁧𢁬𢁮𢁹𢆋𢆸𢇧𢇨𢇤𢇦𢇥𢇰𢎃𢎷𢎻𢎹𢏅𢑓𢓄𢓆𢓋𢗈忹𢗉𢗛𢦔𢪕𢪈𢪍𢻬𢻮𢻱𣄮𣅉𣍟𣏑𣏋𣢅㰝𣥊𣥇𣥋𣦻𣧄㱼𣬂𣲂𣲑𣲏𣲒𤆏𤆑𤆴𤆒𤓴𤖪𤜵𤜷𤜽𤜼𤜯𤜰𤣸𤣯𤬦㽕𤴦𤴧𤽁𤿆𤿇𥃫𥃨𥃪𥐚𥐙䄦𥤣𥤤𥸥𦘵𦘴𦨇𧈝𧟡𧮫𨑠𨙷𨙽䦼𨸞𨸚𨸝𠁫𠄭𠅌𠈪㑊𠈕𠈬𠈆𠈈𠈊㑎𠕖𠖄㓉𠗆𠛵𠜉𠛸𠛨𠛻𠛩𠛶劷劸𠡒劶劺𠡔𠡑𠤹𠤺𠦘𠩎㕻𠰶𠰌𠰐𠰲𠰑𠰘𠰜𠰚𠰙𠰋𠰏𠰝𠰡𠰓𠰛𠰕㘠𡇑𡇈𡊍𡊵㚉㚕𡗸𡛠𡛡㚾𡛞𡛾𡛜𡜁𡛽𡜀𡧗𡧖𡧙𡭥𡯒𡯜𡯡𡯞𡱂𡱋㟃𡶎𡶑𡶋𡶐𡶈𡶉𡶄𡶪𢂍𢂏𢁽𢁿𢁻𢂀𢁾𢂃𢂁𢂆𢂊𢆽𢆾㡷𢇴𢇳𢈂𢇶𢇵𢇲𢈄𢌽𢏆𢒉𢓔𢓖𢓒𢗺𢘆𢘌𢘊𢘏𢨯㧔𢪷𢪾𢪼𢫢𢼆𢻷𢼇𢻶𣬵𣅟𣅥𣅤𣅡𣅚𣅝䏙𣏗𣏕𣏞𣏡𣏠𣏙㭌𣏚𣏟𣏶𣐃㰠𣢓𣢋𣢑𣢒𣢊𣢉𣢏𣥖𣥙𣧊𣧌㱛𣧐𣧍𣧖𣧋𣪃𣪂𣬆𣬪𣬬𣬫𣬩𣲽𣲳𣲾𣳀𣲵𣲶𤆝𤆟𤘠㸭𤘞𤘜𤘲𤘟𤘫𤘡𤘦𤘤𤘝𤘧𤝛𤝙𤞀𤝕𤝐𤝚𤝖𤝣𤝒𤝘㹣𤜹𤣹𤬨㼘𤮼𤯖𤰅𤰠㽴𤴳𤽆𤽂𤽃𤽇𤿋𥁂𥁅𥃽𥃴𥃹𥃺𥍞𥐝䄨𥝘𥝕𥩗𥩘𥫙𥫜𥫛𥸧𥾅𥾇𥾊𦊋𦍏𦔰𦔯𦙨𦙉䏚𦙈𦘿𦙋𦙜𦙆𦙀𦨍𦬛𦬝𦬗䒤𦬢𧖧𧘈𧠆𨑽䢎𨑣𨑤𨑿𨑫𨑼𨑨𨑥𨑪𨚘𨚙𨚮𨸫𨸭𨸱𨸮𨸯𠁭𠄱𠈭𠈹𠈸㑛𠈺𠈵𠉢𠓪𠓫𠔕𠗌𠗈𠗍𠗛𠗋𠜑𠜦𠡢𠡞𠡡𠥁𠤿𠥵𣥥𠭉𠧫𠨚𠩗𠱫𠲩𠱔𠱢𠲪𠱘𠱲𠱣𠱳𠲋𠲌𠱜𠱑𠰖𠱠𠱡𠱞𠱝𠱤𠱓𠱟𠱛𠱮𠱕𠱒𠱨𡇒𡇖𡇓𡊸𡊻𡋫𡋨𡊶𡋪𡋧𡋩𡖑𡘐𡘎𡘏𡜋𡜃𡜫𡜉𡜨𡜬𡜥𡜧𡜏𡧩𡧬㝓𡬧𡭳𡯢𡯦㞁𡱡𡱑𡴒𡴎𡶭𡶯𡶱𡷓𢂔𢂒𢂐𢂝𢂓𢂕𢂘𢂗㢀㡼𢈇𢈋𢈈𢏙𢏕𢓗𢓟㣝𢓞𢓣𢓢㣞𢙇㤢𢘽𢘸𢩄𢨺𢪸𢬪𢬀𢫳𢫲𢫫𢫺𢫭𢫬𢫿𢫻挆𢭎𢫯𢫷𢼐𢼑𢼌𢼋𣃝𣄰㫛𣅺𣅷𣌨𣌧𣍥𣐎𣐋𣑁𣐓𣐏𣐒𣐰𣢠𣢣𣢞𣢗𣢡𣢝𣢛𣥣𣧣𣧤𣧦𣧧𣧝殅𣧬𣧥𣧱𣬻𣬹𣬺𣬼𣬽𣭇𣬿𣭄𣱈𣱉𣱠𣳣𣳩𣳰㳚㳛𣳲𣳭𣳬𣳦𣴖㳗𤆼𤆾𤇀𤇠𤔀𤔂𤓾𤕞𤖳𤖷𤘺𤘹𤘾𤘸𤘽𤙏𤝮𤝟𤞁𤞂𤝱𤝻𤝹𤝷𤝳𤝯𤞗𤝾𤣼𤤑𤤲𤤱𤤐𤫬𤫫𤬬𤬭𤯚𤰮𤰬𤰪𤰳𤱋𤴘𤵂𤴻𤴿𤵁𤵃𤴼𤽌𤽊𤽉𤽖𤿏𥁇𥁈𥁆𥄛𥄑𥄔𥄝𥄗𥄞𥄋𥄜𥄒𥄖𥄍𥄕𥄓𥄘䀚𥎬𥑄𥐬𥑅𥝦𥝮𥝥𥝨𥞄𥤸𥤻𥤺𥤿𥫟𥫝𥫞𥫢䇖𥸬𥸯𥾏𥾐𥾌𥾓𦈥𦊈𦊧𦏷𦒻𦔸𦔹𦔷𦘔𦙮𦙻䏞𦙬𦙿𦙸𦙺𦙯䏥𦣾𦭈𦭋𦭁𦭉𦬻𧈭𧗣𧘚𧘍𧘓𧘑𧘜䙲𧢵䚯𧰩𧲡𧴤𧾻𨊡𨒃𨒍𨒇𨒅𨒊𨒣𨒉𨒄𨒢𨚱𨚰𨚯𨚶𨛉𨛊𨛈𨚴𨱙𨳉𨹁𨹌䧄𨹅𨹚𠂹𠊔𠉮𠊢𠉧㑣𠊣𠉶𠉬𠉤𠊥𠈰𠊦𠉩𠊤𠒐𠕟𠕠𠗚𠗘𠗝㓬𠝙𠝛𠝚𠜹𠜴𠝄𠜳𠜼𠝘𠝪𠡯𠡲𠡰𠡱𠡮𠣰𠣫𠥉𠥌𠧵𠨠𠩪𠩲𠭋𠭝𠱐𠳹𠳺𠳻𠴣𠲷𠴢𠲵𠳄𠲴𠳯㖘𠳍𠳰𠲸𠳋𠳱𠲺𠴃𠳸𠲶𠲼𠲳𠴡𠳷𠳂𠲾𠳉𠲽𠳎𠲹𠳇𠳊𠳌𠴦𡇤𡇠𡇰𡋬𡌤𡌘𡌯𡌥𡔨𡔢𡕪𠅗𡖔𡖒𡖜𡘢𡘰𡘝𡘣𡜵𡜳𡜯𡝍𡜱𡝛𡝚𡜲𡝜𡝙𡝝𡥩𡥭𢈲㝘𡨊𡨠𡨟𡨍𡯨𡯬𡱷𡱰𡱱𡷸𡷖𡷙𡷞𡷕𡷡㟓𡷢𡷝𡷠𡸎𡸈𡷜𡸊𡸌𢂴𢂱𢂶𢂼𢈝𢈤𢈙𢈛𢈢𢈚𢈜𢈡𢏦𢏤𢏧𢏪㣢㣣𢓰𢓵𢓯𢙂𢙮𢘼𢚗𢚌𢚁𢙱𢛎𢚺𢙺𢙾㥔𢙲𢚻㤯𢙳𢚂㦶𢦺𢦸𢩋𢩈𢬫𢫴𢬬𢭂𢭈𢭄𢭅𢬼𢬷𢬳𢭁𢬲𢮇𢼧𢼣𢼭𢼟𢼬𢼨𣁵𣁴𣃧𣄲𣄱𣆙𣆖𣆘𣆝𣆒𣆔𣆕𣆗𦚹㬵𣐑𣐻𣑹𣑂𣑃㭝𣑸𣢬㰩𣢱𣢴𣢳𣢯𣢪𣢲𣥨𣧲𣧾𣧸𣧹𣧿𣧵𣧷𣧽𣨀𣪈𣭮𣭝𣭣𣭠𣱡𣴷𣵱𣴣𣴢𣴟𣴥𤇽𤈑𤈣𤇰𤇯𤔎𤔌𤖺㸟𤙒𤙕𤙓𤙔𤙑𤙘𤞰𤞟㹲𤞡𤞚𤞾𤞠𤤷㼋𤫱𤫲𤬴𤬷𤬵𢎌𤯟𤱌𤱎𤱤𤱣𤰶𤵘𤵨𤵛𤵟𤵙𤵥𤵠𤵞𤿕𤿖𥁎𥁒𥄵𥄹𥅘𥅁𥅗𥄸𥄽𥄲𥅄𥄳𥍣𥍢𥎸𥎰𥎱𥑍𥑣𥑰𥑋𥑏𥑎𥑓𥑇䂢𥑔𥑒𥑙𥑾𥘫𥘮𥝿𥝾𥞁𥞃𥥇𥥔𥥍𥥌𥥋𥥗𥩥𥩤𥩡𥩢𥫰𥫳𥫹𥫴𥫵𥫯𥫸𥫼𥫷𥫽𥬗𥸴𥸶𥸸𥾯𥾮𥾬𥾢𦈨𢻂𦊜𦊳𦊐𦐆𦐄𦐋𦐅𦕀𦕏𦚫𦚝𦚸𦚲𦛒𦛏𦚢䏬𦚥𦚩𦚧𦛐𦤂𦧏𦧐𦨖𦨙𦭵𦭱𦮑𦭲𦮎𦭭𦮈𦭳䒻𦮁𦭶𦭻𦭿𦮐𦭸𧉋𧉈𧉛𧉑𧈽𧉀𧉆𧉍𧈻𧉊𧉁𧈾𧉎𧖬𧗦𧗩𧘩䘡𧘳𧘮𧘞䘟𧙧𧘢𧘧𧟧𧠋𧥦𧮭𧮮谸𧰫𧰪𧴬𧴭䞑䞖𧺏𧺓𧿆𧿅𨈕𨈓𨊱𨊩軎𨒨𨒫𨒾𨒬𨒩𨒦𨓁𨛌𨛑𨛓𨛤𨛋𨛡𨛒𨛣𨛏𨛐𨛥𨜎𨳌𨳊𨳋𨹜𨹡𨹝𨹙𩁷𩚄𠁁𠃽𠄚𠅣𠊶𠊲𠋩𠋧𠋖𠌰𠋬𠋝𠋨𠊰𠊵𠋡𠒘𠒙㒼𠖓𠗥㓘𠝠𠝞㓴𠡻𠣱𠣳𠩿𠭣𠭗𠭘𠴹𠶧𠴵𠵠𠵑𠵢𠵂𠴼𠵃𠶫𠶩𠶴𠵹𠵪𠴻𠶪𠴳𠴷𠴧𠶨𠵰𠴯𠴶𠶵𠴰𠴺𠴸𠶱𠶬𠴮𠵵𠶰𡇷𡈀𡇱𡇳𡍠𡍡𡌲𡌭𡍊𡌪𡕧𡖢𡖣𡖞𡖤𡙄𡘲𡞛𡞘𡞙𡞚𡝦𡝢𡝫𤕢𡝧𡨻𡨣𡨩𡭾𡯳㞅𡯵㞃𡲀𡱾𡱼𡱿𡱽𡲁𡸥𡸩𡸗𡹣𡹧𡹥𡸪𡹨𡸦𡹤𡸛𡹢𡹩𡿾𢁄𢁂𢂷𢃒𢃔𢃗𢃜𢃑𢃕𢃍𢃘𢆚𢉂𢈵𢉘𢈴𢈼𢈻𢈾𢈽𢉚𢉁𢈸𢉅𢈳𢌦㢺𢏷𢏯𢏰𢏮𢒝𢔆𢔑𢔈𢔂𢔊𢔇𢚑𢚎𢚅𢛨𢜗𢛔㥩𢛄𢛕㥅𢛘𢚀𢛞𢝂𢧇𢦿𢮍𢮉𢮐𢮚𢯈𢮒𢮡𢮄𢽗𢽇𢽷𢼺𢽐𢽂𢽃𢾣𣁷𣆲𣇢𣆽𣍴𣒂𣒸㭬𣒺𣒹𣒻𣒇梎𣒃𣒷𠁃𣣎𣢺𣥳𣨉𣨊𣨋𣨇𣨅𣨎𣪗𣭷𣭻𣭺𣭴𣭹𣶆𣷵𣶥𣶝𣶾𣶖𣷡𣶤𣶡𣷶㴄𣶍𣶋𣵸𣶘𣸊𤉝𤈧𤉟𤗀𤗁𤙭𤙞𤚊𤙢𤙤㸺𤟀𤟊𤟑㹹𤟇𤟎猉𤟅𤤶𤥗𤥷𤫶𤫷𤭕𤭄𤬾𤭂𤭒𤯡𤰈𤱨𤱧㽝𤲒𤵾𤶓𤵹𤶁𤵽𤵻𤶔㾊𤽦𤿙𤿠㿼𥁟𥅟𥅞𥅨䀧𥆈𥅪𥅬𥅦𥅧𥆉𥅠𥆅𠌪𥍨𥎹𥎺𥎾𥑯𥒀𥑴𥒁𥑺𥒜𥑳𥒂𥑿𥒧𥑹𥑸𥑽𥒨𥑼𥙨𥙎𥞞䅆䅀𥞘䄿𥞅𥞜𥟅𥞚𥥨𥥥𥥧𥥡𥥠䆝䇋𥩻䇡𥬙𥬎𥬱𥬒𥬐𥬕𥬿𥬝𥬞𥹍𥹈𥹅𥹂𥹉𥹊𥹇䉿𥹌𥿍𥿕𥿄𥿊𥿃䍆𦈩𦊴𦍭𦍦𦍪𦐕𦓓𦓬𦕕䐚𦛖𦛘𦝑𦛠𦜃𦜄𦛚𦛪𦜂𦛓𦛤𦛥𦤻𦥦𦨬𦨡𦨦𦫔𦫕𦫙𦯕𦯫𦯅䓓𦰧𦯰𦮹𦰘𦯮䓐𦮷𦮻𦯯𦮸𦯟𦯚𦯣𦯉𦯬𧆤䖎𧆣𧉞𧉡𧉢𧉣𧊎𧉩𧉫𧊅𧊍𧉤𧉭𧙌𧙉𧙇𧙈𧙂𧙅𧙛𧙝𧙆𧠓𧠚𧠎𧠏𧠛𧠒𧣃𧢾𧢽𧥹𧥼䛃䚶𧦀𧥵𧦛訯𧥴䛀䚸𢁁𢒔𧰰𧲧𧲥𧲤𧴺𧹛𧺟𧺨𧺳𧺡𧺧𧺲𧺝𧺣𧺢𧿯𧿙䟙𧿕𧿝𧿚𧿮𧿗𨈚𨈙𨈘𨊳𨊿𨊻𨋀𨊹𨊸𨋕𨐑𨐐𨓃𨓂𨓩𨓍𨓥𨓊𨓇𨓨𨛱𨜌𨛯𨛮𨛳𨜍𨛶𨛰䣬𨟵𨟴𨟸𨟾𨤑𨤢𨥂𨱜𨱚𨱝𨳑𨳐𨹵𨹻𨾉𩑍𩚉𩚒𩚌𩚈𩡧𩡨𠌡𠋲𠌉𠋸𠋰𠋳𠋵𠋻𠋷𠋯𠌋𠒢𠗵𠗳𠗴𠗲𠚘𠝨𠞀𠞅𠞎𠞏𠋴𠞆𠞤𠢆𠢉𠢇𠢅勜𠢈𠫿𠭦㗌𠷃㖢𠷄𠸻𠷧𠷸𠷻㖶𠶾𠷆㗅㗆𠷼𠷀𠸳𠷉𠷾𠷚𠷌𠶻𠷽𠷟𠷍𠷂𠷈𠸲𠸭𠷒𠸱𠷓𠸮𠷏𡇿𡈉𡇼𡍲𡎪𡎫𡎧𡍻𡍶𡎨𡎬㚃夡𡖪𠨃𡖬𡖭𡖫𡙐𡙘𡟝㛸𡞮𡞢𡞫𡟣𡞯𡟠𡟤㛶𡞧𡞪𡟢𡟡𡟟𡞭𡟥𡟑𡞬𡟕𡞣𡞟𡥹𡥶𡩃𡨽𡩇𡩄𡨿𡩛𡭿𡲚𡲜㞛𡴞𡺈𡹪𡹶𡹲𡹰𡹾𡹬𡹯𡹵𡹷𡹸𡺊𡹹𡺔𢀁𢄅𢃱𢃮𢃯𢄄帿𢃺𢄇𢃴𢃵𢆜𢉭𢉩𢉦𢉢𢉆㢍𢉥𢉝𢉞𢉟𢎎𢐂𢐆𢐄𢐃𢑢𢔧𢔡𢔠𢔤𢔩𢔱𢔣𢔢𢛜𢝍𢛥𢛓𢛮𢛦㥖𢜶𢝀𢠿𢜽𢝄㥛𢝟𢜩𢝉𢞊𢜪𢞇𢝌𢜱𢝈𢜫𢜻𢝁𢛉𢜴𢝓𢜵𢝇𢜸𢧑𢧒𢩑𢩕𢩒𢩓𢮏𢯴𢱇𢰍𢰜𢯻𢰙𢯷𢯹𢯵𢯩𢰊𢯮𢰋𢯽𢰆𢰂𢰖𢰘𢯰𢻔𢽦𢽪𢽵𢽶𢾀𢽭㪖𢽩𢽧𢽨𢽝𣂤𣃾𣃽𣇬𣇩𣈡𣇫𣇧𣇲𣈠𣍺𣔭𣔮𣓊𣔯𣓈㭾𣓋𣓃𣒄𣓒𣓆㮁𣓏𣓕𣓔𣓝𣔫𣖣𣓛𣔬𣔓㰵𣣑𣣌𣣕𣣉𣣏𣣒𣥻𣥼𣨣𣨝𣨜𣨞𣨢𣨤𣨠𣨘𣨡𣮈𣮊𣮔𣮏𣮎𣮐㲛𣮧𣱤㴀𣸘𣸔𣸝𣸅𣷿𣸛𣸚𣸙𣸃𣸎𣹇𣸄𣹚𣸇𣸠𤉪𤉦𤉺𤉥𤊯𤗍𤗏𤗑𤗎𤙟𤙷𤙴𤚈𤙵𤚉𤙲𤟆𤟪𤟠𤠇𤟦𤟥𤟝𤟣𤟚𤦮𤥾𤥽𤥿㻓𤧙𤫺𤫻𤭍𤭔𤭑𤭐𤶘𤶕𤶝𤷁𤶚𤶛㾟𤶜𤷗𤶤𤶖𤶠𤼱𤿩𥁮𥆚𥆥𥆔𥆦𥆙𥆋𥆘𥆑𥆌𥆡𥆣𥇆𥍯𥍭𥍪𥍬𥍱𥍮𥏟𥒬𥒪䂱𥒵𥒭𥒲𥒳𥒫𥒶𥓇𥓆𥙬𥙰𥞳䅋𥦌𥦁𥦃𥥽𥦅𥦐𥦢𥦍𥦏𥥾𥦊𥥿𥥶𥦇𥥸𥪀𥪂𥪁𥪃𥭚𥬴𥬬𥬪𥭀𥬭𥬳𥬫𥭋笿𥬼𥹚𥹷𥹠𥿺𥿹𥿼䋖𥿵𥿨𦀉𥿧𥿦𥿩𦈲𦈬𦊽𦊼𦍺𦍾𦍹䎉𦐣𦐰𦐨𦓔𦫎𦓱𦓰𦕠𦕥𦚨𦜊𦜛𦜒𦜇𦝏𦜎𦝒𦜖䐀𦝓𦝔䐋𦝖𦥭𦥲𦨯𦰪䓜𦱣𦲄𦲯𦱅𦱰𦱖𦰖𦰽𦰸𦱯𦱷𦰮𦰱𦱌𦱟𦱵𦰹𦰺𦰬𦰭𦰿𦰯𦰰𦰾菐䖐𧆲𧊷𧊏䖱𧊖𧊞𧊗𧊕𧊥𧊘蛧𧊣𧊚𧊭𧊦𧊶𧊙𧊯𧊬䖪𧊟𧊔𧗫𧙯𧙭𧙾𧙬𧙡𧙣𧙫𧙞𧘾𧙤𧚭䙽𧠠𧣑𧣕𧦮𧦯𧦟𧦨𧧊𧯡𧰹𧰸𧰻𧲭𧲵𧲯𧲱𧲮𧲴𧲰𧵋𧵑𧵌䝩𧵠𧺾𧺹𧺷𧺶䞡𧺽𧺸𧻁𧿷𧿳𧿴𧿻𧿽𧿵䟧𧿺𨀔䟬𧿲𧿼𨀀䟟𨈫𨈩𨈮䠳𨋗𨋛𨋙𨋘𨋦𨋖𨋑𨋧𨋐𨋜𨓭𨔚𨔙𨔖𨓬𨓯𨓮𨓴𨔯𨜰𨜙𨜘𨜑𨜖𨜒𨜐𨜔𨠌𨠍𨤐𨤩𨤨𨱥𨱦𨳞𨳟𨳠𨳙𨳛𨳘𨳣𨳗𨺦𨺧𨺿𨺨𨺟𨺡𨺢𨺭𨺣𨺠𩂃𩂅𩂂𩂄𩇮𩉝𩎒𩑕𩑓䪲𩑑𩑒𩑐𩖝𩚇𩚗𩚛𩚝𩡩𩨑䯮𩱺䰲𣉄𠃆𠍀𠌮𠍨𠌭𠌴𠌲𠍩𠌱𠍪𠌾𠍯𠍵𠓶𠔠𠔨𠕧𠗻𠗾𠗼𠗽𠗿𠗺𠞬𠞯𠞱㓼𠞿𠞾𠞧𠞪𠢔𠢓𠢙㔪𠣺𠥖𠨢𠪑𠪒𠬈𠬆𠷖𠸷𠹋𠻎𠻐𠸹𠹤𠻏𠹆𠹦𠹖𠹃𠹊𠹘𠹥𠹚𠹛𠹕𠹐𠹌𠻍𠹑𠹇㗓𠹓𠹢𠻌㗗𠹿𠹍𡈊𡈌𡈏𡎻𡎹𡏫𡎮𡏁𡏪𡎾𡎿𡎺𡎷𡎳𡏩𡕮𡖯𡖳𡖲𡙨𡙩𡙪𡟞𡟫𡠑𡟭𡟩𡟷𡟴𡟪𡠒𡟨𡠓𡦎𡦆𡩣𡩥𡮙𡳂𡲮𡲰𡲭𡺴𡻑𡺭㟳𡺽𡺷𡺫𡺯𡻁𡺵𢄏𢄎𢄜𢄓𢄊𢄞𢄔𢄚𢇋𢊇𢊓㢑𢊄𢊅𢊒𢊀𢌫𢍣𢐊㣀𢑦𢔴𢔳𢕍𢞉𢝗愗𢝊㥣𢜹𢜿𢞐𢞏𢞲𢞟𢞕𢞡𢞜𢞎𢞠𢞮𢞖𢟊𢞬㥰𢞦𢞗𢞓𠎶𢧖𢧠𢧘𢩖𢱮𢱾𢱴𢱷𢲀𢱺𢱫𢱟𢱶𢲰𢱣𢾒𢾂𢾝𢾔𢾃𢾋𢾄𣁗𣂄𣂵𣄉𣇦㬂𣈥𣉑𣌾朡𣎅𣔿𣕂𣔸𣔺𣕎𣕅𣔹𣔷𣕄㮝𣖤𣔽𣕁𣣟𣣞𣣊𣣩𣣢𣣠𣦉𣦇𣨲𣨳𣪬𣫹𣬍𣮹𣹞𣹪㴵𣺁𣹩𣺬𣺀𣹵𣹱𣹶𣹯𤊶𤌃𤊿𤌁𤊲𤋄𤗛𤗜𤗘𩙲𤚵𤚨𤚏𤚞𤚎𤟞𤟢𤠠𤠝𤠚𤠙𤠫𤠘𤠐𤠎𤠤𤠟𤠓𤠵𤦃㻠𤧗𤬂𤬃𤭜𤭟𤯇𤲚𤲗𤲣𤷷𤷆𤷌𤷖𤷍𤷹𤷙𤷘㾚𤷑𤷎𤷕㾣𤷔𤷸𤽺𤽹𤽿𤿳𤿵𤿶𤿴𤿷䀅𥁹𥆜𥇟𥇵𥇿𥇑𥇚𥇏𥇍𥇞𥇘𣪭𥇠𥇾𥆞𥍵𥍳𥍲𥏘𥓐𥓖𥓗𥓊𥓍𥚊𥚉𥚋𥟓𥟚𥟎𥟒䅞𥟔𥟗𥟕𥟻𥟍𥟺𥟖䆦𥦛𥦞𥦝𥦤𥦟𥦥𥦨𥪏𥪌𥪕𥭠𥭕𥭜𥭯𥭑𥭡𥮧𥭞𥭢𥭔𥭮𥭒𥭘𥭭𥭟𥭬𥭐𥭝𥮎𥹲𥹸𥹵𥹶𥹼𥹾𥹽𦀕𦀔𦀝𦀜𦀣𦁗𦋈䍝𦋉𦋅𦎇𦎊𦎔𦐹𦑀𦐾𦐸𦓖䎸𦕷𦕸𦕽𦛝𦝞𦝷𦟔𦝦𦝟𦝳𦝲𦞗𦝚𦞖𦝝𦝥𦝰𦝵𦝴𦝜𦝠䐴𦝭𦥁𦨼𦨾䑯𦩉𦯤𦴌𦴕𦴎𦳔𦳇𦴔𦳛𦵟𦳭𦴉𦳏𦳿𦴚𦳵𦳮𦳥𦴍𦳟𦳬𦳗𦴬𦳍𦳣𦴀𦳫𦳽𦴏𦴭𦵐𦳾𦳄𦳡𦳹𧆻𧆼䖔䖓𧆺𧋐𧋖𧋎𧋡𧋕𧋏𧋓蜔𧋔𧖵𧚐𧚡𧚁𧚅𧙮𧚈𧚂𧚆𧚏𧚉𧚀𧚄裑𧠩𧠫𧠪𧠰𧣢𧣣𧣡𧧕𧧄𧧉𧧠𧧟𧧋𧧝𧧨𧧔𧨜𧮵𧮶𧯥𧱅𧱉𧱀𧱆𧱄𧱊𧳉𧳄𧳂𧵨賋𧶂𧵫𧵧𧵬𧵭𧵱䞒𧻗𧻒𧻙䞪𧺼𧻝𧻖䞧𧻘𨀠𨀖𨀘𨀕𨀙𨀧𨁐𨀝𨀬𨀜𨀩𨀫𨀤䟯𨀨𨈹𨋲𨋰軭𨋭𨋯𨋪𨌁𨒼𨕜𨕛𨕚𨔦𨝃𨜳𨜺𨜷𨜶𨜼𨜴𨝊𨜻𨜵𨜽𨝀𨜾𨜗𨠦𨠤𨠐𨠶𨠥䤢𨥦𨱿𨱺𨳻𨳽𨳼𨳺𨻖𨻒𨻈𨻂𨻇𨻍𨻁𨻆𨻌𨾡𩂕𩂔𩂗𩂖𩂓𩂡𩈉𩈈𩉧𩉥𩉢𩉡𩎗𩐁𩐙𩐘𩑜𩑧𩑬䪵𩒖𩑦𩑡𩑮𩑝𩑚𩖢𩖩𩖥𩖦𩖲𩖣𩚬𩚪𩚯𩚫𩚱𩛆𩚭𩚹𩡰䭵𩰌𩲁𩲃𩱾𩵑𩾙𩾔𩾕𪋾𪐘𠄅𠍲㒂𠎙𠒭𠘆𠟂𠟋𠟕𠟒𠟌𠟗𠟅𠟊勬𠢢𠢣𠢡勨𠢦𠢤𠣿𠤀㔵𠨆𠨥𠪟𠭿𠻪𠻫𠻞𠼘𠻠𠻨𠻟𠻱𠻬𠼚𠻦𠻡𠽝𠁷𠼞㗰𠾀𡈗𡐝𡏷𡏯𡏮𡏭𡏵𡐞𡖽𥟿𡖾𡙭𡙶𡡌𡠙𡡈𡠞𡡊𡡉𡠦𡦕𡪅𡩾𡪗𡩽𡭂𡮞𡰌𡰋𡰎𡳏𡻘𡻠𡻨𡻩𡻢𡻡𡻚𡻣𡻙𡻰𡻧𡻤𢄣𢄱𢄳𢄌𦧠𢄦𢄤𢄲𢄧𢄡𢄰𢆢𢇌𢊖𪎒𢊘𢊜𢊛𢍫𢐒𢐔𢕚𢕔𢕑𢕞𢕘㣰𢕙𢞚𢟲𢞭𢠠𢟱𢟰𢟳𢟴𢟩𢟾𢠮𢟨𢟧𢟿𢟵𢧥𢧧𢧦𢧩𢩘𢱬𢳙㨾𢳋㨹𢲾𢲵𢳑𢳂𢲶𢳓㨮𢻚㪢𢾮㪡𢾬𢾩𢾺𢾧𢾳𢾽𢾱𣄎𣄑𣄏𣉒𣉪𣉔𣉕𣉓𣉗㬈𣉛𣉘𣉙㬻𣖸𣖵𣖿𣗁𣖻㮷𣗃𣖭𣖨𣖮𣖪𣗐𣖱𣖼𣖷𣖰𣗺𣗻𣖹𣤇𣣼𣣿𣣲𣣸𣣴㱗㱰𣪮𣬏𣬐𩫁𣯇𣯆𣯎㲧𣯜𣻕𣻓𣼠𣻢𣻱𣻛𣻪𣻫𣻡𣻿𣻖𣻠𣻗𣻥㵔𤌛𤌐𤌏𤌎𤌹𤌊𤌔𤌌𤔣𤕚𤗢𤚓𤚰𤚫𤚽𤚱𤚍𤚭𤚪𤚬𤚼𤛉𤡇𤡢𤡀𤡄𤠿𤡃𤠽𤡠𤡟𤠺𤡂𤡞𤡝𤦼𤧠𤧮𤧫𤨍𤬉𤬌𤭩𤭴𤭧㼱甧𤲭𤷿𤸖𤸈𤸉𤸀𤷼𤸓𤸔𤸊𩠾𤸩𤸗𤸻𤷻㾿𤸎㿤𥀈𥈛𥈚𥈙𥈂䁊𥈗𥈇𥈓𥈉𥈊𥈒𥊪𥈸𥍻𥍼𥍺𥏪𥏫𥏯𥏰𥔉𥔂𥔁𥔫𥔇䃏𥔨𥓻𥔪𥓾𥔄𥔩𥔊禌𥛂禑𥚦䄔𢿆𡰇𥠊𥟽𥠆𥠍𥠉𥠋𥠀𥠅𥠽𥧕𥦿𥦺𥧇𥪘𥪚䈍𥮤𥮜𥮝𥮢𥮖𥮕𥮥𥮪𥮭𥮚𥮣𥮗𥮐𥮯𥮍䈁𥮨𥯮𥺣𥺙𥺡𥺜𥺤𥻋𦁖𦁈𦁙𦁊𦁜䋱𦁏𦁍𦁝𦈺𦋣𦋡𦋞𦎗𦎚𦑈𦑊𦑌𦑎𦑏𦑋𦑍𦑇𦑑𦓚𦓹𦓼𦓻𦓽𦓸𦓺𦖊𦖎𦖈𦖉𦖍𦖐𦖨𦜜𦞫𦞤𦞨𦞭𦞟𦞚𦞰𦞥𦟕𦞢𦞲𦞝𦞡𦤕𦥂𦥳𦦄𦧝𦩜𦩌䑶𦩋𦱧𦶨𦵿𦶍𦶋𦷷𦶩𦷴𦶚𦶌𦶕𦵾𦵷䔊䔃𦵱𦷽𦶈𦶙𦵭𦷔𦶑𦵽𦵣𦵵𦷹𦶎𦵴𦷙𦵦𦷺𦷵虠𧇁𧇉𧌃𧌬𧌴𧌗𧌉𧌄𧌆𧌚𧌎𧌧𧌅𧌇𧌙𧌔𧌌𧌝𧌢𧌣𧖻𧖼𧖺𧚩𧚵𧚬𧚪𧚯𧚷䘵䘶𧠽𧡅𧡈𧠼𧠾𧣬𧣭𧣩𧨊𧨋𧧸𧨄𧧶𧧹𧱓𧱒𧱠𧱣𧳎𧳍𧳐𧳌𧶇𧶍𧶝䝳𧹣𧻴𧻱𧻿𧻵𧻼𧻲𧻶䞱𧻺䞯𨀮𨁅𨁃𨁑𨁌𨁳𨁇𨁋𨁏䟴𨁄𨁍𨁎𨁊𨁁𨁒𨂟𨁔𨁙𨂢𨉋𨉈𨌍𨌟𨌈𨌎𨌝𨌄𨐘𨐛𨕦𨕢𨖃𨕧𨝢𨝏𨝎𨝧𨝓𨝍𨝌𨝨𨝑䣜𨝘𨝛𨝐𨝪𨠹𨠸𨦂𨦄𨦆𨲀𨲆𨴥𨴑䦙𨴎𨴕𨴏𨴘𨴍𨴓𨴖𨴙𨼈𨻷𨻻𨻼𨾴𨾾𨿁𩂦𩂥𩂢𩂤𩈒𩈎𩉺𩉸𩉽𩉾𩊁𩊃𩊀𩊂𩎛𩎙𩎜𩎚𩎡𩐚𩐜𩒊𩑹𩑳𩑵𩑼𩑸𩑠𩒃𩑰䪶䪽𩒇𩒄𩑻𩑾𩑴𩖹𩖸𩖷𩖵𩛌𩛎𩛑餄𩛋馛𩡾𩡺𩡷𩢌𩣡𩨠𩨞𩫂𩬏𩬀䯴𩬐𩰍𩰎𩲎𩲍𩲊𩲋𩲌𩲏𩵗䰵𩵕𩵛𩾬𩾧𩾠𩾞䲪𪊍䴟𪎓𪎔𪎒𠆁𠏖𠏃𠏤𠏢𠐉𠎿𠏈𠏇𠒵𠒲𠘌𠘋𠘊𠘅𠟓𠟦𠟩𠟰𠟪𠟧𠢱𠢳𠢴𠢰𠥜𠥝𠥞𠧀𠪯㕐𠮆𠮃𠮄𠽜𠽰𠾌𠽻𠽦𠽹𠽣𠿅𠽫𠽭𠽲𠽩𠽵𠾉𠿆𠾥𠏧𠽪𠾗𡑚𡑜𡑙𡐤𡐥𡐰𡑘𡐱𡕶𡗁𡚅𡙽𡡥𡢇𡡒𡡔𡢊𡡐𡢆𡡏𡡨𡦝𡪠𡪺𡪽𡪸𡮦㝻𡳞𡳘𡳑𡼓㠉𡼎𡼛𡼺㠇𡼜𡼒𡼖𡼚𡑈𢅋𢄷𢄶𢄸𢄺𢅉𢄼𢅊𢊱𢋂𢊵𢋄𢊶𢐞𢐟𢒯𢕫𢕯𢞒𢟤𢠬𢟯憄𢡏𢡒𢡐𢠺𢠷𢡇𢡤𢡀𢠼𢡅𢡁𢧳㦻𢳁𢲴㩋𢴪𢴢𢴩㩍𢴾𢴼𢴰𢿇𢿕𢿖𢿓𢿎𢿈𣁜𣁟𣁢㫂𣂽𣂿𣉜𣊶㬚𣊝𣉿㬒𣊔𣊁𣍇𣍆㬼𣎔𣙵𣘚𣘪𣘲𣘗𣘣𣙴㯃𣙶𣘮𣘥𣘠𣙱𣘖𣙰𣘬𣘯𣘟𣙤㯀𣤈𣤔𣤊𣤋𣩏𣩙𣩔𣩑𣪶𣯬𣯭𣯪𣻮𣽚𣽳𣽥𣽡𣽕𣽫𣽤𣽛𣽌𣽯𣾄𣽰𤍝𤍗𤍦𤍓𤎘𤎗𤔨𤗫𤗪𤗨𤗬𤗯𤛑𤛊犟𤛏𤛍𤛎𤡰𤡧𤡳𤡭𤡶𤡹𤡪𤢋𤡲𤡱𤡮𤡺𤨖𤨹𤨻𤬐𤮖𤭻𤭼𤭽𤲾𤹜𤸸𤸹𤸮𤸴𤸲𤹛𤸳𤸼㿥𥀎𥀏𥀐𥂇𥉛𥉓䁟𥉜𥈿𥉙𥉊𥉄𥉌𥉀𥉟𥉞𥉎𥉔𥉃𥊇𥎂𥎅𥔱𥕇𥔮𥔯𥕈𥔴䄘𠖟𥠲𥠶𥠳𥠱𥡀𥡁𥧝𥧒𥧙䆭𥧟𥧛𥧘𥧓𥧞𥧠𥧔𥧗𥧚𥪧𥯾𥯝𥱀𥯢䈞𥯖𥯞𥯯䈧𥯫𥯪𥯤𥯑䈘𥯬𥯺𥯙𥯳𥯡𥯜𥯥𥯟𥯔䈠𥰅𥻖𥻕𥻐𥻈𥻄𥻉𥻍𥻒𥻌𥻊𦂠𦂒䋴䌂𦂋𦂡𦂕𦂈𦂆𦂍𦂅𦂑𦂉𦂢𦋰𦋳𦋴𦋯𦌆𦎢𦑞𦑣𦑠𦑛𦑻𦑘𦑙𦑝𦔅𦔆𦔂𦔃𦔄𦖢𦖧𦖦𦖤𦖬𦖩𦟧𦠁𦟟𦟙𦟣𦠏𦤚𦥅𦥈𦥻𦦅𦦆𦧾𦧿𦩤𦩱𦩲𦩡𦩢𦩬𦩠𦩝𦩣𦩧𦸁𦸪𦸰𦸆𦹽𦸢𦺹𦸊𦸐𦶏𦺀𦸓𦸮𦸘𦸗𦸚𦸔𦹇𦹣𦸸䔤𦹿𦸡𦸷𦸱𦸛𦺥𦽬𧇓𧎈𧍮𧍜𧍴𧍔𧎃蝰𧍥𧍠𧍰𧍯𧍭𧍲𧍬𧍶𧍵𧍙𧏇𧗁𧗹𧛣𧛧𧛖𧛏𧛚𧛩𧛒𧡕𧡏𧡍𧡑𧡖𧣹𧣸𧤆𧤃𧤉𧣷𧣵𧩇𧩅𧨾𧩆𧨩𧨴𧨿𧩄𧨵𧩚𧨪𧨳𧨷𧩃𧨹𧨰𧩝𧨲𧨧𧮽𧮺𧮼𧯱𧯰𧱡𧱜𧱘𧱝𧳚𧳙𧳝𧳜𧳞䝽𧶠𧶢𧼏𧼓𧼒𧼑𧼔𧼙𧼎𧼗𧼡𧼠𧼖𨁸踷𨂇𨁹𨂀𨁷𨂁𨁆𨁺𨂜𨂃𨂄𨂭𨂝𨂞𨉕𨌶𨌫𨌧𨍆𨌬𨍅𨌳𨌭𨌴𨌢䡤𨐟𨖳𨖌𨖏𨖯𨖭𨖎𨖬𨖰𨖱𨖮𨖙𨛫𨝴𨝵𨞎𨝭𨝰𨞌𨡏𨡑𨡘𨡌𨡎𨤕𨦾𨧛𨦳𨦮𨲇𨲊𨲈𨲋𨲌𨵄𨴭𨴪𨴱𨴬𨵅𨴰𨴩𨵆𨻴𨼔𨼐𨼏𨼒𨼋𨼌𨼊𨼓𨼎𨿙𨿐霃𩃁𩂿𩂽𩂼𩂺𩂻𩃑𩂾𩃐𩂹𩈚𩈜𩈛𩊑𩊖𩊟𩊜𩊚𩊝𩎭𩎥𩎮𩐃𩐦𩐠𩐣𩐤𩐡𩐨𩐥𩐞𩒔𩒓𩒏𩒚𩒭䫂𩗊𩗅𩚻𩛡𩛪𩛤𩛭𩛦𩛠𩛨𩛥𩠚𩢔𩢖𩢞𩢕𩢡𩢎𩢛𩢒𩢗𩢙𩢓𩨲𩨯𩨮𩨳𩬜𩬞𩬑𩬠𩬟𩬙𩬖𩲬𩲹𩲳𩲦𩲢𩲩𩲧𩵬𩵭𩵩𩶀𩵦𩵥𩵱𩵫𩾽𩿁𩿓𩾶䲬𩾹𩿔𩾷𩿛𩾸𩿀𩾼𩿕𩾿𩿃𩿉𪉘𪊐𪊕𪊗𪌉𪌈𪌆𪌅𪌐𪎗𪎕𪏮𪐟鼏𡭋𠆎𠏨𠏰𠏴𠐆𠏭㒛𠏩𠐀𠒸𠕭𠠄𠠁𠠃𠠂勶㔦𠥢𠪺𠬓𠿞𠿗𠿤𠿎𠿣𠿝𡀺𠿛𠿖𠿠𠿔𠿋𠿡𠿉𠿟𡀼𠿼𠿏𡑹𡑶𡑸𡚇𡢜𡢱𡣁𡢚𡢒䆯𡣂𡢖𡣀𡢑𡢕𡦢𡫃𡫀𡫂𠮊𡮰𡰖𡽆𡽊𡼾𡽅𡽂㠕𡽛𡽄𢀄𤀢𢅕𢅗𢅑𢅎𢅚𢋆𢋃㢜𢋅𢋌𢍱𢐦𢒲𢕺𢖄𢕻𢡴𢢌𢡑𢡈𢢍𢢒𢢘𢢓𢢰𢡎𢢖𢢗𢢜𢣊𢴳𢶃𢶄𢶚𢶌擌𢶙𢻠𢿤𢿸𢿭𢿳𢿴𢿵𢿽𣄙𣊞𣋄𣊟𣎛𣎟𣎚𣚪𣚝𣚒𣚢𣚯𣛫𣚠𣚡𣚔𣚘𣚏𣚇𣚄𣚩𣙼𣤘𣩟𣩢𣩡𣫀𣬕𣯼𣯹𣿈𣿉𣿞𣿊𣿢㵤㵮𤀄𣿌𣿝𣿤𣿓𣿇𣿆㵟𤀷𤏘𤏮𤎲𤎣𤎳𤏭𤏸𤔱𤕛𤕦𤕩𤖖𤖛𤗷𤗳𤗴𤗵𤛢𤛧𤛥𤛩𤛦𤛣𤡴𤡿𤢏𤢐𤢗𤢣𤢎𤢘𤢌𤩂𤩄𤩩𤩃𤩨㻶𤮍𤮈𤮋𤮉𤳌𤴟𤺀𤹞𤹧𤹨𤹥癊𤹴𤹩𤹡𤹢𤹬𤺓𤺆𤾝𤾛𥀛𥀖𥀙𥀔𥀘𥀕𥂙𥂘𥉝𥊉𥉺𥉽𥉋䁳𥉸𥉿𥉷𥉾𥉹𥊫𥉼𥉴𥉵𥊧𥎍𥕎𥕦𥕡𥕍𥕏䃝𥕔𥕼𥛚𥛞𥛘𥛝𥛟𥡦𥡢𥡟𥡤𥡣𥡠𥢈𥡪𥡧𥢇𥠿𥡬𥡮𥢭𥧱𥧴𥧶𥧵𥧸𥧻𥧬𥧯𥧭窹𥧷𥧹𥧽𥪱𥰶𥰛𥰲𥱂𥱍𥰟𥰡𥰜𥰱𥰮𥱇𥰻𥱁𥰘𥰹𥰝𥰧𥰣篗𥻮𥻨𥻥𥻯𥻤𥻬𥻰𦃗𦃘𦃔𦃊𦃒𦃋𦃓𦃦𦃢𦉉𦉈𦋿𦌉𦎰𦎯𦑼𦑸𦑺𦑲𦑹𦑾𦔌𦔋𦗍𦖾𦖼𦖿𦗀𦖽𦠸𦠈𦠅𦠻𦠐𦠌𦠍𦠄𦠺𦠓𦠷𦠹𦤟𦥋𦧮𦩵𦩸𦩼𦩴𦩶𦫪𦫫𦷻𦸙𦺧𦺌𦻜𦺘𦺾𦺞𦼏𦺟𦻝𦺓𦺻𦺶𦺐𦺡𦺽𦻞𦸺𦼢𦺗𦺔𦻃𦺎𦺨𦺙𦺭𦺛𦺢𦼡𦺬𦼎𦼱𧇡𧇞𧇟𧇜𧇝𧇬𧇧𧍩𧎸𧎡𧎨𧎺𧏂𧎦𧎤𧎣𧎫𧎼𧎷𧎿𧎭𧎱𧏊𧏄𧎹𧗈𧗆𧗇𧜊𧜍𧛻𧜃𧜂𧛼𧛹𧛸𧡮𧡣𧡢𧡡𧡫䚈𧡯𧡦𧡬𧤎𧤕𧤖𧤐𧤗𧤑𧩮𧩬𧪇𧪃𧩣𧫜𧯀𧯷𧯺𧱰𧱪𧱨𧱩𧲄𧳬𧳧𧳮𧶺𧶲𧶵𧷋𠠋𧷐𧹭𧹬䞻𧽅𧽆𧼴𧼬𧼵𧽄䞺𧽶𨂱𨂪𨂡𨂲䠒𨂦𨂵𨂴𨂸𨂯𨂰𨂣𨉢𨉤𨍖𨍑𨍏𨍨𨍌𨍐䡡𨍈𨍊𨐡𨗣𨖹𨗞𨖷𨗡𨖶𨖵𨗟䣡𨞗𨞚𨞛𨞘𨞒𨞓𨟗𨝳𨡫𨡴𨡸𨡭𨤘𨧩𨧵𨧪𨨮𨧧𨧾𨧥𨨶𨧰𨧦𨨬𨲉𨲕𨲓𨵍𨵊𨵏𨵐𨵋𨼪𨼫𨼯𨼮𨽼𨿭𨿢𩃠𩃜𩃚𩃖𩃴𩃙𩃕𩈢𩈡𩈤𩈥𩈦𩈣𩊪𩊯𩊰𩊫𩎯𩎷𩎵𩎲𩎳𩐫𩓘𩓂𩒯𩒼𩒸𩒾𩒷𩒴𩒿𩒳𩒱𩒻𩒽𩓖𩓝𩗘𩗓𩗕𩗣𩗢𩗙𩗞𩗟𩗚𩗔𩛳𩛢𩜍𩜁𩜅𩜚𩜃𩜟𩜖𩛺𩛼𩜊馟𩢼𩣊𩢶𩣇𩢿䮉𩢹䮌𩢰𩢸𩢽𩢑𩢻𩢾𩨾𩨽𩬸𩬽𩬹𩬳𩰳𩲻𩳆𩳅𩳇𩳋𩲨𩳈𩲾𩳁𩲽𩶂𩶞𩶁𩶄鮍𩶖𩶎𩶈𩶇𩿠𪀍𩿟𩿤𩿢𩿝𩿬𩿞𩿧𩿪𩿣𪉜鹷𪉝𪊜𪌝𪎝𪎞𪎛𪎵𪎹𪎶𪏳𪏲𪏴𪐦𪐧𪐥𪔆𪔋𪔺𪔼𠐊𠐋𠒿𠓽𠘘𩇟𥎐𠚞𠟼𠠐𠠏𠠍𠢹𠢷𠢺𠤊𡁁𡂋𡁆𡁓𡃢𡀿𠽼𡁋𡁎𡂊𡁇𡒓𧷎𡒌𡒯𡓉𡗆𡗈𡚑𡣏𡣔𡣎𡫝𡫔𡽉𡽣𡽝㠚𡽧𡾻𡽠𡾀𢅟𢅠𢋚𢋒𢋝𢖎𢖈𢕬𢢞𢣉𢢤㦘𢣙𢣒𢣚𢣝𢣎𢣼𢨔𢩠𢷗𢷋𢷍𢷕𢻧𣀀𣀊𣀏𣀂𣀁𣄝𣋊𣋋𣋍𣍏𣎜𣚀𣜍𣜂㯱𣜇𣛷𣛸𣛽𣜤𣛻𣜹𣜺𣤢殩𣩪𣩭𣩴𣰎𣿟𤀫𣿣𤀦𤀪𤀣𤀵𤁊𤏽𤐀𤐞𤏿𤖞𤗺𤛱𤢕𤢨𤢦𤩮𤪃𤩭𤮏㽪𤳟𤳕𤳠𤳓𤳘𤺌癋𤺔𤺖𤺅𤺏𤺩𤺎𤺐𤺸𤻃𤺻𤾥𤾢皢𤾣𥂵𥂬𥂨𥊭𥊸𥊴𥊮𥊶䒅𥋁𥊿𥎒𥐀𥐁𥕵𥕲𥕸𥕰𥕾𥕽𥕿𥛯𥡭𥢎𥢏𥢊𥢍𥢕𥢋𥢌𥢰𥢓𥧼𥨕𥨎𥨘𥨑𥨍𥪻𥪳䈹𥲡𥲚𥲄𥲠𥲆𥲝𥲪𥱸𥲍𥲊𥲦𥱺𥲗𥲈𥲎䈽䉄𥱶𥲁𥲅𥱵𥲨𥲏𥲑𥲢𥲜𥲉𥱿𥲖𥱹𥲟𥲧䉆𥲇𥲛𥲥𥲋𥳉𥻿𥼃𥼀𥼂𥼄𥼅𥻦𥼟𦄔䌎𦄌𦄏𦄙𦄋𦄓𦃕䌐𦄜𦉐𦉎𦌒𦎹𦒅𦒈𦒐𦔓𦗐𦗑𦗒𦗗𦗖𦗥𦡆𦡫𦡅𦠿𦠾䐾𦡪𦡨𦡌𦤣𦤢𦦗𦦑𥪵𦦘𦦜𦪉䒄𦪋𦪊𦪍𦪎𦪈𦽎𦽄𦼷𦽵𦽔𦽶𦼴𦽫𦼲𦽗𦽒𦽅䕉𦺱𦽋𦽇𦼳𦽀𦼼𦽃𦽏𦽕𦽁𦾲𦿀𧐴𧏹𧐙𧐍𧏺𧐞𧐟𧐎𧑀𧐈䗟𧐓𧑌䗰𧗌𧗋𧜤𧜥𧜽𧜠𧜧𧜦𧜝𧜞𧜲𧝬𧝙䚊𧡺𧡹𧤛𧤮𧤣𧤠𧤟𧪶𧪹𧪡𧪣𧪨𧪯𧪚𧪙𧪢𧪝䜂𧪫𧯉𧯾𧰀𧯼𧰇𧱵𧱲𧱳𧱴𧳺𧳼𧳸𧳶𧳻𧷍𧷑𧷏𧹴𧹲𧹳𧽔𧽑䟀𧽓𧽉𧽌𧽊𧽏𧽖𨄀𨃕𨃗𨃿𨃙𨃣𨃓䠕𨃘𨃔𨃞𨉱𨍴𨎈𨍿𨍫𨎇𨍹𨍸𨎉𨎀𨍶𨐩𨐨𨐯𨗥𨘄𨘃𨗰𨗨𨗪𨗱𨘁𨘂𨞩𨞫𨞷𨞶䣢𨢠𨢎𨢆𨢇𨢅𨢋𨢈𨢐𨤚𨤴𨨷𨨯𨨰𨨹𨨴𨨳𨪅𨪄𨨺𨲟𨵩𨵬𨵪𨵧𨵫𨼿𨽀𩀋𩃒𩃸𩄁𩃺𩄖𩅅𩈬𩈭𩈯𩈮𩋁𩋵𩋄𩋞𩋌𩋒𩋂𩋍𩋏𩋝𩋈𩎺𩎾𩎿𩐮𩐰𩓧𩓮𩓨𩓦𩓢𩓤𩓻𩓼𩓪𩗭𩗶𩗳𩗴𩗱𩗷𩗵𩗪𩗫𩜀𩜬𩜒𩜽𩜳䭍𩜰𩝀𩜱𩝗䭊𩜵𩜲𩜭𩜶𩜸𩝢𩝟𩞊𩣖𩣘𩣝𩣤𩣣𩩍𩫗𩭊𩭌𩭐𩭋𩭎𩭏𩰹𩳒𩳍𩳓𩳡𩳎𩳕𩳝𩳔𩳏𩷋𩶦𩷂𩶥𩶩𩶢𩶤𩶰𩷌𩶯𩶿𪀤𪀚𪀔𪀺𪀠𪀛𪀡𪀥𪀞𪀿𪁀𪀘𪀬𪀕𪀭𪀟𪀩𪁋𪊳𪊥𪊧𪊫𪊦𪊪𪌢𪌣𪎡𪎣𪎠𪎽𪎺𪏺𪏸𪏼𪏶𪐴𪑃䵣𪓔𪔍𪕃𪕁𪔽𪕀𪔾𪔿𪕆䶓𪗔𠐵𠐶𠐲𠐺𠓈𠔶𠘞𠘝𠘟𠠜𠠝𠠠㔎𠣀𡂚𡂓𡃝㘊𡂣𡂩𡂜𡂛㘍𡂗𡒱𡓊𡒳𡓍𡣪𡣮𡣯𡫟𡭐𡮻𡾈𡾉𡾌𡾅𡽞𡾔𡾕𢅫𢅪𢅩𢋣𢋱𢐷𢐳𢖑𢖏𢖗𢣔𢣞𢣓𢣠𢥃𢤋𢣿𢤄𢤇𢷹𢷺𢷻𢷸𣀒𣀐𣄟𣋟𣋣𣍒𣎩𣝥𣞇𣝂𣝒𣝌𣚟𣜁𣝈𣝏𣝾𣝍𣝘𣝇𣝞𣝉𣝎𣝝𣝚𣞆𣝑𣤩𣦦𣦧𣩰𣩯𣫓𣫒𣰨𣰛𣰚𣰜𣰡𤀲𤁦𤁽𤁳𤁿𤁥𤂀𤁡𤁵𤁮𤁧𤁱𤁬𤁣𤁢𤂃𤂲𤂁𤁯𤁫𤂂𤂊𤐩𤐰𤐱𤖗𤘀𤗿𤢶𤢵𤣃𤪍𤬘𤮜𤮚𤮙𤺷𤻄𤻆𤻇𤺼𤻀𤻅𤻍𤻁𤺾𤾧𤾸𥀣䁴𥋨𥋞𥋣𥋯𥋜𥋤𥋡𥋥𥎕𥎔𥎓𥎎𥖘𥖜𥖙𥖠𥖞𥜄𥣓𥢴𥢻𥢷𥢸𥢽𥢶𥢹𥢵𥨢𥨡𥫃𥪼𥳿𥳥𥳷𥳒𥴀𥳛𥳰𥳢𥳍䉖𥳡𥳲𥳜𥳌𥳖𥳳𥳺𥳶𥳬𥳪𥳣𥳐䉚𥲕𥳕𥳴𥳋𥳚𥳈𥳟𥳵𥳏𥳇𥳗𥼤䊣𥼜𥼡𥼞𥼘𥼛䊨𦅆𦅋𦅈𦅇𦅧𦅔𦌕𦌢𦏅𦌩𦏇𦒍𦔛𦔠𦗢𦗧𦗨𦗡𦡲𦡻𦡴𦡱𦡹𦡵𦢐𦡷𦡽𦥏𦥐𦦣𦦢𦧴𦪔𦪚𦪛𦪧𦪗𦪕𦪒𦪜𦪖𦾶𦾸𦾰𧀘𦿅𦾵𦿂𧀆𧀙𦾹𧀕𦿖𦾬䔼𦿆𦾳𦿃𦿊𦿏𦾽𦿎𧀔𦾺𧀚𦿁𦾻䖛䖚𧈁𧇼𧑚𧑔𧑡𧑜𧑕𧑊𧑋𧑑𧑩𧑫蟱𧑦𧑘𧑝𧑧𧑖𧒈𧑤𧑎𧝗𧝚𧝑𧝊𧝋䙞𧝇𧝯𧝪𧝫𧝆𧝭𧝔𧝮𧝌𧡸𧢆𧢂䚧𧫡𧫥𧫛𧫞𧫗𧰃𧱼𧱾𧴅𧴇𧷦𧷡𧷻𧷥𧷧𧷟𧷿𧹶𧽣𧽦𧽥𧽞𧽤𧽫𧽟𧽧𧽨𨄈𨄉𨄗𨄕𨄛𨄋𨄨𨄎𨄘𨅓𨅅𨄍𨄊𨄓𨅊𨉹𨎡𨎍𨎢𨎐䡮𨐱𨐰𨘟𨘙𨘌𨘞𨘝𨘇𨝦𨟅𨞺𨞽𨟂𨟃𨞿䤍𨢡𨢢𨢨𨢣𨢪𨢯𨢹𨤷𨪏䥃𨪎𨪇𨪊𨪒𨪗𨪟𨬅𨲩𨲧𨶊𨶂𨶅𨶋𨶉𨶁𨶀𨶇𨶆𨽍𩀜𩀟𩄡𩄝𩄢𩃾𩄛𩄺𩄷𩄗𩄸𩄦𩄘𩈴𩈵𩈲䩳𩋡𩋽𩋣𩋨𩋹𩋿𩋾䩴𩋠𩌀䩰𩋬𩋤𩋯𩋺𩏊𩐱𩐲𩐴𩑁𩔀𩔆𩔈𩔅𩔃𩔋𩔂𩔊𩗰𩘓𩘊𩘏𩘑𩘈𩘋𩘒𩘍𩝛𩝣𩝡𩝨𩝚𩡎𩡍𩡐𩣼𩤒𩣵𩤞𩣰𩣳𩣲𩤁𩣯𩣮𩤖𩣶𩣻𩤜䯛𩩝𩩠𩩦𩩟𩩡𩩛𩩢𩩗𩭩𩭫𩭡𩭷𩭟𩭣𩭢𩭥𩳯𩳢𩴀𩳨𩳲𩳧䰩𩳥𩷕𩷐𩷚𩷓䱑𩷙𩷏𩷖𩷘𩷵𩷎𩷗𩷴𩷍𩸁鮺𪁒𪁕𪁚𪁍𪁔𪁓𪁞𪁧𪁥𪁎䳛𪁏𪁡𪁮𪁑𪁛𪉣𪊷𪊶𪋃𪊻𪊵𪌮𪌰𪌳𪌱𪎤𪏁𪎻𪎾𪐀𪏿𪑀䵥𪐿𪑄䵤䵧𪓛𪔏𪔑𪕎𪕍𪕋𪕌𪖕𠐻𠑅𠑄𠐾𠐼𠐿𠓌𠓎𠘢𠘣𠚟𠣅𠣄𡃩𡂙㘑𡃦𡃧𡃷𡓒㜳𡣽𡤌𡤋𡳭㠡㠢𡾛𢀊𢅯𢅵𢋲𢋸𢐾𢤤𢤎𢤖𢤘𢤐𢤱𢥄㦦𢤩𢤯𢥋𢤶㦣𢸴𢸀𢸫𣀠𣀡𣀞𣀝𣋵𣋲𣋶𣞐𣞝𣞜𣞒𣞔𣟑𣞓𣞷𣞗𣞰𣞟𣟉𣤭𣤬𣩷𣰬𤃀𤂹𤑓𤕀𤜂𤛻㺡𤪋𤪮𤪫𤮡𪋍𤳪𤻝𤻜𤻙𤾫𤾬𤾭𤾲𥀬𥀫𥃂𥌀𥌍𥌆𥋿𥌃𥌁𥌐𥌏𥌅𥎘𥎗𥎖𥎙𥖬𥖫𥜒𥣘𥣟𥣖𥨭𥨲𥴿𥴯𥴦𥴺𥴹䉟𥴻𥴵𥴪𥴡𥴸𥴷𥴴䉜𥴢𥴶𥵄𥽅𥼻𥼶𥽂𦅺𦆆𦆋𦅿𦆁𦌪𦏕𦒟𦒝𦔦𦗴𦗵𦠕𦢎𦢏𦤦𦦧𦦯𦪭𦪬𦪫𦪪𧀯𧀡𧀣𧂣𧁉𧀺𧁈𧀭𧀥𧀩𧀲𧀴𧀤𧀬𧁋𧀻𧀠𧀨𧒘䗵𧒗蠄𧒝𧒨𧗕𧝷𧝸𧝲𧝳𧠂𧤻𧫾𧬋𧫿𧬇䜑䜌𧬰𧯍𧯏𧰐𧰊𧲃𧴕𧴎𧴌𧸂𧷾𧸅𧸕𧸈𧽿𧽷𧽼𧾂𧽾𧽸𧽽𧽹𧾁𨅋𨅣𨅤𨅖𨅔𨅎𨄐𨅏𨅗𨅞𨅑𨅪𨅩𨎭𨎤𨎬𨎩𨎮𠑌𨙐𨘵𨘲𨟘𨟑𨟏𨞾𨣕𨣁𨣆𨣃𨣂𨣀𨣅𨣇𨢿𨣄𨣔𨪐𨫒䥎𨫔𨲯𨲮𨲲𨲭𨶜𨶠𨶶𨼽𨽖𨾂𩀨𩀫𩄼𩄾𩄿𩅂𩄽𩅁𩄻𩅉𩅃𩈹𩈸𩌢𩌈𩌌𩌆𩌓𩌄𩌠𩌝𩌅𩌊𩏖𩏗𩐶𩐷𩔣𩔢𩔠𩔚𩔝𩘜𩘠𩙷𩝿𩞋𩝽𩞙𩞄𩞉𩞅𩞃𩞆𩞞𩡓𩤗䮔𩤙𩤠𩤟𩤣𩤘𩤡𩤩𩤤𩤦𩩞𩩯𩩮𩩴𩩲𩩳𩭽𩭾𩮅𩭿𩮄𩱄𩳵𩳸𩴃𩳶𩸨䱣𩸹𩷻𩸎𩷽𩸟䱡𩸃鯘𩸐䱚𩸍𩸧𩹁𪁲𪁸𪂀䳣𪂅𪁻鵼𪁽𪂑𪂈𪂪𪂍𪂉𪂕𪂛𪂌𪂞𪁼𪂆𪂄𪉨𪋅𪋄𪋉𪋆𪍂𪍅𪍁𪍄𪎦𪏂𪑂𪑉𪑈𪑊𪑌𪑋𪔗𪔘𪔕𪕒𪕔𪕓𪖚𪖛𪗜𪗤𪚑䶯𪚺𠑑𠑛𠓏𣌅𡄓𡄕𡄔𡄣㘓𡄑𡄒㚀𡓰𡚛𡤒𡤖𡤗𡦵𡳴𡾱𢅹𢋿𢋼𢋻𢐿𢑄𢖝𢤲𢥌𢤫𢥚𢨟𢸁𢸭𢸧𢹙𢹚𢹍𢹘𣀣𣀥𣀫𣋿𣎮𣟋𣟊𣟐𣟎𣟌𣟘𣟆𣟒𣟏𣟈𣬗䶰𤃄𩉀𤃩𤃲𤃼𤃪𤃫𤃽𤑸𤑷𤑳𤒞𤑹𤖢𤘃𤜄𤜅𤣎𤪿𤻶𤻷𥀱𥀰𥃉𥃇𥌜𥌨𥌚𥌞𥌛𥌟𥖿𥖻𥖼𥗀𥜚𥣯𥣬𥣩𥣰𥣾𥨴𥵪𥶕𥵬𥵲𥵧𥵠𥵫𥵞𥵯𥵦籆𥵢𥵝𥵜𥲀𥽐𦆟䌣𦆞𦆦𦌵𦌸𦌶𦌺𦏜𦡼𦢫䑉𦢨𦢧𦤫𦤬𦤨𠤫𦦩𦦪𦦫𦧸𦪱𦫰𦫯𧂨𧂋𧂔𧂞𧂏𧂈𧁾𧂁𧀰𧂛𧂂𧂐𧂅𧁿𧂩𧃞𧈍䗺𧓏𧓋𧓘𧓝𧓓𧓐𧓕𧓖𧗖𧞒𧞕𪗋𧞍𧞛𧞝𧞏𧞞𧞦𧢖𧢒𧬖𧬪𧬩𧬬𧰕𧰒𧰑𧰓𧲈𧲋𧲊𧲇𧴗𧴖𧴚𧸙𧸗𧾑𧾔𨆋𨆐𨆏𨆆𨆃𨆎𨆇𨆨𨆅𨆦𨆂𨆧𨎹𨎷𨎴𨎶𨎳𨏆𨘼𨙀𨘸𨞑𨞦𨟚𨣚𨣘𨣙𨣛𨣗𨬖𨬒𨬟䥙𨬔𨬚𨬐𨲳𨶮𨷁𨶰𨶯𨶱𨶭𨶲𨷄𩀼𩀿𩅡𩅦𩈼𩈻𩌬䩾𩌪𩌾𩌯𩌲𩌧𩌩𩍀𩏟𩏞𩐼𩐻𩐾𩔡𩕆𩔼𩔶𩔸𩔵𩘱𩘶𩘭𩘳𩘯𩘮𩘵𩞬𩞩𩞦䮨𩥌𩥃𩥁𩥏𩥂𩥑䮩𩥀𩥐𩥅𩥊𩥋𩪃𩪂𩪁𩫠𩮘𩮟𩮛𩮚𩮙𩮖𩮮𩮞𩴉𩴍𩹎𩹊𩹍𩹀𩸾𩹈𩹂𩹢𩹅𩹑𪃆𪃐𪃀𪂷𪃂𪂼𪃅𪃜𪃛𪃒𪃇𪂶𪃉𪃌𪃑𪃍𪃚𪂺𪃓𪃎𪃶𪂽𪃝𪃈𪄍𪄵𪉱𪉮𪉯𪋖𪍌𪍏𪍔𪍓𪏉𪏈𪐃䵔𪐄䵖𪑗𪑒𪑓䵭𪑖𪑜𪑕𪕤𪕝𪕞𪖢𪖣䶎𪗧𪗦𪚘𪚬𪚯𪚮𠑙𠖩𠠪𠣇𡅁𡄷𡄵𡄳𡄹𡄲𡓿𡔁𡓸𡤛𡤙𡮿𡾼𡾾𡾽𢅽𢑆𢑇𢖤𢨡𢩢𢺆㩲𣀯𣀱㰔𣟸𣟵𣟹𣟲𣟺𣟳𣠔𣟽𣠏𣠎𣠁𣤷𣤵𣤴𣤱𣩺𣩻𣰷𤄫𤃻𤄖𤄓㶓𤄑𤄏𤄛𤄸𤒤𤜉𤜇𤜋𤣓𤣑𤣘𤬝𤮩𤯐𧈕𤼂𤼅𤼁𤼀𥌮𥌰𥌯𥌱𥗒𥗓𥤂𥩀𥩂𥨻𥫎𥶘𥶏𥶔𥶑䉯𥶺𥶐𥶆𥶚𥶌𥶓𥶇𥶈𥶗𥶍𥶜𥶽𥼼𥽙䊰𦇑𦆻𦆾𦉝𦏢𦘅𦢺㔮𦣴𦦲𦧹𦪷𦪶𧂉𧃹𧃙𧃨𧃔𧃛𧃢𧃟𧃑𧃩𧃪𧈓𧈑𧓺𧓸𧓴𧓻𧔎𧔂𧔏𧞐𧞭𧞩𧥈𧭚𧭏𧭎𧭕𧭔𧭊𧯕𧰘𧲍𧴝𧴙𧸦𧸨𧸫𧹽𧾠𧾚𧾛𨆵𨆭𨆲𨊔𨏉𨙎𨙏𨙄𨙒𨣩𨤹䥩镾𨷇𨷓𨷆𨷃䦴𨷒𨽦𩅿𩆀𩆄𩆅𩇣䩍𩉁𩉄𩉃𩍎𩍏䪃𩍉𩍊𩍋𩍃𩏠𩏥𩏡𩏦𩏢𩐌𩑀𩕉𩕊𩕔𩕌𩕕𩕖𩘻𩘽𩞤𩞨𩟃𩞿𩟈𩟂𩟊𩟌𩟉𩞾𩟍𩟇𩟅𩡝䮰𩥬𩥫𩥰𩥭𩥯𩦉𩦄𩪍𩪊𩪎𩪑𩮵𩮱𩮯𩮹𩮷𩮰䰌𩰗䰘𩱏𩴙𩴐𩴘𩴑𩴜𩴒𩹶𩹱鰠𩹺𩹿鰢𩹳𩺫鰞𩹷𩹴𩹾𩺛𩺄𩹼𪄩𪄫𪄎𪄀𪄋𪃾𪃺𪄗𪃿𪄌𪄏䳵𪄂𪄁𪃼𪅉𪄆𪄒𪄓𪉸𪍛𪍢𪍟𪍝𪎫𪏕𪏔𪏓𪐇𪑬𪑰𪑮𪑨𪑭𪑷𪑪𪓎𪓬𪕩𪕨𪖦𪖩𪖪𪘀𪘍𪗸𪗼𪘊𪗷𪘁𪚶𪚻𪚷𪚹𪛊𠑩𠘥𡅙𡔇𡔉𡤢𡬇𡬄𣌏𡳸𡿇𡿑𢅾𢌈𢑊𦇚𢒹𢺈𢺑𢺄𢹳𢺉𢺅𣀳𣌓𣠝𣠟𣠜𣠠𣠚𣠛𣤹𣦯𣫥𣰼𤄷𤄺𤒻㸎𤜐𤜍𤮯𤰑㿘𤼍𤼌𤼋𥃔𥌽𥌾𥌻𥍃𥍀𥍆𥐑𥗝𥗟𥗪𥝊𥤆𥷁𥶹𥷆𥶸𥶻𥷈𥷅𥶿𥷇𥷄𥽥𥽧𦇗𦇛𦇔𦇖𦏨𦘊𦪽𦪾𧄚𧄍𧄏𧄛𧄐𧄑𧄓𧈘𧔠𧔟𧔣𧔝𧔷𧔥𧔤𧔪𧔧𧔳𧔨𧔴𧞺襰𧢝𧥌𧭜𧭪𧭝𧭥𧭣𧭞𧰙𧲗𧸿𧸳𧸲𧾡𧾥𧾤𧾢𨇁䠬𨇇𨇄𨇆𨇅𨇔𨇊𨙓𨣱𨤡䥮𨮷𨮒𨷔𨽄𩆘𩆛𩆑𩆕𩆚𩆒𩍓𩍗䪆𩍜𩍝𩍤𩏪𩏭𩑂𩑃𩕤𩕡𩕠𩙽𩟔𩟕𩟙𩦇𩦙𩦌𩦊𩦏䮳𩦋𩦂𩦛䯫𩯈𩯄𩯌𩯇𩯃𩯆𩯋𩯊𩯉𩯍𩱓𩴡𩴩𩴝𩴤𩴨鱃𩺱𩺲𩺵𩻖𩺯𩻊𩺸𪄅䳸𪅅𪅇𪄴𪅮𪅂𪄱𪄮𪅖𪄸𪄷𪅋𪄭𪄳𪄶鷠𪅈𪋜䵂𪍦𪍭𪍣𪍤䵁𪍧𪏙𪐋𪑾𪒋𪔦𪔬𪔩𪕯𪕫𪕭𪕮𪘜𪘑𪘐𪘤𪘒𪘕𪘗䶬𠑯𠖫𠠮𠣉𠮖𡆅𡚠𡤫𢌍𣀶\xa94\t\xc4㬯㬮𣎱𣡊𣠷𣠼𣠸𣠺𣠾𣡉㱍㱻𣪀𣩿𣫣𣰿𤅒𤅎𤅊𤅋𤓕𤫘𤬠𤮳𤮱𤮰𤼗𥃙𥍉𥐓𥗭𥗫𥗮𥤊𥤋𥩊𥷕𥷗𥷞𥷜𥷮𥽰䊲𦇙𦏗𦣋𦣇𦧼𧄻𧄹𧅋𧅃𧅀𧅆𧕍𧕃𧕇𧕋𧕕𧕒𧭟䜢𧭼𧭻𧾨𧾩𨇞𨇗𨇝𨇖𨇘𨊛𨏠𨏨𨏦𨣸𨣻𨮻鑙𨮸䥰𨳁𨳀𩆤𩆫𩉍𩍥𩍧𩍪𩍦𩍩𩕳𩖀𩕯𩕱𩟤𩟦𩟧𩦠𩦤𩪢𩪧𩪤𩯞𩯛𩱘𩱜𩴯𩴮𩻝𩻤𩻘𩻧𩻚𩹵䲕𪅹𪆀𪅿𪅼𪅵𪅻𪆂䴀𪆉𪅴𪅽𪅺𪆆𪆨𪅲䴂𪆋𪆑𪆳𪆁𪊂𪋡𪋣𪋢𪍶𪍴𪍲𪍵𪐎𪐍𪒔𪒒𪒓𪒑𪒏𪒕𪓐𪓷𪕶𪖯䶟𪘦𪘥𪘭𪚠𪛃𠓗𠫌𡅹𡅺𡔕𡿛𢆅𢥰𢥻𢺥𢺟𣌗𣎲𣠹𣡀𣡎𣤾𣫨𣫧𤅦𤅡𤓚𤜔𤣟𤼘𥀹𥀺𥜰𥜲𥤎𥩌𥷨𥷱𥷫𥷬𥽴𥽳𦇱𦍂𦌾𦍁𦔬𦣒𦦻𧅘𧅗𧅖𧅚䕽𧕡𧕨𧕥𧕟𧕤𧕞𧕛𧕧𧟃𧢢𧥓𧭽𧰠𧾮𧾯𧾰𨇧𨇦𨇩𨏱𨏫𨏪𨏩𨙣𨤂𨣾𨯞𨳂𨷦𨽝𩆭𩍵𩍲𩍷䫶𩙑𩟨𩟭𩟮𩦹𩦡𩦶𩪮𩯨䰒𩰞𩴱𩴲䲑𩼎𩼒𩼉𩼅䲐𩼃𩼟𩼍𩼌𩼄𩼊𪇂𪆿𪇉𪆲𪇁𪆯𪆴𪆰𪆶𪆷𪆻𪆱𪋰𪍸𪍺𪍹𪏤𪏦𪒟𪒝𪒘𪒙𪓹𪔰𪖂𪖁𪖳𪕿𪘺𪘾𪙈𡆆𡤶𡬓𡰢𡿝𢖧𢥽㩹𣌜𣡥𣡠𣡧㱎𣬚𤫣𥜴𥤘𥩒𥷼𥽹𥽸𥽶𦇺𦇸𦔭𦣖𦦽𦦿𦦾𧅄𧅰𧅢𧅮𧕮𧕼𧕴𧕸𧕰𧕹𧕱𧟌𧢧𧢦𧮓𧰡𧲜𨊝𨏴𨏳𨷰𩆸𩆶𩆽𩍳𩎂𩖇𩙖𩙙𩟶𩧉𩧅𩧇𩧊𩧂𩧄𩯲𩯰𩴻𩴺𩼦𩼨𩼴𩼭𪇗𪇒𪇖𪇜𪇓𪇑𪊆䴞𪋯𪋮𪏨𪒬𪒩𪒪𪒫𪓻𪔳𪖈𪖶𪖷𪗐𪗏𪙏𪙙𪙌𡬘𡿠𣡩𤅳𤅲𤫨𥗻𥤜𥸉𥸊𥸈𥽽𥽿𦉧𧅺𧅻𧟑𧢮𧮝𧲝𨇼𨇻𨇽𨏵𨏶𨰏𨰝𩇇𩇁𩆿𩉔𩍿𩏵𩖊𩖌𩙜𩡥𩧏𩧐𩪸𩯺𩱦𩽀𩽁鱲𪇷𪇳𪇯𪇴𪇭𪇲𪇱𪇹𪒴𪓼𪖼𪙞𪙛𪙟𪙝𪛏𪛎𡤺𡿡𢌕𢌔𤅸𤅹𤓦𤮸𤼢𥗾𦣛𦨆𦫈𧆉𧖆𧢭𧮙𧯘𧰣𧴣𧾵𨏹𨤇𨰞𨷻𨽲𨽳𩎇𩑉𩙝𩟸𩰃𩽎𩽍𩽐𩽕𪈉𪈇𪈌𪈃𪈆𪈋𪈊𪊉𪒻𪓿𪔶𪖋𪙦𪙩𪙧𪛂𪛒𡿢㠨𢦈𣍛𣡶𤅽𤖦𥍚𥸗𦣜𧆏𧖔𧖕𧾶𨈄𨟮𩎊𩏹𩰇𩰟𩰪𩵅𩽜𩽛𩽡𩽝𪈘𪈟𪎃𪐖𪐔𪒿𪓀𪙳𪛀𡔗𢦉𥤞𥤟𥩔𥸘𦈅𧆕𧖘𧖛𧥙𨈈𨐁𩇎𩏺𩪾𩵇𩽪𩽩𩽨𩽧𪈥𪈨𪓄𪔷𪖍𪖌䶑爨𥸢𥾂𧟙𨰸𩏻𩧡𩱲𩵈𩽬𩽰𪈱𪈰𪙹𥸣𦣸𩱴𩽳𪈴𪎇𪎲𪗂𪙽𡿥𩎌𩽷𩽵𪈸𪈺𪚃𪚁𤆀𧆙䖇𩑊𩵊𪈼𪛈𥘄𧢯𪚉𪋺𩙡𪈿𧆓𪓉𧟞𩎑𪚎𪚍𧆘𦧅𪚥\xac\x94\r\x16乁𠁢㐅𠂈𠃐𠃏𠄞𠄟𠄠𠤎𠤬𠁡𠀄𠀅𠀃㐄丸𠁽𠂍𠆣𠆤𠓛𠔀刃𠚣𠠲㔿𠫔𠫕𡰣𢌬𢍺𠔃𠀇𠀈𠂏𠂜𠃕𠄒𠄓𠄔𠄡𠆨𠆦𠑶𠔁𠔽內𠔾𠘩𠙷𠙸𠚦𠚩𠚬𠠴𠔄𠥻𠨐𠘯𠫗𠬜𠬟𠬚及𠬞𠮞𠮚𡆠𡆡𡗒𡗓𡦹𡬝𡭕𡳿𡿧㠪𢀑𢀓𢀒𢆯𢆱廾𢌭𢍻𢎝𢎚㢧𢎟𢎠𢎜𢒽㣺𢖪𣁬𣂑𣱴𤕪王𥘅𦉫𦉪𠃠𠀒㐀𠁿𠂠𠂙𠂕𠂞𠃟𠃞𠆯𠆭𠆰𠓟𠕃𠕁𠕈𠕵冬𠘲𠙻𠙹𠚳㓛𠮧𠚲𠚰𠚴包𠣍𠣎北𠤐𠦀𠧓𠨰𠂘𠨯𠨱𠫚𠫙𠬧𠬨𠬡𠬥𠮦𠮢𠮡𠮤叫𠮪𠮥𠮣𠁤𡈿𡉄𡔛𡕓𡖄𡖆𡗕𡚨𡤾𡥀𡯊𡯉𡰦𡴀𡴱𡴼𡴰𡴳𡴲𡴸𢀴𢁓𢁔𢆴𢆲𢌯𢎥𢎧𢎤𢎨𢎩𢎢𢎣𢎦𢒁𢒀𢖭𢨤𢩥𢩨𢩩𣄼𣄻𣍝𣥄𤆂𤓱𤕫𤜛𤣫𤮺𤯓𤴓𥤢𦫴𨈏𨈐𠔇𩰋𩰊𠀙𠀚𠀘𠁦𠂂𠂨𠃥𠃧𠃤𠄕𠄣𠄤𠅃𠇃𠆼𠆻㐸𠆷𠆹𠇏𠘺𠑺𠑻𠓡𠓠𠔉𠕍𠕋𠕋𠕹𠖱𠘶𠘵𠘷𠘸𠙿𠚀𠚁𦥓𠙾𠛍𠚿𠚾𠛂𠚸𠛁攰𠤱𠤯𠥯𠦂㔺𠦇𠦆𠦍𠦔𠧗𠧔𠧘𠨖𠨔𠨮𠨶𠨷𡿮𠫠𠫥𠫟𠬫𠬩𠬭𠬯𠮷𠯉𠯁𠯊𠮲𠮬𠮮𡆲𡆨𡆰𡆧壮𡉒𡉊𡕖𡕕𡕟𡕞𡖈多𡗝𡗢𡗡𡗟𡗠𡚯𡚱𡚺𡥅𡥃𡦽𡧈𡧈𡦾𡦿当𡭙𡰩𡰬𡰯𡰭𡰮𡴄𡴂𡴁𡵀𡵊𡴿𡵏𡵁𡵎𡿫𡿬𠂬𢁛𢁣𢁚𢁙𢇜𢇛𢇙𢇠𢇝𠀠𢎰𢎱𢎲𢎶𢑑𢑒𢑐𢒄㣉𢒃𢗃𢖸𢖹𢖲𢦐𢨦𢨩𢩮𣅀𣄾𣅁𣍞𣎻𣎼𣎹次𣢀㱏𣥅𣦺𣦽𣱖𣱕𣱵𣱷𣲁汎𣲃𣲋𣲄𤆆𤆃𤆋𤕭𤘖𤜨𤣪𤯔𤰃𤴕𤼽𤼾𥐖䂖𥘆𥝍𦈢𦉳𦉭𦉮𦒱𠕎𦘭䏍𦣹𦥒𦬀𦫼𦫺䒒𦫷𦫸芋衣𧰧𨈑𠂮𨑐𨑏𨑍𨙱邔𨙲𨸘𠩄𠀡𠁧𠂯𠂰𠀩𠄗𠄢𠄢𠀥𠄨𠇿𠇙𠇛𠇞𠇲𠇾𠇴𠇝㑅你𠈁𠇜𠑽𠒁𠑾𠑿𠓥𠔏㒵𠔊㒶𠔋𠕑𠕒𠕐𠖸𠘽𠘾𠘼𠚇𠛗𠛛𠛏𠛠𠡌𠡂𠣔𠤑𠤔𠤕𠤒𠤳𠤵㔷𠥱㔰𠦑𠦓𠦕𠧜𠩂𠩀𥎦𠫦𡊏𠬲叟𠬱𠯵𠯍𠯞𠯕𠯚𠯮𠯬𠯏𠯎吸𠯌𠯳𡇁𡇄𡇆𡆴𡇃𡆸𡆻𡆷𡆼𡆿𡇇𡆾𡉠𡉝𡉶𡉬𡉵𡉛𡉱𡉳𡉡𡉚𡉢𡉲𡉟𡉘𡔞𡕘𡖋𡖊𡗴𡗰𡛒𡛍𡛎𡚼𡛐𡥆𡥇㜾㝍㝑𡧎𡧏𡧌𡧋寿𡭠𡭟𡭝𡯔𡰺𡰸𡰼𡰶𡴈𡵾𡵞𡵮𡵓𡵰𡵗𡵝𡵣𡵺𡵹𡶓巡𢀝𢁨𢁳𢁴𢁦𢁯𢁵𢁺𢁰𢁭𢁥𢆊𢇭𢇩𢇣𢇯㡳𢇪𢌱𢌱𢌸𦬠𢌵𢌺𢎁𢎀𢎸𢏁𢎺𢒈𢓏𢓊𢓇𢓅𢓈𢓐𢓉𢖻𢖶𢖽忍𢖴𢗁𢗑𢗒𢗌𢗔𢗋𢗎𢗕𢗘𢦕𢦓𢦙𢦖㦾𢪉𢪔𢪑𢪏𢪄𢪗𢪆𢪅𢪌𢻲𢻭𢻯𣃙𣅆𣅇𣅋𣅐𣅊𣅎𣅌𣅈𣍣𣍡𣍢𣍠𣏃𣏅𣏁𣏐杓𣢂㰞𣢆𣢄𣥌𣥈𣦸𣦿𣧁𣧆𣧅𣧇𣧀𣧃𣫭𣬃𣬢𣬡𣲟𣲐𣲞㳈𣲠𣲔𣲕𣲡㳃㳇𤆚𤆐𤓲𤓳𤕮𤖫𤖩𤘚𤘘𤘛𤝠𤝌𤝂𤜳𤜿𤜾𤜸𤝃𤝆𤜶𤣮𤣵𤣴㽖𤰛㕀𤴫𤴩𤴪𤴮𥀿𥃦𥎧𥎨𥐘𥘈𥝐𥝓𥩖𥩕𦉯𦉿𦒳𦘺𦘸𦘷𦘶𠕔𣍟𦣝𦣼𦣺𦤳𦬄𦬇𦬈𦬆𦬉芝花𧈞豕𨐋𨑃𨑒𨑘𨑞𨙨𨚍𨚂𨙻𨱘䦽丽𠀬𠀮𡘋𠀭𠁩𠂲𠂶𠂩𠃭𠄙𠅏𠈖𠈃𠈎𠈄𠈇𠈐𠈧𠈨𠈍𠈩𠈏𠈢𠈑𠒅𠒄𠒃𠒊𠒆𠔐𠕘𠕚𠜌𠗄𠗅𠙇𠙉𠙊𠙋𢆻𠚍𠚋𠜇𠜆刻𠛯𠛰𠛭𠛹𠜂𠛧𠛴𠛷𠛱𠡓𠡕㔚𠣚𠣙㔬𠤸𠦙𠦢𠦜𠦚𠦛𠧥𠧣𠧡𠧠𠧢𠧤\xaf\xe3H\xfc𠧨即𠨓𠩖𠩋𠩊𠩍𠩌㕉𠫪𠫩𠫫𠫬𠬺𠬼𠬹𠬻𠰔𠰎𠰵𠰊𠰿𠰸㕼𠱎𠰗周𠰞𡆶𡇎𡇊𡇋𡇌𡇉𡊴𡊬𡊈𡊆𡊌𡊓𡊊𡊤𡊇𡊥𡊦𡊅𡊣𡊎𡔟𡕣𡖍𡗺𡗻𡘉𡗾𡗿𡘂𡘄𡗽𡘅𡛹𡛷𡛛𡛚𡥑𡥐𡥗𡧢𡧘𡧚𡧜𡬠𡬟𡭩𡯝𡱀𡱁𡱃𡴍𡴌𡶇𡶩𡶒𡶅㞿𡶌𡶢𡶏𡶊㞺𡶍𡿱𢀷𢂋𢂇𢂂𢆎𢆼𢇸𢇺𢇫𢇹𢇼𢈅𢌛𢌝𢌞𢌱𢌼𢌾𢎄𢏈𢏉𢏓𢏇𢏒𢑕㣇𢒌𢒊𢓕𢓓㣘𢓘𢗐𢗮𢗱𢗻𢗓𢗬𢗍𢘕𢘦𢘎𢘧𢘪𢘀𢘄𢘨𢘈𢘔𢦛㦲𢦡𢦝𢦠𢦞𢦢𢦜𢨲𢨴𢪓𢪖𢪐𢪴𢪵拔𢪶𢫂抱𢪺𢪒𢻻𢼅𢻳𢻾𢻽𤕝𢻺𣁃𣂕𣂔㫄𣅯𣅳𣅜𣅛𣅦𣅴𣅣𣅧𣅠𣅢𣅱𣅲𣅞𣌣𣌦𣍤肭𣏲𣐁𣏝𣏜𣏛𣏳𣏽𣏘𣏸𣏻𣢃𣢌𣢎𣥉𣥔𣥒𣥎𣥘𣥑𣥕𣥓𣥗𣥐𣥏𣥍𣧒𣧓𣧉𣧔𣫯𡴋𠂱𣬅𣬄𣬮𣬭𣱅𣱛𣱚𣱙𣲝𣲎𣲼𣳝𣲺泍𣳘𣳋𣳙𣳚𣳄𣲸𣲿𣳛𣳁沿𣳅灷𤆱𤆯災𤓷𤓶𤓸𤕐𤕑𤕱𤕰𤖰𤖯𤖮𤖭𤘥𤘬𤘢𤘳𤘴㸫𤜺𤝀𤝨㹡𤝤𤝜𤝗𤝓𤝏𤝑𤝔𤝦狝㺲𤤍𤤈玥𤣺𤫪𤬪𤮻𤯕𤯗𡶤𠃰𠂵𤰆𤰨𤰢𤰡𢑖𤰣𡇍𤴰𤴴𤿉𤿊𤿈𥁄𥁄𥃳𥃳𥃸𥃼𥃶𥃻𥃷𥄀𥄂直𥐝𥐤𥘍䄩𥝢𥤰𥤫𥤪䆒𥤭𥫚𥸨𥾆𦉷𦉼𦍐𦍎𦏲𦒲𦓎𦓏𦓥𣐇𦔵𦔱𦘓𦘫𦘬𦙅𦙠𦙁𦙘𦙇𦙛𦙐育𦙟𦙊𦙖𦙃𦙄𦙑𦣽𦤴𦤵𦥔𦥕𦨌𦨋𦨊𦫹𦬵𦬤𦬓𦬜𦭩𦬥𦬡芽苦若𧈠𧖩𧖨𧗞𧘉𧘊𧘋𧟣𧠇𧢲𧢳𧥝𧹘𧺆𧺇𧾹𧾸𨐌𨑄𨑦𨒀𨑵𨑭𨑾𨑧𨒁𨑷𨚨𨚢𨚥𨚛𨱗𨸏𨸲𨸬𨸳𩇧𠁮𠂷𠃶𠃲𠃳𠔗𠄰𠅔𠅓𠉒𠈽𠈼𠈴𠈳𠉔𠈯㑘𠉕𠈿𠈷𠉖𠉡𠉘侻𠈻𠈲𠈮𠈾𠀻𠒌𠒍𠔓𠔖𠔒𠕛𠖊𠗗𠗎𠙐𠙑𠙏𠚎𠚏𠛲剆𠜨𠜚𠜥𠜝𠜔𠜠𠜟𠜛𠜗𠜕𠜜𠜓勇𠡠㔜𠡦𠣣𠣞𠣠𠣡𠤚𠤛𠤘𠤜𠤙𠥂𠥀𠤾𠥄𠥴𠥃卑𠧲𠨛𠨙𠨞卽𠩞𠩦𠩝𠩚𠩟𠩠𠩛𠩜𠩙𠫰𠫱𠭃𠭀𠭂𠭁𠬿𠱗𠱩𠱯𠲫𠱱𠲓𠱖𠲐𠱭𠱬𠱰𠱦𠱧𡇗𡆵𡇛𡇘𡇕𡇔𡊺𡊷𡊾𡊹㘷𡋠𡋝𡋞𤤘𡋘𡊽𡋛𡔝𡔥𡜡𡜌𡜩𡜭𡜛𡜎𡜟𡜄𡜜𡜅𡜆𡜠𡜍𡜪𡜣𡜑𡥜𡥦𡥛𡥏𡥞𡥝𡧱𡧪𡧴𡧫𡧰𡧺𡧻𡬨𡬬𡭫𡬪𡭪𡭯𡯛𡯠𤿑𡯣𡯥𡯧𡱍𡱕𡱔𡱓𡱒𡱎𡱠𢇀𡴏𡴑𡴓𡷆𡶳𡷇𡷒𡶸𡶬𡶮𡷏𡷅𡷤𡷌𡶫𡷉𡶹𡿻𡿸𡿷𡿹𢀠𢀻𢀹𢂞𢂚𢂪𢂫𢂯𢂖𢂩𢂦𢂑㡃𢂮𢂟𢂬𢂠𢆒𢆑𢆿𢈗𢈊𢈌𢍊𢍆𢌴𢍅𢍃𢍄舁𢎇𢎆𢎊𢏚𢏘㢴𠔘𠄴𢏖𢏔𢑔𢑙𢑚𢑗㣠𢓝𢓩𢓠𢘅𢘒𢘓𢘇𢘖𢘣𢘂㤺𢙊𢘶𢙀𢙖𢙃悁𢙋𢙆𢘾𢙉𢦧𢦶𢦦𢦨𢦬𢦩𢦫𢨸𢨷𢨹𢩁𢨾𢫀𢪻𢪿𢪽𢫾𢬞𢫮𢫼捐𢫹𢬆𢫤𢫩𢫥𢬃𢫽𢭉𢺾𢼊𥘦𢼘𢼍𢼏𢼎𢼠𣁱𣂙𣃢𣃟𣃞𣅸𣅹㫤𣅼𣅽𣆄𣆋𣆊冒𣆉𣌬𣌩𣍬栄𣐉𣐈𣐌𣐍𣐐㰦𣢟𣢘𣢢𣢚𣧕𣧢𣧰𣧪𣧫𣧭𣧨𣧠𣪅𣪆𣫰𡹆𣭅𣬸𣬾𣭂𣭀𣱇𣱜𣱞𣲴𣳂𣴔𣳥𣴕𣴑洖𣳮𣳧𣳵𣳴𣳨𣴁𣳯𣳱派𤇛𤆿𤇚𤇇𤇝炭𤇫𤇡𤇁𤆽𤔇𤓽𤔁𤔊𤔃𤕲𤕴𤕵𤖲𤖱𤖴𤙌𤙂𤙀𤙉𤘼𤙍𤙊𤘻𤝡𤝴𤜵𤝿𤝺𤞈𤝼㹯𤝰𤤏𤤨𤤓𤤪𤫭𤫯𤬮𤯁𤯝𠭇𤰭𤰫𤰵𤰯𤰱𤰴𤰶𤰰𤴺𤴹𤴷𤵋𤵔𤵍𤵑𤵇𤼧𤽐𤽍𤽎𤽔𤿒𤿎𥁋𥁉𥄅𥄏𥄙𥄙𥄆𥄌𥄩𥄄𥄈𥄥䀜𥄃𥄧𥄐𥄊𥃲𦭝𥎪𥎫𥎩𥑁𥐺𥐼𥐫𥑉𥘕䄀𥘖祖𥘱𣱊𥜼𥝼𥝭𥝪𥝣𥝫𥝻𥝤𥝺𥝩𥝯𥤷𥤵𥤼𥤶𥤽𥤾𥫡𥫠𥸭𥸫𥾍𥾒䊶𥾎𥾆𥾑𥾖𦉻𦉸𦊁𦊖𦊣𦊕𦊗𦍔𦍒𦏵𦐀𦏻𦏴𦏶𦓨𦓪𦘾𦘽𦘻𦚀䏤𦚈𦚁𦚂𦛎𦚃𦙷𦙹𦙳𦙭𦙰𦚍𦙵𦚌𦙪𦚑𦚉𦚎𡋜𦣿𦥙𦧇𦨒𦨔𦨓𦫋𦭂䒭𦬽苿𦭀𦭄𦭢𦭃𦬿𦭅𦬼𦬼𦭆𦭇虐𧆞𧆝𧈬𧈩𧈯𧈮𧈰𧗡𧗢𧘎𧘙𧘒𧢴𧯚𧴦𧹙𧹚𧺈𧿀𧾺𨊠𠣞𨊢𨒆迬𨒝𨒈𨒏𨒎𨒛𨛁𨛃𨛄𨚖𨛇𨛆𨚿䣃𨤏𨹕𨹂𨹆𨹋𨹃𨹓𨹈𨹉𨹊𨹎𤯞𠚑𩑋𩚀𩚁𩚃𠊊𩚂𢍂𠤢𠁰𠁱𠂼𡴘𠂾𠂺𠃸𠄷𠄵𠫼𠅖𠅙𠅞𠉭𠊑𠊒𠉨𠉦𠉳㑧𠊖𠊋𠊌𠒖𠓮𠔜𠔚𠔙𠔛𠕝𠕞冤𠗜𠗢𠙒𠚒𠜰𠝒𠝂𠝁𠝅𠜷𠝀𠜿𠜽𠜸𠜺𠝉𠡫𠡸𠡹勉𠡬𠣩𠣦𠣥𠣪𠣧𠣨𠤟𠥋𠥊𠥓𠥶𠦣𠦤𠧶𠧳𠧹𠧷𠧼𠨝𠩭𠩱𠩬𠩮𠩰𠩯𠩧𠩨𠫺𠫹𠭎叞𠭍𠭏𠭊𠭌㖖唐𠳮𠲲𠳀𠳁𠳴𠳈𠳅𠳆𠲭𡇢𡇪𡇣𡇡𡋷𡌁𡌒𡋶𡋱𡌟𡋮城𡋳𡌕𡌖𡌗𡋸𡌙𡋲𡋴𡋵𡌓𡌆𡔦𡔤𡕚𢻈𡖗𡖕𡖚𡘟𠫻𡘩𡘠姬娛𡝌𡜰𡜸𡜽𡝑𡝏𡝊𡜴𡜶娰𡥪𡥨𡨜𡨎𡨞㝙𡨉𡨈𡨓𡨁𡨏𡨔𡨆𡨇宲将㝶𡭹𡯪𡯭𡯩𡯮𡱨𡱤𡱧𡱲𡱢𡱴𡱹𡴙𡴗𡴕𡴖𡷦𦊤𡷮㟒𡷟𡷤𡷚𡷪𡷭𡷣𡷩𡿽𠙗𢀡𢃆𢂸𢃋帨𢂽𢂵𢂻𢂿𢂾𢂲𢃁𢃂𢆙𢇆𢈬𢈭𢈥𢈞𢈟𢈣𢍐𢍎𢍍𢍒𢏩𢏬𢏨𢑘𢑜𢑛𢒏𣥲𢓶𢔀𢓼𢓸𢓪𢓾𢓷𢓽𢙰𢙢𢙄㤠𢙍𢙌𢘿𢙦𢘵㤩𢘴𢚍𢚄㤸𢚆𢚉𢚇𢚃𢙵𢙹𢚯𢚰𢦷𢦻𢦹𢩆𢩇𢩅𢫪𢫶𢭨𢬹挽𢬽𢬻𢭍𢬰𢭊𢬾𢭀𢭌𢭇𢬸𢭒𢬺𢭓𢻄𢻁𢼜𢼝𢼪𢼞𢼯𢼢𢼮𢼥㪉㪰𣁊𣁋𣁶㪿𣃨𣃯𣃩㫅𣃪書㫪𣆛𣆞𣆓𣆧𣆜𣆠𣆚𣆫𣆾𣆩𣌭𣍮𣑄𣐽𣐺𣑨𣐼𣑪枅𣐸𣑥𣐹𣑭𣐶𣑭𣐾𣑾𣐷𣢙𣢩𣢭𣢧𣢨𣥦𣥩𣥭𣥧𣥫𣥪𣧮𣨁𣨃𣧴𣧻㰷𣧶𣧼𣫴𣬈𣬊𣬉𣭭𣭢𣭁𣭞𣭜𣳳𣴦㳤𣴹浸𣵬𣴫𣴮𣴻𣵞𣷻𣴧𣴤𣵩𣴰涅𣵢𣴸𣴵𣴪𣴬𣵨𣴱𣴯𣴭𣴺𣴶浩𣴡𣴼𣴠𣵤𣴾㳨𤇷𤈒𤇱𤇵𤈢𤇲𤇟𤈕𤈖𤕺𤕶𤖾㸠𤖽𤘉𤘈𤙗𤞣𤞿𤞸𤞯𤞜𤞥𤝲𤝵𤞝𤞙𤞹𤥎𤤹𤤻𤤵𤤼𤥕𤫰𤫳𤫴𤬻𤬼𤬺𤬶𤰇𠳫𤱕𤱐𤱏𤱒𤱖𠂽𤰲𤵣𤵫𤵦𤵗𤵝㾆𤵭𤵡𤵩𤵚𤵜𤵢𤼪𤼩𤽘𤽚𤿗𤿛𤿜𤿝𤿘𥁔𥁛𥁜𥁓𥁏𥁖𥁐𥁚𥁫𥄶𥅓𥄺𥄼𥄾𥅉𥅀真真𥍤𥍥𥎵𥎶𥎲𥑈𥑊𥑲𥑩𥑚𥑌𥘬𥘰𥙋𥙉𥙂𥙄𥙊𥙏𥞒秫𥞗𥞀𥞂𥞆𥥓𥥞𥥒𥥛𥥎𥩭𥩮𥫺𥫶𥫻𥸳𥸽𥸷𥸾𥸵𥾚𥾫𥾦𥾰𥾙𥾠𥾞𥾨𥾡𥾪𥾭𥾻𥾜𥾥𠂳𦈧𦊍𦊎𦊏𦊒䍖𦊑𦊭𦍗𦍘𦍞𦍝𦍜𦍛䍧𦐊𦐃𦐎𦒸𦕍𦔿𦕇𦕋𦕊𦔽耸𦕁𦕌肁𦙢𦙩𦙡䏑𦚪𦚦𦚬𦚽𦚻𦚤𦚺𦚚䏫𦚡𦛁𦣠𦤊𦤃𦤆𦤹𦤺𦤶𦥛𦥟𦥞𦥧𦥡𦥢𦥝𦧊𦧌𦧎𦨘𦨝𦨟𦨠𦨗𦨕𦬟𦬧𦬦𡸐𦮋𦮮𦮊茝𦮒𦮉𦮇䒶𦮏𦮄𦮃𦮌𦮓𦮍𦮘𦭽𦭾𦯒𦮀𦮙𧆬𧉐𧉕𧉂𧈿𧈼䖣𧉌𧉉𧉗𧉘𧉏𧉜𧉇蚩𧖫䘐𧖯𧖭𧗨𧗧𧘰𧘦𧘯𧘨𧘫𧘝𧘭𧘠𧘪𧘬𧘵𧘡𧘣𧘤𧘶𧘷𧟩𧢺𧢸𧥦𧥭𧥪𧥨𧥩𧥫𧥡𧯜𧯛𧰭𧰬𧲢𧴫𧴲起𧺔𧺒𧺐𧿉𧿄𧿁𧿃𧿍𨈔𨊧𨊯𨊪𨐍𨑔𨑛𨑡𨒮𨒳𨓀𨓄𨒿𨓋𨒭𨒧𨒤𨒽𨒯𨒲𨒥𨛧𨛠𨛎𨛞𨛕𨛟𨛙𨟲𨤣𨥀䤛𨤾䦇𨳏𠭔𨹛𨹘𨹢𨹟𨹧𨹣𨹞𨹠𨾅𨾆𨾇𩇨𩇦𩙱𩚅飢𩚆𩠐𠧸𠂿𠭡𠃾𠃼𠄹𠅡𠅠𠋢𠋪𠋣𠊴𠊮𠊾𠊸𠊨𠊪𠊩𠊳𠋕𠊭𠊹𠊻𠊱𠋂偺𠋫𠊺𠋒𠋔𠓱𠔝𠔜𠕢𠖌𠗦𠗧𠙚𠙘𠙙𠚖𠚗𠝡𠝧𠝱𠝥𠝽𠝾𠡽𠢄𠡼𠡾𠡿𠢀𠣯𠣴𠤞𠥎𠧻𠧺𠧽卿卿𠩹𠩼𠩽𠪄𠩻𠩸㕗𠭞𠭚𠭜𠭙𠭕𠭣𠭖𠭟𠭠𠭛𠵊𠵥𠶳𠴬𠵷𠴽𠵒𠵡𠴪𠴾𠴴𠴩𠶲𠶭𠵀𠵓啣𠴿𠵁𠵲𡇶𡇵𡇴𡇲𡇹𡌧𡍔𡍕𡌮堍埴𡌬𡌸𡌹𡌱𡍏𡍳𡌰𡌫𡍑𡔬𡕛𡕜𡕨𡕬𡕫𡕩𡖟𡘸𡘼𡘺𡘵㚝𡘹𡘶𡘻𡝠𡝡𡝣𡝤𡞔𡞓𡝞𡝨𡝪𡝥婦𡞐𡝟𡝲姘𡨼𡨧𡨤𡨦𡨥㝟𡨨𡨶𡬱𡬰𡬴𡮁𡭽𡮂𡮀㝹𠋓𡯲𡱦𡲌𡱻𡲃㞘𡲎𡱺𡲒𡲑𡲂𡴛𡴜𡸧𡸡㟜崫𡹃㟟𡸘𡹻𡸚𡹋𡹊𡹍𡹏𡸙𡸵𡸓𡹒𡸔𡸞𡸬𡸠𡸢𡸒𡸟𢀀𢀣𢁀𢁃𢃤𢃛𢃪𢃝𢃌𢃙𢃨𢃶𢃣𢃓𢃚𢃖𢃧𢈺𢈿𢉀𢉈𢉙𢈷𢉄庳庰𢉕𢈹𢍔𢍕𢍖𢍗𢍘𢍙𢎍𢏲𢏸𢏵𢏶𢏼𢏾𢏱𢏽𢑞𢒖𢒗𢒜𢒛𢒕彫𢔚𢔉𢔄𢔎𢔜𢔘𢔍𢔐𢔌𢔃𢙻𢚦𢙷𢚈𢙴𢛚𢙶𢙸𢚊𢚏𢙽𢚐𢛭𢛙𢛐𢜚𢛗𢛢𢛡𢛅𢛠𢛊𢜥𢛌戛𢧆𢧁𢩍𢭋𢭤𢮕𢮙掃𢮔𢮢𢮮𢮞𢮧𢮆㧽𢮤𢮑𢮓捨𢮈𢻍𢻊𢼿𢽀𢽆𢽁𢽅𢽘𢼻𢽈𣁎𣁒𣁏𣁻𣁺𣂠𣂟𣃻𣃷𣆸𣆹𣆻𣆷㫴𣆺㫯𣇚𣆵𣆿𣆼𣇀冕望𣍵𣍹𣍶𣐵𣑀𣓀𣒁𣒭𣒯𣒬𣒏𣒨𣒪𣒩𣒈𣑿𣒀𣒼𣢮𣢻𣢽㰱𣥸𣥴𣨌𣨑𣨈𣨆𣨐𣪏𣪐𣪍𣪎𣪖𣪕𣬋𣬌𣭼𣭳𣭵𣭶𣭽𣭸𣭱𣱋𣱎𣴳𣴲㴃𣶑𣷧𣷩𣶢𣶔𣶇𣶣𣷝𣵻𣶃𣶁𣷟𣵼𣷤𣷢𣶓𣵽𣶿𣶛𣶎𣶟㴉𣶌𣵿𣶜𣶄𣵂𣷦𤉑𤉊𤉤𤈥𤈯𤈮𤈫𤈨𤈲𤈭𤈱𤈹𤈬𤉡𤔑𤔐𤔒𤕓𤕕𤕻𤖁𤕽𤕼𤗂𤗅牐𤗄𤘈𤙫𤙨㸾𤙜𤙝𤙪犀𤱶𤝶㹷𤟄𤟋𤟈𤟔𤟕𤟉𤟗㹻𤥸𤥚𤥛𤥜𤥖𤥘𤥱𦧔𤭀㼛𤬿𤭃𤭁𤯢𤯠𤯣𤱦𤱳𤱬𤱱𤱪𤱭𤱮𤱫𤶌𤵷瘐𤵿𤶉𤵺𤶅𤵸𤶊𤶆𤼬𤽧𤽛𤿥𤿡𥁡𥁩𥁪𥅥𥅭𥅣𥆆𥅜𥅢睊𥅹𥅩𥅰𥅶𥅝𥆇𥅮𥅱𥅼𥅳𥅛𥅫𥎿𥏁𥎽𥏉𥎼𥏂𥏀𥒒𥒩𥒏𥑶𥑻𥑵𥒤𥙤𥜽𥞯䄺䄯𥞝𥞙𥞱𥥣𥥢𥥤𥥩䆠𥩵𥩳𥩱𥩴𥬌𥬖䇥𥬑𥬟𥬚𥬍𥬛𥬓𥹎𥹕𥹐䉽𥿒𥿓𥿑𥿱𥿜𥿈𥿌𥿐𥿏𥿟𦈫𦈮𦈭𦊝𦊞𦊟𦊩𦊠𦊘𦊡𦊫𦊯𦍮𦍟𦍚䍭𦍥𦍨𦍲𦐗𦐖𦐘𦐙𦐚𦐢𦐛𦐜𦒾𦓮𦕚𦕐𦕘𦕔𦕗𦕖𦘕𦚛𦚓𦚒𦙲𦚘𦚙𦛟䏸𦛕腘𦛔𦛳𦛴𦛧𦛡𦛦𦛫𦛬𠋛䑐𦤍𦤌𡬯𦥨𦥥𦥫𦥩𦧑𦧓𦨧𦨨䑨䑧𦨤𦫗𦯊𦯇菧𦯘𦯡𦯆𦯸𦯃𦯴𦰗𦯪𦯋𦮆𦯄𦯙𦰙𦯂𦯢䓏𦯞𦯭茣䓍𦰝𦯝𦯛𦯨𦯩𦮿𦯠𦯑𦯜𦯎𦰞𦯌𦯲𦯳著菌菜菊𧆫𧆪𧆧𧆩𧊈𧉵𧉠𧉬𧉳䖫𧉮𧉴𧉶𧉨𧉷𧊆𧗪𧙑𧙐𧙘𧙍𧙏𧙎𧙜𧙒𧙄𧙊𧙋𧟪𧠐𧠑𧣍𧢿𧣄𧣐𧣎𧣀𧣂𧣏𧣉𧢻𧦗𧦒𧥯𧥾𧦚䛁𧦆𧥳訤𧥷𧦏𧥰𧦁𧦂𧦙𧥽𧥿𧥻𧦇𧮲𧰯𧰴𧰮𧰲䝇𧲪䝗𧲩𧴸𧴶𧴷𧵇𧴳𧴹𧴵𧵄𧺥𧺦𧺞𧿣𧿟𧿓𧿱𧿤𧿠𧿖跃𧿦𧿘𧿞𧿛𧿜𨈛𨈟𨈝𨈜𨈞𨈠𨈡䡎𨊶𨊼𨋆𨋇𨊺𨊾𨊽𨊵𨊴𨐏𨑢𨓫𨓙𨓤𨓗𨓘𨓖𨓎𨓝𨓧𨓒𨓅𨓉𨓛𨚒𨚊𨚋𨚉𨜈𨜃𨜆𨜅𨛵𨜋𨛻𨟼𨠁𨠂𨟺𨠈𨟷𨠀𨟿𨠆𨟶𨟻𨠊𨤥𨥃𨥄𨱤𨱢𨱞𨱛𨳖𠁲𠃂𨹷𨺂𨹼𨹽𨺆𨺁𨺄𨹶𨺃𨹿𨹾𨾈𨾍𨾋𨾑𨾏𩁼𩁹𩁺𩇪𩑌𩖙𠋑𩚋𩚍𩚊𩚎𩠑𢉖麻𠁴𠃃𠃄𠄛𠅟𠅪𠅩𠅫𠌌𠋶𠋭𠌩𠋾𠋱𠌗𠋿𠋮𠋽𠌜𠋼𠌀𠌠㑷𠋹𠍧𠌈𠒞𠓳𠔥𠔧𠔟𠕥𠕣𠖔𠖗𠗱𠙟𠙢凲𠙠割𠞌𠞂𠞐𠞋𠞍𠞁𠞝𠢎𠢏𠢒𠤣𠥒博𠨀𠨁𠨂𠨟𠪌𠪉𠪋𠪈𠪍𠪏𦎅𠬀𠬅𠬁𠭨𠭮𠭧𠭵営善𠷗善𠁈𠷔𠷘喙喫𠶺喳𠷛𠶿𠸵𠶼𠷙㗄𠷝𠸀𠷕𠸰𠶽𠷜𡇽𡇾𡈇𤔗𡍯𡍩𡍰𡍧𡎩𡎕𡎐𡍬𡎗𡍱㙒𡍴𡍭𡎎𡔯𪌛𡖩𡙎奢𡙚𡙈𡙕𡙌𡙉𡙏𡙊𡟖𡞠𡞩㛮𡞝𡟔𡞤𡞨𡞦𡟦𡥽𡥷𡥺𡩁𡩉𡩙𡩅𡩈𡩀𡮎𡮕𡮐𡯷𡯸𡯶𡯻𡲞𡲝𡲙屠𡲣𡲩𡲘𡲠𡲖𡲔𡲕𡲟𡲡𡴡𡴝𡴟𡹭嵃𡺚𡺒𡹺𡹽𡺡𡺝𡺙𡹫𡹮𡷦𢁅巽𢃻𢃫𢃲𢃹帽𢃸𢃭𢄁𢃷𢄆𢆛𢇊㡫廃𢉫𢉬𢉴𢉧𢉛𢉠𢉶𢉣𢉡𢌨𢍛𢍞𢍠𢍝𢍜𢏴𢐀𢐈𢐇𢐁𢐅𢑤𢑣𢒞𢒠𢒡𢒟𢔦𢔭𢔨𢔝𢛬𢛣𢛫𢜖𢛈𦘛𢛩𢛤𢜤𢛑㥁𢛝𢛂𢛋𢛇𢛧㥐𢛪𢝋惇𢝠𢝽𢜾𢛔𢝒𢝎𢜼𢜲𢜰𢜣𢧊𢧋𢧉𢧈𢧍𢮘𢮠𢮟𢮜𢮣𢮗𢯱𢯱𢰉𢰃𢯪𢱅𢯭𢰅𢰚𢰕𢰁𢯬𢰎𢰗𢱂𢰒𢰐𢯯𢯿𢰀𢰌𢰏𢰄𢰈𢰔𢱃揤𢰛𢰑𢰓𢻏𢻎𢽯𢽮𢽺𢽰𢽤𢽞𢽬敬𢽛𢽡𢽥𣁿𣂀𣁼𣂧𣂦𣂡㫀𣂨𣂣𣂢𣂭𣂥𣄁𣃿𣃼𣄀𣄵𣇮𣇯𣇶𣇨𣇪𣇣𣈟㫼𣇤𣇺𣇱𣇻最𣌽𣌼㑹𣍸𣍷䐋𣓑𣓇𣓟𣓐𣓠𣓖𣓘𣔖𣔎𣓙𣔏𣔑𣓍𣒉𣓎𣔔𣓚𣔒𣓓𣓂𣓡𣓩𣓪𣓞𣔕𣣙𣣖𣣍𣣐𣣓𣢧𣦂𣦅𣥿𣥽𣦁𣦃𣦄𨒠𣨨𣨏𣨗𣨚𣨥𣪍𣪛𣪙𣮒𣮌𣮕𣮍𣮣𣮓𣮑𣷛𣶙𣶚𣸈𣹌𣸍𣸁𣹎𣸂𣹍𣸡𣷼湮𣶊港𣶕𣸑𣸒𣹆𣸜𣹉𣸌㴞𣸓𣸖𣷽𣸏𣸟𤈰𤉮𤉫𤊛𤉻𤉣𤊗𤉭𤊜𤊘𤉯𤉧𤉩𤔕𤕣𤖆𤖊𤖇𤖈𤖅𤖉𤗊𤗇𤗋𤗌𤗉𤘍𤚃𤚄𤙸𤚂𤙳𤙹𤚌𤟐𤟏𤟒𤟙𤟌𤟫𤟽𤟬𤟨𤟸𤟺𤠔㺅𤟡𤟻𤣧瑇𤦥𤦑𤦦𤥼𤦒𤦢𤦠𤫼𤫾𤭗𤭓𤭘𤯧𤲎異𤲃𤲋𤲄㾗𤶢𤶷𤶩𤶥𤶟𤶪𤶗𤶡𤶞𤶫𤶙𤶣𤼰𤼲𤼯𤽰𤽬𤽯𤿨𤿮𤿪𥁭𥁶𥁰𥁱𥁬𥇫𥆵𥆖𥆐𥆍䀹𥆢𥆰𥆒𥆠𥆓𥇃𥆎𥆶𥆝𥆕𥆳𥆤𥍰𥏔䂓𥏏𠅰𥒔硡𥓀𥒴𥓁䂲𥒽𥒯𥙷𥙸𥙫𥙭𥙮𥙲𥙯𥙱𣂫𥚌𥜿𥞶𥞷稆𥞻𥞸𠞜𥞹稁𥦋𥥺𥦄𥦈𥦑𥥹𥦎𥦀𥦂𥥼䆤𥪄𥩿䇲𥬻𥭱𥬽𥬰䇱𥬾𥬸𥹞𥹤䊁𥹝𥹭𥹏𥹢𥹟𥿬𥿲𥿴𥿷𥿶𦀇𥿭𥿪𥿰𥿸𥿳䋜𦈨𦈱𦈯𦊶𦊮𦊪𦊿𦋌𦋇𦲃𦎀𦍻𦍿𦍽𦎃羕𦑆𦐭𦐥𦐦𦐬𦐷𦐱𦐳𦐲𦐧𦐪𦐮𦐯𦓀𦕱𦕡𦕢𦕦𦘘𦛅𦛃𦛄𦛂𦚮𦜐𦜈𦜟𦜺𦜻𦜶𦜌𦜆𦜪𦝗𦝐𦜠脾𦜬𦜡𦜋𦜙𦜵𦜘𦜏𦜓𦝕𦜹𦜚䐌𦜗𦣤𦣩𤖋𦤐𦤒𦤿𦥮𤔘舄𦧖𦧕𦧗𠬂䑭𦨱𦨰𦨺𦮅𦭫𦱈𦱋𦰷𦱻𦱬𦲅𦱥𦰶𦲆𦰴𦱨𦱚𦱘𦱲𦱪𦯓𦰼𦰳𦰻𦱍𦱏𦱼𦲰𦱛𦱕𦱤𦱫𦱓䓦𦱗𦱞𦱭𦰵𦱩𦱙𦱦𦱐𦱑𦱜𦵝𦲇𦱸𦱉𦱎𦯱𦱳𦲈𦯏𦰶𧆱𧆳𧊜𧊤𧊐𧊸𧊠𧊝𧊨𧊩蜎𧊱𧊓𧊛𧊢𧊡𧊰𧊧𧖳衉𧖴𧗱𧗭𧗬𧙨𧙵𧙪𧙢𧙧𧙠𧙷𧙟𧙹𧠣𧠡𧠜覙𧠟𧠞𧣖𧣓𧣙𧣗𧣔𧣜𧣘𧦣䛑𧦾𧦩𧦡𧦠𧦫𧦢𧦺𧧂𧦜𧦻𧦪𧦬𧦥𧧁䜬𧯢𧰶𧰽𧰺𢑡𧱈𧰼𧲷𧲬𧲳𧲶𧲲䝮𧵝𧵉𧵎𧵏𧵍𧵜𧹝𧺻𧻎𧺿𧺺𧻃䟥𨀆𧿸𧿾𨀓𧿶䟦𨈴𨈭𨈪𨈵𨈬𨋔𨋚𨋓𨋒𨋎𨋏䢟𨓽𨔋𨓾𨓸𨓷𨔗𨓺𨓹𨔀𨓵𨓱𨔃𨔅𨔜𨚬𨚚𨚭𨚠𨜛𨜥𨜤𨜦𨜧𨜨𨜪𨜚𨜮𨜕𨠕𨠔𨠗𨠘𨠏𨠓𨠋𨠢𡍺𨤦鈟𨥑𨥍𨥎𨥊𨥋𨥌𨥐𨥢𨥏𨥒𨱨𨱩𨳥𨳰𨳡𨳢䧦𨺥𨺩𨺤𨺯𨺬𨺮𨺪𨾕𨾙𨾓𨾝𨾘𨾜𨾗䨍𩂉𩂇𩇗𩇭𩇱𩇰𩈅𩈃𩈄𩉟𩑗𩖜𩖡𩖚𩚏𩚓𩚞𩚣𩚘𩚙𩚕𩡮𩡪𩫴𩫷𩱹𩾏𩾑𪀋𪔂𠂄𠄄𠆀𠌯𠌿𠌻𠍢𠍄𠌳𠍟𠌫備𠌹𠌶𠍈𠌵𠍬𠍘𠌺𠒨𠓴𠔩𠖚𠘀𠞻𠞽𠞷𠞲㓸𠞨𠞫𠞦𠞼𠢗𠢖𠣸匓𠥑㔳𠦵𠦴𠦶㔼𠦷𠨅𠨣𠪕𠪘㕏𠪔𠪓𠬇𠭳𠭱𠭲𠭸𠹟𠹪𠹧𠹫𠹩𠺎𠹉𠺀㗜𠹄𠺏𠹅嘆𠹏𠹣𠸶𠹈𠹜𠺓𠹡𠹠𠹎𠹞𠸿𠺤嗠𠹨𡈋𡈑𡈍𡈎𡏂報𡎰𡏃𡏈𡍥𡏗𡎽𡎸𡎶𡏇𡎼𡎱𡎯壷𡔵𡔰𡕝𠭶𡕯𡖰夢𡖴𡙜𡙝𡠈𡟮𡠋𡟲𡟽𡟱𡠐㛼𡠔𡦋𡦌𡦅𡩺𡩬𡩠𡩧𡩦𡩪𡩟𡩭𡩨𡩤𡬺𡬻𡬹𡰂𡰄𡰃𡲴𡲳𡲻𡲬𡲶𡲱𡲯𡲵𡲾𡲲𡴤𡺶𡺹𡺬𡺰𡻗𡺻𡻾𡻋嵫㟵𡺳𡺾𡻍𡺼𡺺𡻇𢄖𢄛𢄗𢄕𢆟廊𢊂𢊃𢊎𢉪𢊉𢊔𢊆𢊐𢍢𢎐𢐌𢐏㣃𢒥𢒧𢒦𢒤𢔵𢔸𢔶𢕃𢕇𢔷𢝃𢞢𢝑𢝲𢝭𢜯𢝔𢝮𢝕𢜷𢝏𢝐愹𢞞慎𢞳𢞧㥱𢟹𢞑慌𢞶𢟝𢞙𢧟𢧕𤟵𢰨𢱀𢱱𢲘𢲕𢱥𢱸𢱿𢱠𢱼𢲖𢱪𢱹𢱳𢱲𢱻𢱰𢻕𢻗𢾏𢾉𢾞𢾇𢾠𢾍𢾈𢽿𢾌𢾓𣁙𩖰𣂱𥇴𣂯𣂲𣄈𣄊𣄶𣄷暑㬁𣈶𣈩𣉊𣈦𣈨𣈤𣈪𣉈𣍄𣎄𣎌𣓄𣓜𣖇𣕓𣕿𣔱𣕑𣕋𣕒𣖀𣖥𣖁𣕃𣔴𣔶𣔲𣕈𣕉𣔼𣕍𣕊椔𣖊𣕆𣔾𣖃𣕐𣖴𣣰𣣤𣣣𣣥𣣦𣣧𣣡𣣯𣣨𣣝𣤃𣦈𣨦𣨖𣨻𣨯𣨹𣨴殟𣨵𣨱𣨸𠮁𣪪𣪣𣪤𣪠𣪡𣪩𣪥𣮯𣮱𣮫𣮮𣮰𣮳𣮬𣮩𣮲𣮨𣮴𣯂𣱏𣱦𣸞𣸕𣸐𣹿𣹾𣹠𣻌𣺲𣹢𣺍𣺎𣹷𣹼𣺏𣹝𣹭𣹣𣺯𣺐𣹽𣹺𣸆滋𣹨𣺴𣹸𣺱滗滇㵁𤋨𤋩𤋀㷗𤋪𤊷𤊺𤊵𤉣𤊴𤊼𤋆𤊸𤊽𤋂𤍍𤋰𤋇𤋁𤋃𤋱𤋬㷢𤔜𤔠𤕘𤕥𤕤𤖌𤖍𤗙𤗠𤘐𤚔𤚒𤚖𤚕𤚿𤚠㹅𤟴𤟶𤟜猽𤠖𤠕𤠜𤠏𤠬𤠔𤠥𤠗𤠒𤠞𤦀𤦨𤦯𤧖𤧢𤦴𤦳𤦰𤦲𤦡𤭠𤭦𤭡𤭎𤭝𤭣𤯦𤯯𤯬𤲘𤲧𤲔𤲕𤲑𤲝𤲙𤲛𤲜𤴝𤴜𤷐𤷠𤷋𤷛𤷚𤷂𤷓𤷥𤽽𤽻𤿿㿲𤿹𥁷𥁼𥁸𥁻𥁺𥇒𥇋𥇉𥇎𥇤𥇊𥇐𢾥䁉𥇙𥇮𥇝𥈀𥇡𥏞𥏢𥏚𥓎碌𥓏𥓚𥓑𥓔𥓭𥓋𥓕𥓱𥚜𥚈𥝁𥟐𥟷𥟏𥟑𥟛𥟞𥟸𥟜𥟙𥦠𥦲𥦡𥦪𥦩䆩𥦦𥦜𥦵𥦧𥪎𥪐𥪑𥮅𥭛𥭰𥭤𥭧𥭣𥭷𥭨𥭥𥭪𥭸𥭦𥺈𥺞𥹴𥹺𥹹𥺋𥹿𦀓𦀞𦀗𦀚𦀢𦁃𦀙𦀟𦀧𦀒𦀤𦀥𠍞𦈵𦈴𦋑𦋄𦋊𦋆𦋋𦋍𦋢𦎉𦎋𦎆𦎈𦎍𦐻𦐼𦐽𦓵𦕹𦕵𦖂𦕿𦖅𦕶𦕼𦕾𦕻𦖀𦕺𢕈䏋𦘝𦛶𦛷𦛹𦝣𦝬𦝮𦜔䐍𦝧𦝯𦝩𦝶𦞕𦝱𦝡𦝸𦝫𦞅𦞄𦤔𦥶𥦶𦥵𦥴𦧢𦨿𦩄𦨽𦩇𦩈𦫛𦫜𦫝𦯥𦯦𦳂𦴮𦴝𦳸𦳰𦳶𦳅𦳆𦳼𦵒𦴯𦴐𦳳𦳒𦳪𦳜𦴛𦳻𦳃𦴖𦴇𦳉𦳘𦴰𦴓𦴱𦳺𦳲𦷣𦳕𦴁𦳱𦴜𦳖𦴈𦴋𦴗𦴠𦳊𦴲𦴅𦳤𦳎𦴳𦴂𦴙𦴞𦳓𦳷𦳨𦴟𦳴𦷘𧆿𧆾𧆽𧋜𧋝𧊫𧋉𧋈𧋟𧋙𧋛𧋌𧋠𧋯𧋳𧊮𧌁𧖷𧖹𧖶𧖸𧗶𧗳𧗵𧗲𧚑𧚝𧚛𧚒𧚍𧚞𧚌䘱裞𧚕裗𧚊𧚎𧚜𧟯𧠭𧡒𧠵𧠨𧠮𧣥𧧪𧧱𧧫𧧘𧧐𧧳𧧛𧧎䛕𧧚𧧗𧧡𧧲𧧈𧧙𧧞𧧑𧧧𧧏𧮷𧱇𧱂𧱃𧲨𧳃𧳁𧳇𧵤𧵪𧵦𧵐𧵮𧵥𣣬𧵿賁𧶚𧵩𧵲𧵯𧵽𧵰𧹠𧻂𧻧𧻑𧻫𧻔𧻜𧻛𨀷𨀥𨀟𨀞𨀡𨀛𨀗𨀢𨀪𨀚𨀭𨀣𨀦𨀶𧿿𨈺𨉀𨉂𨈸𨈻𨈼𨉃𨋨䡖𨋹𨋫𨋱𨋩𨋬𨐕𨑇𨑆𨑅𨔤𨕖𨔡𨔧𨕍𨔱𨔞𨔬𨕏𨔫𨕓𨕑𨕔𨔩𨕐𨔟𨔲𨔪𨔝𨔨𨔮𨔭𨚼𨚻𨚽鄑𨝇𨝁𨜮𨝉䣴𨠮𨠧𨥪𨥻𨥳𨥵𨥩𨥹𨥲𨱶𨱷𨱴𨱳𨴂𨳾𨴁𨳹𨳴𨻓𨻕𨻘𨻃𨻊𨻐𨻋𨻎𨻅𨻦𨻄𨻀𨻔𨻨𨽶𨾢𨾮𨾨𨾣𨾧𨾖𨾩𨾥𨾯𨾤𨾫𨾪𨾦𨾠𩂘𩇛𩇶𩇵𩈌𩈊靵𩉨𩉣𩉦䩘𩉤𩉪𩉩𩎕𩐂𩑪𩑞𩑨𩑩𩑥頋𩖧𩖮𩖨𩖳𩚜𩚩𩚶𩚳𩚲䬳𩚸𩛀𩚿𩚺𩚨𩠼𩡲𩡱𩡵𩡳𩨗𩨕𩨖𩨛𩫺𩫹𩰬𩲅𩲂𩱿𩱽𩲈𤋳𩵎𩵐𩾓𩾗𩾘𩾛𩾜𩾖𪊋𪋽𪋼黹𣦋𠄈𠄇𠍳𠎢𠎯𠎂𠍻𠍴𠎰𠍶𠎹𠎞𠎱𠎡𠎲𠍮像㒇𠍸𠍺𠎃𠎚𠓸𠔱𠕩𠕨𠖜𠘄𠚙𠟇𠟆𠟜𠟄𠟘𠟔𠟟𠟑𠟐𠟖𠟏𠢭𠢯𠢧𠣽𠥛𠥚𠨇𠪝𠪛𠪫𠪤𠪜𠪞𠪥𠪪𠪦𠬎𠭾𠭼𠻮𠻕𠻩𠹂𠻢𠼣𠻙𠻔𠻣𠼕𠻯𠻰𠻖𠻝𠻶𠼜𠻘𠻛𠻚𠻭𡐑𡈕𡈘𡈚𡈟𡈖圗𡈙圖𡏰𡐉𡐛𧯧𡏶𡏴𡎵𡐐𡏲𡐕𡏳𡏸𡔹𡔻𡔶𡐎𡕲𡕴𡖹𡖷𡖸𡙲𡙫𡠝𡠷𡠢𡡃𡡍𡠸𡡛𡡹𡠛𡟳𡦑𡩻𡪔寧𡪖𡩿𡪏𡪄𡩼𡪎𡪐𡪘𡪃𡪀𡪓𡪂\xc2M\f\xd3𡬽𡭁𡮡𡮟𡰊𡰍𡳈𡳉𡳇𡳍𡴥𡺱𡻜嵼𡻥𡻴𡺿𡻛𡻪𡻲㟺𡻱𡻭𡻦𢀂巢𢄥𢄘𢄟𢄠𢇏𢊝𢊙𢊨𢍨𢍦𢍧𢎑𢐓𢐕𢐝𢐖𢐙𢑫𢑨𢑪𢑭𢑩𢒪𢕗𢕒𢕐𢕟𢕧𢕏㣲𢕛𢕜𢕝𢠟𢞝𢞱𢞰𢞔𢟃𢞯𢞨𢟜𢞘𢟄𢞤𢞫𢞪𢞥𢠡𢟫𢟦𢟷𢟸𢟼𢟺𢟠慺𢟶慌𢟡𢧬𢧢𢧤𢧹𢱯𣩌𢲻𢳡𢴀𢳘㨯𢳖𢳢𢳃𢲳𢳒掩𢳎𢳐𢳕𢲹𢲺𢳏𢲽𢻛𢾪𢾭𢾸𢾶𢾵𢾯𢾴𢾷𢾨𢾹𢾾𣂂𣂼𣂸𣂶𣂹㫁𣄐𣈧𣉩𣉰𣉖𣉫𣉺𣉬𣉭暜𦟁𣎍𣎐𣖶𣘐𣘒𣖺𣖽𣘅𣗂𣘇𣗵榣𣖯𣗴𣗷𣗸𣘑𣣶𣣵𣣻𣤁𣤀𣤂𣣾𣣺𣣽㱀𣨷𣩉𣩋𣩆𣩇𣪳𣪲𣪰𣪷𣬑𣯌𣯟𣯓𣯕𣯛𣯅𣯈𣯉𣯢𣯊𣯑𣯒𣯝𣹬𣹳𣼡𣻰𣼟𣼻𣼢𣼣𣻨𣻝㴿𣼛𣻭淹𣻲㵆𣻜𣻩𣼝𣻚𣻦𤌓𤌕𤌖𤌼𤌋𤌜𤌺𤌽𤎚𤌝𤌉𤌈𤌻𤔢𤔦𤔥𤔤𠎛𤖏𤖐𤗦𤗣𤗥𤘑犕𤚮𤚾𤚴𤚳𤚯𤚑𤛂𤚶𤚲𤡗𤠻𤡘𤡑𤡁𤠹㺏𤡙𤡅𤡡𤣨𤧺𤧪𤧯𤧲𤧰𤬈𤭰𤭪𤭳𤭨𤯈𤯲𠭻𤲮𤲫𤲵𤲯𤲲𤲶𤲰𤴞𤸃𤸆𤸟㾬𤸏𤸂𤸙𤸘𤸢𤸕𤸇𤸍𤸤𤸌𤸞𤾉𤾊𥀊𥀋𥀇𥀄𥀉𥂄𥂃𥈘𥈔𥈅𥈖𥈎𥈑𥈈𥈋𥈕𥂅𥈷𥈪𥈍𥈏𥍾𥍶𥍷𥏬𥓼𥔈𥓿𥔃𥔐𥔅𥔆𥓺𥛇𥛀𥛃𥚧𥚨𥚫𥛁𥚪𥛈𥝃𥠂𥠎𥠁𥟾𥠃䅨𥠢𥠡𥠇𥠈𥠌𥧊𥦼𥦾𥦽𥥼𥦻𥦹䆫𥧅𥪙𥭫𥮓𥮔𥮩𥮠𥮦𥮫𥮫𥮞𥮛𥮑𥮙𥺶𥺸𥺚䊑𥺘𥺛𥺳𥺢𢊪𥺠𥺟𦁛𦁰𦁔𦁱𦁥𦁑𦂝𦁡𦁚𦁴𦁌𦁋𦁞𦁘𦁓𦁒𦈻𦈹𦋖𦋧𦋗𦋘𦋙𦋚𦋛𦋜𦋠𦋤𦋝𦋵𦋱𦎘𦎝𦎙𦑐𦑉翤𦑢𦓗𦔁𦓷聠𦖌𦖜肈𦘟𦘞𦜽𦜿𦜑𦝙𦜾𦝘𦠀𦞞𦞶𦞪𦞯𦞬䐰𦟀𦞩𠬐𦣮𦥷𦥹𦥺𦥽𦥿𦦃𦥸𦥾𦧤𦧦𦧥䑝𦩎𦩙䑵䑫𦩏𦩊𦫣𦫞𦫢𦱴𦲬𦱡𦱢𦱶𦱺𦱹𦱠𦵤𦺁𦷟𦶄𦶊𦶒𦵹𦷞𦶘𦵬𦷡𦵫𦵼𦵲𦶁𦷠𦵥䓿𦵪𦶗𦶪𦷓𦶫𦵮𦶬𦵶𦵻𦶭𦳕𦶔𦷗𦵰𦷕𦷱𦵺𦵨𦶖䔋𦵳𦶮𦷝𦷚𦶯𦶰𦷛𦵢𦷸𧇂𧇇𧇈𧇅𧇃𧇆𧋞䗈𧌑𧌕𧌨𧌡𧌭𧍑𧌫𧌖𧌤𧌵𧌟𧌒𧌐𧎀𧌻𧌩𧌪𧌛𧌘𧌠𧌿𧌜𧌦𧌋𧖾𧗄𧖽𧗺𧗻𧚱𧚲𧚰𧚹𧚦𧚽𧚶𧚸𧚴𧚳𧚧𧚮𧚼𧟲𧟳𧟴𧠻𧡀𧡆𧣰𧣮𧣨𧣱𧣫𧣯𧧼𧨠𧨉𧨆𧨃誠𧨙𧨤𧨛𧨝𧧽𧨞𧧴𧨁𧨈𧨅𧨣𧧿𧯪𧱍𧱎𧱖𧱗𧱑𧱕𧱔𧳋𧳔𧳑𧳏𧳒𧳊𧳖𧶋𧶈𧶅𧶉𡪛𧶻𧶌䝴𧶙𧶊𧻬𧻯𧻹𧼉𧻸𧻳𧼀𧼝䞬𧻽𨁈𨁜𨁕𨁞𨁉𨁣𨁠𨁓𨁢𨁀𨉉𨉊𨉌𨉍𨌊𨌆𨌯𨌡𨌇𨌉𨌂𨌖𨌃𨌋𨌏𨕠𨕨𨕞𨕝𨕣𨕟𨕤𨕸𨕡𨕩𨕥𨛚𨛦𨛛𨛜𨜹𨝗𨝕𨝥𨝡𨝔𨡅𨡁𨠾𨠷𨤒𨤓𨤬𨦖鋘𨦜𨦝𨦅鋗𨦟𨦣𨲂𨲃開𨴝𨴔𨴟𨴠𨹇𨺀𨻉𨻹𨼆𨻰𨻸𨻯𨻽𨻱𨻾𨾸𨿀𨿃𨾵𨾶𨿉雃𨾽𨾺𨾿𨾳𨿆𨾲𨾼𨾷𨾹𨿈𩂵𩂳䨖𩂬𩂩𩂭𩈑𩈍𩈖𩊉𩉼𩊋𩉻𩊈𩊄𩎝𩎘𩒈𩒆𩒂𩒋頋𩑱𩑲𩒁𩒌𩒀𩒉𩖻𩗁𩖾𩖶𩖽𩛁𩚴𩚷𩛈䬻𩛓𩛐𩚰𩛊𩛍𩠕𩠙𩠗𩠿𩡀𩡄𩡼𩡸𩡹𩢈駂𩢉𩡽𩨣𩨤𩨦𩨢𩨧𩨟𩫉𩫃𩬊𩬋𩬄䯯𩬃𩬁𩬉𩰏𩰮𩰭𩲑𩲙𩲞𩲐𩲔𩲝𩲘𩲛𩲜𩲚𩵔𩾝𩾟𩾣𩾤𩾥𪌂𪐗𪐛𪐙𪓑𡔷\xcf\x18\x10p𠁕𠆂𠆉𠆇𠎾𠎽𠏊𠏔𠎼𠏄𠏁𠏂𠏗𠍰𠎺𠏣𠎻𠒱𠒶𠒰𠕬𠘍𠘇𠟱𠟵𠟭𠟯𠟫𠟬𠟮𠠇𠧄𠨈𠪳𠪭𠪰𠬑𠆊𠾃𠽺𠾂𠽨𠽱𠾰𠾯㗶𠽬𠽯𠽢𠽥𠾁𠽿𠽤𠽳𠽮𠽧𠽴𠽽𠽸𠾅𠾪𠾈𠾦噑𠾊𠾄噕𡈤𡈢𡈣𡐲𡑛𡑏㙧𡐦𡐾𡑂𡑇𡐢𠚛𡑊𡐟𡑉𡐫𡐳墬𡐪𡐨𡔽𡔼𡖿𡙹𡚀𡙼𡙺𡙸𡢂𡡜𡡚𡡋𡡼𡡿𡠟𡢉𡢋𡡗𡡾𡡽𡡓𡪟𡪼𡪥𡪆𡫜𡪤𡪶𡪢𡪴𡭈𡭆𡮧𡰔𡰒𡰑𡳡𡳐𡴦𡴨𡴩𡼯𡼍𡼥𡼔𡼼𡼋𡼑𡼰𡼘𡼙𡼶𡼗𡼐𡼕𢀃𢀩𢁌𢄿𢄾𢄴𢅂𢄵𠟳𢇍𢇑𢇒𢊭𢊺𢋁𢊻𢊴𢊼𢍬𢎒𢐡𢐤𣼙𢒭𢒮𢒫𢒬𢕰𢕶𢕱𢕷慿𢟬𢠜𢟻𢟮𢣢𢟽𢠞𢠻𢡊憯㦒𢡽𢡖𢡄𢡋𢧺𢧨𢧸𢧿𢧷𢧶𢧾𢨇𢩝𢳔摩𢳈𢳅揅𢳉𢵍𢴠𢴶𢴵𢶈𢴥𢴡𢴦㩅𢴿𢴻𢴤𢵃𢵎𢵏𢵄𢵀𢵁𢴹𢴫𢻝𢻞𢿍𢿋𢿛𢿘𢿙𢿒𢿊𢿑𢿔𢿹𢿐𣁞𣁝𣂊𣄔𣊃𣊄𣊛𣊂𣉽𣉼𣊙𣉾𣊚𣊕𣉻𣊆𣍈𣎒𣖲𣙂𣙲𣘕𣘰𣙃𣙛𣘝𣙳𣘭㯉𣘡𣘜槪𣘩𣘞𣘳𣤐𣤎歔𣤍𣤑𣤒𣤗𣤉𣤏𣦘𣦔𣦖𣦙𣦕𣦗𣩐𣩒𣩍𣩗𣩓𣩖𣪸𣬒𣯱𣯰𣯮𣯯𣯥𣯫𣯧𣯲𣯦𣯷𣰉𣱫𣱪𣹹𣻍𣻯𣻤𣻣𣽬𣽧𣽢𣽘𣽩𣾫𣽗𣾭𣽔𣾮𣽶𣽉𣽅𣽏潮𣽐𣽽𣽄𣽦𣽈𣾣𣽑𣽓𣽞𣽠𣽇𣽞𣾅𣾬𣽭𣽵𣾨㵕𣽃𣽎𣽜𣽖𣽮𣽣𣽪𤍖𤌒𤍜𤍘𤍕𤍾𤎨𤌑𤍙𤍚𤍧𤍔𤍛𤏰𤔩𤔬𤔪𤔧𤕧𤕨𤖓𤖕𤖚𤗮𤗱𤗭𤗩𤛋𤛟𤛛𤠼𤡎𤡬𤡻𤡼𤢃𤡫𤡦𤡣𤢠𤢅𤡨𤢢𤧬㻨𤧧𤨐𤨘𤨺𤨚𤨸𤩁𤨛𤨔𤨜𤨝𤬒𤬍𤬇𤭾𤭿𠪹𤮀𤭺𤯳𤲹𤲻𤲺𤲽𤲷𤲿𤴡𤸺𤸽𤸶𤹉𤺁㾶𤸿𤹍𤸾㾽𤸰𤹏𤼷𤾘𤾙𥀑𥀍𥂈𥂊𥈜𥉕𥉖𥉢𥉗𥉂𥈐𥉍𥉚𥉦𥉆𢡾𥉏𦋹𥎇𥎄𥏴𥏵𥏲𥏷𥕅磌𥕉𥔵𥓽𥔰𥕃𥕆䃖𥔳𥛆福𥛔𧇘𥛠𠾧𥝄𠎘𥡔𥠾𥠵𥠺䅱𥠴𥠹𥡆𥡚𥡛𥠼𥧜𥧖𥧐𥪧𥪬𥪧𥪥𥯴𥰤𥯠𥯽𥯲𥯼𥰂𥯱𥯿𥯒𥯘𥯓𥯹築𥯭𥯷𥯰𥯗𥰉𥯵𥻂𥻅𥻏䊘𥻁𥻆𥻔𥻎𦂛𦂙𦂏𦂷𦂂𦂓𦂴𦃁𦂐𦂚𦂔𦂜𦂵縂緇𦂟𦂎𦂞𦈼𦉇𦉁𦋫𦋬𦋭𦋮𦋟𦋲𦌍𦌎𠆆𦎩𦎤𦎥𦑟𦑚𦑦𦑨𦑪𦑤𦑥𦓁𦓘𦓚𦓙𦔉𦖪𦖣𦖹𥧢𦖥𦖫𦘠𦞉𦞋𦟛𦟤𦟯𦟢𦟩𦟰𦟳𦟝𦠞𦤝𦥆𦥼𦦀𦦁𦦂𦦇𦦈𦦊𦦌𦧧𦧫𦧪𦩨𦩫𦩩𦩦𦩪𦩥𦫥𦫤𦫦𦴘𦸤𦹈𦸯𦹾𦶉𦸞𦹉𦸦𦸉𦸴𦸭𦹊𦸕蓳𦸳𦸨𦸧𦸠𦸫𦸩𦹪𦸋𦸎𦸜𦷿蔖𦸵𦸑𦹋𦹌𦸟𦸏𦸹𦹨𦸝𦸈𦸌𦸍𦹢𦺯𦸲𦸬𦹤𦸄𦸖𦹍𦸇𦺮𧇔𧇑𧇕𧇐𧍱𧍝𧍛𧍺蝫𧍿𧍹𧍧𧎞𧎔𧍤𧍞𧎁𧍚𧍗𧍼𧎘𧍦𧍾𧎂𧍨𧍟𧍣𧎗䗍𧍓𧍻𧍽𧍸𧐆𧗀𧗂𧗃𧛜𧛦𧛬𧛲𧛨𧛫𧛪𧛥𧛠𧛐𧛘𧛕𧛓𧛤𧛳𧛛𧜯𧟹𧡝𧡛𧡌𧡘𧡠𧡓𧡐𧤇𧤈𧣴𧣶𧤄𧣽𧤅𧨨𧩖𧩂𧩜𧨽𧨯𧨫𧩘𧩡𧨬𧩑𧩐𧨼𧨶䛶𧩠𧩛𧨮𧩀𧩟𧨦𧨭𧩞𧨻𧯯𧯳𧯮𧯭𧱚𧱢𧱛𧱟𧱞𧱤𧳠𧳡𧳥𧶥𧶛𧶟𧶜䝺𧶪𧶤𧶣𧹧𧼦𧼚䞸𧼜𧼕𧼛𧼘𧼞𧼥跰𨁻𨁵𨂓𨂏𨁼𨂛𨂈𨂑𨁾䠁𨂆𨉛𨉔𨌵𨍀𨌷𨌩𨌦𨌣𨌰𨌠𨌨𨌲𨌪𨌱𨌤𨍁𨍂𨍃𨌥𨌸𨐜𨐝𨐞𨓶𨖥𨖉𨖝𨖊𨖇𨖫𨖆𨖞𨖓𨖡𨖈𨖣𨖟𨖠𨛿𨜀𨜂𨛸𨛷𨜁𨞇𨞉𨝶𨝮𨝷𨞂𨞅𨞃𨞄𨡗𨡋𨡖𨡝𨡠𨡍𨡕𨡔𨡓𨡐𨡣𨡢𨤗𨤖𨤭𨧙𨦿䤯𨦲𨦬𨦰𨧏𨦸䤱𨧓𨦱𨦯𨧎𨦴𨦷𨧕䦜𨴸𨴨𨴿𨵀𨵁𨴳𨴫𨴮𨼑𨼘𨼗𨼙𨼖䧦𨼍𨼦𨽹𨿘𨿳𨿗𨿌𨿏𨿋𨿑𨿎𨿔𨿕𨿓𨿖𨿍𨿚𨿒𨿞𩃌𩂸𩂷𩃂𩃃𩇺𩇹𩈟𩈝𤎂𩊦𩊐𩊞𩊘𩊓𩊒𩊥𩊕𩎩𩎨𩎪𩎫𩎬𩐟𩐢𩒪𩒖𩒑𩒫𩒢𩒤𩒝𩒗𩑽𩒟𩒜𩒙𩒦𩒒𩒧𩗉𩗈𩗇𩗋𩙴𩛉䬸𩛒𩛛𩛫𩛩𩛯𩛮𩛹𩛞𩠖𩡅𩡿𩢘𩢟𩢆𩢐𩢚𩢝𩢍𩢜𩢠𩢧𩢏𩩁𩨵𩨷𩨰𩨺𩨶䯎𩫊𩬒𩬕𩬢𩬓𩬘𩬡𩰐𩰠𩰯𩲥𩲣𩲶𩲫𩵨𩵧𩵯𩵷𩵤𩵸䰺𩵶𩵮䲱𩿐𩾳𩿋𩿖𩾱𩾴𩿗𩿆𩾺𩿑𩿒𩾵𩿄𩿌𩾻𩾲𪉛𪉚𪉙𪊙𪊔𪊖𪊓𪊑𪊏𪊘𪌌𪌊𪌎𪌒𪌑𪌋𪎚䵇𪎴䵑𪐜𪐠𪐡𪓓𪓒𪔸\xd3<\x1f!𤳏𠄝𠆒𠆏𠆌𠏪𠒹𠕮𠖠𩇝𠘒𠘗𠙭𠠅𠟻𠟽𠠆𠠀𠟿𠟾𠢶𠤅𠤇𠪼𠪻𠮋𠮇𧯻𠿧𠿦𠿢𨗓𠿙𠿊𡀒𡀐𠿜𠿐𠾋𡀓𢨐𠿚噴𠿽𠿥𠿌𡀹𠿇𠿘𡑠𡑤𡒆𡑞𡑦𡑟𡑷𡒂𡒖𡔾𡕹𡕺𡚈𡚉𡚌𤏠𡙻𡚆𡢔𡢗𡢷𡢵𡢸𡢓𡢲𡢴𡢙𡣃𡢝𡢛𡢣㝇𡦟𡦞𡦣𡦠𡦡𡫅𡫆𡪾𡫄𡪿𡭌𡭊𡮱𡰕𡰗𡰐𡰘𡳠𡳟𡴧𡽃𡽈𡼝𡽐𡽘𡽇𡽔𡽓𡽀𡽕㠗𡽚𢑰𢅛𢅍𢅖𢅏幩𢅙㡢𢅔𢅓𢅐𢊳𢍮㢣𢍯𢎓𢐩𢐥𢐨𢑯𣊸𣊸𢒳𢕹𢕾𢕼𢖃憲𢠀𢠴𢡥𢠶𢢧𢡙𢡘𢠱𢡢𢠸𢡳𢠾𢡕𢡔𢢕憤𢢥𢢔𢢚𢢛𢢙𢢟𢡓𢨒𢨊𢨉𢨌𢨋𢨎𢨍𢨈𢴸𢴺𢴷𢴽𢴭𢶂𢶕𢶇𢶝𢶵𢶁𢷇𢶛𢶘𢶔𢶆𢶐𢴴𢶎𢶅𢶦𢶖𢿪𢿟𣊽𢿷𢿫𢿠𢿨𢿡𢿩𢿮𢿥𢿬𣁣𣃆𣃂𣃁𣃀𣃃𣄘𣊮𣊣𣋅𣊠𣊴𣊥㬞𣊤𣊨𣊦𣚨𣚍𣚂𣚞𣚉𣚃𣚑𣚦𣚚𣚐𣚅𣙽𣚣𣚛𣚥𣛰𣚖𣚈𣛖𣛘𣚆𣚤𣚗𣤜𣤙𣦞𣦟𣦝𣦜𣩝𣩞𣩳𣩣𣪾𣫁𣪼𣪿𣪻𣪽𣬓𣬔𣯸𣯺𣰆𣯾𣱑𤀋𣿱𣾩𣿕𣿜𣿋𣿲𤁟𣿛𣿖𣿙𣿑𣿏𣿘𣿠𣿔𣿳𤀅𣿎𣿴濆𣿚𤀎𤀆𣿗𣿍𤀇𣿡𤀈𤎾𤏯𤏙𤏚燗𤎠𤏟𤎞𤎿𤎯𤎧𤎪𤏴𤎮𤎵𤎸𤏛𤏀𤎷𤎢𤏝𤎡𤎥𤎴𤎦𤎫𤎬𤎟𤎱𤏼𤔮𤔰𤖙𤗸𤗶𤛴𤛫𤛡𤛤𤡵𤡩㺚𤢙𤢞𤢔𤢓𤢑𤨙𤨗𤩉𤩇𤩈𤩒𤩀𤩣𤩆𤩟𤬎𤮅𤮇𤯋𤯌𤯉𤳋𡳧𤴠𤹟𤹠𤹪𤹶𤹳𤹱𤹦㿂𤹫𤹹𤹻𤼼𤾞𤾜𥀜𥀞𥀡𥂖𥂡𥂶𥂠𥂔𥂗𥊁𥊆𥋀𥊊䁆𥊅𥊕𥉉𥊂𥊄𢡸𥊐𥊱𥏾𥏻𥕐𥕖𥕚𥕌䃜𥕗𥛡𥛙𥛛𥛴𥝅𥝇𥡴𥡫𥡨𥡩𥡹穏𥢀𥢁𥡡𥡰𥡯𥡳𥡝𥧺䆳𥧳𥨊𥨗竮𥪰𥰾𥱆𥱏𥱄𥱩𥰳𥰯𥱎𥰺𥰫𥲩𥱐𥱅𥰷𥰥𥰬𥰿𥰴𥰩𥰞𥰽𥻭糒𥻳𥻪𥻫𦃌𦃎𦃐𦃣𦃠𦃑𦃜𦃝𦃰𦃛𦃥𦃱𦃉𦃨𦃧𦃈𦃞𦃍𦃅𦃆𦃤𦃃𦋻𦋼𦋾𦌃𦌀𦋽𦌅𦌓𦎧𦎴𦑰𦑴𦑱𦑵𦑽𦑳𦓄𦓃𦓞𦓝𦔑𦔏𦔐𦗃𦗂𦗊𦗁𦘣𦞱𦠤𦠑𦠥𦠊𦠟𦠠𦠔𦠨𦠃𦠒𦠋𦠗𢨑𦥉𦦉𦦐𦦏𦦖𦦕𦦓𦧭𦧰𤏞𦪅𦩷𦩺𦪄𦩹𦩽𦩻𦫭𦫬𦷶𦶛𦺝𦼑𦻄𦻈𦻟𦺿𦺳𦺺𦼐𦼆𦺈𦺚𦻠𦻌𦺵𦻡𦺼𦻆𦼋𦺤𦺕𦺣𦻢𦻍𦻣𦺫𦻋𦺏𧏊𦺩𦼤𦻤𦼒𦻥𦽧𦺪𦺠𦻦𦻁𦻧𦼍𦺆𦺰𦺋𦻨𦻉𦼙𦻀𦼌𦻩𦻪𧇭𧇣𧇤𧇨𧇒𧇢虦𧏏𧏑𧏃𧏉𧏪𧎴𧏜𧎽𧏦𧏈𧏬𧎲𧎩𧏓𧏀𧏩𧏕䗙𧏅䗝𧏋𧏐𧏒𧏤𧏨𧏍𧎶𧎪𧏔𨑉𧏁𧏝𧏎𧗉𧗊𧛽𧛙𧛿𧜄𧛺𧜆𧜕𧜑𧜇𧜋𧟼𧟽𧡭𧡥𧤔𧤓𧤌𧤋𧤘𧤢𧩽𧩯𧩵𧩻𧪀𧩿𧪖𧩭𧩫𧪆𧩳𧩢𧩷𧪁𧩩𧪂𧪗𧪄䛼𧩸諭𧩺𧩪𧩥𧩾𧪔𧯂𧯁𧯹𧱱𧱫𧱭𧱯𧱮𧳲𧳨𧳯𧳩𧳪𧳭𧳳𧳦𧷉𧶴𧶳𧶹𧷊𧶱𧶶𧷇𧷄𧶷𧹯𧼳𧼲𧼟𠠄𧼫𧼰𧼶𧽂𧽁𧼯𨂥𨂫𨂩𨂳𨂧𨂠𨃒𨂨𨂶𨂬𨂮𨃦𨉥𨍇𨍣𨍍𨍟𨍋𨍠𨍉𨍓輸輺𨍗𨍎𨍒𨍔𨐠𨐢𨗁𨗗𨗕𨗄𨗐𨖻𨗢𨗋𨖸𨖿𨗘𨗀𨖺𨖴𨖼𨗔𨗭𨗂𨗙𨗌𨜯𨜣𨞖𨞍𨞞𨞙𨢃䤇𨡪𨡩𨡵𨡬𨢀𨡮𨢁𨡷𨡶𨡳𨡰𨢄𨤙𨧲錽𨧬𨧫𨧶𨧯𨧴𨧮𨧳𨧭𨲛𨲘𨲔𡑺𨲚閷𨵌䦨𨵗𨵑𨵢𨵒𨵜𨵓䦕𨺅𨼭𨼾𨼰𨽽𨿦𨿶𨿤𨿩𨿬𨿲𨿨𨿣𨿫𨿰𨿪𨿧𨿽𨿥𨿮𨿵𨿹𨿱𨿴嶲𩀑𩃞𩃘𩃝𩃛𩃓𩃡𩇞𩇼𩈨𩊷𩊸𩋇𩊲𩊩𩊹𩊼𩊳𩋑𩎰𩎱𩎶𩐄𩐬𩓁𩒞𩒺䫊𩓑𩓓𩓔𩓕𩓅𩒶𩒲𩒵𩓃𩒹𩓆𩗖𩗠𩗛𩗗𩗝𩜕𩜌𩜏𩜎𩛾𩜄𩜋𩜂𩛿𩜈𩜐𩜗𩜓𩜆𩜉𩠤𩠦𩠥𩡈𩢵𩣒𩣕𩣄𩢯𩢭𩣁𩣀𩣌𩣍𩣃𩣈𩣅𩢺𩣐𩣂𩣎䮍𩩃𩩂𩩀𩨱𩫏𩫎𩬰𩬴𩬲𩭃𩬼𩰑𩰵𩰴𩰷𩰱𩲿𩲼𩳂𩳉𩶃𩶋𩶠𩶍𩶌𩶚𩶊𩶑𩿱𪀏𪀁𪀇𪀌䳀𩿜𩿮𩿷𩿲𩿸𩿫䳊𩿦𩿭𩿩𪀈𪀀𩿥𩿼𪉡𪉞𪉟䴥𪊛𪌖𪌕𪌠𪌔𪌚𪌗𪎜䵊𪏱䵓𪏰𪐨𪐤𪐪𪐫𪓕𪔄𪔅𪖑𪖒𪚦𠄉𠐢𠐚𠐎𠐛𠐈𥂳𩇟𠚝𠠒𠠞𠢻𠢿𠢼𠤈𠤉𠥣𠧈𣜨𠮎𠮍𡁃𡁍𡁭𡁂𡁒𡁑𡁩𡁐𡁖𡁬𡁄𡁅𡁫㘅𡁨𡁗𡈮𡈱𡈭𡒋𡒲𡒍𡒣𡒤𡒐𡒉𡒎𡒒𡕻𡚐𡚏𡣩𡣍𡣅𡣐𡣉𡣑𡣇𡣒𡡟𡣡𡣌𡣆𡣤𡦩𡫓𡫚𡫒𡫛𡭍𡭎𡰜𡳨𡽬𡽟𡽦𡽤𡽸𡽥𡽭𡽡𡽺𢀇𢅦𢅨𢅢𢋓𢋔𢋑𢋜𢍳𢍲𢐭𢐬𢐯𢑲𢒴𢖉𢣍𢢦𢢢𢢠𢡆𢢡𦡗𢢣𢢱𢣛𢣶𢣜𢣖𢨓𢶜𢶗𢷘𢷤𢷔𢷥𢷛𢷐𢷚𢷌𢷜𢷭𢷓𢷑𢷎𢷙𢷝摷𢻦𢻥𢻩𢿿𢿾𣀎𣂌𣃊𣃋𣄚𣋗𣋏𣋐𣋉𣋌𣋓𣎤𣎣𣚁𣜦𣛳𣜧𣛲𣛿𣛵𣛼𣜎㯰𣛶𣜀𣜣𣜅𣜥𣜈𣝐𣤥𣤠𣤣𣤡𣦡𣩩𣩬𣩨𣩫𣫌𣫍𣰐𣰍𣰊𣰖𣰔𣱒𤀨𤀮𤀬𤁍𤁉𤀱𤀰𤀳𤀭𤀶𤀾𤀯𤀧𤀴𤁌𤏾𤐒𤐟𤐗𤏵𤐕𤏷㸂𡽽𤏺𤏹𤑋𤖜𤖝㸣𤛰𤛶𤛯𤢝𤢚𤢧𤢯𤢪𤩤𤪆𤩵𤩰𤪄𤩫𤪅𤩬𤬗𤮔𤮓𤳒𤳗𤳔𤺦𤺧𤺜𤺋𤺍𤺘𤺹𤺛𤺝𤺙𤺨𤺑𤺇𤺚𤾦𥀟𥂧𥂱𥂲𥂷𥋐𥊳𥊵𥋄𥋃𥊼𥊲𥊺𥊻𥋂𥋍𥊾𥊷𥎏𥐃𥐄𥕱𥕷𥕴𥖋𥕺䃣𥖌𥕳𥛿𥛻𥛽𥛰𥛳𥛼𥛲𥢒𥢞𥢮𥢖𥨔𥨒𥨓𥨖𥨏𥪶𥪽𥲌𥲮𥲬𥲫𥲙𥲒𥲻𥲞𥱾𥲯𥲼𥲘𥼁𥼉𥼆䊠𥼇𥼈𦃏𦄎𦄲䌕𦄒𦄖𦄗𦄛𦄕𦄱𦄘𦄚𦄐𦄊𦄉𦉏𦉇𦌏𦌐𦋙𦌨𦌑𦌖𦌗𦌂𦎻𦎺𦏃𦎼𦒊𦒄𦒆𦓠𦔗𦔘𦔞𦔕𦗓𦗚𦗙𦟦𦟴𦟡𦡩𦡀𦡘𦡬𦡍𦤡𦦙𦧱𣽨𦪆𦽛𦽜𦽷𦼬𦼬𦽘𦽤𦽡𦽈𦾗𦽙𦼩𦽢𦼮𦽨𦾐𦼺𦼽𦽞𦽆𦽩𦽥𦼾𦽉𦽖𦿡𦽸𦽹𦽦𦽊𦼵𦽺𦾒𦽠𦼶𦽑𦽂䕝𦼿𦽻𦽝𦾔𦽪虧𧇲𧎻𧐛𧐚𧐳𧐗𧐊𧐕𧐒𧐁𧐭𧏽𧐣𧐅𧐑𧐀𧐜𧐵𧐮𧐶𠪿𧐯𧐌𧐉𧐱𧏼𧐏𧐘𧐂𧘀𧜟𧜷䙚𧜸𧜫𧜮𧜡𧜰𧜩𧜱裺𧜁𧜬𧜢𧟿𧡷𧡧𧡿𧡽𧡻𧡾𧤞𧤜𧤡𧤦𧤧𧤩𧤬𧤝𧤫𧪿𧪤𧪪𧪮𧪟𧫐𧪱𧪭𧪛𧪲𧪩𧪳𧪴𧪺𧪥𧪷𧪬𧫋𧫏𧯆𧰁𧯿𧯽𧱷𧱸𧱶𧳷𧷌䞅𧷔𧷗𧷕𧷖𧷓𧽝𧽕𨃠𨅈𨃮𨃛𨃧𨃖𨃥𨃡𨃝𨃢𨉰𨉦𨉯𨉮𨎁𨍩𨍮𨍭𨍬𨎄𨎅𨍱𨍳𨍪𨍯𨍻𨕪𨕼𨕽𨖁𨗺𨘅𨗫𨗻𨗩𨗯𨗼𨗧𨗭𨗮𨗽𨜲𨞧𨞵𨞱𨞹𨞨𨞳鄛𨢊𨢑𨢍𨢙䤋𨢚𨢛𨢒𨢏𨢫𤳛䤹𨩵𨨱𨨸䤷𨨵𨩩𨨻𨨽𨩬𨩹𨨼𨨾𨲗𨲥𨲢𨲝𨲡𨵨𨵯𨵭𨵲𨵵𨵷𨵹𨺵𨻪𨺫𨽃𨽁𨽂𩀉𩀈𩀆𩀁𩀃𩀀𩀄𩀊𩀎𩀍𩀇𩀏𩀔𩀂𩀕𩀌𩀐𩀅𩃽𩃿𩄄𩄀𩄉𩈫𩈚𩈱𩋛𩋅𩋓𩋎䩫𩋉𩊿𩋋𩋀𩋐𩋔𩏁𩎹𩎻𩏃𩏉𩐇𩐭𩓸𩓡𩓺𩓯𩓽䫍𩓫𩓱𩓭𩓾𩗸𩘃𩗮𩗨𩘁𩛬𩜢𩜔𩜴𩜯𩜹𩝁𩜼𩝉𩝘𩜺𩝂𩜿𩠢𩠣𩡊𩣜𩣙𩣛𩣢駾𩣟𩣠𩣗𩩏𩩆𩩓𩩕𩩎𩩊𩩌𩩣𩭑𩭓𩭘𩭍𩭚𩭉𩭝𩰒𩰼𩳑𩳟𩳖𩳘𩳠𩳙𩶱𩶬𩶪𩷮𩶽䱍𩷃𩶨𩶮𩶫𪀝𪀧𪀸𪀒𪁈𪀫𪀖𪀙𪀽𪀣𪀪𪉤𪊭𪊬𪊑𪊱𪊯𪌬𪌤𪌥𪌨𪏹𪏷𪏽𪏾𪐹𪐳𪓗𪓘𪓤𡒡𪔎𪔻𪕄𪕂𪖔𪖓𪗕𪚧𠐱𠐣𠐨𠐧𠓆𠖣𠘡𠠛㔐𠣁𠣃𠤪𠥩𠨧𡂶𡂧𡂤𡂦𡂐𡂞𡂔𡂨𡂥𡂢𡂑㙻𡒼𡒽𡒴𡒰𡓤𡕅𡕋𡕾𡕿𡕽𡗃𡣼𡣷𡣰𡣭𡣱𡣸𡫩𡳫㠝𡾐𡾍𡾆𡾑𡾊𢀈𢁐𢋪𢋦𢋢𢋧𢋭𢋡𢐴𢑶𢑵𢑳𢒵𢖐𢖒𢣡𢣟𢣕𢣲𢣾𢤓𢤮𢨙𢸈擵𢸅𢸉𢸆𢸃𢷽𢷼𢸂𢹂𣀚𣀑𣀕𣀖𣃌𣃏𣃐𣄢𣄞𣄡𣋥㬧𣍔𣋯𣍓𣍑𣎭𣝧𣝨𣞤𣝷𣞉𣝄𣞋𣝹𣝛𣝃𣝗𣝊𣝼𣝆𣝻𣝖𣦨𣩶𣩲𣫖𣫘𣫑𣰢𣰠𣰣𣰞𣱮𤂣𤁷𤂦𤁭𤂢𤁼𤂄𤁨𤁴𤂆𤂥𤁻𤁺𤁩𤁲𤁶𤑊𤐷𤐪𤐭𤑆𤐨𤐦𤑂𤐬𤐸𤐫𤐳𤑅𤔹𤛹𤛺𤢸𤢷𤢻𤢹𤢴𤩯𤪇𤪏𤪢𤪣璅𤮛𤮝𤯎㿏𤺽𤺿𤺒㿍𤻯𤻉𤾤𤾨𤾪𥀨𥀤𥀥𥀦𥂩𥂺𥂾𥂼𥃀𥋰𥋟𥋠𥋭𥋛𥋻𥊹𥋚𥋫𥋬𥖚𥖟𥖛𥖗𥖡𥜏𥜅𥝈䆂𥢼𥢺𥪿𥴁𥳨𥳯𥳤𥳦𥴂𥳫𥳠𥳭𥳙䈧𥴔𥳮䉒𥴓𥳹𥳸𥼥糣𥼙𥼠𥽃𥼿𦅅𦅌𦅉𦅏𦅁𦄾𦅍𦅒𦅊𦅎𦅳𦅂𦅑𦅕𦅐𦅬\xdb\x115\xfe𦅪𦅓𦅨𦉕𦉖𦌜𦌝羀𦌞𦌣𦌟𦏡𦏍𦏋𦏌𦏈𦏉𦒛𦒔𦒒𦒖𦒓𦒕𦒏𦔝𦔢𦔡𦗩𦗯𦗠𦗦𦗤𦠩𦠬𦡶𦢃𦡸𦠼𤑃𦡺䑑𦣲𦦞𦦛𦦚𦧶𦧵𦪥𦪓𦪤𦺂𦻇𦾷𧀝𦿢𦿗𦿣𦿤𦾼𦿓䕚𦽚𦿒𦼭䕡𦿄𦿐𦿥𧀅𧀇𦿦𧀛𦿧𦿘𦾾𧀗𦾴𦿙𦿨𦾭𦾯𦿈𦿩𦿪𧇾𧇻𧈀𧑲𧑰𧑆𧑙𧑞𧑈𧑏𧑱𧑠𧑟𧑮𧑳蟡䗗𧑇𧑭𧑯𧒁𧑬𧑸𧒃𧑉𧑨𧑴𧑣𧑪𦢅𧑥𧑢𧓈𧗐𧗔𧗑𧘃𧝂𧝣𧝖𧝛𧝕𧝐𧜭𧝄𧝈𧝅𧞂䙥𧝢𧢅𧢍𧤶𧤯𧤱𧤷𧪧𧫙𧫰𧫲𧫽𧫨𧫫𧫮𧫠𧫩𧫭𧫖𧫸𧫔𧫟𧫧𧫘𧫣𧫯𧬏𧯊𡕆𧰅𧰌𧰍𧱿𧲀𧲁𧴁𧴃𧷲𧷢𧷣𧷞𧷩𧷨𧷠𧸁𧷵𧷤贁𧹷𧽴𧽯𧽢𧽡𧽭𧽳𧽪𧽩𨄒𨄭𨄔𨄏𨄩𨄖𨄃𨅆𨅜𨄑𨄆𨃜𨄫𨄪𨄬𨄧𨉺𨎜𨎙𨎕𨎋𨎘𨎖𨐲𨖤𨖪𨘛䢯𨘍𨘜𨘊𨘠𨘈𨘚𨘓𨘑𨝝𨝟𨝞𨝠𨞻𨞼𨟁𨟀𨟈𨟎𨢧𨢲𨢶𨢥𨢺𨢵𨢭𨢤𨣓𨢰𨢱𨤝𨪍䥁𨪑𨪶𨪷𨪽𨪼𨪖𨪔𨪠𨲨𨲦𨶄𨶖𨶈𨶚𨻑𨻏𨽐𨽑𨽎𨾀𨾁𩀛𩀗𩀠𩀙𩀚𩀡𩀞𩀘𩀝䨩𩄭𩄤𩄧𩄮𩄥𩄜𩄙䨪𩄣𩄟𩄚𩄫𩄞𩇠𩈳𩋦𩋼𩋥𩋸𩋶𩋪𩋲𩋭𩋫𩋰𩋱𩏆𩏈𩏎𩏅䪖𩏋𩔓𩔔𩔇𩔌𩘚䬒𩘗𩘆𩘖𩘇𩘐𩘉𩘙𩘕𩝮𩜻𩜾𩝬𩝥𩝤𩝙𩝼𩝰𩝹𩠬𩡒𩡑𩡌𩤀𩤐𩤓𩣿𩣾𩣭𩩚𩩘𩩜𩫚𩭸𩭞𩭧𩭤𩰓𩰣𩱁𩰿𩱀𩳭𩳣𩳪𩳰𩳦𩳩𩳱𩳮𩷔𩷑𩷜𩷬𪁩𪁭𪁗𪁦𪃹𪁊𪁠𪁖𪁙𪁌𪁪𪁢𪁣𪁰𪁟䳎𪁤𪁝𪁨𪂟𪊺𪊸𪊼𪊹𪊴𪌻𪌭𪌸𪌯𪌲𪑁𪓋𪓞𪓝𪓚𪓜𪔐𪕏𪕉𪖘𪖖𪖗𪗇𠆜𪗚𪗘𪚨𠄊𠆝𠓋𠔸𠔹𠖦𠠣𠠢𠠡𠧎𠮒𠮑𡃬𡃠𡃭𡄈𡃥𡃮𡃤𡃫𡃣𡃪𡄊𡓤𡓑𡓣𡓔𡓐𡓙㙾壡𡓚𡓓𡕌𡕍𡕼𡖀𡗌𡣿𡤈𪔈𡤁嬾𡤀𡫭𡫳𡫮𡫱𡫰𡫞𡭑𡾂𡾩𡾘𡾝𡾚𡾞𡾟𡾦𡾧𢀉𢀯𢅱𢅭𢅲𢋯𢋰𢐻𢐹𢐼𢐸𤯽𢐺𢑸𢑹𢑷𢖘𢖜𢖙𢖚𢤑𢤊𢣽𢤏𢤙𢤃𢤕𢤌𢤉𢤒𢤴𢤪𢤈𢤭𢤨懶𢤬𢤦𢤳㦧𢨚𢨞𢸇𢸬𢸲𢸨𢸱𢸩𢸰𢸪𢹄𢸮𣃑𣄤𣋷𣋴𣋳𣋸𣞵𣞖𣞘𣞕𣞑𣞲𣞞𣝙𣞣𣞚𣞛䌠𦆃𣫚𣫟𣬖𣰟𣰭𣰮𣰫𤂸𤂻𤂿𤂺𤃕𤃣𤃁𤂾𤂽𤂴𤃂𤂡𤂇𤃗𤃊𤂵𤃚𤃙𤂳𤃘𤑧𤑱𤑢𤑜𤑨𤑒𤑖𡄉𠑂𤖠𤜀𤛾𤛿𤣋獺𤣊𤢩𤣈𤪴𤪬𤪪𤪩𤰎𤳩𤳭𤳲𤳫𤳮𤻨𤻛𤻗𤻣𤻥𤻮𤻕𤾳𥀭𥃁𥃃𥌌𥌎𥌇𥌗𥌉𥌖𥎚𥎜𥖴𥖷𥖳𡕎𥜓𥜔𥣦𥣜𥣨𥣥𥨮𥨬𥨫𥫍𥴽𥴲𥴭𥵅𥴾𥵐𥴥𥵓𥴣𥼷𥼸𥽇𥼽𥼾𥽉𥽊𥼝糩𦆥𦆌𦆍𦆉𦆖𦆅𦆏𦅷𦆎𦆂𦆊𦆈𦅻𦆗𦅽𦅹𦉙𦌤𦌭𦌮𦌫罺𦏚𦏘𦏖𦒇𦒠𦔨𦔥𦔟𦗳𦗷𦗶𦠫𦡛𦡈臋𦡜𦡭𦢝𦢙𦢌𦢑𦢛𦢚𦢜𦢕𦢍𦢖𦢥𦤪𦦡𦦤𦦭𦪩𦪮𦪰𧀍𦽣𦽱𧁑𧁔𧀪𧁄𧁕𧂙𧀹𧀿𧀾𧁏𧁍𧀵𧁀𧀱𦿕𧀫𧀳𧁁𧁖𧁌𧁆𧀼𧁅𧁃𧀽𧁰𧁗𧀸𧁘䕥𧀷𧈇𧈆𧈉𧒫𧒐𧒓𧒼𧒩𧒞𧒿𧒪𧒑𧒤𧒚𧒧𧒡𧒜𧒢𧒛𧒟𧒔𧒏𧋰𧒒𧒕𧒥𧒦𧘄𧞜𧝹𧝺𧝾𧝻𧝱𧞁𧝿𧝼𧝽𧝵𧝶𧢎𧢏𧤾𧤿𧥀𧥁䜄𧬍𧬗𧬐𧬎𧫪𧬝𧬃𧬢𧬄𧬀𧬠𧬡𧬣𧬓𧬉𧬑𧯋𧯎𧯐𧯌𧯔𡓛𧰉𧰋𧲅𧴆𧴍䝤𧴔𧴏𧸉𧸔𧸋𧷼𧸄𧸊𧸀𧷽𧸇𧸆𧸑𧹹𧹸𧾈𧾀𧾋𨅙𨅡𨅵䠣𨅕𨅛𨅟𨅒𨅢𨅲𨅝𨅨𨅱𨅥𨅦𨅧𨅶𨅌𨅠𨅴𨅐𨊊𨊁𨊆𨉿𨊀𨎥𨎧𨎨𨎦𨎯𦆕𨎱𨐵𨐹𨗃𨘰𨘪𨘫𨘭𨘮𨘬𨘣𨘡𨘢𨝬𨞁𨞏𨞀𨟐𨟍𨟕𨟔𨣑𨢾𨣋𨣎𨢽𨣐𨬄𨫕𨫐𨫓𨫻𨫘𨫑𨫽𨫙𨫖𨫦𨫹𨶡𨶝𨶞闙𨻿𨽟𨽗𨽠𨽙𩀬𩀥𩀧𩀰𩀤𩀦𩀭𩀮𩀩𩀱𩅅𩅇𩅒𩅊𩅐𩅆𩅏𩅎𩅓𩌡𩌉𩌚𩌛𥌕𩌐䩶𩌔𩌎𩌒𩌋𩏏𩏒𩏐𩏔𩏓𩏑𠬘𩐊𩐉𩔜𩔧𩔥𩔛𩔩𩔟𩔰𩔤𩔨𩔙𩔯𩘤𩘡𩘥𩘦𩘧𩙶𩝦𩝵𩝩𩝾𩞏𩝴𧈐𩞭𩠫𩠪𩡘𩤢𩤝𩤫𩤬𩤭𩤳𩤛𩤧𩤪𩤨𩤻𩩾𩩱𩩹𩩸𩩰𩩭𩩽𩩵𩩶𩫞𩭦𩮃𩮇𩭻𩮉𩮆𩮂𩭼𩮁𩱃𩱅𩳷𩳹𩳺𩸅𩷾𩸏𩷿𩷺𩸉𩸠𩸌𩸼𩸢𩷸𩸊𩸈𩷷𪂎𪁷𪂋𪂴𪂊𪂚𪁹𪂃𪁿𪂡𪂏𪂣𪂁𪂔𪁾𪂂𪂐𪂥𪂭𪂝𪉧𪉩𪋋𪋈𪋑𪍀𪍋𪍉𪍆𪌾𪍃𪌿𪎥𪎧𪑎𪑍𢖛𪓥𪓡𪓢𪔖𪔔鼖𪕙𪕑𪕟𪕊𪕗𪕘𪗊𪗛𪗞𪗠𪗡𪗟𪗢𪗣𪚒𪚿𪚾𠄋𠑒𠑎㒹㒹𠖧𠠦𠠧𠣆𠤋𠥪𠥸𡄚𡄖𡄙𡄗𡄛𡄘𡄠𡈷𡓬𡖁𩈽𡤏𡦭𡫻𡭒𡾯𡾷𡾲𢅸𢆬𢌀𢋾𢌂𢋽𢌃𢑀𢑃𢑂𢒷𢖢𢤀𢤵𢥁𢤅𠑓𢥐𢥎𢥓𢹓𢹐𢹗𢹟𢹕𢻪𣀦𩠰𣀪𣀨𣀬𣀭𣃔𣌇𣍘𣍙𣟅𣟍𣟇𣟨㱋𣤲𪚗𣫞𣰲𠫈瀛𤃱𤃰𤃬𤄉𤃹𤃵𤃾𤑴𤒁𤑼𤑵𤒉𤒂𤒗𤒆𤑽𤒓𤒜𤑶𤒀𤕅𠚡𤜃𤣉𤣍𤣐𤪭𤫅𤮢𤳬𤳶𤳳𤻳𤻺𤻹𤻵𤻴𤻸𤾶𤾴𥀲𥃍𥃈𥃎𥃊𥌪𥌡𥌝𥖽𥖾𥗂䃱𥗉𥜟𥣱𥣭𥣲𥣠𥣷𥨰𥨷𥨱𥨶𥫈𥵭𥵩𥵽𥵡𥵰𥵱𥵳𥵶𥵵𥵸𥵹𥵤𥵮𥽑𥽏𥼢𦆯𦆪𦆣𦆝𦆜𦆡𦆶𦆬𦆢𦆧𦆰𦆩𦇃𦌱𦌲𦌳𦌴𦌷𦌼𦏙𦏝𦏞𦏟𦏠𦒥𦒪𦒢𦒣𦘄𦘃𦗾𦘨𦢯𦢭𦢦𦦬𦦥𦦰𦦱𦨁𦪲𦫒𦫱𦿑𧁇𧃉𧂧𧃈𧂕𧂎𧂑𧁽𧃮𧂍𧂌𧂰𧂖𧁂𧁼𧂱𧂲𧂄𧂝𧂡𧂘𧂳𧀢𧁻𧂊𧃎𧂀𧂗𧂴𧂵𧂚𧂟𧂓𧈒𧈋𧈎𧒣𧓌𧓜𧓡𧓞𧓒𧓛𧓙𧓗𧓔𧓉𧓫𧓚𧓑𧔄𧓊𧓢𧗗𧞌𧞙𧞢𧞖𧞔𧞟䙧𧞎𧠃𧢔𧢓𧥄𧬯𧬵𧬫𧬲𧬭𧬶䜔𧬱𧬒𧬳𧬟𧬴𧭀𧬿𧭁𧯓𧲉𧴘𧴐𧸚𧸥𧸞𧾒𧾕𧾓𨆄𨆚𨆉𨆍𨆈𨆑𨆛𨆜𩕈𨊎𨊒𨏅𨏁𨎸𨎾𨎵𨏇𨐼䢉𨗬𨘿𨘹𨘷𨞡𨞔𨞠𨟞𨟛𨟟𨟜𨣖𨣜𨣝𨣠𨣣𨣡𨬘𨬙𨭓鐕𨭄𨬎𨬏𨭇𨬗𨬝𨬑𨲴𨲶𨶷𨶾𨷂𨶴𨼱𨼕𨾃𩀻𩀶𩁂𩁐𩁀𩀴𩀸𩀽𩀾𩀵𩀺𩁆𩀷𩀹𩁅𩅷𩅝𩅪𩅶𩅫𩅬𩅥𩅤𩅱𩅟𩅲𩇾𩇿𩈾𩌫𩌶𩌭𩌹𩌿𩌻𩌳𩍂𩌺𩏕𩏘𩏙𩐍𩐊𩐹𩐺𩔽𩕅𩔺𩔷𩔾𩕂𩞇𩞌𩞂𩝫𩞠𩞚𩞐𩞎𩞡𩞽𩞲𩞵𩞷𩞪𩞢𩞥𩞮𩞫𩞼𩠲𩡚𩥤𩥜𩥍𩤿𩤾𩥒𩥘𩥗𩥞𩥓𩥔𩥖𨽥𩪄𩪅𩪆𩮗𩮡𩮢䰋鬒𩮣𩮦䰗𩰕𩰤𩱊𩱉𩱈𩱋𩴎𩥢𩴋𩴊𩴈𩴆𩹃𩹇𩸿𩹆𩹐䱭𩹋䱰𩹒𪃗𪂿𪃘𪃠𪃧𪂸𪃕𪃯𪃦𪃤𪃔𪃖𪃪䳭𪂻𪃩𪃎𪉭𪉰𪉳𪉲𪋔𪋎𪋕𪍚𪍘𪍒𪍐𪍕𪍎𪍍𪏋𪏌𪏍𪏑𪑘𪑣𪑞𪑔𪑛𪓩𪓧𪓨𪔝𪕧𪕚𪕜𪕛𪕡𪖡䶍𪖤𪗩𪗯𪗥𪗵𪗫𪗭𪚖𪚕𪚳𪚵𪚱㒧𠑍𠘤𠠫𠧏𠫉𡄶𡄱𡅔𡄺𡄸𡈹𡈸𡓴𡓳𡓶𡓼𡓵𡓱𡚜𡤘𡤚𡫼𡫾𡬃𡭓𡴫𡿆𡿁𢅻𢌆𢑉𢒸𢖣𢥒𢥍𢥟𢥞𢥡𢨣𢺌𢹯𢹵𢹴𢹱𢹰𢹬𣄦𣌈朇𣟰𣟯𣟻𣠐𣠌𣟶𣟾𣩼𣫡𣰸𣱯𤄘𤄕𤄜𤄐𤄵灊𤄗𤄝𤄦𤄨𤄩𤄪𤄚㶖𢌇𤒫𤒬𤒡𤒮𤒣𤒧𤕈𤘄𤛽㹚𤜈𤣒𤫆𤪾𤬜𤮦𤮧𤮫𤰀𤳻𤳼𤳹𤴁𤼆𤼄𤾸𥀴𥌶𥌲𥍂𥌭𥌵𥗘𥜣𥜠𥤁𥤀𥤃𪚼𥨼𥩁𥨵𥫉𥶎𥶞𥶙𥶴𥶪𥶟𥶖𥶝𥷃𥵴𥶫䊯𥽣𥽛𥽚𦇅𦇄𦇏𦇈𦆤𦆽𦇓𦉠𦌻𦏣𦏧𦒨𦒬𦒩𦔪𦘈𦘉𦢹𦫁𦫂𦪸𧃡𧃫𧃥𧃺𧃤𧄋𧃗𧃯𧃠𧃬𧃻𦾱𧃓𧃦𧃼𧃣𧃏𧃚𧃜𧃒𧂤䕫𧈗𧈖𧓲𧓼𧔘𧓶𧓾𧓳𧓷𧔁𧓹𧔆𧓵𧔐𧓟𧔃𧔈𧗘𧘅𧞗𧞳𧞮𧞯𧞧𧞱𧞨𧞬𧞫𧠄𧢚𧥊𧭂𧭘𧭋𧭅𧭉𧭌𧭙𧭖䜛𧭆𧭄𧭛𧭩𧲎𧲏𧴞𦢼𧸯𧾞𧾝𧾟𧾜𨆴𨆮𨆫𨆳𨆯𨇂𨊓𨊕𨊖𨊗𨏏𨏎𨏋𨑋𨙆𨙃𨙅𨙑𨞮𨞯𨞰𨞸𨟢𨟣𨟦𨣭𨣪𨤺𨭙𨮄𨭟𨭼𨭜𨭘𨭕𨭝𨭾𨮀𨮃𨭗䥣𨭞𨲺𨷕𨽘𨽨𨽧𩁊𩁌𩁒𩁉𩁎𩁇𩁍𩁋𩁏𩅾𩆇𩆃𩆉𤫊𩆆𩆈𩉇𩉂𩉉𩉈䪀䪄䩿𩍄𩍑𩍆𩍇𩏤𩐏𩐿𩕋𩕗𩕒䫱𩕑𩕘𩕞𩕓𩕝𩕍𩘼𩘿𩙆𩘾𩙄𩙅𩙁𩘸𩙂𩙃𩙺𩞣𩞺𩟀𩟄𩟆𩟋𩡡𩥺𩥳𩥲𩥴𩦀𩦁驇𩪕𩪏𩪐𩪒𩪔𩪋𩫦𩮲𩮸𩮽𩮾𩯎𩮺𩰖𩰦𩰥𩱌𩱐𩱎𩱑𩱒𩴔𩴕𩴛𩺂𩺙𩺚𩹽𩺅𩺆𩺜𩹹𩹻𩺀𩺃𩺁𪄞𪄛𪄪𪃻𪄅𪄠𪄑𪄄𪃽𪄃𪄐𪄘𪄊𪅎𪄉𪄇𪄜䴜𪉷𪉶𪋘𪋙𪍡𪍜𪍞𪍠𪎪𪏖𪏘𪐉𪐈𪑫𪑥𪑳𪑴𪓭𪔤𪔥𪕪𪖧𪖨𪘋𪗺𪗹𪗿𪘄𪗶𪘅𪚙𪚫𪚽𪚸㒨𠑤𠑚𠕲𠥺𠮕𡅟𡅡𡅞𡅚𡅜𡅝𡅮𡅗𡅘𡔂𡔋𡔈𡕑𡤥𡤨𡤣𡤡𡤤𡤩𡬆𡬊𡿊𡿌𡿋𡿏𢅿𢅼𢇖𢌉𢌋𢍶𢑽𢖥𢥛𢥜𢥝𢥥𢹭𢺂𢺃𢺇𢺕𢺊𣃖𣄨𣌍𣠮㰘𣠭𣠡𣠙𣤻𣤼𣤺𣩾𣰻𤅇𤄹𤄴𤄶𤅆𤄻𤅁𤅅𤓀𤓄𤒺𤒽𤒾𤖣𤜌𤣙𤫍𤮮𤳿𤴤𤾻𤾼𥀶𥃕𥃓𥃖𥌼𥌿𥗞𥗣𥜧𥜩䆋𥤅𥤇𥤌𥫐𥶾𥶼𥷐𥷂𥶵𥷉𥽦䌫𦇕𦇢𦇜𦇣𦇚𦇂𦇨𦏩𦒯𦒭𦒮𦘋𦣁𦦶𦦹𦫀𧂥𧄨𧄢𧄩𧄘𧄟𧄜𧄒𧄪䕺𧄡𧄫𧄗𧃰𧄖𧄣𧔫𧔦𧔽𧔢𧔺𧔱𧔲𧕔𧔡𧔵䘉𧔮𧔰𧔯𧔶𧗚𧗙𧞻𧞼𧞷𧟂𧞽𧞲䙭𥍄𥷑𧭒𧭫𧭧䜟𧭡𧭬𧭑𧭴𧭷𧭯𧭭𧭨變𧮄𧲐𧲒𧲓𧴟𧸱𧸸𧸹𧸷𧾫𨇃𨇈𨇋𨇏𨊘𨏔𨏓𨏘𨏙𨏝𨏑𨏖𨐾𨙘𨙗𨙕𨟄𨟤𨣮𨣯𨣳𨣲𨣰𨮫𨮚𨮯𨮗䥪䥬𨮿𨮔𨮓𨲾𨲻𨲼𨷙𨽬𩁓𩁔𩁛𩁘𩁖𩁗𩆟𩆔𩆖𩆗𩉊𩉋𩍕𩍚𩍞𩍟𩍙𩍛𩍘𩍔𩏨𩏫𩏬𩐓𩕦𩕫𩕢𩕩𩕣𩕟𩕎𩙈𩙊𩟣𩟛𩟘𩟖𩦆𩦐𩦑𩦈𩦔𩦃䮲𩦜𩦍𩦅𩪘𩪚𩪜𩫧𩯅𩯐𩯑𩰙𩰧䰝𩱔𩴭𩴢䰭𩴟𩺽𩺮𩺻𩺭𩺼𩺹𩺴𩻋𩺷𩺳𩺺𩺶𩻍𩺾𩻉鱀𪅟𪄻𪅡𪅜𪆊𪅚𪅍𪅆𪅄𪄽𪅀𪄼𪆌𪅁𪅌𪄾𪅊𪉼𪉺𪍥𪍮𪍩𪍪𪍯𪍬𪍰𪍨𪍫䵅𪎬𪏞𪏝𪏜𤓅𪒌𪒃𪒂𪒈𪒅𪒁𪓵𪓯𪓴𪓲𪓶𪓳𪓰𪓱𪔫𪔧𪔨𪕬𪖮𪘎𪘛𪘖𪘟𪘢𪘠𪘡𪘔𪘓𪚝𪚜𪚞𪚟𠑫𠣈𡅸𡅶𡅰𡅱𡔊𡤬𢌑𢑾𢥗𢥦𢥩𢥧𢥨𢥱𢥯𪎮𢺋𢺖𣀵𣀺𣁫𣌖𣡃𣡆𣠿𣠻𣠽𣦱𣦲𣫤𣱀𤅝𤅑𤅍𤅌𤅛𤓔𤓒𤓊𤓈𤓐𤓉𤕉𤖤𤜒𤜏𤜑𤣛𤣝𤣜𤫔𤬡𤮲𤴄𤴂𤼑𤼖𤼐𤾾𧈚𥍌𥍊𥗲𥗱𥜪𥜬𥜯𥜱𥤑𥤒𥩋𥷛𥷖𥷝𥷢𥷤𥷠𥷟𥷣𥽮𥽱𦇩𦇪𦉣𦏫𦏪𦢽𦢻𦣆𦣌𦣈𤓏𦣉𦣊𦣏𦣐𦦺𦧻𦨃𦫄𧅉𧅓𧄶𧅊𧅁𧅎𧄙𧅍𧅅𧅌𧅇𧅈𧅏𧄷𧅂𧕊𧕆䘊𧕉𧕏𧕑𧕌䘌𧘆𧥑𧭺𧮃𧭾𧭿𧮀𤮵𧲑𧲔𧲕𧴡𧴠𧸽𧹀𧹆𧺄𧾬𧾭䠮𨇚𨇙𨏢𨏡𨏣𨏤𨏞𨏟𨙁𨙙𨙜𨙚𨟨𨣶𨣼𨣷𨣺𨣹𨯄𨮾𨯒𨮽𨮕𨯔𨷤𩁡𩁢𩁣𩁤𩁠𩁜𩁝𩁞𩆥𩆦𩉌𩍨𩍮𩍱𩍫𩍯𩏮𩏱𩏰𩏯𩐕𩐔𨽮𩑇𩕼𩕺𩕴𩕶𩕮𩕸𩕭𩕬𩙎𩟗𩟪𩟥𩡤𩦳𩦣𩦥𩦧𩦢𩦟𩦦𩦞𩦨𩪩𩪦𩪪𩪫𩪠𩪡𩪨𩫨𩫩𩫪𩯤𩯟𩯠𩰨𩱝𩱛𩱖𩱗𩱟𩱢𩻡𩻣𩻞𩻙𩻨𩻲𩻶𩻦𩻴𩻵𩻥𩻢䲍𪆖𪆪𪆗𪅰𪆡𪆝𪆩𪅯𪅾𪆔𪆚𪅳𪆇𪅱𪅷𪆈𪆍𪆅𪅸𪆭𪊀𪊁𪋥𪋧𪍼𪎯𪏡𪒖𪒣𪒐𪓸𪔮𪕵𪕾𪕽𪕻𪕸𪖰𪘴𪘶𪘰𪘀𪘮𪘷𠫎𠮗𠮘𡅼𡅾𡅽𡅿𡔏𡚤𡤱𡤮𡤯𡤲𡴬𡿔𡿜𡿘𢀎𢆃𢆄𢌒𢥲𢥼𢥾𢺦𢺠𢺞𢺢𣄪𣌘𣡍𣡘𣡛𥤔㱎𣫦𤅤𤅨𤅠𤅢𤓗𤕋𤣠𤬢𤴅𤼙𤼚𤿃𥃘𥃜𥍏𥗷𥗴𥗸𥤓𥩐𥷰𥷩𥷯𥷭𥷪𥷸𥸅𥷲𥽲䌱𦇹𦇳𦇶𦇲𦇷𦉦𦉥𦍀𦌾𦣑𦣓𦨄𧅙𧅠𧅛𧅝𧅞𧅩𧅜𧅡𧅪𧅟𧕎𧕜𧕠𧕣𧗛𧟊𧟉𧟈𧢥𧢣𧮎𧮇𧮉𧮅𧮆𧮏𧮊𧮐𧲘𧲛𧹇𧹉𧹈𨇥𨇪𨇭𨏰𨏯𨏲𨏬𨑀𨙠𨙟𨟫𨟬𨤀𨤁𨯢𨯟𨯠𨯡𨯣𨳃𨷫𨷪𨽡𨽱𩁫𩁨𩁩𩁧𩆰𩆱𩆲𩉑𩍴𩕿𩖄𩙓𩙔𩟰𩟴𩟱𩠷𩦿䮼䮻𩦽𩦾𩦷𩧀𩦺𩦸𩦼𩦻𩫫𩯧𩰝𩰜𩱣𢑋𩴸𩴶𩴴𩴷𩼆𩼜𩼂𩼝𩼞𩻠𩼑𩼘𩼡𩼠𩼐𪆽𪆮𪆵𪆸𪆹𪆾𪇄𪆼𪆺𪊃𪋪𪋬𪍾𪍻𪏥𪒧𪒞𪒚𪔯𪔱𪖃𪖵𪙆𪙁𪙃𪘽𪙂𪘻𪙇𠥫𠧐𡆎𡬑𡬘𢆃𢑌𣄫𣡏𣡞𣡣𣡫𣤿𣫩𤅭𤅫𤅪𩼪𤓠𤓝𤓡𤓞𤓜𤕍𤜖𤣣𤫥𤫤𤼝𥝋𪛁𥩎𥫖𥷽𥸇𥷾𥸃𥸋𥷿𥽺𥽷𦇻𦍃𦏭𦦼𤓟𦨅𦫆𦫅𧅴𧅳𧅲𧅸𧕳𧕺𤼜𧕶𧕵𧕷𧟏𧟎𧟍𧢨𧮘𧮒𧮔𧲚𧾴𨇫𨇴𨇳𨇮𨇲𨇰𨙤𨰅𨯻𨯼𨷳𩁬𩆺𩍹𩍾𩍸𩏴𩐖𩙗𩧈𩧃𩧎𩪳𩫭𩫮𩯱𩯸䰔𩱤𩱥𩼵𩼩𩼬𩼱𩼫𩼮𩼥𪇚𪇠𪇔𪇡𪇝𪏧𪐒𪒮𪒯𪖆𪖅𪗑𪙘𪘿𪙒𪙋𪙓𪙐𪙖𪙗𪛍𪛋𠠱𠠰𠧑㘜𡆒𡆐𡆏𡆓𡆑㝲𢌓𢑿𢺬𤅰𤓥𤬣𤬤𤴈𤴇𤼟𤼠𤼡𥃡𥍔𥍗𥍕𥗺𥜸𧆆𥜷𥩓𥽾𥽻䌴𦇽𦇼𦍆𦤲𦫇𧅱𧆃𧅯𧅾𧅽𧆁𧅼𧖊𧕾𧖀𧕽𧕿𧖂𧟔𧟐𧟒𧥕𧮛𧮚𧹎𨇱𨏸𨏽𨙞𨙦𨤅𨰊𨰋𨰎𨰍𨰑𨰉𨰐𨰌𨰒𨳄𨷷𨷸𨽪𨽫𩇀𩇂𩎅𩎀𩎄𩎁𩇄𩖋𩖍𩖎𩪷𩪹𩫯𩯻𩯿𩱧𩱨𩱩𩴿𩼼𩽌𩼽𩼾𩼿𩼻𪇿䴎𪇶𪇮𪇼𪇸𪈁𪇺𪊈𪎀𪐑𪓾𪔀𪔵𥀼𪖽𪖻𪙜𪙚𪛑𪛐𠨋𣡳𡤹𡬚𡿣𢆇𢍷𢍸𢺱𣡪𣡴𣫫𤅻𤖥𤣤𤴋𤴎𤴌𥜹𥸐𥸑𥸖𥾁𥾀𦇾𦈁𦈀𦍇𦏯𧆈𧖋𧖍𧖇𧖅𧖏𧖄𧖈𧖎𧖌𧟗𧟖𧮟𢆈𨈃𨏾𨟯𨤉𨤈𨰠𨰤𨰟𩁰𩉖𩏷𩏸𩏶𩖐𩙞𩟷𩧖𩧕𩪻𩫰𩰁𩱬𩵁𩵂𩽏𩽓𩽘𪈒𪈄𪈓𪈅𪈍𪎂𪎁𪒵𪒺𪖾𪙥𪙣𪙮𪙪𪙬𪛄𠥬𧮥𡬒𢑍𣌟𣡷𣡸𣱄𤅼𤓫𤓪𤓬𤖧𤜙𤼣癴𥘁𥸜𦈃𦍈𦣚𧆑𧆒𧖖𧖗𧖑𧖓𧟘𧟕𧥗𧥘𧮢𨈅𨏺𨤌𨤊𨰮𨰩𨰯𨳅𩁲𩇈𩉚𩉙𩎉𩎈𩧛𩧙𩧚𩪼䯬𩰆𩰅𩱯𩱭𩱰𩽠𩽞𩽣𩽚𩽟𪈙𪈝𪈛𪈢𪈜𪈞𪋷𪎄𪐕𪒸𪓁𪔁𪔊𪗒𪙵𪙴𪙲𠫐𡬛𢀐𢍹𤓭𤮹𤼤𤿅𥘃𦍊𦣷𦧁𦧂𧆔𧖙𧖚𧥚𧮨𧰥𧲞𨑁𨰲𨰳𩇋𩇊𩇌𩖓𩧠𩱱𩽫𪈫𪈩𪈚𪈦𪈧𪈪𪋸𥀽𪙶𡔘𣡺𤅿䉹𦏰𧆚𧆖𧖞𧖝𧗜𧟚𨈋𨐂𨑂𨙧𩎋𩧢𩽱𪈯𪈮𪓆𪖎𪙻𪙷𪙸𪙼𣡼𦈇𧆗𧯙𨤍𨤎𩧣𩧤𩫳𩱵𪈷𪈵𪖏𪚀𪙿𪛔𢺴𪏬𨽵𤴐𦉩𧖤𧟛𩇑𩎍𩽶𪈹𪚄𪚂𠫒𤓮𤴑𥾄𨽴𩎏𩎎𪈻𠣋𪚈𪚆𣍜𥎤𩁵𪚋𡔙𧲟𩧥𪉀𤴒𪋻𪚌𥎥𩱷𩱸𪛖𧟟𩇒𡔚𧮩𩇓𪓊𦧄𧢱𩙤")
+		return ioutil.NopCloser(r), nil
+	})
+}
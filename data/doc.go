@@ -3,4 +3,8 @@
 // package as a side effect of its import. To use:
 //
 //	import _ "code.google.com/p/go-charset"
+//
+// The datafiles package does the same with go:embed,
+// reading the data files themselves; only one of the
+// two need be imported.
 package data
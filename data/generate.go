@@ -73,12 +73,26 @@ func main() {
 		fatalf("cannot read datafiles dir: %v", err)
 	}
 	for _, name := range names {
+		if !isDataFile(name) {
+			// Such as the Go source of the datafiles package.
+			continue
+		}
 		writeFile("data_"+name+".go", tmpl, info{
 			Path: filepath.Join(dataDir, name),
 		})
 	}
 }
 
+// isDataFile reports whether name is one of the files
+// read by the charset package.
+func isDataFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".dat", ".cp":
+		return true
+	}
+	return name == "charsets.json"
+}
+
 func writeFile(name string, t *template.Template, data interface{}) {
 	w, err := os.Create(name)
 	if err != nil {
@@ -0,0 +1,42 @@
+// The datafiles package embeds the charset data files using go:embed
+// and registers them with the charset package as a side effect of
+// its import, so that a binary needs no external files. To use:
+//
+//	import _ "github.com/suapapa/go-charset/datafiles"
+//
+// Files set with charset.SetDataFS take precedence over the
+// embedded ones, so a directory can still be used to override
+// them, for example with charset.SetDataFS(os.DirFS(dir)).
+//
+// It is an alternative to the data package, which holds the same
+// files as generated Go source: a program needs only one of them.
+// With both, each file is registered twice, and the registration
+// made last, by whichever package is initialized later, is the one
+// used; as the contents are the same, it makes no difference.
+package datafiles
+
+import (
+	"embed"
+	"io"
+	"io/fs"
+
+	"github.com/suapapa/go-charset/charset"
+)
+
+// FS holds the embedded data files.
+//
+//go:embed *.dat *.cp charsets.json
+var FS embed.FS
+
+func init() {
+	names, err := fs.Glob(FS, "*")
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range names {
+		name := name
+		charset.RegisterDataFile(name, func() (io.ReadCloser, error) {
+			return FS.Open(name)
+		})
+	}
+}
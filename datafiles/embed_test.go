@@ -0,0 +1,21 @@
+package datafiles_test
+
+import (
+	"testing"
+
+	"github.com/suapapa/go-charset/charset"
+	_ "github.com/suapapa/go-charset/datafiles"
+)
+
+func TestEmbedded(t *testing.T) {
+	out, err := charset.DecodeString("cp949", "\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee \xbf\xec\xb8\xae\xb8\xbb")
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if out != "아름다운 우리말" {
+		t.Errorf("expected %q got %q", "아름다운 우리말", out)
+	}
+	if _, err := charset.EncodeString("koi8-r", "привет"); err != nil {
+		t.Errorf("encode failed: %v", err)
+	}
+}